@@ -0,0 +1,43 @@
+// api/handlers/achievement.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"good_blast/errors"
+	"good_blast/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AchievementHandler handles achievement requests.
+type AchievementHandler struct {
+	Service services.AchievementServiceInterface
+}
+
+// NewAchievementHandler creates a new instance of AchievementHandler.
+func NewAchievementHandler(service services.AchievementServiceInterface) *AchievementHandler {
+	return &AchievementHandler{
+		Service: service,
+	}
+}
+
+// GetAchievements lists every achievement with the user's progress and unlock time.
+func (h *AchievementHandler) GetAchievements(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	achievements, err := h.Service.GetAchievements(ctx, userID)
+	if err != nil {
+		log.Println("GetAchievements error:", err)
+		if err == errors.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		respondInternalError(c, err, "could not fetch achievements")
+		return
+	}
+
+	c.JSON(http.StatusOK, achievements)
+}
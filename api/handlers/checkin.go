@@ -0,0 +1,83 @@
+// api/handlers/checkin.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"good_blast/errors"
+	"good_blast/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CheckInHandler handles daily check-in requests.
+type CheckInHandler struct {
+	Service services.CheckInServiceInterface
+}
+
+// NewCheckInHandler creates a new instance of CheckInHandler.
+func NewCheckInHandler(service services.CheckInServiceInterface) *CheckInHandler {
+	return &CheckInHandler{
+		Service: service,
+	}
+}
+
+// respondCheckInError answers the client errors shared by the check-in endpoints.
+func respondCheckInError(c *gin.Context, err error, msg string) {
+	switch err {
+	case errors.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.ErrAlreadyCheckedIn:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.ErrStreakNotLapsed, errors.ErrStreakNotRestorable, errors.ErrNotEnoughCoins:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondInternalError(c, err, msg)
+	}
+}
+
+// GetCheckIn returns the user's streak, the calendar and what the next check-in pays.
+func (h *CheckInHandler) GetCheckIn(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	status, err := h.Service.GetCheckIn(ctx, userID)
+	if err != nil {
+		log.Println("GetCheckIn error:", err)
+		respondCheckInError(c, err, "could not fetch check-in")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// CheckIn claims today's check-in reward.
+func (h *CheckInHandler) CheckIn(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	result, err := h.Service.CheckIn(ctx, userID)
+	if err != nil {
+		log.Println("CheckIn error:", err)
+		respondCheckInError(c, err, "could not check in")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RestoreStreak pays coins to restore a lapsed streak.
+func (h *CheckInHandler) RestoreStreak(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	status, err := h.Service.RestoreStreak(ctx, userID)
+	if err != nil {
+		log.Println("RestoreStreak error:", err)
+		respondCheckInError(c, err, "could not restore streak")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
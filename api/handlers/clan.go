@@ -0,0 +1,221 @@
+// api/handlers/clan.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"good_blast/errors"
+	"good_blast/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClanHandler handles clan requests.
+type ClanHandler struct {
+	Service services.ClanServiceInterface
+}
+
+// NewClanHandler creates a new instance of ClanHandler.
+func NewClanHandler(service services.ClanServiceInterface) *ClanHandler {
+	return &ClanHandler{
+		Service: service,
+	}
+}
+
+// clanActorRequest names the user acting on a clan.
+type clanActorRequest struct {
+	UserID string `json:"userId" binding:"required"`
+}
+
+// respondClanError answers the client errors shared by the clan endpoints.
+func respondClanError(c *gin.Context, err error, msg string) {
+	switch err {
+	case errors.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.ErrClanNotFound, errors.ErrClanMemberNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.ErrAlreadyInClan, errors.ErrClanFull:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.ErrClanPermissionDenied:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.ErrNotInClan, errors.ErrClanLeaderMustTransfer, errors.ErrInvalidClanName, errors.ErrInvalidClanRole:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.ErrInvalidCursor:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+	default:
+		respondInternalError(c, err, msg)
+	}
+}
+
+// CreateClan creates a clan led by the requesting user.
+func (h *ClanHandler) CreateClan(c *gin.Context) {
+	var req struct {
+		UserID  string `json:"userId" binding:"required"`
+		Name    string `json:"name" binding:"required"`
+		Country string `json:"country"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId and name are required"})
+		return
+	}
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	clan, err := h.Service.CreateClan(ctx, req.UserID, req.Name, req.Country)
+	if err != nil {
+		log.Println("CreateClan error:", err)
+		respondClanError(c, err, "could not create clan")
+		return
+	}
+
+	c.JSON(http.StatusOK, clan)
+}
+
+// SearchClans returns one page of clans whose name starts with ?name=, optionally only those of
+// ?country=.
+func (h *ClanHandler) SearchClans(c *gin.Context) {
+	page, ok := parsePageRequest(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	clans, err := h.Service.SearchClans(ctx, c.Query("name"), c.Query("country"), page)
+	if err != nil {
+		log.Println("SearchClans error:", err)
+		respondClanError(c, err, "could not search clans")
+		return
+	}
+
+	c.JSON(http.StatusOK, clans)
+}
+
+// GetClan returns a clan with its members.
+func (h *ClanHandler) GetClan(c *gin.Context) {
+	clanID := c.Param("clanId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	clan, err := h.Service.GetClan(ctx, clanID)
+	if err != nil {
+		log.Println("GetClan error:", err)
+		respondClanError(c, err, "could not fetch clan")
+		return
+	}
+
+	c.JSON(http.StatusOK, clan)
+}
+
+// JoinClan adds the requesting user to a clan.
+func (h *ClanHandler) JoinClan(c *gin.Context) {
+	clanID := c.Param("clanId")
+	var req clanActorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId is required"})
+		return
+	}
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	clan, err := h.Service.JoinClan(ctx, clanID, req.UserID)
+	if err != nil {
+		log.Println("JoinClan error:", err)
+		respondClanError(c, err, "could not join clan")
+		return
+	}
+
+	c.JSON(http.StatusOK, clan)
+}
+
+// LeaveClan takes the requesting user out of a clan.
+func (h *ClanHandler) LeaveClan(c *gin.Context) {
+	clanID := c.Param("clanId")
+	var req clanActorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId is required"})
+		return
+	}
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	if err := h.Service.LeaveClan(ctx, clanID, req.UserID); err != nil {
+		log.Println("LeaveClan error:", err)
+		respondClanError(c, err, "could not leave clan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Left clan",
+		"clanId":  clanID,
+		"userId":  req.UserID,
+	})
+}
+
+// KickMember removes a member from a clan on behalf of an officer or the leader.
+func (h *ClanHandler) KickMember(c *gin.Context) {
+	clanID := c.Param("clanId")
+	var req struct {
+		UserID   string `json:"userId" binding:"required"`
+		MemberID string `json:"memberId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId and memberId are required"})
+		return
+	}
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	if err := h.Service.KickMember(ctx, clanID, req.UserID, req.MemberID); err != nil {
+		log.Println("KickMember error:", err)
+		respondClanError(c, err, "could not remove clan member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Member removed",
+		"clanId":   clanID,
+		"memberId": req.MemberID,
+	})
+}
+
+// SetMemberRole changes a member's role on behalf of the leader.
+func (h *ClanHandler) SetMemberRole(c *gin.Context) {
+	clanID := c.Param("clanId")
+	memberID := c.Param("memberId")
+	var req struct {
+		UserID string `json:"userId" binding:"required"`
+		Role   string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId and role are required"})
+		return
+	}
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	member, err := h.Service.SetMemberRole(ctx, clanID, req.UserID, memberID, req.Role)
+	if err != nil {
+		log.Println("SetMemberRole error:", err)
+		respondClanError(c, err, "could not change clan role")
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// GetClanLeaderboard returns one page of clans by the total level of their members.
+func (h *ClanHandler) GetClanLeaderboard(c *gin.Context) {
+	page, ok := parsePageRequest(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	clans, err := h.Service.GetClanLeaderboard(ctx, page)
+	if err != nil {
+		log.Println("GetClanLeaderboard error:", err)
+		respondClanError(c, err, "failed to retrieve clan leaderboard")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"leaderboard": clans.Items,
+		"count":       len(clans.Items),
+		"nextCursor":  clans.NextCursor,
+	})
+}
@@ -0,0 +1,75 @@
+// api/handlers/clan_tournament.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"good_blast/errors"
+	"good_blast/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClanTournamentHandler handles requests about clans competing in tournaments.
+type ClanTournamentHandler struct {
+	Service services.ClanTournamentServiceInterface
+}
+
+// NewClanTournamentHandler creates a new instance of ClanTournamentHandler.
+func NewClanTournamentHandler(service services.ClanTournamentServiceInterface) *ClanTournamentHandler {
+	return &ClanTournamentHandler{
+		Service: service,
+	}
+}
+
+// respondClanTournamentError answers the client errors shared by the clan tournament endpoints.
+func respondClanTournamentError(c *gin.Context, err error, msg string) {
+	switch err {
+	case errors.ErrTournamentNotActive:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tournament is not active"})
+	case errors.ErrClanNotEntered:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.ErrClanAlreadyEntered:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondClanError(c, err, msg)
+	}
+}
+
+// EnterTournament enters a clan in a tournament on behalf of its leader or an officer.
+func (h *ClanTournamentHandler) EnterTournament(c *gin.Context) {
+	clanID := c.Param("clanId")
+	tournamentID := c.Param("tournamentId")
+	var req clanActorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId is required"})
+		return
+	}
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	standing, err := h.Service.EnterTournament(ctx, clanID, tournamentID, req.UserID)
+	if err != nil {
+		log.Println("EnterClanTournament error:", err)
+		respondClanTournamentError(c, err, "could not enter clan in tournament")
+		return
+	}
+
+	c.JSON(http.StatusOK, standing)
+}
+
+// GetStanding returns a clan's rank in its tournament group and its members' contributions.
+func (h *ClanTournamentHandler) GetStanding(c *gin.Context) {
+	clanID := c.Param("clanId")
+	tournamentID := c.Param("tournamentId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	standing, err := h.Service.GetStanding(ctx, clanID, tournamentID)
+	if err != nil {
+		log.Println("GetClanTournamentStanding error:", err)
+		respondClanTournamentError(c, err, "could not fetch clan tournament standing")
+		return
+	}
+
+	c.JSON(http.StatusOK, standing)
+}
@@ -0,0 +1,21 @@
+// api/handlers/errors.go
+package handlers
+
+import (
+	"net/http"
+
+	"good_blast/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondInternalError answers 503 when the failure was caused by load or contention
+// (throttling, transaction conflicts) so clients know to retry, and 500 with msg otherwise.
+func respondInternalError(c *gin.Context, err error, msg string) {
+	if errors.IsRetryable(err) {
+		c.Header("Retry-After", "1")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service is busy, please retry"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+}
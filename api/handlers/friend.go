@@ -0,0 +1,130 @@
+// api/handlers/friend.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"good_blast/errors"
+	"good_blast/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FriendHandler handles friend list requests.
+type FriendHandler struct {
+	Service services.FriendServiceInterface
+}
+
+// NewFriendHandler creates a new instance of FriendHandler.
+func NewFriendHandler(service services.FriendServiceInterface) *FriendHandler {
+	return &FriendHandler{
+		Service: service,
+	}
+}
+
+// respondFriendError answers the client errors shared by the friend endpoints.
+func respondFriendError(c *gin.Context, err error, msg string) {
+	switch err {
+	case errors.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.ErrFriendNotFound, errors.ErrFriendRequestNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.ErrFriendshipExists, errors.ErrFriendListFull:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.ErrFriendRequestNotAllowed:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.ErrCannotFriendSelf, errors.ErrUserBlocked:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondInternalError(c, err, msg)
+	}
+}
+
+// GetFriends returns the user's friends, pending requests and blocked users.
+func (h *FriendHandler) GetFriends(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	friends, err := h.Service.GetFriends(ctx, userID)
+	if err != nil {
+		log.Println("GetFriends error:", err)
+		respondFriendError(c, err, "could not fetch friends")
+		return
+	}
+
+	c.JSON(http.StatusOK, friends)
+}
+
+// SendRequest asks another user to be the user's friend.
+func (h *FriendHandler) SendRequest(c *gin.Context) {
+	userID := c.Param("userId")
+	var req struct {
+		FriendID string `json:"friendId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "friendId is required"})
+		return
+	}
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	friendship, err := h.Service.SendRequest(ctx, userID, req.FriendID)
+	if err != nil {
+		log.Println("SendFriendRequest error:", err)
+		respondFriendError(c, err, "could not send friend request")
+		return
+	}
+
+	c.JSON(http.StatusOK, friendship)
+}
+
+// AcceptRequest accepts a friend request the user received.
+func (h *FriendHandler) AcceptRequest(c *gin.Context) {
+	userID := c.Param("userId")
+	friendID := c.Param("friendId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	friendship, err := h.Service.AcceptRequest(ctx, userID, friendID)
+	if err != nil {
+		log.Println("AcceptFriendRequest error:", err)
+		respondFriendError(c, err, "could not accept friend request")
+		return
+	}
+
+	c.JSON(http.StatusOK, friendship)
+}
+
+// RemoveFriend unfriends a user, declines or withdraws a request, or lifts a block.
+func (h *FriendHandler) RemoveFriend(c *gin.Context) {
+	userID := c.Param("userId")
+	friendID := c.Param("friendId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	if err := h.Service.RemoveFriend(ctx, userID, friendID); err != nil {
+		log.Println("RemoveFriend error:", err)
+		respondFriendError(c, err, "could not remove friend")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Friend removed",
+		"userId":   userID,
+		"friendId": friendID,
+	})
+}
+
+// BlockUser blocks another user, ending any friendship or request with them.
+func (h *FriendHandler) BlockUser(c *gin.Context) {
+	userID := c.Param("userId")
+	friendID := c.Param("friendId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	friendship, err := h.Service.BlockUser(ctx, userID, friendID)
+	if err != nil {
+		log.Println("BlockUser error:", err)
+		respondFriendError(c, err, "could not block user")
+		return
+	}
+
+	c.JSON(http.StatusOK, friendship)
+}
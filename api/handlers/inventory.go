@@ -0,0 +1,183 @@
+// api/handlers/inventory.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"good_blast/errors"
+	"good_blast/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InventoryHandler handles requests for the items a user holds.
+type InventoryHandler struct {
+	Service services.InventoryServiceInterface
+}
+
+// NewInventoryHandler creates a new instance of InventoryHandler.
+func NewInventoryHandler(service services.InventoryServiceInterface) *InventoryHandler {
+	return &InventoryHandler{
+		Service: service,
+	}
+}
+
+// boosterRequest defines the expected payload for buying or using boosters.
+type boosterRequest struct {
+	BoosterID string `json:"boosterId" binding:"required"`
+	Quantity  int    `json:"quantity"` // defaults to 1
+}
+
+// respondInventoryError answers the client errors shared by the inventory endpoints.
+func respondInventoryError(c *gin.Context, err error, msg string) {
+	switch err {
+	case errors.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.ErrUnknownBooster:
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown booster"})
+	case errors.ErrInvalidQuantity, errors.ErrNotEnoughCoins, errors.ErrNotEnoughBoosters, errors.ErrBoosterLimitReached,
+		errors.ErrNoLivesLeft, errors.ErrLivesFull:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondInternalError(c, err, msg)
+	}
+}
+
+// GetBoosters lists the boosters a user holds next to the shop catalog.
+func (h *InventoryHandler) GetBoosters(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	boosters, err := h.Service.GetBoosters(ctx, userID)
+	if err != nil {
+		log.Println("GetBoosters error:", err)
+		respondInventoryError(c, err, "could not fetch boosters")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"userId":   userID,
+		"boosters": boosters,
+	})
+}
+
+// PurchaseBooster buys boosters with coins.
+func (h *InventoryHandler) PurchaseBooster(c *gin.Context) {
+	userID := c.Param("userId")
+
+	var req boosterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "boosterId is required"})
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	purchase, err := h.Service.PurchaseBooster(ctx, userID, req.BoosterID, req.Quantity)
+	if err != nil {
+		log.Println("PurchaseBooster error:", err)
+		respondInventoryError(c, err, "could not purchase boosters")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Boosters purchased successfully",
+		"userId":    userID,
+		"boosterId": purchase.BoosterID,
+		"quantity":  purchase.Quantity,
+		"cost":      purchase.Cost,
+		"coins":     purchase.Coins,
+		"count":     purchase.Count,
+	})
+}
+
+// ConsumeBooster uses boosters during a level.
+func (h *InventoryHandler) ConsumeBooster(c *gin.Context) {
+	userID := c.Param("userId")
+
+	var req boosterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "boosterId is required"})
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	left, err := h.Service.ConsumeBooster(ctx, userID, req.BoosterID, req.Quantity)
+	if err != nil {
+		log.Println("ConsumeBooster error:", err)
+		respondInventoryError(c, err, "could not use boosters")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Boosters used successfully",
+		"userId":    userID,
+		"boosterId": req.BoosterID,
+		"quantity":  req.Quantity,
+		"count":     left,
+	})
+}
+
+// GetLives returns the user's lives, when the next one regenerates and any unlimited-lives boost.
+func (h *InventoryHandler) GetLives(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	status, err := h.Service.GetLives(ctx, userID)
+	if err != nil {
+		log.Println("GetLives error:", err)
+		respondInventoryError(c, err, "could not fetch lives")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"userId": userID,
+		"lives":  status,
+	})
+}
+
+// ConsumeLife spends a life when a level starts.
+func (h *InventoryHandler) ConsumeLife(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	status, err := h.Service.ConsumeLife(ctx, userID)
+	if err != nil {
+		log.Println("ConsumeLife error:", err)
+		respondInventoryError(c, err, "could not use a life")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Life used successfully",
+		"userId":  userID,
+		"lives":   status,
+	})
+}
+
+// RefillLives buys lives back up to the maximum with coins.
+func (h *InventoryHandler) RefillLives(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	status, err := h.Service.RefillLives(ctx, userID)
+	if err != nil {
+		log.Println("RefillLives error:", err)
+		respondInventoryError(c, err, "could not refill lives")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Lives refilled successfully",
+		"userId":  userID,
+		"lives":   status,
+	})
+}
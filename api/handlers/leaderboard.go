@@ -4,8 +4,10 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"strconv"
 
 	"good_blast/errors"
+	"good_blast/models"
 	"good_blast/services"
 
 	"github.com/gin-gonic/gin"
@@ -23,44 +25,78 @@ func NewLeaderboardHandler(service services.LeaderboardServiceInterface) *Leader
 	}
 }
 
-// GetGlobalLeaderboard retrieves the top users globally based on level.
+// parsePageRequest reads the optional ?limit= and ?cursor= query parameters.
+func parsePageRequest(c *gin.Context) (models.PageRequest, bool) {
+	var page models.PageRequest
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return page, false
+		}
+		page.Limit = limit
+	}
+	page.Cursor = c.Query("cursor")
+	return page, true
+}
+
+// GetGlobalLeaderboard retrieves the top users globally based on level, one page at a time.
 func (h *LeaderboardHandler) GetGlobalLeaderboard(c *gin.Context) {
+	page, ok := parsePageRequest(c)
+	if !ok {
+		return
+	}
+
 	ctx := c.Request.Context()
 
-	users, err := h.Service.GetGlobalLeaderboard(ctx)
+	users, err := h.Service.GetGlobalLeaderboard(ctx, page)
 	if err != nil {
 		log.Println("Error retrieving global leaderboard:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve global leaderboard"})
+		if err == errors.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+		respondInternalError(c, err, "failed to retrieve global leaderboard")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"leaderboard": users,
-		"count":       len(users),
+		"leaderboard": users.Items,
+		"count":       len(users.Items),
+		"nextCursor":  users.NextCursor,
 	})
 }
 
-// GetCountryLeaderboard retrieves the top users in a specific country based on level.
+// GetCountryLeaderboard retrieves the top users in a specific country based on level, one page at a time.
 func (h *LeaderboardHandler) GetCountryLeaderboard(c *gin.Context) {
 	countryCode := c.Query("countryCode")
 	if countryCode == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "countryCode is required"})
 		return
 	}
+	page, ok := parsePageRequest(c)
+	if !ok {
+		return
+	}
 
 	ctx := c.Request.Context()
 
-	users, err := h.Service.GetCountryLeaderboard(ctx, countryCode)
+	users, err := h.Service.GetCountryLeaderboard(ctx, countryCode, page)
 	if err != nil {
 		log.Println("Error retrieving country leaderboard:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve country leaderboard"})
+		if err == errors.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+		respondInternalError(c, err, "failed to retrieve country leaderboard")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"leaderboard": users,
+		"leaderboard": users.Items,
 		"countryCode": countryCode,
-		"count":       len(users),
+		"count":       len(users.Items),
+		"nextCursor":  users.NextCursor,
 	})
 }
 
@@ -77,7 +113,7 @@ func (h *LeaderboardHandler) GetTournamentLeaderboard(c *gin.Context) {
 	entries, err := h.Service.GetTournamentLeaderboard(ctx, groupId)
 	if err != nil {
 		log.Println("Error retrieving tournament leaderboard:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve tournament leaderboard"})
+		respondInternalError(c, err, "failed to retrieve tournament leaderboard")
 		return
 	}
 
@@ -109,7 +145,7 @@ func (h *LeaderboardHandler) GetTournamentRank(c *gin.Context) {
 		case errors.ErrUserNotFoundInLeaderboard:
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found in the leaderboard"})
 		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve tournament rank"})
+			respondInternalError(c, err, "failed to retrieve tournament rank")
 		}
 		return
 	}
@@ -120,3 +156,48 @@ func (h *LeaderboardHandler) GetTournamentRank(c *gin.Context) {
 		"rank":         rank,
 	})
 }
+
+// GetFriendsLeaderboard ranks a user and their friends by level.
+func (h *LeaderboardHandler) GetFriendsLeaderboard(c *gin.Context) {
+	userId := c.Param("userId")
+
+	ctx := c.Request.Context()
+
+	users, err := h.Service.GetFriendsLeaderboard(ctx, userId)
+	if err != nil {
+		log.Println("Error retrieving friends leaderboard:", err)
+		if err == errors.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		respondInternalError(c, err, "failed to retrieve friends leaderboard")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"userId":      userId,
+		"leaderboard": users,
+		"count":       len(users),
+	})
+}
+
+// GetFriendsInTournament shows which of a user's friends entered today's tournament, or the one
+// named by ?tournamentId=, and their rank in their group.
+func (h *LeaderboardHandler) GetFriendsInTournament(c *gin.Context) {
+	userId := c.Param("userId")
+
+	ctx := c.Request.Context()
+
+	standings, err := h.Service.GetFriendsInTournament(ctx, userId, c.Query("tournamentId"))
+	if err != nil {
+		log.Println("Error retrieving friends in tournament:", err)
+		if err == errors.ErrTournamentNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "tournament not found"})
+			return
+		}
+		respondInternalError(c, err, "failed to retrieve friends in tournament")
+		return
+	}
+
+	c.JSON(http.StatusOK, standings)
+}
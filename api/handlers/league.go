@@ -0,0 +1,66 @@
+// api/handlers/league.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"good_blast/errors"
+	"good_blast/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LeagueHandler handles league requests.
+type LeagueHandler struct {
+	Service services.LeagueServiceInterface
+}
+
+// NewLeagueHandler creates a new instance of LeagueHandler.
+func NewLeagueHandler(service services.LeagueServiceInterface) *LeagueHandler {
+	return &LeagueHandler{
+		Service: service,
+	}
+}
+
+// GetLeague returns the user's league with its reward scaling and movement rules.
+func (h *LeagueHandler) GetLeague(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	league, err := h.Service.GetLeague(ctx, userID)
+	if err != nil {
+		log.Println("GetLeague error:", err)
+		if err == errors.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		respondInternalError(c, err, "could not fetch league")
+		return
+	}
+
+	c.JSON(http.StatusOK, league)
+}
+
+// GetLeagueHistory returns one page of the user's promotions, relegations and stays, newest first.
+func (h *LeagueHandler) GetLeagueHistory(c *gin.Context) {
+	page, ok := parsePageRequest(c)
+	if !ok {
+		return
+	}
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	history, err := h.Service.GetLeagueHistory(ctx, userID, page)
+	if err != nil {
+		log.Println("GetLeagueHistory error:", err)
+		if err == errors.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+		respondInternalError(c, err, "could not fetch league history")
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,121 @@
+// api/handlers/level.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LevelHandler handles level attempt requests.
+type LevelHandler struct {
+	Service services.LevelServiceInterface
+}
+
+// NewLevelHandler creates a new instance of LevelHandler.
+func NewLevelHandler(service services.LevelServiceInterface) *LevelHandler {
+	return &LevelHandler{
+		Service: service,
+	}
+}
+
+// StartLevel spends a life and opens a session for an attempt at a level.
+func (h *LevelHandler) StartLevel(c *gin.Context) {
+	level, err := strconv.Atoi(c.Param("level"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "level must be an integer"})
+		return
+	}
+	var req struct {
+		UserID string `json:"userId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId is required"})
+		return
+	}
+
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	session, lives, err := h.Service.StartLevel(ctx, req.UserID, level)
+	if err != nil {
+		log.Println("StartLevel error:", err)
+		switch err {
+		case errors.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.ErrLevelLocked, errors.ErrNoLivesLeft:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			respondInternalError(c, err, "could not start level")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Level started",
+		"sessionId": session.SessionID,
+		"userId":    session.UserID,
+		"level":     session.Level,
+		"startedAt": session.StartedAt,
+		"lives":     lives,
+	})
+}
+
+// FinishLevel reports the result of a level session.
+func (h *LevelHandler) FinishLevel(c *gin.Context) {
+	sessionID := c.Param("sessionId")
+	var req struct {
+		UserID string `json:"userId" binding:"required"`
+		models.LevelResult
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userId is required; moves and score must be integers"})
+		return
+	}
+
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	outcome, err := h.Service.FinishLevel(ctx, req.UserID, sessionID, req.LevelResult)
+	if err != nil {
+		log.Println("FinishLevel error:", err)
+		switch err {
+		case errors.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.ErrLevelSessionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "level session not found"})
+		case errors.ErrLevelSessionFinished:
+			c.JSON(http.StatusConflict, gin.H{"error": "level session has already been finished"})
+		case errors.ErrLevelSessionExpired, errors.ErrInvalidLevelResult:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			respondInternalError(c, err, "could not finish level")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, outcome)
+}
+
+// GetCatalog returns the level catalog. The catalog version is its ETag, so clients that already
+// hold the current version get 304 Not Modified.
+func (h *LevelHandler) GetCatalog(c *gin.Context) {
+	catalog := h.Service.GetCatalog()
+	etag := strconv.Quote(catalog.Version)
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, catalog)
+}
+
+// GetCatalogVersion returns the version of the level catalog, for clients checking whether
+// their copy is current.
+func (h *LevelHandler) GetCatalogVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"version": h.Service.GetCatalog().Version})
+}
@@ -0,0 +1,71 @@
+// api/handlers/quest.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"good_blast/errors"
+	"good_blast/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuestHandler handles daily quest requests.
+type QuestHandler struct {
+	Service services.QuestServiceInterface
+}
+
+// NewQuestHandler creates a new instance of QuestHandler.
+func NewQuestHandler(service services.QuestServiceInterface) *QuestHandler {
+	return &QuestHandler{
+		Service: service,
+	}
+}
+
+// respondQuestError answers the client errors shared by the quest endpoints.
+func respondQuestError(c *gin.Context, err error, msg string) {
+	switch err {
+	case errors.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.ErrQuestNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.ErrQuestAlreadyClaimed:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.ErrQuestNotCompleted:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondInternalError(c, err, msg)
+	}
+}
+
+// GetQuests returns the user's quests for today with their progress.
+func (h *QuestHandler) GetQuests(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	quests, err := h.Service.GetQuests(ctx, userID)
+	if err != nil {
+		log.Println("GetQuests error:", err)
+		respondQuestError(c, err, "could not fetch quests")
+		return
+	}
+
+	c.JSON(http.StatusOK, quests)
+}
+
+// ClaimQuest grants the reward of a completed quest.
+func (h *QuestHandler) ClaimQuest(c *gin.Context) {
+	userID := c.Param("userId")
+	questID := c.Param("questId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	quest, err := h.Service.ClaimQuest(ctx, userID, questID)
+	if err != nil {
+		log.Println("ClaimQuest error:", err)
+		respondQuestError(c, err, "could not claim quest")
+		return
+	}
+
+	c.JSON(http.StatusOK, quest)
+}
@@ -0,0 +1,93 @@
+// api/handlers/season.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeasonHandler handles season pass requests.
+type SeasonHandler struct {
+	Service services.SeasonServiceInterface
+}
+
+// NewSeasonHandler creates a new instance of SeasonHandler.
+func NewSeasonHandler(service services.SeasonServiceInterface) *SeasonHandler {
+	return &SeasonHandler{
+		Service: service,
+	}
+}
+
+// respondSeasonError answers the client errors shared by the season pass endpoints.
+func respondSeasonError(c *gin.Context, err error, msg string) {
+	switch err {
+	case errors.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.ErrNoActiveSeason, errors.ErrSeasonTierNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.ErrSeasonTierClaimed, errors.ErrSeasonPremiumOwned:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.ErrInvalidSeasonTrack, errors.ErrSeasonTierLocked, errors.ErrSeasonPremiumRequired, errors.ErrNotEnoughCoins:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondInternalError(c, err, msg)
+	}
+}
+
+// GetSeason returns the running season with the user's XP, tiers and claims.
+func (h *SeasonHandler) GetSeason(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	season, err := h.Service.GetSeason(ctx, userID)
+	if err != nil {
+		log.Println("GetSeason error:", err)
+		respondSeasonError(c, err, "could not fetch season")
+		return
+	}
+
+	c.JSON(http.StatusOK, season)
+}
+
+// ClaimTier grants the reward of a reached tier on the track given by ?track=, free by default.
+func (h *SeasonHandler) ClaimTier(c *gin.Context) {
+	userID := c.Param("userId")
+	tier, err := strconv.Atoi(c.Param("tier"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tier must be an integer"})
+		return
+	}
+	track := c.DefaultQuery("track", models.SeasonTrackFree)
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	claim, err := h.Service.ClaimTier(ctx, userID, tier, track)
+	if err != nil {
+		log.Println("ClaimTier error:", err)
+		respondSeasonError(c, err, "could not claim season tier")
+		return
+	}
+
+	c.JSON(http.StatusOK, claim)
+}
+
+// PurchasePremium buys the running season's premium pass.
+func (h *SeasonHandler) PurchasePremium(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	season, err := h.Service.PurchasePremium(ctx, userID)
+	if err != nil {
+		log.Println("PurchasePremium error:", err)
+		respondSeasonError(c, err, "could not purchase premium pass")
+		return
+	}
+
+	c.JSON(http.StatusOK, season)
+}
@@ -35,7 +35,7 @@ func (h *TournamentHandler) StartTournamentHandler(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "tournament already active for today"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not start tournament"})
+		respondInternalError(c, err, "could not start tournament")
 		return
 	}
 
@@ -63,7 +63,7 @@ func (h *TournamentHandler) EndTournamentHandler(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "tournament is already inactive"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not end tournament"})
+		respondInternalError(c, err, "could not end tournament")
 		return
 	}
 
@@ -108,10 +108,10 @@ func (h *TournamentHandler) EnterTournament(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "not enough coins (need 500)"})
 		case errors.ErrAlreadyInTournament:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "you are already in the tournament"})
-		case errors.ErrRequirementsNotMet:
+		case errors.ErrRequirementsNotMet, errors.ErrRequirementsNotMetForEntry:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "you do not meet the requirements to enter the tournament"})
 		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+			respondInternalError(c, err, "transaction failed")
 		}
 		return
 	}
@@ -142,10 +142,12 @@ func (h *TournamentHandler) UpdateScore(c *gin.Context) {
 	if err != nil {
 		log.Println("UpdateScore error:", err)
 		switch err {
+		case errors.ErrTournamentNotActive:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "tournament is not active"})
 		case errors.ErrTournamentEntryNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "tournament entry not found"})
 		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update tournament score"})
+			respondInternalError(c, err, "could not update tournament score")
 		}
 		return
 	}
@@ -181,6 +183,10 @@ func (h *TournamentHandler) ClaimReward(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "no tournament entry found for this user"})
 		case errors.ErrRewardAlreadyClaimed:
 			c.JSON(http.StatusBadRequest, gin.H{"error": "reward has already been claimed"})
+		case errors.ErrTournamentCancelled:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "the tournament was cancelled; no rewards are paid"})
+		case errors.ErrRewardExpired:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "the claim window for this reward has closed"})
 		case errors.ErrNoRewardForRank:
 			c.JSON(http.StatusOK, gin.H{
 				"message":      "No reward available for your rank in the group",
@@ -190,7 +196,7 @@ func (h *TournamentHandler) ClaimReward(c *gin.Context) {
 				"reward":       0,
 			})
 		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not claim reward"})
+			respondInternalError(c, err, "could not claim reward")
 		}
 		return
 	}
@@ -200,6 +206,73 @@ func (h *TournamentHandler) ClaimReward(c *gin.Context) {
 		"userId":       req.UserID,
 		"tournamentId": tournamentID,
 		"rank":         rank,
-		"reward":       reward,
+		"reward":       reward.Coins,
+		"bundle":       reward,
+	})
+}
+
+// GetPendingRewards lists the rewards a user has earned but not claimed yet.
+func (h *TournamentHandler) GetPendingRewards(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	rewards, err := h.Service.GetPendingRewards(ctx, userID)
+	if err != nil {
+		log.Println("GetPendingRewards error:", err)
+		switch err {
+		case errors.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			respondInternalError(c, err, "could not retrieve pending rewards")
+		}
+		return
+	}
+
+	total := 0
+	for _, r := range rewards {
+		total += r.Reward
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"userId":      userID,
+		"rewards":     rewards,
+		"count":       len(rewards),
+		"totalReward": total,
+	})
+}
+
+// ClaimAllRewards claims every pending reward of a user and returns a per-tournament breakdown.
+func (h *TournamentHandler) ClaimAllRewards(c *gin.Context) {
+	userID := c.Param("userId")
+	ctx := c.Request.Context() // Extract context from the HTTP request
+
+	result, err := h.Service.ClaimAllRewards(ctx, userID)
+	if err != nil {
+		log.Println("ClaimAllRewards error:", err)
+		switch err {
+		case errors.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			// Rewards claimed before the failure stay claimed; retrying claims the rest
+			if result != nil && len(result.Claims) > 0 {
+				c.JSON(http.StatusOK, gin.H{
+					"message":     "Some rewards could not be claimed, retry to claim the rest",
+					"userId":      result.UserID,
+					"claims":      result.Claims,
+					"count":       len(result.Claims),
+					"totalReward": result.TotalReward,
+				})
+				return
+			}
+			respondInternalError(c, err, "could not claim rewards")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Rewards claimed",
+		"userId":      result.UserID,
+		"claims":      result.Claims,
+		"count":       len(result.Claims),
+		"totalReward": result.TotalReward,
 	})
 }
@@ -43,7 +43,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	user, err := h.Service.CreateUser(ctx, req.Username, req.Country)
 	if err != nil {
 		log.Println("Error creating user:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create user"})
+		respondInternalError(c, err, "could not create user")
 		return
 	}
 
@@ -85,7 +85,7 @@ func (h *UserHandler) UpdateProgress(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "newLevel must be greater than current level"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update user progress"})
+		respondInternalError(c, err, "could not update user progress")
 		return
 	}
 
@@ -7,22 +7,94 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// levelFinishPath replaces the client-reported progress and score endpoints: level sessions
+// apply both from the result of a level that was actually started.
+const levelFinishPath = "/levels/sessions/{sessionId}/finish"
+
+// deprecated marks the responses of an endpoint that is going away in the next release, and
+// points clients to its successor.
+func deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+">; rel=\"successor-version\"")
+		c.Next()
+	}
+}
+
 // SetupRoutes sets up all the API routes with their respective handlers.
-func SetupRoutes(router *gin.Engine, userHandler *handlers.UserHandler, tournamentHandler *handlers.TournamentHandler, leaderboardHandler *handlers.LeaderboardHandler) {
+func SetupRoutes(router *gin.Engine, userHandler *handlers.UserHandler, tournamentHandler *handlers.TournamentHandler, leaderboardHandler *handlers.LeaderboardHandler, inventoryHandler *handlers.InventoryHandler, levelHandler *handlers.LevelHandler, checkInHandler *handlers.CheckInHandler, questHandler *handlers.QuestHandler, achievementHandler *handlers.AchievementHandler, seasonHandler *handlers.SeasonHandler, leagueHandler *handlers.LeagueHandler, clanHandler *handlers.ClanHandler, clanTournamentHandler *handlers.ClanTournamentHandler, friendHandler *handlers.FriendHandler) {
 	// User routes
 	router.POST("/users", userHandler.CreateUser)
-	router.PUT("/users/:userId/progress", userHandler.UpdateProgress)
+	router.PUT("/users/:userId/progress", deprecated(levelFinishPath), userHandler.UpdateProgress)
 
 	// Tournament routes
 	router.POST("/tournaments/start", tournamentHandler.StartTournamentHandler)
 	router.PUT("/tournaments/end/:tournamentId", tournamentHandler.EndTournamentHandler)
 	router.POST("/tournaments/enter", tournamentHandler.EnterTournament)
-	router.PUT("/tournaments/:tournamentId/score", tournamentHandler.UpdateScore)
+	router.PUT("/tournaments/:tournamentId/score", deprecated(levelFinishPath), tournamentHandler.UpdateScore)
 	router.POST("/tournaments/:tournamentId/claim", tournamentHandler.ClaimReward)
+	router.GET("/users/:userId/rewards/pending", tournamentHandler.GetPendingRewards)
+	router.POST("/users/:userId/rewards/claim-all", tournamentHandler.ClaimAllRewards)
+
+	// League routes
+	router.GET("/users/:userId/league", leagueHandler.GetLeague)
+	router.GET("/users/:userId/league/history", leagueHandler.GetLeagueHistory)
+
+	// Inventory routes
+	router.GET("/users/:userId/boosters", inventoryHandler.GetBoosters)
+	router.POST("/users/:userId/boosters/purchase", inventoryHandler.PurchaseBooster)
+	router.POST("/users/:userId/boosters/consume", inventoryHandler.ConsumeBooster)
+	router.GET("/users/:userId/lives", inventoryHandler.GetLives)
+	router.POST("/users/:userId/lives/consume", inventoryHandler.ConsumeLife)
+	router.POST("/users/:userId/lives/refill", inventoryHandler.RefillLives)
+
+	// Level routes
+	router.POST("/levels/:level/start", levelHandler.StartLevel)
+	router.POST("/levels/sessions/:sessionId/finish", levelHandler.FinishLevel)
+	router.GET("/levels/catalog", levelHandler.GetCatalog)
+	router.GET("/levels/catalog/version", levelHandler.GetCatalogVersion)
+
+	// Check-in routes
+	router.GET("/users/:userId/check-in", checkInHandler.GetCheckIn)
+	router.POST("/users/:userId/check-in", checkInHandler.CheckIn)
+	router.POST("/users/:userId/check-in/restore", checkInHandler.RestoreStreak)
+
+	// Quest routes
+	router.GET("/users/:userId/quests", questHandler.GetQuests)
+	router.POST("/users/:userId/quests/:questId/claim", questHandler.ClaimQuest)
+
+	// Achievement routes
+	router.GET("/users/:userId/achievements", achievementHandler.GetAchievements)
+
+	// Season pass routes
+	router.GET("/users/:userId/season", seasonHandler.GetSeason)
+	router.POST("/users/:userId/season/tiers/:tier/claim", seasonHandler.ClaimTier)
+	router.POST("/users/:userId/season/premium", seasonHandler.PurchasePremium)
+
+	// Clan routes
+	router.POST("/clans", clanHandler.CreateClan)
+	router.GET("/clans", clanHandler.SearchClans)
+	router.GET("/clans/:clanId", clanHandler.GetClan)
+	router.POST("/clans/:clanId/join", clanHandler.JoinClan)
+	router.POST("/clans/:clanId/leave", clanHandler.LeaveClan)
+	router.POST("/clans/:clanId/kick", clanHandler.KickMember)
+	router.PUT("/clans/:clanId/members/:memberId/role", clanHandler.SetMemberRole)
+	router.POST("/clans/:clanId/tournaments/:tournamentId/enter", clanTournamentHandler.EnterTournament)
+	router.GET("/clans/:clanId/tournaments/:tournamentId", clanTournamentHandler.GetStanding)
+
+	// Friend routes
+	router.GET("/users/:userId/friends", friendHandler.GetFriends)
+	router.POST("/users/:userId/friends/requests", friendHandler.SendRequest)
+	router.POST("/users/:userId/friends/:friendId/accept", friendHandler.AcceptRequest)
+	router.POST("/users/:userId/friends/:friendId/remove", friendHandler.RemoveFriend)
+	router.POST("/users/:userId/friends/:friendId/block", friendHandler.BlockUser)
 
 	// Leaderboard routes
 	router.GET("/leaderboard/global", leaderboardHandler.GetGlobalLeaderboard)
 	router.GET("/leaderboard/country", leaderboardHandler.GetCountryLeaderboard)
 	router.GET("/leaderboard/tournament", leaderboardHandler.GetTournamentLeaderboard)
 	router.GET("/tournaments/:tournamentId/rank", leaderboardHandler.GetTournamentRank)
+	router.GET("/leaderboard/clans", clanHandler.GetClanLeaderboard)
+	router.GET("/users/:userId/friends/leaderboard", leaderboardHandler.GetFriendsLeaderboard)
+	router.GET("/users/:userId/friends/tournament", leaderboardHandler.GetFriendsInTournament)
 }
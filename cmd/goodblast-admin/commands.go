@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"good_blast/config"
+	"good_blast/models"
+)
+
+// newFlags returns a flag set for a subcommand that reports errors instead of exiting.
+func newFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// required fails when any of the named string flags is empty.
+func required(fs *flag.FlagSet, names ...string) error {
+	var missing []string
+	for _, name := range names {
+		if f := fs.Lookup(name); f == nil || f.Value.String() == "" {
+			missing = append(missing, "-"+name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required flag(s): %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// printJSON writes v as indented JSON.
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func startTournament(ctx context.Context, a *admin, args []string) error {
+	if err := newFlags("start-tournament").Parse(args); err != nil {
+		return err
+	}
+	t, err := a.tournaments.StartTournament(ctx)
+	if err != nil {
+		return err
+	}
+	return printJSON(a.out, t)
+}
+
+func endTournament(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("end-tournament")
+	id := fs.String("id", "", "tournament ID (YYYY-MM-DD)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "id"); err != nil {
+		return err
+	}
+	if err := a.tournaments.EndTournament(ctx, *id); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "tournament %s ended\n", *id)
+	return nil
+}
+
+func cancelTournament(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("cancel-tournament")
+	id := fs.String("id", "", "tournament ID (YYYY-MM-DD)")
+	actor := fs.String("actor", os.Getenv("USER"), "operator name recorded with the refunds")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "id"); err != nil {
+		return err
+	}
+	report, err := a.tournaments.CancelTournament(ctx, *id, *actor)
+	if report != nil {
+		// Print progress even on failure; the cancellation resumes when run again
+		if perr := printJSON(a.out, report); perr != nil && err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+func standings(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("standings")
+	group := fs.String("group", "", "group ID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "group"); err != nil {
+		return err
+	}
+	// Operators want the current state, not a cached snapshot
+	a.leaderboards.GroupScoreChanged(ctx, *group)
+	entries, err := a.leaderboards.GetTournamentLeaderboard(ctx, *group)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "%-5s %-36s %s\n", "RANK", "USER", "SCORE")
+	for i, e := range entries {
+		fmt.Fprintf(a.out, "%-5d %-36s %d\n", i+1, e.UserID, e.Score)
+	}
+	return nil
+}
+
+func showUser(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("user")
+	id := fs.String("id", "", "user ID")
+	history := fs.Int("history", 20, "number of history entries to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "id"); err != nil {
+		return err
+	}
+	user, err := a.users.GetUser(ctx, *id)
+	if err != nil {
+		return err
+	}
+	out := struct {
+		User    *models.User          `json:"user"`
+		History []models.HistoryEntry `json:"history"`
+	}{User: user, History: []models.HistoryEntry{}}
+
+	if *history > 0 {
+		page, err := a.users.GetUserHistory(ctx, *id, models.PageRequest{Limit: *history})
+		if err != nil {
+			return err
+		}
+		out.History = page.Items
+	}
+	return printJSON(a.out, out)
+}
+
+func adjustCoins(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("adjust-coins")
+	id := fs.String("user", "", "user ID")
+	amount := fs.Int("amount", 0, "coins to add; negative to remove")
+	reason := fs.String("reason", "", "why the balance is changed (recorded in the user's history)")
+	actor := fs.String("actor", os.Getenv("USER"), "operator name recorded with the change")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "user", "reason"); err != nil {
+		return err
+	}
+	user, err := a.users.AdjustCoins(ctx, *id, *amount, *reason, *actor)
+	if err != nil {
+		return err
+	}
+	return printJSON(a.out, user)
+}
+
+func grant(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("grant")
+	id := fs.String("user", "", "user ID")
+	raw := fs.String("bundle", "", `reward bundle, e.g. {"coins":100,"boosters":{"rocket":2},"lives":1}`)
+	reason := fs.String("reason", "", "why the reward is granted (recorded in the user's history)")
+	actor := fs.String("actor", os.Getenv("USER"), "operator name recorded with the grant")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "user", "bundle", "reason"); err != nil {
+		return err
+	}
+	var bundle models.RewardBundle
+	dec := json.NewDecoder(strings.NewReader(*raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&bundle); err != nil {
+		return fmt.Errorf("invalid -bundle: %v", err)
+	}
+	if err := bundle.Validate(); err != nil {
+		return fmt.Errorf("invalid -bundle: %v", err)
+	}
+	user, err := a.users.GrantReward(ctx, *id, bundle, *reason, *actor)
+	if err != nil {
+		return err
+	}
+	return printJSON(a.out, user)
+}
+
+func settle(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("settle")
+	id := fs.String("id", "", "tournament ID (YYYY-MM-DD)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "id"); err != nil {
+		return err
+	}
+	report, err := a.tournaments.SettleTournament(ctx, *id)
+	if report != nil {
+		// Print progress even on failure; settlement can simply be re-run
+		if perr := printJSON(a.out, report); perr != nil && err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+func expireRewards(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("expire-rewards")
+	id := fs.String("id", "", "tournament ID (YYYY-MM-DD)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "id"); err != nil {
+		return err
+	}
+	report, err := a.tournaments.ExpireRewards(ctx, *id)
+	if report != nil {
+		// Print progress even on failure; expiry can simply be re-run
+		if perr := printJSON(a.out, report); perr != nil && err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+func applyLeagues(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("apply-leagues")
+	id := fs.String("id", "", "tournament ID (YYYY-MM-DD)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "id"); err != nil {
+		return err
+	}
+	report, err := a.tournaments.ApplyLeagues(ctx, *id)
+	if report != nil {
+		// Print progress even on failure; applying can simply be re-run
+		if perr := printJSON(a.out, report); perr != nil && err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+func clanRewards(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("clan-rewards")
+	id := fs.String("id", "", "tournament ID (YYYY-MM-DD)")
+	actor := fs.String("actor", os.Getenv("USER"), "operator name recorded with the payments")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "id"); err != nil {
+		return err
+	}
+	report, err := a.clanRewards.DistributeRewards(ctx, *id, *actor)
+	if report != nil {
+		// Print progress even on failure; paying resumes when run again
+		if perr := printJSON(a.out, report); perr != nil && err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+func flushCache(ctx context.Context, a *admin, args []string) error {
+	if err := newFlags("flush-cache").Parse(args); err != nil {
+		return err
+	}
+	if a.cfg.Cache.Backend != config.CacheBackendRedis {
+		return fmt.Errorf("cache backend %q lives inside each server process; restart the servers to flush it", a.cfg.Cache.Backend)
+	}
+	n, err := a.leaderboards.FlushCaches(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "flushed %d cached leaderboard(s)\n", n)
+	return nil
+}
+
+func export(ctx context.Context, a *admin, args []string) error {
+	fs := newFlags("export")
+	id := fs.String("id", "", "tournament ID (YYYY-MM-DD)")
+	format := fs.String("format", "csv", "csv or json")
+	path := fs.String("out", "", "output file; defaults to stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "id"); err != nil {
+		return err
+	}
+
+	w := a.out
+	if *path != "" {
+		f, err := os.Create(*path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	rw, err := newResultWriter(*format, w)
+	if err != nil {
+		return err
+	}
+	if err := a.tournaments.TournamentResults(ctx, *id, rw.Write); err != nil {
+		return err
+	}
+	return rw.Close()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"good_blast/models"
+)
+
+// resultWriter streams tournament results in an export format.
+type resultWriter interface {
+	Write(r models.TournamentResult) error
+	Close() error // flushes and terminates the output; required even when nothing was written
+}
+
+func newResultWriter(format string, w io.Writer) (resultWriter, error) {
+	switch format {
+	case "csv":
+		return newCSVResultWriter(w)
+	case "json":
+		return &jsonResultWriter{w: w}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q (want csv or json)", format)
+}
+
+var csvHeader = []string{"tournamentId", "groupId", "userId", "score", "rank", "reward", "claimedReward"}
+
+type csvResultWriter struct {
+	w *csv.Writer
+}
+
+func newCSVResultWriter(w io.Writer) (*csvResultWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	return &csvResultWriter{w: cw}, nil
+}
+
+func (c *csvResultWriter) Write(r models.TournamentResult) error {
+	return c.w.Write([]string{
+		r.TournamentID,
+		r.GroupID,
+		r.UserID,
+		strconv.Itoa(r.Score),
+		strconv.Itoa(r.Rank),
+		strconv.Itoa(r.Reward),
+		strconv.FormatBool(r.ClaimedReward),
+	})
+}
+
+func (c *csvResultWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonResultWriter writes a JSON array one element at a time, so large tournaments
+// are never held in memory.
+type jsonResultWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonResultWriter) Write(r models.TournamentResult) error {
+	sep := ",\n  "
+	if j.count == 0 {
+		sep = "[\n  "
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(j.w, sep); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	j.count++
+	return err
+}
+
+func (j *jsonResultWriter) Close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
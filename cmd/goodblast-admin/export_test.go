@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"good_blast/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var exportResults = []models.TournamentResult{
+	{TournamentID: "2024-01-15", GroupID: "g1", UserID: "u1", Score: 30, Rank: 1, Reward: 5000, ClaimedReward: true},
+	{TournamentID: "2024-01-15", GroupID: "g1", UserID: "u2", Score: 5, Rank: 11},
+}
+
+func writeAll(t *testing.T, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	rw, err := newResultWriter(format, &buf)
+	assert.NoError(t, err)
+	for _, r := range exportResults {
+		assert.NoError(t, rw.Write(r))
+	}
+	assert.NoError(t, rw.Close())
+	return buf.String()
+}
+
+func TestExport_CSV(t *testing.T) {
+	out := writeAll(t, "csv")
+	assert.Equal(t, "tournamentId,groupId,userId,score,rank,reward,claimedReward\n"+
+		"2024-01-15,g1,u1,30,1,5000,true\n"+
+		"2024-01-15,g1,u2,5,11,0,false\n", out)
+}
+
+func TestExport_JSONIsAValidArray(t *testing.T) {
+	var decoded []models.TournamentResult
+	assert.NoError(t, json.Unmarshal([]byte(writeAll(t, "json")), &decoded))
+	assert.Equal(t, exportResults, decoded)
+
+	var buf bytes.Buffer
+	rw, _ := newResultWriter("json", &buf)
+	assert.NoError(t, rw.Close())
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestExport_UnknownFormat(t *testing.T) {
+	_, err := newResultWriter("xml", &bytes.Buffer{})
+	assert.Error(t, err)
+}
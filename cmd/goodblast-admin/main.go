@@ -0,0 +1,167 @@
+// Command goodblast-admin runs operator tasks against the game backend through the same
+// service layer as the API, so business rules and audit records stay in one place.
+//
+//	goodblast-admin [-config file] <command> [flags]
+//
+// Run it without a command to list the available commands.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"good_blast/config"
+	"good_blast/database"
+	"good_blast/services"
+	"good_blast/services/cache"
+	redisclient "good_blast/services/redis_client"
+)
+
+// admin holds the services commands operate on.
+type admin struct {
+	cfg          *config.Config
+	users        *services.UserService
+	tournaments  *services.TournamentService
+	clanRewards  *services.ClanTournamentService
+	leaderboards *services.LeaderboardService
+	out          io.Writer
+}
+
+// command is one subcommand. run receives the arguments after the command name.
+type command struct {
+	usage string
+	run   func(ctx context.Context, a *admin, args []string) error
+}
+
+var commands = map[string]command{
+	"start-tournament":  {"start today's tournament", startTournament},
+	"end-tournament":    {"-id ID: end a tournament so rewards can be claimed", endTournament},
+	"cancel-tournament": {"-id ID [-actor NAME]: void a tournament and refund entry fees; re-run to resume", cancelTournament},
+	"standings":         {"-group GROUP_ID: show a group's standings", standings},
+	"user":              {"-id USER_ID [-history N]: show a user and their recent balance history", showUser},
+	"adjust-coins":      {"-user USER_ID -amount N -reason TEXT [-actor NAME]: add (or remove) coins", adjustCoins},
+	"grant":             {"-user USER_ID -bundle JSON -reason TEXT [-actor NAME]: grant a reward bundle", grant},
+	"settle":            {"-id ID: pay every unclaimed reward of an ended tournament", settle},
+	"expire-rewards":    {"-id ID: mark rewards left unclaimed past the claim deadline as expired", expireRewards},
+	"apply-leagues":     {"-id ID: promote and relegate the entrants of an ended tournament", applyLeagues},
+	"clan-rewards":      {"-id ID [-actor NAME]: pay the clan rewards of an ended tournament; re-run to resume", clanRewards},
+	"flush-cache":       {"drop all cached leaderboards", flushCache},
+	"export":            {"-id ID [-format csv|json] [-out FILE]: export tournament results", export},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: goodblast-admin [-config file] <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", name, commands[name].usage)
+	}
+}
+
+func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a JSON config file (env vars override it)")
+	timeout := flag.Duration("timeout", 30*time.Minute, "overall deadline for the command")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("goodblast-admin: failed to load configuration: %v", err)
+	}
+
+	// Keep the service logs out of the command output
+	log.SetOutput(io.Discard)
+	a, closeAdmin, err := newAdmin(cfg)
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		log.Fatalf("goodblast-admin: %v", err)
+	}
+	defer closeAdmin()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	if err := cmd.run(ctx, a, flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+// newAdmin wires the services the same way the API does. Only the Redis cache is shared
+// with running servers, so the other backends are replaced by a no-op cache here.
+func newAdmin(cfg *config.Config) (*admin, func(), error) {
+	if err := database.InitDynamoDB(cfg.DynamoDB); err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize DynamoDB: %w", err)
+	}
+	db := &database.DynamoDB{}
+
+	var c cache.Cache = cache.NewNoop()
+	if cfg.Cache.Backend == config.CacheBackendRedis {
+		client, err := redisclient.NewClient(cfg.Redis)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		c = cache.NewRedis(client)
+	}
+
+	leaderboards := services.NewLeaderboardService(db, c)
+	leaderboards.Policies.Global, _ = services.ParseCachePolicy(cfg.Cache.GlobalLeaderboardPolicy)
+	leaderboards.Policies.Country, _ = services.ParseCachePolicy(cfg.Cache.CountryLeaderboardPolicy)
+	leaderboards.Policies.Tournament, _ = services.ParseCachePolicy(cfg.Cache.TournamentLeaderboardPolicy)
+
+	users := services.NewUserService(db)
+	users.Leaderboards = leaderboards
+	tournaments := services.NewTournamentService(db)
+	tournaments.SeatShards = cfg.Tournament.SeatShards
+	tournaments.ClaimWindow = cfg.Tournament.ClaimWindow.D()
+	tournaments.Rewards = cfg.Tournament.Rewards
+	tournaments.Leagues = cfg.Tournament.Leagues
+	tournaments.Leaderboards = leaderboards
+
+	// Rewards settled from here count towards quests, achievements and the season pass like rewards claimed through the API
+	quests := services.NewQuestService(db)
+	quests.Pool = cfg.Quests.Pool
+	quests.PerDay = cfg.Quests.PerDay
+	tournaments.Quests = quests
+	tournaments.Achievements = services.NewAchievementService(db)
+	seasons := services.NewSeasonService(db)
+	seasons.Seasons = cfg.SeasonPass.Seasons
+	tournaments.Seasons = seasons
+	clanTournaments := services.NewClanTournamentService(db)
+	clanTournaments.Rewards = cfg.Clans.TournamentRewards
+
+	a := &admin{
+		cfg:          cfg,
+		users:        users,
+		tournaments:  tournaments,
+		clanRewards:  clanTournaments,
+		leaderboards: leaderboards,
+		out:          os.Stdout,
+	}
+	return a, func() { c.Close() }, nil
+}
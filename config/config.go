@@ -0,0 +1,685 @@
+// config/config.go
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"good_blast/models"
+)
+
+// Config is the full, typed application configuration.
+// It is built from defaults, an optional JSON file and environment variable overrides, in that order.
+type Config struct {
+	Server     ServerConfig     `json:"server"`
+	DynamoDB   DynamoDBConfig   `json:"dynamodb"`
+	Redis      RedisConfig      `json:"redis"`
+	Cache      CacheConfig      `json:"cache"`
+	Tournament TournamentConfig `json:"tournament"`
+	Inventory  InventoryConfig  `json:"inventory"`
+	Levels     LevelsConfig     `json:"levels"`
+	CheckIn    CheckInConfig    `json:"checkIn"`
+	Quests     QuestsConfig     `json:"quests"`
+	SeasonPass SeasonPassConfig `json:"seasonPass"`
+	Clans      ClansConfig      `json:"clans"`
+	Friends    FriendsConfig    `json:"friends"`
+}
+
+// ServerConfig configures the HTTP server.
+type ServerConfig struct {
+	Port              string   `json:"port"`
+	ReadTimeout       Duration `json:"readTimeout"`
+	ReadHeaderTimeout Duration `json:"readHeaderTimeout"`
+	WriteTimeout      Duration `json:"writeTimeout"`
+	IdleTimeout       Duration `json:"idleTimeout"`
+	ShutdownTimeout   Duration `json:"shutdownTimeout"`
+}
+
+// DynamoDBConfig configures the DynamoDB client and table names.
+type DynamoDBConfig struct {
+	Region                     string `json:"region"`
+	Endpoint                   string `json:"endpoint,omitempty"` // e.g. http://localhost:8000 for DynamoDB Local
+	UsersTable                 string `json:"usersTable"`
+	TournamentsTable           string `json:"tournamentsTable"`
+	TournamentEntriesTable     string `json:"tournamentEntriesTable"`
+	UserHistoryTable           string `json:"userHistoryTable"`           // audit trail of balance changes per user
+	LevelSessionsTable         string `json:"levelSessionsTable"`         // one item per level attempt
+	UserQuestsTable            string `json:"userQuestsTable"`            // daily quest progress per user
+	UserAchievementsTable      string `json:"userAchievementsTable"`      // unlocked achievements per user
+	SeasonProgressTable        string `json:"seasonProgressTable"`        // season pass XP and claims per user and season
+	LeagueHistoryTable         string `json:"leagueHistoryTable"`         // league moves per user and tournament
+	ClansTable                 string `json:"clansTable"`                 // one item per clan
+	ClanMembersTable           string `json:"clanMembersTable"`           // members and their roles per clan
+	ClanTournamentEntriesTable string `json:"clanTournamentEntriesTable"` // clans entered per tournament and their scores
+	ClanContributionsTable     string `json:"clanContributionsTable"`     // tournament score per clan entry and member
+	FriendshipsTable           string `json:"friendshipsTable"`           // friend requests, friends and blocks per user
+	MigrationsTable            string `json:"migrationsTable"`            // applied schema versions, written by cmd/migrate
+
+	MaxAttempts    int      `json:"maxAttempts"`    // attempts per call for throttles/conflicts, including the first
+	RetryBaseDelay Duration `json:"retryBaseDelay"` // first backoff; doubles per retry with full jitter
+	RetryMaxDelay  Duration `json:"retryMaxDelay"`  // cap for a single backoff
+	CallTimeout    Duration `json:"callTimeout"`    // deadline for a single DynamoDB request
+}
+
+// RedisConfig configures the Redis connection.
+type RedisConfig struct {
+	Addr     string `json:"addr"`
+	Password string `json:"password,omitempty"`
+	DB       int    `json:"db"`
+	TLS      bool   `json:"tls"`
+}
+
+// Cache backends accepted by CacheConfig.Backend.
+const (
+	CacheBackendRedis  = "redis"
+	CacheBackendMemory = "memory"
+	CacheBackendNone   = "none"
+)
+
+// CacheConfig configures the cache backend and how long cached data is served.
+type CacheConfig struct {
+	Backend                  string   `json:"backend"`    // redis, memory or none
+	MaxEntries               int      `json:"maxEntries"` // capacity of the in-memory backend
+	GlobalLeaderboardTTL     Duration `json:"globalLeaderboardTTL"`
+	CountryLeaderboardTTL    Duration `json:"countryLeaderboardTTL"`
+	TournamentLeaderboardTTL Duration `json:"tournamentLeaderboardTTL"`
+
+	// What happens to a cached leaderboard when rankings change: "invalidate" or "write-through"
+	GlobalLeaderboardPolicy     string `json:"globalLeaderboardPolicy"`
+	CountryLeaderboardPolicy    string `json:"countryLeaderboardPolicy"`
+	TournamentLeaderboardPolicy string `json:"tournamentLeaderboardPolicy"`
+}
+
+// TournamentConfig configures how tournaments are run.
+type TournamentConfig struct {
+	SeatShards  int      `json:"seatShards"`  // seat counters per new tournament without leagues; more shards absorb more concurrent joins
+	ClaimWindow Duration `json:"claimWindow"` // how long rewards stay claimable after a tournament ends
+
+	// Reward bundle per rank within a group. Set only from the config file; a table there
+	// replaces the default one as a whole.
+	Rewards models.RewardTable `json:"rewards"`
+
+	// League ladder, lowest league first. Set only from the config file; a ladder there replaces
+	// the default Bronze to Diamond one as a whole.
+	Leagues models.LeagueLadder `json:"leagues"`
+}
+
+// InventoryConfig configures the items users can buy and hold.
+type InventoryConfig struct {
+	// Boosters sold in the shop. Set only from the config file; a catalog there replaces the
+	// default one as a whole. Boosters in tournament rewards must be listed here.
+	Boosters models.BoosterCatalog `json:"boosters"`
+
+	MaxLives          int      `json:"maxLives"`          // lives regenerate up to this count
+	LifeRegenInterval Duration `json:"lifeRegenInterval"` // time to regenerate one life
+	LivesRefillCost   int      `json:"livesRefillCost"`   // coins to refill lives to maxLives
+}
+
+// LevelsConfig configures level attempts.
+type LevelsConfig struct {
+	MaxSessionDuration Duration `json:"maxSessionDuration"` // how long after starting a level its result may be reported
+
+	// JSON file with the level catalog (difficulty and rewards per level); empty means the
+	// built-in catalog paying 100 coins per level. Read by LoadLevelCatalog.
+	CatalogFile string `json:"catalogFile,omitempty"`
+}
+
+// CheckInConfig configures daily check-ins.
+type CheckInConfig struct {
+	// Reward per day of a streak. Set only from the config file; a calendar there replaces the
+	// default one as a whole. Boosters in it must be listed in inventory.boosters.
+	Calendar models.CheckInCalendar `json:"calendar"`
+
+	RestoreCost    int `json:"restoreCost"`    // coins per missed day to restore a lapsed streak; 0 disables restores
+	MaxRestoreDays int `json:"maxRestoreDays"` // most missed days a streak can be restored across
+}
+
+// QuestsConfig configures daily quests.
+type QuestsConfig struct {
+	// Quests daily missions are drawn from. Set only from the config file; a pool there
+	// replaces the default one as a whole. Boosters in rewards must be listed in inventory.boosters.
+	Pool models.QuestPool `json:"pool"`
+
+	PerDay int `json:"perDay"` // quests each user gets per day, drawn from the pool
+}
+
+// SeasonPassConfig configures the season pass.
+type SeasonPassConfig struct {
+	// Seasons of the pass; none by default, which leaves the pass switched off. Set only from
+	// the config file. Boosters in tier rewards must be listed in inventory.boosters.
+	Seasons models.SeasonSchedule `json:"seasons"`
+}
+
+// ClansConfig configures clans.
+type ClansConfig struct {
+	MaxMembers int `json:"maxMembers"` // most members a clan can have, leader included
+
+	// Reward bundle per clan rank within a clan tournament group and member contribution. Set
+	// only from the config file; a table there replaces the default one as a whole.
+	TournamentRewards models.ClanRewardTable `json:"tournamentRewards"`
+}
+
+// FriendsConfig configures the friend graph.
+type FriendsConfig struct {
+	MaxFriends int `json:"maxFriends"` // most accepted friends a user can have
+}
+
+// Cache policies accepted by the CacheConfig *LeaderboardPolicy fields.
+const (
+	CachePolicyInvalidate   = "invalidate"
+	CachePolicyWriteThrough = "write-through"
+)
+
+// Duration is a time.Duration that reads and writes JSON as a string such as "60s".
+type Duration time.Duration
+
+// D returns the value as a time.Duration.
+func (d Duration) D() time.Duration {
+	return time.Duration(d)
+}
+
+// MarshalJSON encodes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string such as "1m30s".
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %v", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              "8080",
+			ReadTimeout:       Duration(10 * time.Second),
+			ReadHeaderTimeout: Duration(5 * time.Second),
+			WriteTimeout:      Duration(15 * time.Second),
+			IdleTimeout:       Duration(60 * time.Second),
+			ShutdownTimeout:   Duration(20 * time.Second),
+		},
+		DynamoDB: DynamoDBConfig{
+			UsersTable:                 "Users",
+			TournamentsTable:           "Tournaments",
+			TournamentEntriesTable:     "TournamentEntries",
+			UserHistoryTable:           "UserHistory",
+			LevelSessionsTable:         "LevelSessions",
+			UserQuestsTable:            "UserQuests",
+			UserAchievementsTable:      "UserAchievements",
+			SeasonProgressTable:        "SeasonProgress",
+			LeagueHistoryTable:         "LeagueHistory",
+			ClansTable:                 "Clans",
+			ClanMembersTable:           "ClanMembers",
+			ClanTournamentEntriesTable: "ClanTournamentEntries",
+			ClanContributionsTable:     "ClanContributions",
+			FriendshipsTable:           "Friendships",
+			MigrationsTable:            "SchemaMigrations",
+			MaxAttempts:                5,
+			RetryBaseDelay:             Duration(25 * time.Millisecond),
+			RetryMaxDelay:              Duration(1 * time.Second),
+			CallTimeout:                Duration(5 * time.Second),
+		},
+		Redis: RedisConfig{
+			Addr: "localhost:6379", // Redis is running inside same container
+		},
+		Cache: CacheConfig{
+			Backend:                  CacheBackendRedis,
+			MaxEntries:               10000,
+			GlobalLeaderboardTTL:     Duration(60 * time.Second),
+			CountryLeaderboardTTL:    Duration(60 * time.Second),
+			TournamentLeaderboardTTL: Duration(60 * time.Second),
+
+			GlobalLeaderboardPolicy:     CachePolicyInvalidate,
+			CountryLeaderboardPolicy:    CachePolicyInvalidate,
+			TournamentLeaderboardPolicy: CachePolicyWriteThrough,
+		},
+		Tournament: TournamentConfig{
+			SeatShards:  8,
+			ClaimWindow: Duration(7 * 24 * time.Hour),
+			Rewards:     append(models.RewardTable(nil), models.DefaultRewardTable...),
+			Leagues:     append(models.LeagueLadder(nil), models.DefaultLeagueLadder...),
+		},
+		Inventory: InventoryConfig{
+			Boosters:          append(models.BoosterCatalog(nil), models.DefaultBoosterCatalog...),
+			MaxLives:          models.DefaultLivesPolicy.Max,
+			LifeRegenInterval: Duration(models.DefaultLivesPolicy.RegenInterval),
+			LivesRefillCost:   900,
+		},
+		Levels: LevelsConfig{
+			MaxSessionDuration: Duration(2 * time.Hour),
+		},
+		CheckIn: CheckInConfig{
+			Calendar:       append(models.CheckInCalendar(nil), models.DefaultCheckInCalendar...),
+			RestoreCost:    200,
+			MaxRestoreDays: 3,
+		},
+		Quests: QuestsConfig{
+			Pool:   append(models.QuestPool(nil), models.DefaultQuestPool...),
+			PerDay: models.DefaultQuestsPerDay,
+		},
+		Clans: ClansConfig{
+			MaxMembers:        models.DefaultClanMaxMembers,
+			TournamentRewards: append(models.ClanRewardTable(nil), models.DefaultClanRewardTable...),
+		},
+		Friends: FriendsConfig{
+			MaxFriends: models.DefaultMaxFriends,
+		},
+	}
+}
+
+// Load builds the effective configuration: defaults, then the JSON file at path (if any),
+// then environment variable overrides. The result is validated before being returned.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %v", err)
+		}
+		// Decoding into the default lists would merge the file's entries into them field by field
+		defaultRewards, defaultLeagues, defaultBoosters, defaultCalendar, defaultQuests := cfg.Tournament.Rewards, cfg.Tournament.Leagues, cfg.Inventory.Boosters, cfg.CheckIn.Calendar, cfg.Quests.Pool
+		cfg.Tournament.Rewards, cfg.Tournament.Leagues, cfg.Inventory.Boosters, cfg.CheckIn.Calendar, cfg.Quests.Pool = nil, nil, nil, nil, nil
+		defaultClanRewards := cfg.Clans.TournamentRewards
+		cfg.Clans.TournamentRewards = nil
+
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+		if cfg.Tournament.Rewards == nil {
+			cfg.Tournament.Rewards = defaultRewards
+		}
+		if cfg.Tournament.Leagues == nil {
+			cfg.Tournament.Leagues = defaultLeagues
+		}
+		if cfg.Inventory.Boosters == nil {
+			cfg.Inventory.Boosters = defaultBoosters
+		}
+		if cfg.CheckIn.Calendar == nil {
+			cfg.CheckIn.Calendar = defaultCalendar
+		}
+		if cfg.Quests.Pool == nil {
+			cfg.Quests.Pool = defaultQuests
+		}
+		if cfg.Clans.TournamentRewards == nil {
+			cfg.Clans.TournamentRewards = defaultClanRewards
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides fields from environment variables. Variable names match the ones
+// used by fly.toml and the Dockerfile so existing deployments keep working.
+func (c *Config) applyEnv() error {
+	setString(&c.Server.Port, "PORT")
+	setString(&c.DynamoDB.Region, "DYNAMODB_REGION")
+	setString(&c.DynamoDB.Endpoint, "DYNAMODB_ENDPOINT")
+	setString(&c.DynamoDB.UsersTable, "USERS_TABLE")
+	setString(&c.DynamoDB.TournamentsTable, "TOURNAMENTS_TABLE")
+	setString(&c.DynamoDB.TournamentEntriesTable, "TOURNAMENT_ENTRIES_TABLE")
+	setString(&c.DynamoDB.UserHistoryTable, "USER_HISTORY_TABLE")
+	setString(&c.DynamoDB.LevelSessionsTable, "LEVEL_SESSIONS_TABLE")
+	setString(&c.DynamoDB.UserQuestsTable, "USER_QUESTS_TABLE")
+	setString(&c.DynamoDB.UserAchievementsTable, "USER_ACHIEVEMENTS_TABLE")
+	setString(&c.DynamoDB.SeasonProgressTable, "SEASON_PROGRESS_TABLE")
+	setString(&c.DynamoDB.LeagueHistoryTable, "LEAGUE_HISTORY_TABLE")
+	setString(&c.DynamoDB.ClansTable, "CLANS_TABLE")
+	setString(&c.DynamoDB.ClanMembersTable, "CLAN_MEMBERS_TABLE")
+	setString(&c.DynamoDB.ClanTournamentEntriesTable, "CLAN_TOURNAMENT_ENTRIES_TABLE")
+	setString(&c.DynamoDB.ClanContributionsTable, "CLAN_CONTRIBUTIONS_TABLE")
+	setString(&c.DynamoDB.FriendshipsTable, "FRIENDSHIPS_TABLE")
+	setString(&c.DynamoDB.MigrationsTable, "MIGRATIONS_TABLE")
+
+	// REDIS_HOST/REDIS_PORT are kept for backwards compatibility; REDIS_ADDR wins if both are set.
+	if host, port := os.Getenv("REDIS_HOST"), os.Getenv("REDIS_PORT"); host != "" {
+		if port == "" {
+			port = "6379"
+		}
+		c.Redis.Addr = host + ":" + port
+	}
+	setString(&c.Redis.Addr, "REDIS_ADDR")
+	setString(&c.Redis.Password, "REDIS_PASSWORD")
+
+	var errs []string
+	collect := func(err error) {
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	collect(setInt(&c.DynamoDB.MaxAttempts, "DYNAMODB_MAX_ATTEMPTS"))
+	collect(setDuration(&c.DynamoDB.RetryBaseDelay, "DYNAMODB_RETRY_BASE_DELAY"))
+	collect(setDuration(&c.DynamoDB.RetryMaxDelay, "DYNAMODB_RETRY_MAX_DELAY"))
+	collect(setDuration(&c.DynamoDB.CallTimeout, "DYNAMODB_CALL_TIMEOUT"))
+	collect(setInt(&c.Redis.DB, "REDIS_DB"))
+	collect(setBool(&c.Redis.TLS, "REDIS_TLS"))
+
+	collect(setDuration(&c.Server.ReadTimeout, "SERVER_READ_TIMEOUT"))
+	collect(setDuration(&c.Server.ReadHeaderTimeout, "SERVER_READ_HEADER_TIMEOUT"))
+	collect(setDuration(&c.Server.WriteTimeout, "SERVER_WRITE_TIMEOUT"))
+	collect(setDuration(&c.Server.IdleTimeout, "SERVER_IDLE_TIMEOUT"))
+	collect(setDuration(&c.Server.ShutdownTimeout, "SERVER_SHUTDOWN_TIMEOUT"))
+
+	setString(&c.Cache.Backend, "CACHE_BACKEND")
+	collect(setInt(&c.Cache.MaxEntries, "CACHE_MAX_ENTRIES"))
+	collect(setDuration(&c.Cache.GlobalLeaderboardTTL, "CACHE_GLOBAL_LEADERBOARD_TTL"))
+	collect(setDuration(&c.Cache.CountryLeaderboardTTL, "CACHE_COUNTRY_LEADERBOARD_TTL"))
+	collect(setDuration(&c.Cache.TournamentLeaderboardTTL, "CACHE_TOURNAMENT_LEADERBOARD_TTL"))
+	setString(&c.Cache.GlobalLeaderboardPolicy, "CACHE_GLOBAL_LEADERBOARD_POLICY")
+	setString(&c.Cache.CountryLeaderboardPolicy, "CACHE_COUNTRY_LEADERBOARD_POLICY")
+	setString(&c.Cache.TournamentLeaderboardPolicy, "CACHE_TOURNAMENT_LEADERBOARD_POLICY")
+
+	collect(setInt(&c.Tournament.SeatShards, "TOURNAMENT_SEAT_SHARDS"))
+	collect(setDuration(&c.Tournament.ClaimWindow, "TOURNAMENT_CLAIM_WINDOW"))
+
+	collect(setInt(&c.Inventory.MaxLives, "LIVES_MAX"))
+	collect(setDuration(&c.Inventory.LifeRegenInterval, "LIVES_REGEN_INTERVAL"))
+	collect(setInt(&c.Inventory.LivesRefillCost, "LIVES_REFILL_COST"))
+
+	collect(setDuration(&c.Levels.MaxSessionDuration, "LEVELS_MAX_SESSION_DURATION"))
+	setString(&c.Levels.CatalogFile, "LEVEL_CATALOG_FILE")
+
+	collect(setInt(&c.CheckIn.RestoreCost, "CHECK_IN_RESTORE_COST"))
+	collect(setInt(&c.CheckIn.MaxRestoreDays, "CHECK_IN_MAX_RESTORE_DAYS"))
+
+	collect(setInt(&c.Quests.PerDay, "QUESTS_PER_DAY"))
+
+	collect(setInt(&c.Clans.MaxMembers, "CLANS_MAX_MEMBERS"))
+
+	collect(setInt(&c.Friends.MaxFriends, "FRIENDS_MAX_FRIENDS"))
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid environment configuration: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Validate checks that the configuration is complete and consistent.
+func (c *Config) Validate() error {
+	var errs []string
+
+	if c.Server.Port == "" {
+		errs = append(errs, "server.port is required")
+	} else if p, err := strconv.Atoi(c.Server.Port); err != nil || p <= 0 || p > 65535 {
+		errs = append(errs, "server.port must be a valid TCP port")
+	}
+	for name, d := range map[string]Duration{
+		"server.readTimeout":       c.Server.ReadTimeout,
+		"server.readHeaderTimeout": c.Server.ReadHeaderTimeout,
+		"server.writeTimeout":      c.Server.WriteTimeout,
+		"server.idleTimeout":       c.Server.IdleTimeout,
+		"server.shutdownTimeout":   c.Server.ShutdownTimeout,
+	} {
+		if d <= 0 {
+			errs = append(errs, name+" must be positive")
+		}
+	}
+
+	if c.DynamoDB.Region == "" {
+		errs = append(errs, "dynamodb.region is required (DYNAMODB_REGION)")
+	}
+	if c.DynamoDB.UsersTable == "" || c.DynamoDB.TournamentsTable == "" || c.DynamoDB.TournamentEntriesTable == "" ||
+		c.DynamoDB.UserHistoryTable == "" || c.DynamoDB.LevelSessionsTable == "" || c.DynamoDB.UserQuestsTable == "" ||
+		c.DynamoDB.UserAchievementsTable == "" || c.DynamoDB.SeasonProgressTable == "" || c.DynamoDB.LeagueHistoryTable == "" ||
+		c.DynamoDB.ClansTable == "" || c.DynamoDB.ClanMembersTable == "" || c.DynamoDB.ClanTournamentEntriesTable == "" ||
+		c.DynamoDB.ClanContributionsTable == "" || c.DynamoDB.FriendshipsTable == "" || c.DynamoDB.MigrationsTable == "" {
+		errs = append(errs, "dynamodb table names must not be empty")
+	}
+	if c.DynamoDB.MaxAttempts < 1 {
+		errs = append(errs, "dynamodb.maxAttempts must be at least 1")
+	}
+	if c.DynamoDB.RetryBaseDelay <= 0 || c.DynamoDB.RetryMaxDelay < c.DynamoDB.RetryBaseDelay {
+		errs = append(errs, "dynamodb.retryBaseDelay must be positive and not exceed retryMaxDelay")
+	}
+	if c.DynamoDB.CallTimeout <= 0 {
+		errs = append(errs, "dynamodb.callTimeout must be positive")
+	}
+	if c.DynamoDB.Endpoint != "" && !strings.HasPrefix(c.DynamoDB.Endpoint, "http://") && !strings.HasPrefix(c.DynamoDB.Endpoint, "https://") {
+		errs = append(errs, "dynamodb.endpoint must be an http(s) URL")
+	}
+
+	if c.Cache.Backend == CacheBackendRedis && c.Redis.Addr == "" {
+		errs = append(errs, "redis.addr is required when cache.backend is redis")
+	}
+	if c.Redis.DB < 0 {
+		errs = append(errs, "redis.db must not be negative")
+	}
+
+	switch c.Cache.Backend {
+	case CacheBackendRedis, CacheBackendNone:
+	case CacheBackendMemory:
+		if c.Cache.MaxEntries <= 0 {
+			errs = append(errs, "cache.maxEntries must be positive for the memory backend")
+		}
+	default:
+		errs = append(errs, "cache.backend must be one of redis, memory, none")
+	}
+	for name, d := range map[string]Duration{
+		"cache.globalLeaderboardTTL":     c.Cache.GlobalLeaderboardTTL,
+		"cache.countryLeaderboardTTL":    c.Cache.CountryLeaderboardTTL,
+		"cache.tournamentLeaderboardTTL": c.Cache.TournamentLeaderboardTTL,
+	} {
+		if d < 0 {
+			errs = append(errs, name+" must not be negative")
+		}
+	}
+	for name, p := range map[string]string{
+		"cache.globalLeaderboardPolicy":     c.Cache.GlobalLeaderboardPolicy,
+		"cache.countryLeaderboardPolicy":    c.Cache.CountryLeaderboardPolicy,
+		"cache.tournamentLeaderboardPolicy": c.Cache.TournamentLeaderboardPolicy,
+	} {
+		if p != CachePolicyInvalidate && p != CachePolicyWriteThrough {
+			errs = append(errs, name+" must be invalidate or write-through")
+		}
+	}
+
+	if c.Tournament.SeatShards < 1 || c.Tournament.SeatShards > 1000 {
+		errs = append(errs, "tournament.seatShards must be between 1 and 1000")
+	}
+	if c.Tournament.ClaimWindow < Duration(time.Hour) {
+		errs = append(errs, "tournament.claimWindow must be at least 1h")
+	}
+	if len(c.Tournament.Rewards) == 0 {
+		errs = append(errs, "tournament.rewards must have at least one tier")
+	} else if err := c.Tournament.Rewards.Validate(); err != nil {
+		errs = append(errs, "tournament.rewards: "+err.Error())
+	}
+	if err := c.Tournament.Leagues.Validate(); err != nil {
+		errs = append(errs, "tournament.leagues: "+err.Error())
+	}
+	if err := c.Inventory.Boosters.Validate(); err != nil {
+		errs = append(errs, "inventory.boosters: "+err.Error())
+	}
+	if c.Inventory.MaxLives < 1 || c.Inventory.MaxLives > 100 {
+		errs = append(errs, "inventory.maxLives must be between 1 and 100")
+	}
+	if c.Inventory.LifeRegenInterval < Duration(time.Minute) {
+		errs = append(errs, "inventory.lifeRegenInterval must be at least 1m")
+	}
+	if c.Inventory.LivesRefillCost < 1 {
+		errs = append(errs, "inventory.livesRefillCost must be positive")
+	}
+	if c.Levels.MaxSessionDuration < Duration(time.Minute) {
+		errs = append(errs, "levels.maxSessionDuration must be at least 1m")
+	}
+	for _, tier := range c.Tournament.Rewards {
+		for id := range tier.Bundle.Boosters {
+			if _, ok := c.Inventory.Boosters.Find(id); !ok {
+				errs = append(errs, fmt.Sprintf("tournament.rewards: booster %q is not in inventory.boosters", id))
+			}
+		}
+	}
+	if err := c.CheckIn.Calendar.Validate(); err != nil {
+		errs = append(errs, "checkIn.calendar: "+err.Error())
+	}
+	for i, day := range c.CheckIn.Calendar {
+		for id := range day.Boosters {
+			if _, ok := c.Inventory.Boosters.Find(id); !ok {
+				errs = append(errs, fmt.Sprintf("checkIn.calendar: day %d booster %q is not in inventory.boosters", i+1, id))
+			}
+		}
+	}
+	if c.CheckIn.RestoreCost < 0 {
+		errs = append(errs, "checkIn.restoreCost must not be negative")
+	}
+	if c.CheckIn.MaxRestoreDays < 1 || c.CheckIn.MaxRestoreDays > 30 {
+		errs = append(errs, "checkIn.maxRestoreDays must be between 1 and 30")
+	}
+	if err := c.Quests.Pool.Validate(); err != nil {
+		errs = append(errs, "quests.pool: "+err.Error())
+	}
+	for _, q := range c.Quests.Pool {
+		for id := range q.Reward.Boosters {
+			if _, ok := c.Inventory.Boosters.Find(id); !ok {
+				errs = append(errs, fmt.Sprintf("quests.pool: quest %q booster %q is not in inventory.boosters", q.ID, id))
+			}
+		}
+	}
+	if c.Quests.PerDay < 1 || c.Quests.PerDay > len(c.Quests.Pool) {
+		errs = append(errs, "quests.perDay must be between 1 and the number of quests in quests.pool")
+	}
+	if err := c.SeasonPass.Seasons.Validate(); err != nil {
+		errs = append(errs, "seasonPass.seasons: "+err.Error())
+	}
+	for _, s := range c.SeasonPass.Seasons {
+		for _, id := range s.Boosters() {
+			if _, ok := c.Inventory.Boosters.Find(id); !ok {
+				errs = append(errs, fmt.Sprintf("seasonPass.seasons: season %q booster %q is not in inventory.boosters", s.ID, id))
+			}
+		}
+	}
+	if c.Clans.MaxMembers < 2 || c.Clans.MaxMembers > 500 {
+		errs = append(errs, "clans.maxMembers must be between 2 and 500")
+	}
+	if len(c.Clans.TournamentRewards) == 0 {
+		errs = append(errs, "clans.tournamentRewards must have at least one tier")
+	} else if err := c.Clans.TournamentRewards.Validate(); err != nil {
+		errs = append(errs, "clans.tournamentRewards: "+err.Error())
+	}
+	for _, tier := range c.Clans.TournamentRewards {
+		for id := range tier.Bundle.Boosters {
+			if _, ok := c.Inventory.Boosters.Find(id); !ok {
+				errs = append(errs, fmt.Sprintf("clans.tournamentRewards: booster %q is not in inventory.boosters", id))
+			}
+		}
+	}
+	if c.Friends.MaxFriends < 1 || c.Friends.MaxFriends > 1000 {
+		errs = append(errs, "friends.maxFriends must be between 1 and 1000")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// LoadLevelCatalog reads and validates the level catalog file named by levels.catalogFile.
+// Boosters the catalog grants must be listed in inventory.boosters.
+func (c *Config) LoadLevelCatalog() (*models.LevelCatalog, error) {
+	if c.Levels.CatalogFile == "" {
+		return models.DefaultLevelCatalog, nil
+	}
+
+	raw, err := os.ReadFile(c.Levels.CatalogFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read level catalog: %v", err)
+	}
+	var catalog models.LevelCatalog
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse level catalog %s: %v", c.Levels.CatalogFile, err)
+	}
+	if err := catalog.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid level catalog %s: %v", c.Levels.CatalogFile, err)
+	}
+	for _, id := range catalog.Boosters() {
+		if _, ok := c.Inventory.Boosters.Find(id); !ok {
+			return nil, fmt.Errorf("invalid level catalog %s: booster %q is not in inventory.boosters", c.Levels.CatalogFile, id)
+		}
+	}
+	return &catalog, nil
+}
+
+// Redacted returns a copy of the configuration that is safe to print or log.
+func (c *Config) Redacted() *Config {
+	cp := *c
+	if cp.Redis.Password != "" {
+		cp.Redis.Password = "********"
+	}
+	return &cp
+}
+
+// Dump writes the redacted effective configuration as indented JSON.
+func (c *Config) Dump() ([]byte, error) {
+	return json.MarshalIndent(c.Redacted(), "", "  ")
+}
+
+func setString(dst *string, key string) {
+	if v := os.Getenv(key); v != "" {
+		*dst = v
+	}
+}
+
+func setInt(dst *int, key string) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer", key)
+	}
+	*dst = n
+	return nil
+}
+
+func setBool(dst *bool, key string) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s must be a boolean", key)
+	}
+	*dst = b
+	return nil
+}
+
+func setDuration(dst *Duration, key string) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s must be a duration like \"30s\"", key)
+	}
+	*dst = Duration(d)
+	return nil
+}
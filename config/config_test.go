@@ -0,0 +1,454 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"good_blast/config"
+	"good_blast/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoad_DefaultsWithRequiredEnv(t *testing.T) {
+	t.Setenv("DYNAMODB_REGION", "eu-north-1")
+
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, "8080", cfg.Server.Port)
+	assert.Equal(t, "Users", cfg.DynamoDB.UsersTable)
+	assert.Equal(t, "localhost:6379", cfg.Redis.Addr)
+	assert.Equal(t, 60*time.Second, cfg.Cache.GlobalLeaderboardTTL.D())
+}
+
+func TestLoad_FileThenEnvOverrides(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"server": {"port": "9090", "writeTimeout": "30s"},
+		"dynamodb": {"region": "us-east-1", "endpoint": "http://localhost:8000"},
+		"redis": {"addr": "cache:6379", "db": 2},
+		"cache": {"countryLeaderboardTTL": "2m"}
+	}`)
+	t.Setenv("DYNAMODB_REGION", "")
+	t.Setenv("PORT", "7070")
+	t.Setenv("REDIS_PASSWORD", "secret")
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "7070", cfg.Server.Port)
+	assert.Equal(t, 30*time.Second, cfg.Server.WriteTimeout.D())
+	assert.Equal(t, 10*time.Second, cfg.Server.ReadTimeout.D()) // untouched default
+	assert.Equal(t, "us-east-1", cfg.DynamoDB.Region)
+	assert.Equal(t, "http://localhost:8000", cfg.DynamoDB.Endpoint)
+	assert.Equal(t, "cache:6379", cfg.Redis.Addr)
+	assert.Equal(t, 2, cfg.Redis.DB)
+	assert.Equal(t, "secret", cfg.Redis.Password)
+	assert.Equal(t, 2*time.Minute, cfg.Cache.CountryLeaderboardTTL.D())
+}
+
+func TestLoad_LegacyRedisHostPort(t *testing.T) {
+	t.Setenv("DYNAMODB_REGION", "eu-north-1")
+	t.Setenv("REDIS_HOST", "redis.internal")
+	t.Setenv("REDIS_PORT", "6380")
+
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, "redis.internal:6380", cfg.Redis.Addr)
+}
+
+func TestLoad_UnknownFieldRejected(t *testing.T) {
+	path := writeConfigFile(t, `{"dynamodb": {"region": "eu-north-1", "tabel": "x"}}`)
+
+	_, err := config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse config file")
+}
+
+func TestLoad_ValidationErrors(t *testing.T) {
+	t.Setenv("DYNAMODB_REGION", "")
+	t.Setenv("DYNAMODB_ENDPOINT", "localhost:8000")
+	t.Setenv("PORT", "not-a-port")
+
+	_, err := config.Load("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dynamodb.region is required")
+	assert.Contains(t, err.Error(), "dynamodb.endpoint must be an http(s) URL")
+	assert.Contains(t, err.Error(), "server.port must be a valid TCP port")
+}
+
+func TestLoad_TournamentSeatShards(t *testing.T) {
+	t.Setenv("DYNAMODB_REGION", "eu-north-1")
+
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, 8, cfg.Tournament.SeatShards)
+
+	t.Setenv("TOURNAMENT_SEAT_SHARDS", "0")
+	_, err = config.Load("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "tournament.seatShards must be between 1 and 1000")
+}
+
+func TestLoad_TournamentClaimWindow(t *testing.T) {
+	t.Setenv("DYNAMODB_REGION", "eu-north-1")
+
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, cfg.Tournament.ClaimWindow.D())
+
+	t.Setenv("TOURNAMENT_CLAIM_WINDOW", "72h")
+	cfg, err = config.Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, 72*time.Hour, cfg.Tournament.ClaimWindow.D())
+
+	t.Setenv("TOURNAMENT_CLAIM_WINDOW", "10m")
+	_, err = config.Load("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "tournament.claimWindow must be at least 1h")
+}
+
+func TestLoad_RewardTableReplacesDefault(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"tournament": {"rewards": [
+			{"minRank": 1, "maxRank": 1, "bundle": {"boosters": {"rocket": 2}, "cosmetics": ["gold-frame"]}},
+			{"minRank": 2, "maxRank": 5, "bundle": {"coins": 500}}
+		]}
+	}`)
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RewardTable{
+		{MinRank: 1, MaxRank: 1, Bundle: models.RewardBundle{Boosters: map[string]int{"rocket": 2}, Cosmetics: []string{"gold-frame"}}},
+		{MinRank: 2, MaxRank: 5, Bundle: models.RewardBundle{Coins: 500}},
+	}, cfg.Tournament.Rewards)
+	assert.Equal(t, 5000, models.DefaultRewardTable.ForRank(1).Coins) // the default is not modified
+}
+
+func TestLoad_InvalidRewardTable(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"tournament": {"rewards": [
+			{"minRank": 1, "maxRank": 3, "bundle": {"coins": 500}},
+			{"minRank": 3, "maxRank": 5, "bundle": {"coins": 100}}
+		]}
+	}`)
+
+	_, err := config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "tournament.rewards: ranks 3-5 overlap the previous tier")
+}
+
+func TestLoad_BoosterCatalogReplacesDefault(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"tournament": {"rewards": [{"minRank": 1, "maxRank": 1, "bundle": {"boosters": {"rocket": 1}}}]},
+		"inventory": {"boosters": [{"id": "rocket", "name": "Rocket", "price": 150}]}
+	}`)
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoosterCatalog{{ID: "rocket", Name: "Rocket", Price: 150}}, cfg.Inventory.Boosters)
+	assert.Len(t, models.DefaultBoosterCatalog, 3) // the default is not modified
+}
+
+func TestLoad_RewardBoosterNotInCatalog(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"tournament": {"rewards": [{"minRank": 1, "maxRank": 1, "bundle": {"boosters": {"laser": 1}}}]}
+	}`)
+
+	_, err := config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `tournament.rewards: booster "laser" is not in inventory.boosters`)
+}
+
+func TestLoad_InvalidBoosterCatalog(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"inventory": {"boosters": [
+			{"id": "hammer", "name": "Hammer", "price": 300},
+			{"id": "hammer", "name": "Big Hammer", "price": 600}
+		]}
+	}`)
+
+	_, err := config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `inventory.boosters: booster "hammer" is listed twice`)
+}
+
+func TestLoad_InvalidEnvDuration(t *testing.T) {
+	t.Setenv("DYNAMODB_REGION", "eu-north-1")
+	t.Setenv("CACHE_GLOBAL_LEADERBOARD_TTL", "sixty")
+
+	_, err := config.Load("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "CACHE_GLOBAL_LEADERBOARD_TTL")
+}
+
+func TestDump_RedactsPassword(t *testing.T) {
+	cfg := config.Default()
+	cfg.Redis.Password = "hunter2"
+
+	out, err := cfg.Dump()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "hunter2")
+	assert.Contains(t, string(out), `"readTimeout": "10s"`)
+	assert.Equal(t, "hunter2", cfg.Redis.Password) // original untouched
+}
+
+func TestLoad_LivesFromEnv(t *testing.T) {
+	t.Setenv("DYNAMODB_REGION", "eu-north-1")
+	t.Setenv("LIVES_MAX", "7")
+	t.Setenv("LIVES_REGEN_INTERVAL", "20m")
+
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, cfg.Inventory.MaxLives)
+	assert.Equal(t, 20*time.Minute, cfg.Inventory.LifeRegenInterval.D())
+	assert.Equal(t, 900, cfg.Inventory.LivesRefillCost)
+
+	t.Setenv("LIVES_REGEN_INTERVAL", "10s")
+	_, err = config.Load("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "inventory.lifeRegenInterval must be at least 1m")
+}
+
+func TestLoadLevelCatalog(t *testing.T) {
+	cfg := config.Default()
+	catalog, err := cfg.LoadLevelCatalog()
+	assert.NoError(t, err)
+	assert.Equal(t, models.DefaultLevelCatalog, catalog)
+
+	cfg.Levels.CatalogFile = writeConfigFile(t, `{
+		"version": "v7",
+		"default": {"difficulty": "normal", "coinReward": 10, "firstClearBonus": {"coins": 90}},
+		"levels": [{"level": 3, "difficulty": "hard", "firstClearBonus": {"boosters": {"hammer": 1}}}],
+		"milestones": [{"every": 5, "bundle": {"lives": 2}}]
+	}`)
+	catalog, err = cfg.LoadLevelCatalog()
+	assert.NoError(t, err)
+	assert.Equal(t, "v7", catalog.Version)
+	assert.Equal(t, models.DifficultyHard, catalog.Level(3).Difficulty)
+	assert.Equal(t, 10, catalog.Level(4).CoinReward)
+}
+
+func TestLoadLevelCatalog_ExampleFile(t *testing.T) {
+	cfg := config.Default()
+	cfg.Levels.CatalogFile = filepath.Join("..", "levels.example.json")
+
+	_, err := cfg.LoadLevelCatalog()
+	assert.NoError(t, err)
+}
+
+func TestLoadLevelCatalog_Invalid(t *testing.T) {
+	cfg := config.Default()
+
+	cfg.Levels.CatalogFile = writeConfigFile(t, `{"version": "v1", "default": {"difficulty": "extreme"}}`)
+	_, err := cfg.LoadLevelCatalog()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "default: difficulty must be one of normal, hard, super_hard")
+
+	cfg.Levels.CatalogFile = writeConfigFile(t, `{
+		"version": "v1",
+		"default": {"difficulty": "normal"},
+		"milestones": [{"every": 10, "bundle": {"boosters": {"laser": 1}}}]
+	}`)
+	_, err = cfg.LoadLevelCatalog()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `booster "laser" is not in inventory.boosters`)
+
+	cfg.Levels.CatalogFile = writeConfigFile(t, `{"default": {"difficulty": "normal"}}`)
+	_, err = cfg.LoadLevelCatalog()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "version is required")
+}
+
+func TestLoad_CheckInCalendar(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"checkIn": {"calendar": [{"coins": 50}, {"coins": 75, "boosters": {"hammer": 1}}, {"lives": 2}]}
+	}`)
+	t.Setenv("CHECK_IN_RESTORE_COST", "0")
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.Len(t, cfg.CheckIn.Calendar, 3)
+	assert.Equal(t, 0, cfg.CheckIn.RestoreCost)
+	assert.Equal(t, 3, cfg.CheckIn.MaxRestoreDays)
+	assert.Len(t, models.DefaultCheckInCalendar, 7) // the default is not modified
+
+	path = writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"checkIn": {"calendar": [{"coins": 50}, {}]}
+	}`)
+	_, err = config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checkIn.calendar: day 2 grants nothing")
+}
+
+func TestLoad_QuestPool(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"quests": {"pool": [
+			{"id": "clear_5", "description": "Clear 5 levels", "event": "level_cleared", "target": 5, "reward": {"coins": 200}},
+			{"id": "top_1", "description": "Win your group", "event": "tournament_rank", "target": 1, "maxRank": 1, "reward": {"boosters": {"hammer": 1}}}
+		]}
+	}`)
+	t.Setenv("QUESTS_PER_DAY", "2")
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Quests.Pool, 2)
+	assert.Equal(t, 2, cfg.Quests.PerDay)
+	assert.Len(t, models.DefaultQuestPool, 5) // the default is not modified
+
+	// More quests per day than the pool holds
+	t.Setenv("QUESTS_PER_DAY", "3")
+	_, err = config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "quests.perDay must be between 1 and the number of quests")
+
+	path = writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"quests": {"perDay": 1, "pool": [{"id": "top", "event": "tournament_rank", "target": 1, "reward": {"coins": 10}}]}
+	}`)
+	_, err = config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `quests.pool: quest "top": maxRank must be positive`)
+}
+
+func TestLoad_SeasonPass(t *testing.T) {
+	assert.Empty(t, config.Default().SeasonPass.Seasons) // off unless configured
+
+	path := writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"seasonPass": {"seasons": [{
+			"id": "s1", "name": "Spring", "startsAt": "2025-03-01T00:00:00Z", "endsAt": "2025-05-01T00:00:00Z",
+			"levelXP": 10, "placementXP": [{"minRank": 1, "maxRank": 3, "xp": 100}], "premiumCost": 1000,
+			"tiers": [{"xp": 100, "free": {"coins": 100}}, {"xp": 300, "free": {"coins": 200}, "premium": {"boosters": {"hammer": 1}}}]
+		}]}
+	}`)
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.Len(t, cfg.SeasonPass.Seasons, 1)
+	assert.Equal(t, 2, cfg.SeasonPass.Seasons[0].TierFor(300))
+
+	path = writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"seasonPass": {"seasons": [{
+			"id": "s1", "startsAt": "2025-03-01T00:00:00Z", "endsAt": "2025-05-01T00:00:00Z",
+			"tiers": [{"xp": 100, "free": {"boosters": {"shovel": 1}}}]
+		}]}
+	}`)
+	_, err = config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `seasonPass.seasons: season "s1" booster "shovel" is not in inventory.boosters`)
+
+	path = writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"seasonPass": {"seasons": [
+			{"id": "s1", "startsAt": "2025-03-01T00:00:00Z", "endsAt": "2025-05-01T00:00:00Z", "tiers": [{"xp": 100, "free": {"coins": 100}}]},
+			{"id": "s2", "startsAt": "2025-04-01T00:00:00Z", "endsAt": "2025-06-01T00:00:00Z", "tiers": [{"xp": 100, "free": {"coins": 100}}]}
+		]}
+	}`)
+	_, err = config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `seasonPass.seasons: seasons "s1" and "s2" overlap`)
+}
+
+func TestLoad_LeagueLadder(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"tournament": {"leagues": [
+			{"id": "rookie", "name": "Rookie", "rewardPercent": 100, "promoteTop": 3},
+			{"id": "pro", "name": "Pro", "rewardPercent": 250, "relegateBottom": 3}
+		]}
+	}`)
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Tournament.Leagues, 2)
+	assert.Len(t, models.DefaultLeagueLadder, 5) // the default is not modified
+
+	path = writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"tournament": {"leagues": [{"id": "rookie", "rewardPercent": 100, "promoteTop": 20, "relegateBottom": 20}]}
+	}`)
+	_, err = config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `tournament.leagues: league "rookie": promoteTop and relegateBottom must not be negative or exceed a group`)
+
+	path = writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"tournament": {"leagues": [{"id": "rookie", "rewardPercent": 100, "promoteTop": 3, "minGroupSize": 40}]}
+	}`)
+	_, err = config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `tournament.leagues: league "rookie": minGroupSize must be between 0 and 35`)
+}
+
+func TestLoad_ClanMaxMembers(t *testing.T) {
+	t.Setenv("DYNAMODB_REGION", "eu-north-1")
+
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, 50, cfg.Clans.MaxMembers)
+	assert.Equal(t, "Clans", cfg.DynamoDB.ClansTable)
+	assert.Equal(t, "ClanMembers", cfg.DynamoDB.ClanMembersTable)
+
+	t.Setenv("CLANS_MAX_MEMBERS", "1")
+	_, err = config.Load("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "clans.maxMembers must be between 2 and 500")
+}
+
+func TestLoad_ClanTournamentRewards(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"clans": {"tournamentRewards": [
+			{"minRank": 1, "maxRank": 2, "minContribution": 1, "bundle": {"coins": 300}},
+			{"minRank": 1, "maxRank": 1, "minContribution": 100, "bundle": {"coins": 900}}
+		]}
+	}`)
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, cfg.Clans.MaxMembers)
+	assert.Equal(t, 900, cfg.Clans.TournamentRewards.For(1, 150).Coins)
+	assert.Equal(t, 300, cfg.Clans.TournamentRewards.For(2, 150).Coins)
+	assert.Len(t, models.DefaultClanRewardTable, 9) // the default is not modified
+
+	path = writeConfigFile(t, `{
+		"dynamodb": {"region": "eu-north-1"},
+		"clans": {"tournamentRewards": [
+			{"minRank": 1, "maxRank": 3, "minContribution": 10, "bundle": {"coins": 300}},
+			{"minRank": 3, "maxRank": 5, "minContribution": 10, "bundle": {"coins": 100}}
+		]}
+	}`)
+	_, err = config.Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "clans.tournamentRewards: ranks 3-5 from contribution 10 overlap another tier")
+}
+
+func TestLoad_FriendsMaxFriends(t *testing.T) {
+	t.Setenv("DYNAMODB_REGION", "eu-north-1")
+
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, 100, cfg.Friends.MaxFriends)
+	assert.Equal(t, "Friendships", cfg.DynamoDB.FriendshipsTable)
+
+	t.Setenv("FRIENDS_MAX_FRIENDS", "0")
+	_, err = config.Load("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "friends.maxFriends must be between 1 and 1000")
+}
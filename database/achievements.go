@@ -0,0 +1,170 @@
+// database/achievements.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// achievementStatsUpdate builds the user update that adds delta to a user's achievement stats.
+func achievementStatsUpdate(userId string, delta models.AchievementStats) *dynamodb.Update {
+	var add []string
+	names := map[string]*string{}
+	values := map[string]*dynamodb.AttributeValue{}
+	for _, c := range []struct {
+		name, attr string
+		n          int
+	}{
+		{"#tw", "tournamentWins", delta.TournamentWins},
+		{"#pf", "podiumFinishes", delta.PodiumFinishes},
+		{"#ce", "coinsEarned", delta.CoinsEarned},
+	} {
+		if c.n == 0 {
+			continue
+		}
+		value := ":" + strings.TrimPrefix(c.name, "#")
+		add = append(add, c.name+" "+value)
+		names[c.name] = aws.String(c.attr)
+		values[value] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(c.n))}
+	}
+
+	return &dynamodb.Update{
+		TableName:                 aws.String(usersTable),
+		Key:                       map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(userId)}},
+		UpdateExpression:          aws.String("ADD " + strings.Join(add, ", ")),
+		ConditionExpression:       aws.String("attribute_exists(userId)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+}
+
+// AddAchievementStats adds delta to a user's lifetime achievement stats and returns the user as
+// updated. It returns ErrUserNotFound when the user does not exist.
+func (db *DynamoDB) AddAchievementStats(ctx context.Context, userId string, delta models.AchievementStats) (*models.User, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+	if delta.IsEmpty() {
+		return nil, fmt.Errorf("AddAchievementStats: nothing to add for %s", userId)
+	}
+
+	u := achievementStatsUpdate(userId, delta)
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 u.TableName,
+		Key:                       u.Key,
+		UpdateExpression:          u.UpdateExpression,
+		ConditionExpression:       u.ConditionExpression,
+		ExpressionAttributeNames:  u.ExpressionAttributeNames,
+		ExpressionAttributeValues: u.ExpressionAttributeValues,
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	}
+
+	var result *dynamodb.UpdateItemOutput
+	// Not idempotent: a retry after a write that landed would count twice
+	err := withRetry(ctx, "AddAchievementStats", false, func(ctx context.Context) error {
+		var err error
+		result, err = svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, errors.ErrUserNotFound
+		}
+		if errors.IsRetryable(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to add achievement stats: %w", err)
+	}
+
+	var user models.User
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+	return &user, nil
+}
+
+// UnlockAchievement records an unlocked achievement. An achievement that is already unlocked
+// keeps its original unlock time.
+func (db *DynamoDB) UnlockAchievement(ctx context.Context, a models.UnlockedAchievement) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	item, err := dynamodbattribute.MarshalMap(a)
+	if err != nil {
+		return fmt.Errorf("failed to marshal achievement: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(userAchievementsTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(achievementId)"),
+	}
+
+	err = withRetry(ctx, "UnlockAchievement", true, func(ctx context.Context) error {
+		_, err := svc.PutItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		return fmt.Errorf("failed to unlock achievement: %w", err)
+	}
+	return nil
+}
+
+// QueryUserAchievements retrieves every achievement a user has unlocked.
+func (db *DynamoDB) QueryUserAchievements(ctx context.Context, userId string) ([]models.UnlockedAchievement, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(userAchievementsTable),
+		KeyConditionExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userId)},
+		},
+	}
+
+	var unlocked []models.UnlockedAchievement
+	for {
+		var result *dynamodb.QueryOutput
+		err := withRetry(ctx, "QueryUserAchievements", true, func(ctx context.Context) error {
+			var err error
+			result, err = svc.QueryWithContext(ctx, input)
+			return err
+		})
+		if err != nil {
+			if errors.IsRetryable(err) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to query user achievements: %w", err)
+		}
+
+		var page []models.UnlockedAchievement
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal user achievements: %w", err)
+		}
+		unlocked = append(unlocked, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return unlocked, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
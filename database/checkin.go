@@ -0,0 +1,108 @@
+// database/checkin.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/google/uuid"
+)
+
+// checkInUpdate builds the user update that replaces the stored check-in state from with to and
+// applies b, conditioned on the stored state so a day is never checked in twice. A negative
+// b.Coins is a cost the balance must cover.
+func checkInUpdate(userId string, from, to models.CheckInState, b models.RewardBundle) *dynamodb.Update {
+	u := bundleUpdate(userId, b)
+	prependSet(u, "#cs = :streak, #lc = :day")
+	u.ExpressionAttributeNames["#cs"] = aws.String("checkInStreak")
+	u.ExpressionAttributeNames["#lc"] = aws.String("lastCheckIn")
+	u.ExpressionAttributeValues[":streak"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(to.CheckInStreak))}
+	u.ExpressionAttributeValues[":day"] = &dynamodb.AttributeValue{S: aws.String(to.LastCheckIn)}
+
+	// The streak only changes together with the day, so the day identifies the stored state
+	condition := "attribute_exists(userId) AND attribute_not_exists(#lc)"
+	if from.LastCheckIn != "" {
+		condition = "attribute_exists(userId) AND #lc = :oldDay"
+		u.ExpressionAttributeValues[":oldDay"] = &dynamodb.AttributeValue{S: aws.String(from.LastCheckIn)}
+	}
+	if b.Coins < 0 {
+		condition += " AND #c >= :cost"
+		u.ExpressionAttributeValues[":cost"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(-b.Coins))}
+	}
+	u.ConditionExpression = aws.String(condition)
+	return u
+}
+
+// CheckInTransaction replaces the user's check-in state from with to, applies entry.Coins and
+// entry.Bundle and records the history entry, atomically. A negative entry.Coins is a cost. It
+// returns ErrNotEnoughCoins when the balance doesn't cover the cost and ErrTransactionConflict
+// when the stored check-in state no longer equals from.
+func (db *DynamoDB) CheckInTransaction(ctx context.Context, entry models.HistoryEntry, from, to models.CheckInState) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+	fillHistoryEntry(&entry)
+
+	var bundle models.RewardBundle
+	if entry.Bundle != nil {
+		bundle = *entry.Bundle
+	}
+	bundle.Coins = entry.Coins
+	if err := prepareBundle(ctx, entry.UserID, bundle); err != nil {
+		return err
+	}
+
+	update := checkInUpdate(entry.UserID, from, to, bundle)
+	update.ReturnValuesOnConditionCheckFailure = aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld)
+
+	entryMap, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{Update: update},
+			{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(userHistoryTable),
+					Item:                entryMap,
+					ConditionExpression: aws.String("attribute_not_exists(entryId)"),
+				},
+			},
+		},
+	}
+
+	err = withRetry(ctx, "CheckInTransaction", true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if cancellationReason(err, 0) == reasonConditionalCheck {
+			old := cancellationItem(err, 0)
+			if old == nil {
+				return errors.ErrUserNotFound
+			}
+			var user models.User
+			if err := dynamodbattribute.UnmarshalMap(old, &user); err == nil && user.CheckInState == from && user.Coins < -entry.Coins {
+				return errors.ErrNotEnoughCoins
+			}
+			return errors.ErrTransactionConflict
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("CheckInTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+// database/clan_tournaments.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// Clan tournaments
+//
+// A clan entry's score always equals the sum of its ClanContributions items: every score a
+// member adds is written to both in one transaction. Members' scores only count from the moment
+// their clan entered the tournament.
+
+// clanEntryKey is the key of a clan's entry in a tournament.
+func clanEntryKey(tournamentID, clanID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"tournamentId": {S: aws.String(tournamentID)},
+		"clanId":       {S: aws.String(clanID)},
+	}
+}
+
+// clanContributionKey is the key of a member's contribution to a clan's tournament entry.
+func clanContributionKey(tournamentID, clanID, userID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"entryKey": {S: aws.String(models.ClanEntryKey(tournamentID, clanID))},
+		"userId":   {S: aws.String(userID)},
+	}
+}
+
+// GetClanTournamentEntry retrieves a clan's entry in a tournament; nil when the clan has not
+// entered it.
+func (db *DynamoDB) GetClanTournamentEntry(ctx context.Context, tournamentId, clanId string) (*models.ClanTournamentEntry, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.GetItemInput{
+		TableName:      aws.String(clanEntriesTable),
+		Key:            clanEntryKey(tournamentId, clanId),
+		ConsistentRead: aws.Bool(true),
+	}
+
+	var result *dynamodb.GetItemOutput
+	err := withRetry(ctx, "GetClanTournamentEntry", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.GetItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get clan tournament entry: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var entry models.ClanTournamentEntry
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal clan tournament entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// EnterClanTournament enters a clan in a tournament with a score of 0. The group is assigned
+// from the tournament's clan seat pool (see seats.go), so it holds at most ClanGroupCapacity
+// clans. The caller has already checked that t is active. It returns ErrClanAlreadyEntered when
+// the clan has entered before.
+func (db *DynamoDB) EnterClanTournament(ctx context.Context, entry models.ClanTournamentEntry, t *models.Tournament) (*models.ClanTournamentEntry, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	// Reject repeated entries before they burn a seat; the put condition below still guards the race
+	existing, err := db.GetClanTournamentEntry(ctx, t.TournamentID, entry.ClanID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.ErrClanAlreadyEntered
+	}
+
+	groupID, err := assignClanGroup(ctx, seats, t)
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return nil, err
+		}
+		log.Println("EnterClanTournament seat allocation error:", err)
+		return nil, fmt.Errorf("failed to allocate seat: %w", err)
+	}
+	entry.TournamentID = t.TournamentID
+	entry.GroupID = groupID
+	entry.Score = 0
+
+	item, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal clan tournament entry: %w", err)
+	}
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(clanEntriesTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(clanId)"), // one entry per clan
+	}
+
+	// Not retried: a retry after a put that landed would report the clan as already entered
+	err = withRetry(ctx, "EnterClanTournament", false, func(ctx context.Context) error {
+		_, err := svc.PutItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, errors.ErrClanAlreadyEntered
+		}
+		if errors.IsRetryable(err) {
+			return nil, err
+		}
+		log.Println("EnterClanTournament DynamoDB error:", err)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	log.Printf("Clan %s entered tournament %s in group %s", entry.ClanID, t.TournamentID, groupID)
+	return &entry, nil
+}
+
+// AddClanContribution adds a member's tournament score to their clan's entry and to their
+// contribution, atomically. It returns ErrClanNotEntered when the clan has not entered the
+// tournament.
+func (db *DynamoDB) AddClanContribution(ctx context.Context, tournamentId, clanId, userId string, increment int) error {
+	inc := &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(increment))}
+	items := []*dynamodb.TransactWriteItem{
+		{Update: &dynamodb.Update{
+			TableName:                 aws.String(clanEntriesTable),
+			Key:                       clanEntryKey(tournamentId, clanId),
+			UpdateExpression:          aws.String("ADD #s :inc"),
+			ConditionExpression:       aws.String("attribute_exists(clanId)"),
+			ExpressionAttributeNames:  map[string]*string{"#s": aws.String("score")},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":inc": inc},
+		}},
+		{Update: &dynamodb.Update{
+			TableName:        aws.String(clanContributionsTable),
+			Key:              clanContributionKey(tournamentId, clanId, userId),
+			UpdateExpression: aws.String("SET #t = :t, #c = :c ADD #s :inc"),
+			ExpressionAttributeNames: map[string]*string{
+				"#t": aws.String("tournamentId"),
+				"#c": aws.String("clanId"),
+				"#s": aws.String("score"),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":t":   {S: aws.String(tournamentId)},
+				":c":   {S: aws.String(clanId)},
+				":inc": inc,
+			},
+		}},
+	}
+
+	return runTransaction(ctx, "AddClanContribution", items, func(err error) error {
+		if cancellationReason(err, 0) == reasonConditionalCheck {
+			return errors.ErrClanNotEntered
+		}
+		return nil
+	})
+}
+
+// QueryClanTournamentGroup queries the ClanGroupScoreIndex for the clans of a group, highest
+// score first.
+func (db *DynamoDB) QueryClanTournamentGroup(ctx context.Context, groupId string) ([]models.ClanTournamentEntry, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(clanEntriesTable),
+		IndexName:              aws.String("ClanGroupScoreIndex"),
+		KeyConditionExpression: aws.String("groupId = :gid"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":gid": {S: aws.String(groupId)},
+		},
+		ScanIndexForward: aws.Bool(false), // descending by score
+		Limit:            aws.Int64(models.ClanGroupCapacity),
+	}
+
+	var result *dynamodb.QueryOutput
+	err := withRetry(ctx, "QueryClanTournamentGroup", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.QueryWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query clan tournament group: %w", err)
+	}
+
+	var entries []models.ClanTournamentEntry
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal clan tournament entries: %w", err)
+	}
+	return entries, nil
+}
+
+// QueryClanTournamentEntries retrieves one page of the clans entered in a tournament.
+func (db *DynamoDB) QueryClanTournamentEntries(ctx context.Context, tournamentId string, page models.PageRequest) (models.Page[models.ClanTournamentEntry], error) {
+	var out models.Page[models.ClanTournamentEntry]
+	if svc == nil {
+		return out, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(clanEntriesTable),
+		KeyConditionExpression: aws.String("tournamentId = :tid"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":tid": {S: aws.String(tournamentId)},
+		},
+	}
+
+	items, next, err := queryPage(ctx, input, "clan-entries:"+tournamentId, page, defaultEntriesPageSize, maxEntriesPageSize)
+	if err == errors.ErrInvalidCursor {
+		return out, err
+	}
+	if err != nil {
+		return out, fmt.Errorf("failed to query clan tournament entries: %w", err)
+	}
+
+	entries := make([]models.ClanTournamentEntry, 0, len(items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &entries); err != nil {
+		return out, fmt.Errorf("failed to unmarshal clan tournament entries: %w", err)
+	}
+	out.Items = entries
+	out.NextCursor = next
+	return out, nil
+}
+
+// QueryClanContributions retrieves every member's contribution to a clan's tournament entry.
+func (db *DynamoDB) QueryClanContributions(ctx context.Context, tournamentId, clanId string) ([]models.ClanContribution, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(clanContributionsTable),
+		KeyConditionExpression: aws.String("entryKey = :k"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":k": {S: aws.String(models.ClanEntryKey(tournamentId, clanId))},
+		},
+	}
+
+	var contributions []models.ClanContribution
+	for {
+		var result *dynamodb.QueryOutput
+		err := withRetry(ctx, "QueryClanContributions", true, func(ctx context.Context) error {
+			var err error
+			result, err = svc.QueryWithContext(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query clan contributions: %w", err)
+		}
+
+		var page []models.ClanContribution
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal clan contributions: %w", err)
+		}
+		contributions = append(contributions, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return contributions, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// clanRewardPaidUpdate builds the update that marks a contribution's clan reward as paid,
+// conditioned on the contribution existing and its reward not being paid yet.
+func clanRewardPaidUpdate(tournamentID, clanID, userID string) *dynamodb.Update {
+	return &dynamodb.Update{
+		TableName:                 aws.String(clanContributionsTable),
+		Key:                       clanContributionKey(tournamentID, clanID, userID),
+		UpdateExpression:          aws.String("SET #rp = :trueVal"),
+		ConditionExpression:       aws.String("attribute_exists(userId) AND attribute_not_exists(#rp)"),
+		ExpressionAttributeNames:  map[string]*string{"#rp": aws.String("rewardPaid")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":trueVal": {BOOL: aws.Bool(true)}},
+	}
+}
+
+// PayClanRewardTransaction grants entry.Bundle and entry.Coins to a member for their
+// contribution to a clan's entry in entry.TournamentID, marks the reward paid and records the
+// history entry, atomically. It returns ErrRewardAlreadyClaimed when the reward has been paid.
+func (db *DynamoDB) PayClanRewardTransaction(ctx context.Context, entry models.HistoryEntry, clanId string) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+	fillHistoryEntry(&entry)
+
+	var bundle models.RewardBundle
+	if entry.Bundle != nil {
+		bundle = *entry.Bundle
+	}
+	bundle.Coins = entry.Coins
+
+	entryMap, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	err = grantBundle(ctx, "PayClanRewardTransaction", entry.UserID, bundle,
+		&dynamodb.TransactWriteItem{Update: clanRewardPaidUpdate(entry.TournamentID, clanId, entry.UserID)},
+		&dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName:           aws.String(userHistoryTable),
+				Item:                entryMap,
+				ConditionExpression: aws.String("attribute_not_exists(entryId)"),
+			},
+		},
+	)
+	if err != nil {
+		if err == errors.ErrUserNotFound || cancellationReason(err, 0) == reasonConditionalCheck {
+			return errors.ErrUserNotFound
+		}
+		if cancellationReason(err, 1) == reasonConditionalCheck {
+			return errors.ErrRewardAlreadyClaimed
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("PayClanRewardTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,581 @@
+// database/clans.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// Page sizes for clan searches and the clan leaderboard
+const (
+	defaultClanPageSize = 20
+	maxClanPageSize     = 100
+)
+
+// Clan bookkeeping
+//
+// A clan's memberCount and totalLevel always equal the count and level sum of its ClanMembers
+// items: every write that adds, removes or re-levels a member item adjusts the clan item in the
+// same transaction. The user item's clanId is set and removed in those transactions too, so a
+// user is in at most one clan.
+
+// clanKey is the key of a clan item.
+func clanKey(clanID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{"clanId": {S: aws.String(clanID)}}
+}
+
+// clanMemberKey is the key of a user's membership item in a clan.
+func clanMemberKey(clanID, userID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"clanId": {S: aws.String(clanID)},
+		"userId": {S: aws.String(userID)},
+	}
+}
+
+// clanTotalsUpdate builds the clan update that adds members and levels to its totals. Both may
+// be negative.
+func clanTotalsUpdate(clanID string, members, levels int) *dynamodb.Update {
+	return &dynamodb.Update{
+		TableName:           aws.String(clansTable),
+		Key:                 clanKey(clanID),
+		UpdateExpression:    aws.String("ADD #mc :m, #tl :l"),
+		ConditionExpression: aws.String("attribute_exists(clanId)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#mc": aws.String("memberCount"),
+			"#tl": aws.String("totalLevel"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":m": {N: aws.String(strconv.Itoa(members))},
+			":l": {N: aws.String(strconv.Itoa(levels))},
+		},
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+}
+
+// userJoinClanUpdate builds the user update that puts a user in a clan. It is conditioned on the
+// user being in no clan and still at the level the membership counts.
+func userJoinClanUpdate(member models.ClanMember) *dynamodb.Update {
+	return &dynamodb.Update{
+		TableName:           aws.String(usersTable),
+		Key:                 map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(member.UserID)}},
+		UpdateExpression:    aws.String("SET #cl = :c"),
+		ConditionExpression: aws.String("attribute_exists(userId) AND attribute_not_exists(#cl) AND #lvl = :lvl"),
+		ExpressionAttributeNames: map[string]*string{
+			"#cl":  aws.String("clanId"),
+			"#lvl": aws.String("level"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":c":   {S: aws.String(member.ClanID)},
+			":lvl": {N: aws.String(strconv.Itoa(member.Level))},
+		},
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+}
+
+// userLeaveClanUpdate builds the user update that takes a user out of a clan.
+func userLeaveClanUpdate(member models.ClanMember) *dynamodb.Update {
+	return &dynamodb.Update{
+		TableName:                 aws.String(usersTable),
+		Key:                       map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(member.UserID)}},
+		UpdateExpression:          aws.String("REMOVE #cl"),
+		ConditionExpression:       aws.String("#cl = :c"),
+		ExpressionAttributeNames:  map[string]*string{"#cl": aws.String("clanId")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":c": {S: aws.String(member.ClanID)}},
+	}
+}
+
+// userJoinFailure returns the error for a failed userJoinClanUpdate condition, given the user
+// item reported with the cancellation.
+func userJoinFailure(item map[string]*dynamodb.AttributeValue) error {
+	if item == nil {
+		return errors.ErrUserNotFound
+	}
+	if _, ok := item["clanId"]; ok {
+		return errors.ErrAlreadyInClan
+	}
+	return errors.ErrTransactionConflict // the user's level changed since it was read
+}
+
+// CreateClanTransaction creates a clan with leader as its only member and puts the leader in it,
+// atomically. It returns ErrUserNotFound, ErrAlreadyInClan, or ErrTransactionConflict when the
+// leader's level changed since leader.Level was read.
+func (db *DynamoDB) CreateClanTransaction(ctx context.Context, clan models.Clan, leader models.ClanMember) error {
+	clanItem, err := dynamodbattribute.MarshalMap(clan)
+	if err != nil {
+		return fmt.Errorf("failed to marshal clan: %w", err)
+	}
+	memberItem, err := dynamodbattribute.MarshalMap(leader)
+	if err != nil {
+		return fmt.Errorf("failed to marshal clan member: %w", err)
+	}
+
+	return runTransaction(ctx, "CreateClanTransaction", []*dynamodb.TransactWriteItem{
+		{
+			Put: &dynamodb.Put{
+				TableName:           aws.String(clansTable),
+				Item:                clanItem,
+				ConditionExpression: aws.String("attribute_not_exists(clanId)"),
+			},
+		},
+		{
+			Put: &dynamodb.Put{
+				TableName: aws.String(clanMembersTable),
+				Item:      memberItem,
+			},
+		},
+		{Update: userJoinClanUpdate(leader)},
+	}, func(err error) error {
+		if cancellationReason(err, 2) == reasonConditionalCheck {
+			return userJoinFailure(cancellationItem(err, 2))
+		}
+		return nil
+	})
+}
+
+// JoinClanTransaction adds member to their clan, counting member.Level in the clan's total, and
+// puts the user in the clan, atomically. It returns ErrClanNotFound, ErrClanFull when the clan
+// has maxMembers members already, ErrUserNotFound, ErrAlreadyInClan, or ErrTransactionConflict
+// when the user's level changed since member.Level was read.
+func (db *DynamoDB) JoinClanTransaction(ctx context.Context, member models.ClanMember, maxMembers int) error {
+	memberItem, err := dynamodbattribute.MarshalMap(member)
+	if err != nil {
+		return fmt.Errorf("failed to marshal clan member: %w", err)
+	}
+
+	join := clanTotalsUpdate(member.ClanID, 1, member.Level)
+	join.ConditionExpression = aws.String("attribute_exists(clanId) AND #mc < :max")
+	join.ExpressionAttributeValues[":max"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(maxMembers))}
+
+	return runTransaction(ctx, "JoinClanTransaction", []*dynamodb.TransactWriteItem{
+		{Update: join},
+		{
+			Put: &dynamodb.Put{
+				TableName:           aws.String(clanMembersTable),
+				Item:                memberItem,
+				ConditionExpression: aws.String("attribute_not_exists(userId)"),
+			},
+		},
+		{Update: userJoinClanUpdate(member)},
+	}, func(err error) error {
+		switch {
+		case cancellationReason(err, 0) == reasonConditionalCheck:
+			if cancellationItem(err, 0) == nil {
+				return errors.ErrClanNotFound
+			}
+			return errors.ErrClanFull
+		case cancellationReason(err, 1) == reasonConditionalCheck:
+			return errors.ErrAlreadyInClan
+		case cancellationReason(err, 2) == reasonConditionalCheck:
+			return userJoinFailure(cancellationItem(err, 2))
+		}
+		return nil
+	})
+}
+
+// RemoveClanMemberTransaction takes member out of their clan, as read: it fails when the
+// member's role or counted level changed since. When by is set the member is being kicked and
+// by must still hold their role. The leader can't be removed. It returns
+// ErrClanLeaderMustTransfer when member is the leader, ErrClanMemberNotFound when they are no
+// longer in the clan, ErrClanPermissionDenied when by lost their role, and
+// ErrTransactionConflict when the member changed.
+func (db *DynamoDB) RemoveClanMemberTransaction(ctx context.Context, member models.ClanMember, by *models.ClanMember) error {
+	leave := clanTotalsUpdate(member.ClanID, -1, -member.Level)
+	leave.ConditionExpression = aws.String("attribute_exists(clanId) AND #ld <> :u")
+	leave.ExpressionAttributeNames["#ld"] = aws.String("leaderId")
+	leave.ExpressionAttributeValues[":u"] = &dynamodb.AttributeValue{S: aws.String(member.UserID)}
+
+	items := []*dynamodb.TransactWriteItem{
+		{Update: leave},
+		{
+			Delete: &dynamodb.Delete{
+				TableName:           aws.String(clanMembersTable),
+				Key:                 clanMemberKey(member.ClanID, member.UserID),
+				ConditionExpression: aws.String("#lvl = :lvl AND #r = :r"),
+				ExpressionAttributeNames: map[string]*string{
+					"#lvl": aws.String("level"),
+					"#r":   aws.String("role"),
+				},
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":lvl": {N: aws.String(strconv.Itoa(member.Level))},
+					":r":   {S: aws.String(member.Role)},
+				},
+				ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+			},
+		},
+		{Update: userLeaveClanUpdate(member)},
+	}
+	if by != nil {
+		items = append(items, &dynamodb.TransactWriteItem{
+			ConditionCheck: &dynamodb.ConditionCheck{
+				TableName:                 aws.String(clanMembersTable),
+				Key:                       clanMemberKey(by.ClanID, by.UserID),
+				ConditionExpression:       aws.String("#r = :r"),
+				ExpressionAttributeNames:  map[string]*string{"#r": aws.String("role")},
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":r": {S: aws.String(by.Role)}},
+			},
+		})
+	}
+
+	return runTransaction(ctx, "RemoveClanMemberTransaction", items, func(err error) error {
+		switch {
+		case cancellationReason(err, 0) == reasonConditionalCheck:
+			if cancellationItem(err, 0) == nil {
+				return errors.ErrClanNotFound
+			}
+			return errors.ErrClanLeaderMustTransfer
+		case cancellationReason(err, 1) == reasonConditionalCheck:
+			if cancellationItem(err, 1) == nil {
+				return errors.ErrClanMemberNotFound
+			}
+			return errors.ErrTransactionConflict
+		case cancellationReason(err, 2) == reasonConditionalCheck:
+			return errors.ErrClanMemberNotFound
+		case cancellationReason(err, 3) == reasonConditionalCheck:
+			return errors.ErrClanPermissionDenied
+		}
+		return nil
+	})
+}
+
+// DisbandClanTransaction deletes a clan whose only member is its leader, together with the
+// leader's membership, atomically. It returns ErrClanNotFound, ErrClanLeaderMustTransfer when
+// the clan has other members or another leader, and ErrClanMemberNotFound when leader is no
+// longer in the clan.
+func (db *DynamoDB) DisbandClanTransaction(ctx context.Context, leader models.ClanMember) error {
+	return runTransaction(ctx, "DisbandClanTransaction", []*dynamodb.TransactWriteItem{
+		{
+			Delete: &dynamodb.Delete{
+				TableName:           aws.String(clansTable),
+				Key:                 clanKey(leader.ClanID),
+				ConditionExpression: aws.String("#ld = :u AND #mc = :one"),
+				ExpressionAttributeNames: map[string]*string{
+					"#ld": aws.String("leaderId"),
+					"#mc": aws.String("memberCount"),
+				},
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":u":   {S: aws.String(leader.UserID)},
+					":one": {N: aws.String("1")},
+				},
+				ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+			},
+		},
+		{
+			Delete: &dynamodb.Delete{
+				TableName:           aws.String(clanMembersTable),
+				Key:                 clanMemberKey(leader.ClanID, leader.UserID),
+				ConditionExpression: aws.String("attribute_exists(userId)"),
+			},
+		},
+		{Update: userLeaveClanUpdate(leader)},
+	}, func(err error) error {
+		switch {
+		case cancellationReason(err, 0) == reasonConditionalCheck:
+			if cancellationItem(err, 0) == nil {
+				return errors.ErrClanNotFound
+			}
+			return errors.ErrClanLeaderMustTransfer
+		case cancellationReason(err, 1) == reasonConditionalCheck, cancellationReason(err, 2) == reasonConditionalCheck:
+			return errors.ErrClanMemberNotFound
+		}
+		return nil
+	})
+}
+
+// SetClanRoleTransaction gives member a new role on behalf of by, the clan's leader. Making
+// member the leader hands leadership over: by becomes an officer. The leader's own role can
+// only change by handing over leadership. It returns ErrClanMemberNotFound when member is no
+// longer in the clan and ErrClanPermissionDenied when by is no longer the leader or member is.
+func (db *DynamoDB) SetClanRoleTransaction(ctx context.Context, member models.ClanMember, role string, by models.ClanMember) error {
+	// Both conditions compare the current role with :leader
+	setRole := func(m models.ClanMember, role, condition string) *dynamodb.Update {
+		return &dynamodb.Update{
+			TableName:                aws.String(clanMembersTable),
+			Key:                      clanMemberKey(m.ClanID, m.UserID),
+			UpdateExpression:         aws.String("SET #r = :r"),
+			ConditionExpression:      aws.String(condition),
+			ExpressionAttributeNames: map[string]*string{"#r": aws.String("role")},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":r":      {S: aws.String(role)},
+				":leader": {S: aws.String(models.ClanRoleLeader)},
+			},
+			ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+		}
+	}
+
+	var items []*dynamodb.TransactWriteItem
+	if role == models.ClanRoleLeader {
+		items = []*dynamodb.TransactWriteItem{
+			{Update: setRole(member, models.ClanRoleLeader, "attribute_exists(userId) AND #r <> :leader")},
+			{Update: setRole(by, models.ClanRoleOfficer, "#r = :leader")},
+			{
+				Update: &dynamodb.Update{
+					TableName:                aws.String(clansTable),
+					Key:                      clanKey(member.ClanID),
+					UpdateExpression:         aws.String("SET #ld = :to"),
+					ConditionExpression:      aws.String("#ld = :from"),
+					ExpressionAttributeNames: map[string]*string{"#ld": aws.String("leaderId")},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+						":to":   {S: aws.String(member.UserID)},
+						":from": {S: aws.String(by.UserID)},
+					},
+				},
+			},
+		}
+	} else {
+		items = []*dynamodb.TransactWriteItem{
+			{Update: setRole(member, role, "attribute_exists(userId) AND #r <> :leader")},
+			{
+				ConditionCheck: &dynamodb.ConditionCheck{
+					TableName:                 aws.String(clanMembersTable),
+					Key:                       clanMemberKey(by.ClanID, by.UserID),
+					ConditionExpression:       aws.String("#r = :leader"),
+					ExpressionAttributeNames:  map[string]*string{"#r": aws.String("role")},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":leader": {S: aws.String(models.ClanRoleLeader)}},
+				},
+			},
+		}
+	}
+
+	return runTransaction(ctx, "SetClanRoleTransaction", items, func(err error) error {
+		if cancellationReason(err, 0) == reasonConditionalCheck && cancellationItem(err, 0) == nil {
+			return errors.ErrClanMemberNotFound
+		}
+		for i := range items {
+			if cancellationReason(err, i) == reasonConditionalCheck {
+				return errors.ErrClanPermissionDenied
+			}
+		}
+		return nil
+	})
+}
+
+// SyncClanMemberLevel moves the level member counts in their clan's total to level. It is
+// conditioned on the membership still counting member.Level, so concurrent syncs never count a
+// level twice. It returns ErrClanMemberNotFound when the member left and ErrTransactionConflict
+// when their counted level changed.
+func (db *DynamoDB) SyncClanMemberLevel(ctx context.Context, member models.ClanMember, level int) error {
+	return runTransaction(ctx, "SyncClanMemberLevel", []*dynamodb.TransactWriteItem{
+		{
+			Update: &dynamodb.Update{
+				TableName:                aws.String(clanMembersTable),
+				Key:                      clanMemberKey(member.ClanID, member.UserID),
+				UpdateExpression:         aws.String("SET #lvl = :to"),
+				ConditionExpression:      aws.String("#lvl = :from"),
+				ExpressionAttributeNames: map[string]*string{"#lvl": aws.String("level")},
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":to":   {N: aws.String(strconv.Itoa(level))},
+					":from": {N: aws.String(strconv.Itoa(member.Level))},
+				},
+				ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+			},
+		},
+		{Update: clanTotalsUpdate(member.ClanID, 0, level-member.Level)},
+	}, func(err error) error {
+		if cancellationReason(err, 0) == reasonConditionalCheck {
+			if cancellationItem(err, 0) == nil {
+				return errors.ErrClanMemberNotFound
+			}
+			return errors.ErrTransactionConflict
+		}
+		if cancellationReason(err, 1) == reasonConditionalCheck {
+			return errors.ErrClanNotFound
+		}
+		return nil
+	})
+}
+
+// GetClan retrieves a clan by clanId; nil when it doesn't exist.
+func (db *DynamoDB) GetClan(ctx context.Context, clanId string) (*models.Clan, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.GetItemInput{
+		TableName:      aws.String(clansTable),
+		Key:            clanKey(clanId),
+		ConsistentRead: aws.Bool(true),
+	}
+
+	var result *dynamodb.GetItemOutput
+	err := withRetry(ctx, "GetClan", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.GetItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get clan: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var clan models.Clan
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &clan); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal clan: %w", err)
+	}
+	return &clan, nil
+}
+
+// GetClanMember retrieves a user's membership in a clan; nil when they are not a member.
+func (db *DynamoDB) GetClanMember(ctx context.Context, clanId, userId string) (*models.ClanMember, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.GetItemInput{
+		TableName:      aws.String(clanMembersTable),
+		Key:            clanMemberKey(clanId, userId),
+		ConsistentRead: aws.Bool(true),
+	}
+
+	var result *dynamodb.GetItemOutput
+	err := withRetry(ctx, "GetClanMember", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.GetItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get clan member: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var member models.ClanMember
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &member); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal clan member: %w", err)
+	}
+	return &member, nil
+}
+
+// QueryClanMembers retrieves every member of a clan.
+func (db *DynamoDB) QueryClanMembers(ctx context.Context, clanId string) ([]models.ClanMember, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(clanMembersTable),
+		KeyConditionExpression: aws.String("clanId = :c"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":c": {S: aws.String(clanId)},
+		},
+	}
+
+	var members []models.ClanMember
+	for {
+		var result *dynamodb.QueryOutput
+		err := withRetry(ctx, "QueryClanMembers", true, func(ctx context.Context) error {
+			var err error
+			result, err = svc.QueryWithContext(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query clan members: %w", err)
+		}
+
+		var page []models.ClanMember
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal clan members: %w", err)
+		}
+		members = append(members, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return members, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// SearchClans retrieves one page of clans whose name starts with namePrefix, in name order.
+// With a country only that country's clans are searched; an empty prefix matches every name.
+func (db *DynamoDB) SearchClans(ctx context.Context, namePrefix, country string, page models.PageRequest) (models.Page[models.Clan], error) {
+	var out models.Page[models.Clan]
+	if svc == nil {
+		return out, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(clansTable),
+		IndexName:              aws.String("ClanNameIndex"),
+		KeyConditionExpression: aws.String("clanPK = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": {S: aws.String(models.ClanGlobalPK)},
+		},
+	}
+	if country != "" {
+		input.IndexName = aws.String("CountryClanIndex")
+		input.KeyConditionExpression = aws.String("country = :pk")
+		input.ExpressionAttributeValues[":pk"] = &dynamodb.AttributeValue{S: aws.String(country)}
+	}
+	if prefix := models.ClanNameKey(namePrefix); prefix != "" {
+		input.KeyConditionExpression = aws.String(*input.KeyConditionExpression + " AND begins_with(nameKey, :prefix)")
+		input.ExpressionAttributeValues[":prefix"] = &dynamodb.AttributeValue{S: aws.String(prefix)}
+	}
+
+	scope := "clans:" + country + ":" + models.ClanNameKey(namePrefix)
+	items, next, err := queryPage(ctx, input, scope, page, defaultClanPageSize, maxClanPageSize)
+	if err == errors.ErrInvalidCursor {
+		return out, err
+	}
+	if err != nil {
+		return out, fmt.Errorf("failed to search clans: %w", err)
+	}
+
+	clans := make([]models.Clan, 0, len(items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &clans); err != nil {
+		return out, fmt.Errorf("failed to unmarshal clans: %w", err)
+	}
+	out.Items = clans
+	out.NextCursor = next
+	return out, nil
+}
+
+// QueryClanLeaderboard queries the ClanLevelIndex for one page of clans, highest total member
+// level first.
+func (db *DynamoDB) QueryClanLeaderboard(ctx context.Context, page models.PageRequest) (models.Page[models.Clan], error) {
+	var out models.Page[models.Clan]
+	if svc == nil {
+		return out, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(clansTable),
+		IndexName:              aws.String("ClanLevelIndex"),
+		KeyConditionExpression: aws.String("clanPK = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": {S: aws.String(models.ClanGlobalPK)},
+		},
+		ScanIndexForward: aws.Bool(false), // descending by total level
+	}
+
+	items, next, err := queryPage(ctx, input, "clan-leaderboard", page, defaultClanPageSize, maxClanPageSize)
+	if err == errors.ErrInvalidCursor {
+		return out, err
+	}
+	if err != nil {
+		return out, fmt.Errorf("failed to query clan leaderboard: %w", err)
+	}
+
+	clans := make([]models.Clan, 0, len(items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &clans); err != nil {
+		return out, fmt.Errorf("failed to unmarshal clans: %w", err)
+	}
+	out.Items = clans
+	out.NextCursor = next
+	return out, nil
+}
@@ -5,10 +5,11 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
+	"strconv"
 	"sync"
 	"time"
 
+	"good_blast/config"
 	"good_blast/errors"
 	"good_blast/models"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/google/uuid"
 )
 
 // DynamoDB is a wrapper struct to implement DatabaseInterface
@@ -28,40 +30,92 @@ var (
 	svcOnce  sync.Once
 	svcError error
 
-	// Table names from environment variables
+	// Table names from configuration
 	usersTable             string
 	tournamentsTable       string
 	tournamentEntriesTable string
+	userHistoryTable       string
+	levelSessionsTable     string
+	userQuestsTable        string
+	userAchievementsTable  string
+	seasonProgressTable    string
+	leagueHistoryTable     string
+	clansTable             string
+	clanMembersTable       string
+	clanEntriesTable       string
+	clanContributionsTable string
+	friendshipsTable       string
 )
 
-func InitDynamoDB() error {
+// InitDynamoDB creates the shared DynamoDB client from the given configuration.
+func InitDynamoDB(cfg config.DynamoDBConfig) error {
 	log.Println("InitDynamoDB: Starting initialization...")
 
-	region := os.Getenv("DYNAMODB_REGION")
-	log.Printf("InitDynamoDB: DYNAMODB_REGION=%s", region)
-	if region == "" {
-		return fmt.Errorf("DYNAMODB_REGION environment variable not set")
-	}
-
-	usersTable = os.Getenv("USERS_TABLE")
-	tournamentsTable = os.Getenv("TOURNAMENTS_TABLE")
-	tournamentEntriesTable = os.Getenv("TOURNAMENT_ENTRIES_TABLE")
+	log.Printf("InitDynamoDB: region=%s", cfg.Region)
+	if cfg.Region == "" {
+		return fmt.Errorf("DynamoDB region not set")
+	}
+
+	usersTable = cfg.UsersTable
+	tournamentsTable = cfg.TournamentsTable
+	tournamentEntriesTable = cfg.TournamentEntriesTable
+	userHistoryTable = cfg.UserHistoryTable
+	levelSessionsTable = cfg.LevelSessionsTable
+	userQuestsTable = cfg.UserQuestsTable
+	userAchievementsTable = cfg.UserAchievementsTable
+	seasonProgressTable = cfg.SeasonProgressTable
+	leagueHistoryTable = cfg.LeagueHistoryTable
+	clansTable = cfg.ClansTable
+	clanMembersTable = cfg.ClanMembersTable
+	clanEntriesTable = cfg.ClanTournamentEntriesTable
+	clanContributionsTable = cfg.ClanContributionsTable
+	friendshipsTable = cfg.FriendshipsTable
 
 	// Log table names
 	log.Printf("InitDynamoDB: USERS_TABLE=%s", usersTable)
 	log.Printf("InitDynamoDB: TOURNAMENTS_TABLE=%s", tournamentsTable)
 	log.Printf("InitDynamoDB: TOURNAMENT_ENTRIES_TABLE=%s", tournamentEntriesTable)
-
-	if usersTable == "" || tournamentsTable == "" || tournamentEntriesTable == "" {
-		return fmt.Errorf("one or more DynamoDB table environment variables are not set")
-	}
-
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(region),
-	})
+	log.Printf("InitDynamoDB: USER_HISTORY_TABLE=%s", userHistoryTable)
+	log.Printf("InitDynamoDB: LEVEL_SESSIONS_TABLE=%s", levelSessionsTable)
+	log.Printf("InitDynamoDB: USER_QUESTS_TABLE=%s", userQuestsTable)
+	log.Printf("InitDynamoDB: USER_ACHIEVEMENTS_TABLE=%s", userAchievementsTable)
+	log.Printf("InitDynamoDB: SEASON_PROGRESS_TABLE=%s", seasonProgressTable)
+	log.Printf("InitDynamoDB: LEAGUE_HISTORY_TABLE=%s", leagueHistoryTable)
+	log.Printf("InitDynamoDB: CLANS_TABLE=%s", clansTable)
+	log.Printf("InitDynamoDB: CLAN_MEMBERS_TABLE=%s", clanMembersTable)
+	log.Printf("InitDynamoDB: CLAN_TOURNAMENT_ENTRIES_TABLE=%s", clanEntriesTable)
+	log.Printf("InitDynamoDB: CLAN_CONTRIBUTIONS_TABLE=%s", clanContributionsTable)
+	log.Printf("InitDynamoDB: FRIENDSHIPS_TABLE=%s", friendshipsTable)
+
+	if usersTable == "" || tournamentsTable == "" || tournamentEntriesTable == "" || userHistoryTable == "" ||
+		levelSessionsTable == "" || userQuestsTable == "" || userAchievementsTable == "" ||
+		seasonProgressTable == "" || leagueHistoryTable == "" || clansTable == "" || clanMembersTable == "" ||
+		clanEntriesTable == "" || clanContributionsTable == "" || friendshipsTable == "" {
+		return fmt.Errorf("one or more DynamoDB table names are not set")
+	}
+
+	retryPolicy = RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   cfg.RetryBaseDelay.D(),
+		MaxDelay:    cfg.RetryMaxDelay.D(),
+		CallTimeout: cfg.CallTimeout.D(),
+	}
+
+	awsCfg := &aws.Config{
+		Region: aws.String(cfg.Region),
+		// withRetry owns retries so backoff and error classification happen in one place
+		MaxRetries: aws.Int(0),
+	}
+	if cfg.Endpoint != "" {
+		// Point at DynamoDB Local or another compatible endpoint
+		log.Printf("InitDynamoDB: using endpoint override %s", cfg.Endpoint)
+		awsCfg.Endpoint = aws.String(cfg.Endpoint)
+	}
+
+	sess, err := session.NewSession(awsCfg)
 	if err != nil {
 		log.Printf("InitDynamoDB: failed to create AWS session: %v", err)
-		return fmt.Errorf("failed to create AWS session: %v", err)
+		return fmt.Errorf("failed to create AWS session: %w", err)
 	}
 
 	svc = dynamodb.New(sess)
@@ -80,7 +134,7 @@ func (db *DynamoDB) PutUser(ctx context.Context, user models.User) error {
 
 	av, err := dynamodbattribute.MarshalMap(user)
 	if err != nil {
-		return fmt.Errorf("failed to marshal user: %v", err)
+		return fmt.Errorf("failed to marshal user: %w", err)
 	}
 
 	input := &dynamodb.PutItemInput{
@@ -88,9 +142,12 @@ func (db *DynamoDB) PutUser(ctx context.Context, user models.User) error {
 		Item:      av,
 	}
 
-	_, err = svc.PutItemWithContext(ctx, input)
+	err = withRetry(ctx, "PutUser", true, func(ctx context.Context) error {
+		_, err := svc.PutItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to put user: %v", err)
+		return fmt.Errorf("failed to put user: %w", err)
 	}
 
 	return nil
@@ -109,9 +166,14 @@ func (db *DynamoDB) GetUser(ctx context.Context, userId string) (*models.User, e
 		},
 	}
 
-	result, err := svc.GetItemWithContext(ctx, input)
+	var result *dynamodb.GetItemOutput
+	err := withRetry(ctx, "GetUser", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.GetItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %v", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 	if result.Item == nil {
 		// User not found
@@ -121,43 +183,12 @@ func (db *DynamoDB) GetUser(ctx context.Context, userId string) (*models.User, e
 	var user models.User
 	err = dynamodbattribute.UnmarshalMap(result.Item, &user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal user: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
 	}
 
 	return &user, nil
 }
 
-// UpdateUserCoinsAndLevel updates the user's level and coin balance
-func (db *DynamoDB) UpdateUserCoinsAndLevel(ctx context.Context, userId string, newLevel, newCoins int) error {
-	if svc == nil {
-		return fmt.Errorf("DynamoDB client not initialized")
-	}
-
-	input := &dynamodb.UpdateItemInput{
-		TableName: aws.String(usersTable),
-		Key: map[string]*dynamodb.AttributeValue{
-			"userId": {S: aws.String(userId)},
-		},
-		UpdateExpression: aws.String("SET #lvl = :lvlVal, #cns = :coinsVal"),
-		ExpressionAttributeNames: map[string]*string{
-			"#lvl": aws.String("level"),
-			"#cns": aws.String("coins"),
-		},
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":lvlVal":   {N: aws.String(fmt.Sprintf("%d", newLevel))},
-			":coinsVal": {N: aws.String(fmt.Sprintf("%d", newCoins))},
-		},
-		ReturnValues: aws.String("UPDATED_NEW"),
-	}
-
-	_, err := svc.UpdateItemWithContext(ctx, input)
-	if err != nil {
-		return fmt.Errorf("failed to update user: %v", err)
-	}
-
-	return nil
-}
-
 // PutTournament inserts a new tournament into the Tournaments table
 func (db *DynamoDB) PutTournament(ctx context.Context, tournament models.Tournament) error {
 	if svc == nil {
@@ -166,7 +197,7 @@ func (db *DynamoDB) PutTournament(ctx context.Context, tournament models.Tournam
 
 	av, err := dynamodbattribute.MarshalMap(tournament)
 	if err != nil {
-		return fmt.Errorf("failed to marshal tournament: %v", err)
+		return fmt.Errorf("failed to marshal tournament: %w", err)
 	}
 
 	input := &dynamodb.PutItemInput{
@@ -174,9 +205,12 @@ func (db *DynamoDB) PutTournament(ctx context.Context, tournament models.Tournam
 		Item:      av,
 	}
 
-	_, err = svc.PutItemWithContext(ctx, input)
+	err = withRetry(ctx, "PutTournament", true, func(ctx context.Context) error {
+		_, err := svc.PutItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to put tournament: %v", err)
+		return fmt.Errorf("failed to put tournament: %w", err)
 	}
 
 	return nil
@@ -195,9 +229,14 @@ func (db *DynamoDB) GetTournament(ctx context.Context, tournamentId string) (*mo
 		},
 	}
 
-	result, err := svc.GetItemWithContext(ctx, input)
+	var result *dynamodb.GetItemOutput
+	err := withRetry(ctx, "GetTournament", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.GetItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tournament: %v", err)
+		return nil, fmt.Errorf("failed to get tournament: %w", err)
 	}
 	if result.Item == nil {
 		return nil, nil
@@ -206,7 +245,7 @@ func (db *DynamoDB) GetTournament(ctx context.Context, tournamentId string) (*mo
 	var t models.Tournament
 	err = dynamodbattribute.UnmarshalMap(result.Item, &t)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal tournament: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal tournament: %w", err)
 	}
 	return &t, nil
 }
@@ -232,9 +271,84 @@ func (db *DynamoDB) UpdateTournamentStatus(ctx context.Context, tournamentId str
 		ReturnValues: aws.String("UPDATED_NEW"),
 	}
 
-	_, err := svc.UpdateItemWithContext(ctx, input)
+	err := withRetry(ctx, "UpdateTournamentStatus", true, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update tournament status: %v", err)
+		return fmt.Errorf("failed to update tournament status: %w", err)
+	}
+	return nil
+}
+
+// CloseTournament deactivates a tournament and records the deadline for claiming its rewards
+func (db *DynamoDB) CloseTournament(ctx context.Context, tournamentId, claimDeadline string) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(tournamentsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"tournamentId": {S: aws.String(tournamentId)},
+		},
+		UpdateExpression:    aws.String("SET #act = :false, #cd = :deadline"),
+		ConditionExpression: aws.String("attribute_exists(tournamentId)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#act": aws.String("active"),
+			"#cd":  aws.String("claimDeadline"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":false":    {BOOL: aws.Bool(false)},
+			":deadline": {S: aws.String(claimDeadline)},
+		},
+	}
+
+	err := withRetry(ctx, "CloseTournament", true, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return errors.ErrTournamentNotFound
+		}
+		return fmt.Errorf("failed to close tournament: %w", err)
+	}
+	return nil
+}
+
+// CancelTournament deactivates a tournament and marks it cancelled so no rewards can be claimed
+func (db *DynamoDB) CancelTournament(ctx context.Context, tournamentId string) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(tournamentsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"tournamentId": {S: aws.String(tournamentId)},
+		},
+		UpdateExpression:    aws.String("SET #act = :false, #can = :true"),
+		ConditionExpression: aws.String("attribute_exists(tournamentId)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#act": aws.String("active"),
+			"#can": aws.String("cancelled"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":false": {BOOL: aws.Bool(false)},
+			":true":  {BOOL: aws.Bool(true)},
+		},
+	}
+
+	err := withRetry(ctx, "CancelTournament", true, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return errors.ErrTournamentNotFound
+		}
+		return fmt.Errorf("failed to cancel tournament: %w", err)
 	}
 	return nil
 }
@@ -247,7 +361,7 @@ func (db *DynamoDB) PutTournamentEntry(ctx context.Context, entry models.Tournam
 
 	av, err := dynamodbattribute.MarshalMap(entry)
 	if err != nil {
-		return fmt.Errorf("failed to marshal tournament entry: %v", err)
+		return fmt.Errorf("failed to marshal tournament entry: %w", err)
 	}
 
 	input := &dynamodb.PutItemInput{
@@ -255,9 +369,12 @@ func (db *DynamoDB) PutTournamentEntry(ctx context.Context, entry models.Tournam
 		Item:      av,
 	}
 
-	_, err = svc.PutItemWithContext(ctx, input)
+	err = withRetry(ctx, "PutTournamentEntry", true, func(ctx context.Context) error {
+		_, err := svc.PutItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to put tournament entry: %v", err)
+		return fmt.Errorf("failed to put tournament entry: %w", err)
 	}
 	return nil
 }
@@ -276,9 +393,14 @@ func (db *DynamoDB) GetTournamentEntry(ctx context.Context, tournamentId, userId
 		},
 	}
 
-	result, err := svc.GetItemWithContext(ctx, input)
+	var result *dynamodb.GetItemOutput
+	err := withRetry(ctx, "GetTournamentEntry", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.GetItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tournament entry: %v", err)
+		return nil, fmt.Errorf("failed to get tournament entry: %w", err)
 	}
 	if result.Item == nil {
 		return nil, nil
@@ -287,7 +409,7 @@ func (db *DynamoDB) GetTournamentEntry(ctx context.Context, tournamentId, userId
 	var entry models.TournamentEntry
 	err = dynamodbattribute.UnmarshalMap(result.Item, &entry)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal tournament entry: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal tournament entry: %w", err)
 	}
 
 	return &entry, nil
@@ -315,17 +437,43 @@ func (db *DynamoDB) UpdateTournamentScore(ctx context.Context, tournamentId, use
 		ReturnValues: aws.String("UPDATED_NEW"),
 	}
 
-	_, err := svc.UpdateItemWithContext(ctx, input)
+	err := withRetry(ctx, "UpdateTournamentScore", false, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update tournament score: %v", err)
+		return fmt.Errorf("failed to update tournament score: %w", err)
 	}
 	return nil
 }
 
-// QueryTournamentEntries retrieves all entries for a specific tournament
-func (db *DynamoDB) QueryTournamentEntries(ctx context.Context, tournamentId string) ([]models.TournamentEntry, error) {
+// leaderboardProjection limits leaderboard queries to the fields leaderboards show,
+// leaving out the user's inventory.
+var (
+	leaderboardProjection     = aws.String("#uid, #un, #lvl, #cn, #ctry, #gpk")
+	leaderboardProjectedNames = map[string]*string{
+		"#uid":  aws.String("userId"),
+		"#un":   aws.String("username"),
+		"#lvl":  aws.String("level"),
+		"#cn":   aws.String("coins"),
+		"#ctry": aws.String("country"),
+		"#gpk":  aws.String("globalPK"),
+	}
+)
+
+// Page sizes for the paginated queries
+const (
+	defaultLeaderboardPageSize = 1000
+	maxLeaderboardPageSize     = 1000
+	defaultEntriesPageSize     = 500
+	maxEntriesPageSize         = 1000
+)
+
+// QueryTournamentEntries retrieves one page of entries for a specific tournament, ordered by userId
+func (db *DynamoDB) QueryTournamentEntries(ctx context.Context, tournamentId string, page models.PageRequest) (models.Page[models.TournamentEntry], error) {
+	var out models.Page[models.TournamentEntry]
 	if svc == nil {
-		return nil, fmt.Errorf("DynamoDB client not initialized")
+		return out, fmt.Errorf("DynamoDB client not initialized")
 	}
 
 	input := &dynamodb.QueryInput{
@@ -336,80 +484,143 @@ func (db *DynamoDB) QueryTournamentEntries(ctx context.Context, tournamentId str
 		},
 	}
 
-	result, err := svc.QueryWithContext(ctx, input)
+	items, next, err := queryPage(ctx, input, "entries:"+tournamentId, page, defaultEntriesPageSize, maxEntriesPageSize)
+	if err == errors.ErrInvalidCursor {
+		return out, err
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query tournament entries: %v", err)
+		return out, fmt.Errorf("failed to query tournament entries: %w", err)
 	}
 
-	entries := make([]models.TournamentEntry, 0, *result.Count)
-	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &entries)
+	entries := make([]models.TournamentEntry, 0, len(items))
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &entries)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal tournament entries: %v", err)
+		return out, fmt.Errorf("failed to unmarshal tournament entries: %w", err)
 	}
-	return entries, nil
+	out.Items = entries
+	out.NextCursor = next
+	return out, nil
 }
 
-// QueryGlobalLeaderboard queries the GlobalLevelIndex to retrieve top 1000 users globally
-func (db *DynamoDB) QueryGlobalLeaderboard(ctx context.Context) ([]models.User, error) {
+// QueryUnclaimedEntriesByUser queries the UserEntriesIndex for one page of a user's entries in
+// tournaments since sinceTournamentId (inclusive) whose reward is neither claimed, refunded nor expired,
+// newest tournament first. Entries that earn no reward are included; ranks are not known here.
+func (db *DynamoDB) QueryUnclaimedEntriesByUser(ctx context.Context, userId, sinceTournamentId string, page models.PageRequest) (models.Page[models.TournamentEntry], error) {
+	var out models.Page[models.TournamentEntry]
 	if svc == nil {
-		return nil, fmt.Errorf("DynamoDB client not initialized")
+		return out, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(tournamentEntriesTable),
+		IndexName:              aws.String("UserEntriesIndex"),
+		KeyConditionExpression: aws.String("userId = :uid AND tournamentId >= :since"),
+		FilterExpression:       aws.String("(attribute_not_exists(#cr) OR #cr = :false) AND attribute_not_exists(#rf) AND attribute_not_exists(#ex)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#cr": aws.String("claimedReward"),
+			"#rf": aws.String("refunded"),
+			"#ex": aws.String("expired"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":uid":   {S: aws.String(userId)},
+			":since": {S: aws.String(sinceTournamentId)},
+			":false": {BOOL: aws.Bool(false)},
+		},
+		ScanIndexForward: aws.Bool(false), // newest tournament first
+	}
+
+	items, next, err := queryPage(ctx, input, "unclaimed:"+userId+":"+sinceTournamentId, page, defaultEntriesPageSize, maxEntriesPageSize)
+	if err == errors.ErrInvalidCursor {
+		return out, err
+	}
+	if err != nil {
+		return out, fmt.Errorf("failed to query unclaimed entries: %w", err)
+	}
+
+	entries := make([]models.TournamentEntry, 0, len(items))
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &entries)
+	if err != nil {
+		return out, fmt.Errorf("failed to unmarshal tournament entries: %w", err)
+	}
+	out.Items = entries
+	out.NextCursor = next
+	return out, nil
+}
+
+// QueryGlobalLeaderboard queries the GlobalLevelIndex for one page of users globally, highest level first
+func (db *DynamoDB) QueryGlobalLeaderboard(ctx context.Context, page models.PageRequest) (models.Page[models.User], error) {
+	var out models.Page[models.User]
+	if svc == nil {
+		return out, fmt.Errorf("DynamoDB client not initialized")
 	}
 
 	input := &dynamodb.QueryInput{
-		TableName:              aws.String(usersTable),
-		IndexName:              aws.String("GlobalLevelIndex"),
-		KeyConditionExpression: aws.String("globalPK = :g"),
+		TableName:                aws.String(usersTable),
+		IndexName:                aws.String("GlobalLevelIndex"),
+		ProjectionExpression:     leaderboardProjection,
+		ExpressionAttributeNames: leaderboardProjectedNames,
+		KeyConditionExpression:   aws.String("globalPK = :g"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":g": {S: aws.String("GLOBAL")},
 		},
 		ScanIndexForward: aws.Bool(false), // descending by level
-		Limit:            aws.Int64(1000), // Set limit to 1000
 	}
 
-	result, err := svc.QueryWithContext(ctx, input)
+	items, next, err := queryPage(ctx, input, "global", page, defaultLeaderboardPageSize, maxLeaderboardPageSize)
+	if err == errors.ErrInvalidCursor {
+		return out, err
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query global leaderboard: %v", err)
+		return out, fmt.Errorf("failed to query global leaderboard: %w", err)
 	}
 
 	var users []models.User
-	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &users)
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &users)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal users: %v", err)
+		return out, fmt.Errorf("failed to unmarshal users: %w", err)
 	}
-	return users, nil
+	out.Items = users
+	out.NextCursor = next
+	return out, nil
 }
 
-// QueryUsersByCountryLevel queries the CountryLevelIndex to retrieve top 1000 users in a country
-func (db *DynamoDB) QueryUsersByCountryLevel(ctx context.Context, country string) ([]models.User, error) {
+// QueryUsersByCountryLevel queries the CountryLevelIndex for one page of users in a country, highest level first
+func (db *DynamoDB) QueryUsersByCountryLevel(ctx context.Context, country string, page models.PageRequest) (models.Page[models.User], error) {
+	var out models.Page[models.User]
 	if svc == nil {
-		return nil, fmt.Errorf("DynamoDB client not initialized")
+		return out, fmt.Errorf("DynamoDB client not initialized")
 	}
 
 	input := &dynamodb.QueryInput{
-		TableName:              aws.String(usersTable),
-		IndexName:              aws.String("CountryLevelIndex"), // Your GSI name
-		KeyConditionExpression: aws.String("country = :c"),
+		TableName:                aws.String(usersTable),
+		IndexName:                aws.String("CountryLevelIndex"), // Your GSI name
+		ProjectionExpression:     leaderboardProjection,
+		ExpressionAttributeNames: leaderboardProjectedNames,
+		KeyConditionExpression:   aws.String("country = :c"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":c": {S: aws.String(country)},
 		},
 		// false => descending order by the sort key (level)
 		ScanIndexForward: aws.Bool(false),
-		Limit:            aws.Int64(1000), // Set limit to 1000
 	}
 
-	result, err := svc.QueryWithContext(ctx, input)
+	items, next, err := queryPage(ctx, input, "country:"+country, page, defaultLeaderboardPageSize, maxLeaderboardPageSize)
+	if err == errors.ErrInvalidCursor {
+		return out, err
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error querying CountryLevelIndex: %w", err)
+		return out, fmt.Errorf("error querying CountryLevelIndex: %w", err)
 	}
 
 	var users []models.User
-	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &users)
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &users)
 	if err != nil {
 		log.Println("Error unmarshaling country leaderboard:", err)
-		return nil, fmt.Errorf("failed to unmarshal users: %v", err)
+		return out, fmt.Errorf("failed to unmarshal users: %w", err)
 	}
-
-	return users, nil
+	out.Items = users
+	out.NextCursor = next
+	return out, nil
 }
 
 // QueryTournamentEntriesByGroupScore queries the GroupScoreIndex to retrieve top 35 users in a group
@@ -426,31 +637,58 @@ func (db *DynamoDB) QueryTournamentEntriesByGroupScore(ctx context.Context, grou
 			":gid": {S: aws.String(groupId)},
 		},
 		ScanIndexForward: aws.Bool(false), // descending by score
-		Limit:            aws.Int64(35),   // Set limit to 35
+		Limit:            aws.Int64(models.GroupCapacity),
 	}
 
-	result, err := svc.QueryWithContext(ctx, input)
+	var result *dynamodb.QueryOutput
+	err := withRetry(ctx, "QueryTournamentEntriesByGroupScore", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.QueryWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query tournament entries by group score: %v", err)
+		return nil, fmt.Errorf("failed to query tournament entries by group score: %w", err)
 	}
 
 	var entries []models.TournamentEntry
 	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &entries)
 	if err != nil {
 		log.Println("Error unmarshaling group leaderboard:", err)
-		return nil, fmt.Errorf("failed to unmarshal tournament entries: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal tournament entries: %w", err)
 	}
 
 	return entries, nil
 }
 
-// EnterTournamentTransaction handles the transaction logic to enter a tournament
-func (db *DynamoDB) EnterTournamentTransaction(ctx context.Context, userID string, level, coins int, t *models.Tournament) error {
+// EnterTournamentTransaction handles the transaction logic to enter a tournament.
+// The group is assigned from a sharded seat counter of the user's league (see seats.go), so
+// concurrent joins never write the tournament row; they only check that it is still active and
+// not cancelled, so a join racing EndTournament or CancelTournament is rejected with
+// ErrTournamentNotActive instead of charging the fee.
+func (db *DynamoDB) EnterTournamentTransaction(ctx context.Context, userID string, level, coins int, t *models.Tournament, league string) error {
 	if svc == nil {
 		return fmt.Errorf("DynamoDB client not initialized")
 	}
 
-	// 1. Update User Row: Deduct 500 coins, ensure coins >= 500 and level >= 10.
+	// Reject repeated joins before they burn a seat; the put condition below still guards the race
+	existing, err := db.GetTournamentEntry(ctx, t.TournamentID, userID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.ErrAlreadyInTournament
+	}
+
+	groupID, err := assignGroup(ctx, seats, t, league)
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("EnterTournamentTransaction seat allocation error:", err)
+		return fmt.Errorf("failed to allocate seat: %w", err)
+	}
+
+	// 1. Update User Row: Deduct the entry fee, ensure the user can pay it and is at least the minimum entry level.
 	updateUser := &dynamodb.Update{
 		TableName:                aws.String(usersTable),
 		Key:                      map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(userID)}},
@@ -458,177 +696,278 @@ func (db *DynamoDB) EnterTournamentTransaction(ctx context.Context, userID strin
 		ConditionExpression:      aws.String("#c >= :cost AND #lvl >= :minLvl"),
 		ExpressionAttributeNames: map[string]*string{"#c": aws.String("coins"), "#lvl": aws.String("level")},
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":cost":   {N: aws.String("500")},
-			":minLvl": {N: aws.String("10")},
+			":cost":   {N: aws.String(strconv.Itoa(models.EntryFee))},
+			":minLvl": {N: aws.String(strconv.Itoa(models.MinEntryLevel))},
 		},
 	}
 
-	// 2. Update Tournaments Row: Increment currentGroupCount. If it hits 35, increment currentGroupIndex & reset currentGroupCount=1.
-	groupIndex := t.CurrentGroupIndex
-	groupCount := t.CurrentGroupCount
-
-	newGroupIndex := groupIndex
-	newGroupCount := groupCount + 1
-	if newGroupCount > 35 {
-		newGroupIndex = groupIndex + 1
-		newGroupCount = 1
-	}
-
-	updateTournament := &dynamodb.Update{
-		TableName:                aws.String(tournamentsTable),
-		Key:                      map[string]*dynamodb.AttributeValue{"tournamentId": {S: aws.String(t.TournamentID)}},
-		UpdateExpression:         aws.String("SET #gi = :newIndex, #gc = :newCount"),
-		ConditionExpression:      aws.String("#gi = :oldIndex AND #gc = :oldCount"),
-		ExpressionAttributeNames: map[string]*string{"#gi": aws.String("currentGroupIndex"), "#gc": aws.String("currentGroupCount")},
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":oldIndex": {N: aws.String(fmt.Sprintf("%d", groupIndex))},
-			":oldCount": {N: aws.String(fmt.Sprintf("%d", groupCount))},
-			":newIndex": {N: aws.String(fmt.Sprintf("%d", newGroupIndex))},
-			":newCount": {N: aws.String(fmt.Sprintf("%d", newGroupCount))},
-		},
-		ReturnValuesOnConditionCheckFailure: aws.String("NONE"),
-	}
-
-	// 3. Put the new entry in TournamentEntries with a unique groupID.
-	groupID := fmt.Sprintf("%s-group-%d", t.TournamentID, newGroupIndex)
-
+	// 2. Put the new entry in TournamentEntries with the assigned groupID.
 	entry := models.TournamentEntry{
 		TournamentID:  t.TournamentID,
 		UserID:        userID,
 		Score:         0,
 		GroupID:       groupID,
 		ClaimedReward: false,
+		League:        league,
 	}
 
 	entryMap, err := dynamodbattribute.MarshalMap(entry)
 	if err != nil {
-		if tcErr, ok := err.(*dynamodb.TransactionCanceledException); ok {
-			for _, r := range tcErr.CancellationReasons {
-				if aws.StringValue(r.Code) == "ConditionalCheckFailed" {
-					return errors.ErrAlreadyInTournament
-				}
-				// Handle other cancellation reasons if necessary
-			}
-			return fmt.Errorf("transaction canceled for unknown reasons")
-		} else if aerr, ok := err.(awserr.Error); ok {
-			log.Println("DynamoDB error:", aerr.Error())
-			return fmt.Errorf("database error")
-		} else {
-			log.Println("Unknown error:", err.Error())
-			return fmt.Errorf("unknown error")
-		}
+		return fmt.Errorf("failed to marshal tournament entry: %w", err)
 	}
 
 	putEntry := &dynamodb.Put{
-		TableName: aws.String(tournamentEntriesTable),
-		Item:      entryMap,
+		TableName:           aws.String(tournamentEntriesTable),
+		Item:                entryMap,
+		ConditionExpression: aws.String("attribute_not_exists(userId)"), // one entry per user
 	}
 
-	// Build the transaction input.
+	// 3. Check the tournament is still open.
+	checkActive := &dynamodb.ConditionCheck{
+		TableName:                aws.String(tournamentsTable),
+		Key:                      map[string]*dynamodb.AttributeValue{"tournamentId": {S: aws.String(t.TournamentID)}},
+		ConditionExpression:      aws.String("#act = :true AND attribute_not_exists(#cx)"),
+		ExpressionAttributeNames: map[string]*string{"#act": aws.String("active"), "#cx": aws.String("cancelled")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":true": {BOOL: aws.Bool(true)},
+		},
+	}
+
+	// Build the transaction input. The token makes retries of this exact transaction idempotent.
 	inputTxn := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
 		TransactItems: []*dynamodb.TransactWriteItem{
 			{Update: updateUser},
-			{Update: updateTournament},
 			{Put: putEntry},
+			{ConditionCheck: checkActive},
 		},
 	}
 
 	// Execute the transaction.
-	_, err = svc.TransactWriteItemsWithContext(ctx, inputTxn)
-	if err != nil {
-		// Handle specific DynamoDB errors.
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case dynamodb.ErrCodeTransactionCanceledException:
-				// Log the full error message for debugging
-				log.Printf("Transaction canceled: %v", aerr.Message())
-				// TODO: Implement more specific error handling if possible
-				return errors.ErrAlreadyInTournament
-			case dynamodb.ErrCodeConditionalCheckFailedException:
-				log.Println("Conditional check failed:", aerr.Message())
-				return errors.ErrRequirementsNotMet
-			default:
-				log.Println("DynamoDB error:", aerr.Error())
-				return fmt.Errorf("database error")
-			}
-		} else {
-			log.Println("Unknown error:", err.Error())
-			return fmt.Errorf("unknown error")
+	err = withRetry(ctx, "EnterTournamentTransaction", true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, inputTxn)
+		return err
+	})
+	if err != nil {
+		// Map the per-item cancellation reasons (same order as TransactItems) to domain errors
+		switch {
+		case cancellationReason(err, 2) == reasonConditionalCheck:
+			return errors.ErrTournamentNotActive
+		case cancellationReason(err, 1) == reasonConditionalCheck:
+			return errors.ErrAlreadyInTournament
+		case cancellationReason(err, 0) == reasonConditionalCheck:
+			return errors.ErrRequirementsNotMetForEntry
+		case errors.IsRetryable(err):
+			return err
 		}
+		log.Println("EnterTournamentTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
 	}
 
 	log.Printf("User %s successfully entered tournament %s in group %s", userID, t.TournamentID, groupID)
 	return nil
 }
 
-// ClaimRewardTransaction handles the transaction logic to claim rewards
-func (db *DynamoDB) ClaimRewardTransaction(ctx context.Context, userID string, reward int, tournamentID string) error {
+// ClaimRewardTransaction grants a tournament reward bundle and marks the entry's reward as
+// claimed, atomically. The whole bundle is applied or nothing is.
+func (db *DynamoDB) ClaimRewardTransaction(ctx context.Context, userID string, reward models.RewardBundle, tournamentID string) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	err := grantBundle(ctx, "ClaimRewardTransaction", userID, reward, &dynamodb.TransactWriteItem{
+		Update: &dynamodb.Update{
+			TableName: aws.String(tournamentEntriesTable),
+			Key: map[string]*dynamodb.AttributeValue{
+				"tournamentId": {S: aws.String(tournamentID)},
+				"userId":       {S: aws.String(userID)},
+			},
+			UpdateExpression: aws.String("SET #cr = :trueVal, #ca = :claimedAt"),
+			ExpressionAttributeNames: map[string]*string{
+				"#cr": aws.String("claimedReward"),
+				"#ca": aws.String("claimedAt"),
+				"#rf": aws.String("refunded"),
+				"#ex": aws.String("expired"),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":trueVal":   {BOOL: aws.Bool(true)},
+				":claimedAt": {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
+				":falseVal":  {BOOL: aws.Bool(false)}, // For condition
+			},
+			// Refunded entries belong to a cancelled tournament and pay nothing; expired ones missed the deadline
+			ConditionExpression:                 aws.String("(attribute_not_exists(#cr) OR #cr = :falseVal) AND attribute_not_exists(#rf) AND attribute_not_exists(#ex)"),
+			ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+		},
+	})
+	if err != nil {
+		if err == errors.ErrUserNotFound || cancellationReason(err, 0) == reasonConditionalCheck {
+			return errors.ErrUserNotFound
+		}
+		if cancellationReason(err, 1) == reasonConditionalCheck {
+			old := cancellationItem(err, 1)
+			if _, refunded := old["refunded"]; refunded {
+				return errors.ErrTournamentCancelled
+			}
+			if _, expired := old["expired"]; expired {
+				return errors.ErrRewardExpired
+			}
+			return errors.ErrRewardAlreadyClaimed
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("ClaimRewardTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+// RefundEntryTransaction returns a cancelled tournament's entry fee to the user: it marks the
+// entry refunded, credits entry.Coins and records the history entry, atomically. An entry
+// can only be refunded once, so a resumed cancellation never pays twice, and an entry whose
+// reward was already claimed or settled is not refunded (ErrRewardAlreadyClaimed), so no entrant
+// is paid both.
+func (db *DynamoDB) RefundEntryTransaction(ctx context.Context, entry models.HistoryEntry) error {
 	if svc == nil {
 		return fmt.Errorf("DynamoDB client not initialized")
 	}
+	fillHistoryEntry(&entry)
+
+	entryMap, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
 
 	input := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
 		TransactItems: []*dynamodb.TransactWriteItem{
 			{
 				Update: &dynamodb.Update{
-					TableName: aws.String(usersTable),
+					TableName: aws.String(tournamentEntriesTable),
 					Key: map[string]*dynamodb.AttributeValue{
-						"userId": {S: aws.String(userID)},
+						"tournamentId": {S: aws.String(entry.TournamentID)},
+						"userId":       {S: aws.String(entry.UserID)},
 					},
-					UpdateExpression: aws.String("SET #c = #c + :r"),
+					UpdateExpression:    aws.String("SET #rf = :true"),
+					ConditionExpression: aws.String("attribute_exists(userId) AND attribute_not_exists(#rf) AND (attribute_not_exists(#cr) OR #cr = :false)"),
 					ExpressionAttributeNames: map[string]*string{
-						"#c": aws.String("coins"),
+						"#rf": aws.String("refunded"),
+						"#cr": aws.String("claimedReward"),
 					},
 					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-						":r": {N: aws.String(fmt.Sprintf("%d", reward))},
+						":true":  {BOOL: aws.Bool(true)},
+						":false": {BOOL: aws.Bool(false)},
 					},
+					ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
 				},
 			},
 			{
 				Update: &dynamodb.Update{
-					TableName: aws.String(tournamentEntriesTable),
-					Key: map[string]*dynamodb.AttributeValue{
-						"tournamentId": {S: aws.String(tournamentID)},
-						"userId":       {S: aws.String(userID)},
-					},
-					UpdateExpression: aws.String("SET #cr = :trueVal, #ca = :claimedAt"),
-					ExpressionAttributeNames: map[string]*string{
-						"#cr": aws.String("claimedReward"),
-						"#ca": aws.String("claimedAt"),
-					},
-					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-						":trueVal":   {BOOL: aws.Bool(true)},
-						":claimedAt": {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
-						":falseVal":  {BOOL: aws.Bool(false)}, // For condition
-					},
-					ConditionExpression: aws.String("attribute_not_exists(#cr) OR #cr = :falseVal"),
+					TableName:                 aws.String(usersTable),
+					Key:                       map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(entry.UserID)}},
+					UpdateExpression:          aws.String("SET #c = #c + :r"),
+					ExpressionAttributeNames:  map[string]*string{"#c": aws.String("coins")},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":r": {N: aws.String(fmt.Sprintf("%d", entry.Coins))}},
+				},
+			},
+			{
+				Put: &dynamodb.Put{
+					TableName: aws.String(userHistoryTable),
+					Item:      entryMap,
 				},
 			},
 		},
 	}
 
-	_, err := svc.TransactWriteItemsWithContext(ctx, input)
+	err = withRetry(ctx, "RefundEntryTransaction", true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
-		// Handle specific DynamoDB errors.
-		if tcErr, ok := err.(*dynamodb.TransactionCanceledException); ok {
-			log.Printf("Transaction canceled: %v", tcErr.Message())
-			for i, r := range tcErr.CancellationReasons {
-				log.Printf("Cancellation reason %d: Code=%s, Message=%s", i, aws.StringValue(r.Code), aws.StringValue(r.Message))
+		if cancellationReason(err, 0) == reasonConditionalCheck {
+			old := cancellationItem(err, 0)
+			if _, refunded := old["refunded"]; refunded {
+				return errors.ErrEntryAlreadyRefunded
 			}
-			for _, r := range tcErr.CancellationReasons {
-				if aws.StringValue(r.Code) == "ConditionalCheckFailed" {
-					return errors.ErrRewardAlreadyClaimed
-				}
+			if claimed, ok := old["claimedReward"]; ok && aws.BoolValue(claimed.BOOL) {
+				return errors.ErrRewardAlreadyClaimed
 			}
-			return fmt.Errorf("transaction canceled")
-		} else if aerr, ok := err.(awserr.Error); ok {
-			log.Println("DynamoDB error:", aerr.Error())
-			return fmt.Errorf("database error")
-		} else {
-			log.Println("Unknown error:", err.Error())
-			return fmt.Errorf("unknown error")
+			return errors.ErrEntryAlreadyRefunded
 		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("RefundEntryTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// ExpireEntryReward marks an unclaimed entry's reward as expired so it can no longer be claimed.
+// Expiring an entry twice is harmless; a claimed or refunded entry returns ErrRewardAlreadyClaimed.
+func (db *DynamoDB) ExpireEntryReward(ctx context.Context, tournamentId, userId string) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
 	}
 
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(tournamentEntriesTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"tournamentId": {S: aws.String(tournamentId)},
+			"userId":       {S: aws.String(userId)},
+		},
+		UpdateExpression:    aws.String("SET #ex = :true"),
+		ConditionExpression: aws.String("attribute_exists(userId) AND (attribute_not_exists(#cr) OR #cr = :false) AND attribute_not_exists(#rf)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#ex": aws.String("expired"),
+			"#cr": aws.String("claimedReward"),
+			"#rf": aws.String("refunded"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":true":  {BOOL: aws.Bool(true)},
+			":false": {BOOL: aws.Bool(false)},
+		},
+	}
+
+	err := withRetry(ctx, "ExpireEntryReward", true, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return errors.ErrRewardAlreadyClaimed
+		}
+		return fmt.Errorf("failed to expire entry reward: %w", err)
+	}
+	return nil
+}
+
+// MarkRefundsDone records that every entrant of a cancelled tournament has been refunded
+func (db *DynamoDB) MarkRefundsDone(ctx context.Context, tournamentId string) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(tournamentsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"tournamentId": {S: aws.String(tournamentId)},
+		},
+		UpdateExpression:         aws.String("SET #rd = :true"),
+		ConditionExpression:      aws.String("#can = :true"),
+		ExpressionAttributeNames: map[string]*string{"#rd": aws.String("refundsDone"), "#can": aws.String("cancelled")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":true": {BOOL: aws.Bool(true)},
+		},
+	}
+
+	err := withRetry(ctx, "MarkRefundsDone", true, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark refunds done: %w", err)
+	}
 	return nil
 }
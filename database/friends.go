@@ -0,0 +1,400 @@
+// database/friends.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// maxBatchGetKeys is the most keys DynamoDB accepts in one BatchGetItem request.
+const maxBatchGetKeys = 100
+
+// Friend bookkeeping
+//
+// A user's friendCount always equals the number of their "accepted" Friendships items: the
+// transactions that accept or end a friendship adjust both users' counts together with both
+// items.
+
+// friendshipKey is the key of userID's item for their relation with friendID.
+func friendshipKey(userID, friendID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"userId":   {S: aws.String(userID)},
+		"friendId": {S: aws.String(friendID)},
+	}
+}
+
+// friendCountUpdate builds the user update that adds delta to a user's friend count. Adding a
+// friend is conditioned on the user having fewer than maxFriends friends.
+func friendCountUpdate(userID string, delta, maxFriends int) *dynamodb.Update {
+	u := &dynamodb.Update{
+		TableName:                aws.String(usersTable),
+		Key:                      map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(userID)}},
+		UpdateExpression:         aws.String("ADD #fc :d"),
+		ConditionExpression:      aws.String("attribute_exists(userId)"),
+		ExpressionAttributeNames: map[string]*string{"#fc": aws.String("friendCount")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":d": {N: aws.String(strconv.Itoa(delta))},
+		},
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+	if delta > 0 {
+		u.ConditionExpression = aws.String("attribute_exists(userId) AND (attribute_not_exists(#fc) OR #fc < :max)")
+		u.ExpressionAttributeValues[":max"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(maxFriends))}
+	}
+	return u
+}
+
+// friendshipDelete builds the delete of userID's item for their relation with friendID,
+// conditioned on the relation still having status.
+func friendshipDelete(userID, friendID, status string) *dynamodb.Delete {
+	return &dynamodb.Delete{
+		TableName:                 aws.String(friendshipsTable),
+		Key:                       friendshipKey(userID, friendID),
+		ConditionExpression:       aws.String("#s = :s"),
+		ExpressionAttributeNames:  map[string]*string{"#s": aws.String("status")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":s": {S: aws.String(status)}},
+	}
+}
+
+// friendshipStatus returns the status of a Friendships item reported with a cancellation.
+func friendshipStatus(item map[string]*dynamodb.AttributeValue) string {
+	if s, ok := item["status"]; ok {
+		return aws.StringValue(s.S)
+	}
+	return ""
+}
+
+// SendFriendRequestTransaction records a friend request: request is the sender's "requested"
+// item and incoming the recipient's "incoming" item. Neither user may have an item for the other
+// yet. It returns ErrUserBlocked when the sender blocked the recipient,
+// ErrFriendRequestNotAllowed when the recipient blocked the sender, and ErrFriendshipExists
+// when the users are already related.
+func (db *DynamoDB) SendFriendRequestTransaction(ctx context.Context, request, incoming models.Friendship) error {
+	requestItem, err := dynamodbattribute.MarshalMap(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal friendship: %w", err)
+	}
+	incomingItem, err := dynamodbattribute.MarshalMap(incoming)
+	if err != nil {
+		return fmt.Errorf("failed to marshal friendship: %w", err)
+	}
+
+	put := func(item map[string]*dynamodb.AttributeValue) *dynamodb.Put {
+		return &dynamodb.Put{
+			TableName:                           aws.String(friendshipsTable),
+			Item:                                item,
+			ConditionExpression:                 aws.String("attribute_not_exists(friendId)"),
+			ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+		}
+	}
+
+	return runTransaction(ctx, "SendFriendRequestTransaction", []*dynamodb.TransactWriteItem{
+		{Put: put(requestItem)},
+		{Put: put(incomingItem)},
+	}, func(err error) error {
+		switch {
+		case cancellationReason(err, 0) == reasonConditionalCheck:
+			if friendshipStatus(cancellationItem(err, 0)) == models.FriendStatusBlocked {
+				return errors.ErrUserBlocked
+			}
+			return errors.ErrFriendshipExists
+		case cancellationReason(err, 1) == reasonConditionalCheck:
+			if friendshipStatus(cancellationItem(err, 1)) == models.FriendStatusBlocked {
+				return errors.ErrFriendRequestNotAllowed
+			}
+			return errors.ErrFriendshipExists
+		}
+		return nil
+	})
+}
+
+// AcceptFriendRequestTransaction accepts the request friendID sent userID, counting the friend on
+// both users. Each user must have fewer than maxFriends friends. It returns
+// ErrFriendRequestNotFound when the request was withdrawn or answered, ErrUserNotFound, and
+// ErrFriendListFull when either user already has maxFriends friends.
+func (db *DynamoDB) AcceptFriendRequestTransaction(ctx context.Context, userID, friendID string, maxFriends int) error {
+	accept := func(userID, friendID, status string) *dynamodb.Update {
+		return &dynamodb.Update{
+			TableName:                aws.String(friendshipsTable),
+			Key:                      friendshipKey(userID, friendID),
+			UpdateExpression:         aws.String("SET #s = :accepted"),
+			ConditionExpression:      aws.String("#s = :s"),
+			ExpressionAttributeNames: map[string]*string{"#s": aws.String("status")},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":accepted": {S: aws.String(models.FriendStatusAccepted)},
+				":s":        {S: aws.String(status)},
+			},
+		}
+	}
+
+	return runTransaction(ctx, "AcceptFriendRequestTransaction", []*dynamodb.TransactWriteItem{
+		{Update: accept(userID, friendID, models.FriendStatusIncoming)},
+		{Update: accept(friendID, userID, models.FriendStatusRequested)},
+		{Update: friendCountUpdate(userID, 1, maxFriends)},
+		{Update: friendCountUpdate(friendID, 1, maxFriends)},
+	}, func(err error) error {
+		switch {
+		case cancellationReason(err, 0) == reasonConditionalCheck, cancellationReason(err, 1) == reasonConditionalCheck:
+			return errors.ErrFriendRequestNotFound
+		case cancellationReason(err, 2) == reasonConditionalCheck:
+			if cancellationItem(err, 2) == nil {
+				return errors.ErrUserNotFound
+			}
+			return errors.ErrFriendListFull
+		case cancellationReason(err, 3) == reasonConditionalCheck:
+			if cancellationItem(err, 3) == nil {
+				return errors.ErrUserNotFound
+			}
+			return errors.ErrFriendListFull
+		}
+		return nil
+	})
+}
+
+// RemoveFriendshipTransaction ends a relation as read by its user: it withdraws a sent request,
+// declines an incoming one, unfriends, or lifts a block. Both items of the relation are deleted
+// and an accepted friend is uncounted on both users. It returns ErrTransactionConflict when the
+// relation changed since it was read.
+func (db *DynamoDB) RemoveFriendshipTransaction(ctx context.Context, f models.Friendship) error {
+	items := []*dynamodb.TransactWriteItem{
+		{Delete: friendshipDelete(f.UserID, f.FriendID, f.Status)},
+	}
+	if counterpart := f.Counterpart(); counterpart != "" {
+		items = append(items, &dynamodb.TransactWriteItem{Delete: friendshipDelete(f.FriendID, f.UserID, counterpart)})
+	}
+	if f.Status == models.FriendStatusAccepted {
+		items = append(items,
+			&dynamodb.TransactWriteItem{Update: friendCountUpdate(f.UserID, -1, 0)},
+			&dynamodb.TransactWriteItem{Update: friendCountUpdate(f.FriendID, -1, 0)},
+		)
+	}
+
+	return runTransaction(ctx, "RemoveFriendshipTransaction", items, func(err error) error {
+		for i := range items {
+			if cancellationReason(err, i) == reasonConditionalCheck {
+				return errors.ErrTransactionConflict
+			}
+		}
+		return nil
+	})
+}
+
+// BlockUserTransaction puts block, a "blocked" item, in place of existing, the user's relation
+// with the blocked user as read (nil when there was none). Any relation but the other user's own
+// block of the user ends: its items are deleted and an accepted friend is uncounted. It returns
+// ErrTransactionConflict when the relation changed since it was read.
+func (db *DynamoDB) BlockUserTransaction(ctx context.Context, block models.Friendship, existing *models.Friendship) error {
+	blockItem, err := dynamodbattribute.MarshalMap(block)
+	if err != nil {
+		return fmt.Errorf("failed to marshal friendship: %w", err)
+	}
+
+	put := &dynamodb.Put{
+		TableName:           aws.String(friendshipsTable),
+		Item:                blockItem,
+		ConditionExpression: aws.String("attribute_not_exists(friendId)"),
+	}
+	items := []*dynamodb.TransactWriteItem{{Put: put}}
+	if existing != nil {
+		put.ConditionExpression = aws.String("#s = :s")
+		put.ExpressionAttributeNames = map[string]*string{"#s": aws.String("status")}
+		put.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{":s": {S: aws.String(existing.Status)}}
+		if counterpart := existing.Counterpart(); counterpart != "" {
+			items = append(items, &dynamodb.TransactWriteItem{Delete: friendshipDelete(block.FriendID, block.UserID, counterpart)})
+		}
+		if existing.Status == models.FriendStatusAccepted {
+			items = append(items,
+				&dynamodb.TransactWriteItem{Update: friendCountUpdate(block.UserID, -1, 0)},
+				&dynamodb.TransactWriteItem{Update: friendCountUpdate(block.FriendID, -1, 0)},
+			)
+		}
+	}
+
+	return runTransaction(ctx, "BlockUserTransaction", items, func(err error) error {
+		for i := range items {
+			if cancellationReason(err, i) == reasonConditionalCheck {
+				return errors.ErrTransactionConflict
+			}
+		}
+		return nil
+	})
+}
+
+// GetFriendship retrieves userId's relation with friendId; nil when there is none.
+func (db *DynamoDB) GetFriendship(ctx context.Context, userId, friendId string) (*models.Friendship, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.GetItemInput{
+		TableName:      aws.String(friendshipsTable),
+		Key:            friendshipKey(userId, friendId),
+		ConsistentRead: aws.Bool(true),
+	}
+
+	var result *dynamodb.GetItemOutput
+	err := withRetry(ctx, "GetFriendship", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.GetItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get friendship: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var f models.Friendship
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &f); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal friendship: %w", err)
+	}
+	return &f, nil
+}
+
+// QueryFriendships retrieves every relation of a user, whatever its status.
+func (db *DynamoDB) QueryFriendships(ctx context.Context, userId string) ([]models.Friendship, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(friendshipsTable),
+		KeyConditionExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userId)},
+		},
+	}
+
+	var friendships []models.Friendship
+	for {
+		var result *dynamodb.QueryOutput
+		err := withRetry(ctx, "QueryFriendships", true, func(ctx context.Context) error {
+			var err error
+			result, err = svc.QueryWithContext(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query friendships: %w", err)
+		}
+
+		var page []models.Friendship
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal friendships: %w", err)
+		}
+		friendships = append(friendships, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return friendships, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// batchGetItems reads the items with the given keys from table, maxBatchGetKeys at a time.
+// Keys DynamoDB leaves unprocessed are requested again after a backoff; when they are still
+// unprocessed after the policy's attempts it returns ErrThrottled. Missing items are skipped, and
+// items come back in no particular order.
+func batchGetItems(ctx context.Context, op, table string, keys []map[string]*dynamodb.AttributeValue) ([]map[string]*dynamodb.AttributeValue, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	var items []map[string]*dynamodb.AttributeValue
+	for start := 0; start < len(keys); start += maxBatchGetKeys {
+		end := start + maxBatchGetKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+		pending := map[string]*dynamodb.KeysAndAttributes{
+			table: {Keys: keys[start:end]},
+		}
+
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt > 0 {
+				if attempt >= retryPolicy.MaxAttempts {
+					return nil, errors.ErrThrottled
+				}
+				select {
+				case <-time.After(retryPolicy.backoff(attempt - 1)):
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+
+			input := &dynamodb.BatchGetItemInput{RequestItems: pending}
+			var result *dynamodb.BatchGetItemOutput
+			err := withRetry(ctx, op, true, func(ctx context.Context) error {
+				var err error
+				result, err = svc.BatchGetItemWithContext(ctx, input)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, result.Responses[table]...)
+			pending = result.UnprocessedKeys
+		}
+	}
+	return items, nil
+}
+
+// BatchGetUsers retrieves the users with the given IDs. Users that don't exist are left out.
+func (db *DynamoDB) BatchGetUsers(ctx context.Context, userIds []string) ([]models.User, error) {
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(userIds))
+	for _, id := range userIds {
+		keys = append(keys, map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(id)}})
+	}
+
+	items, err := batchGetItems(ctx, "BatchGetUsers", usersTable, keys)
+	if errors.IsRetryable(err) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	users := make([]models.User, 0, len(items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &users); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal users: %w", err)
+	}
+	return users, nil
+}
+
+// BatchGetTournamentEntries retrieves the entries of the given users in a tournament. Users who
+// did not enter are left out.
+func (db *DynamoDB) BatchGetTournamentEntries(ctx context.Context, tournamentId string, userIds []string) ([]models.TournamentEntry, error) {
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(userIds))
+	for _, id := range userIds {
+		keys = append(keys, map[string]*dynamodb.AttributeValue{
+			"tournamentId": {S: aws.String(tournamentId)},
+			"userId":       {S: aws.String(id)},
+		})
+	}
+
+	items, err := batchGetItems(ctx, "BatchGetTournamentEntries", tournamentEntriesTable, keys)
+	if errors.IsRetryable(err) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tournament entries: %w", err)
+	}
+
+	entries := make([]models.TournamentEntry, 0, len(items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tournament entries: %w", err)
+	}
+	return entries, nil
+}
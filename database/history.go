@@ -0,0 +1,139 @@
+// database/history.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/google/uuid"
+)
+
+// Page sizes for a user's history.
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 500
+)
+
+// historyTimeFormat is fixed-width so entry IDs sort chronologically as strings.
+const historyTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// newHistoryEntryID returns a sort key that orders entries by creation time.
+func newHistoryEntryID(at time.Time) string {
+	return at.UTC().Format(historyTimeFormat) + "#" + uuid.New().String()
+}
+
+// fillHistoryEntry sets the creation time and entry ID when the caller left them empty.
+func fillHistoryEntry(entry *models.HistoryEntry) {
+	now := time.Now().UTC()
+	if entry.CreatedAt == "" {
+		entry.CreatedAt = now.Format(time.RFC3339)
+	}
+	if entry.EntryID == "" {
+		entry.EntryID = newHistoryEntryID(now)
+	}
+}
+
+// AdjustCoinsTransaction adds entry.Coins (which may be negative) to the user's balance and
+// records the entry in the user's history, atomically. The balance can't go below zero.
+func (db *DynamoDB) AdjustCoinsTransaction(ctx context.Context, entry models.HistoryEntry) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+	fillHistoryEntry(&entry)
+
+	// coins + delta >= 0  <=>  coins >= -delta
+	minBalance := 0
+	if entry.Coins < 0 {
+		minBalance = -entry.Coins
+	}
+
+	entryMap, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Update: &dynamodb.Update{
+					TableName:                aws.String(usersTable),
+					Key:                      map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(entry.UserID)}},
+					UpdateExpression:         aws.String("SET #c = #c + :d"),
+					ConditionExpression:      aws.String("attribute_exists(userId) AND #c >= :min"),
+					ExpressionAttributeNames: map[string]*string{"#c": aws.String("coins")},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+						":d":   {N: aws.String(strconv.Itoa(entry.Coins))},
+						":min": {N: aws.String(strconv.Itoa(minBalance))},
+					},
+				},
+			},
+			{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(userHistoryTable),
+					Item:                entryMap,
+					ConditionExpression: aws.String("attribute_not_exists(entryId)"),
+				},
+			},
+		},
+	}
+
+	err = withRetry(ctx, "AdjustCoinsTransaction", true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if cancellationReason(err, 0) == reasonConditionalCheck {
+			return errors.ErrNegativeBalance
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("AdjustCoinsTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// QueryUserHistory retrieves one page of a user's history, newest first
+func (db *DynamoDB) QueryUserHistory(ctx context.Context, userId string, page models.PageRequest) (models.Page[models.HistoryEntry], error) {
+	var out models.Page[models.HistoryEntry]
+	if svc == nil {
+		return out, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(userHistoryTable),
+		KeyConditionExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userId)},
+		},
+		ScanIndexForward: aws.Bool(false), // newest first
+	}
+
+	items, next, err := queryPage(ctx, input, "history:"+userId, page, defaultHistoryPageSize, maxHistoryPageSize)
+	if err == errors.ErrInvalidCursor {
+		return out, err
+	}
+	if err != nil {
+		return out, fmt.Errorf("failed to query user history: %w", err)
+	}
+
+	entries := make([]models.HistoryEntry, 0, len(items))
+	err = dynamodbattribute.UnmarshalListOfMaps(items, &entries)
+	if err != nil {
+		return out, fmt.Errorf("failed to unmarshal user history: %w", err)
+	}
+	out.Items = entries
+	out.NextCursor = next
+	return out, nil
+}
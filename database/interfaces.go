@@ -10,23 +10,89 @@ import (
 type DatabaseInterface interface {
 	PutUser(ctx context.Context, user models.User) error
 	GetUser(ctx context.Context, userId string) (*models.User, error)
-	UpdateUserCoinsAndLevel(ctx context.Context, userId string, newLevel, newCoins int) error
+	AdvanceLevel(ctx context.Context, userId string, fromLevel, toLevel int, reward models.RewardBundle) error
 
 	PutTournament(ctx context.Context, tournament models.Tournament) error
 	GetTournament(ctx context.Context, tournamentId string) (*models.Tournament, error)
 	UpdateTournamentStatus(ctx context.Context, tournamentId string, active bool) error
+	CloseTournament(ctx context.Context, tournamentId, claimDeadline string) error
+	CancelTournament(ctx context.Context, tournamentId string) error
+	MarkRefundsDone(ctx context.Context, tournamentId string) error
 
 	PutTournamentEntry(ctx context.Context, entry models.TournamentEntry) error
 	GetTournamentEntry(ctx context.Context, tournamentId, userId string) (*models.TournamentEntry, error)
 	UpdateTournamentScore(ctx context.Context, tournamentId, userId string, increment int) error
+	ExpireEntryReward(ctx context.Context, tournamentId, userId string) error
 
-	QueryGlobalLeaderboard(ctx context.Context) ([]models.User, error)
-	QueryUsersByCountryLevel(ctx context.Context, country string) ([]models.User, error)
+	QueryGlobalLeaderboard(ctx context.Context, page models.PageRequest) (models.Page[models.User], error)
+	QueryUsersByCountryLevel(ctx context.Context, country string, page models.PageRequest) (models.Page[models.User], error)
 	QueryTournamentEntriesByGroupScore(ctx context.Context, groupId string) ([]models.TournamentEntry, error)
 
-	EnterTournamentTransaction(ctx context.Context, userID string, level, coins int, t *models.Tournament) error
-	ClaimRewardTransaction(ctx context.Context, userID string, reward int, tournamentID string) error
+	EnterTournamentTransaction(ctx context.Context, userID string, level, coins int, t *models.Tournament, league string) error
+	ClaimRewardTransaction(ctx context.Context, userID string, reward models.RewardBundle, tournamentID string) error
+	GrantRewardTransaction(ctx context.Context, entry models.HistoryEntry) error
+	RefundEntryTransaction(ctx context.Context, entry models.HistoryEntry) error
 
-	// Add the following if needed
-	QueryTournamentEntries(ctx context.Context, tournamentId string) ([]models.TournamentEntry, error)
+	QueryTournamentEntries(ctx context.Context, tournamentId string, page models.PageRequest) (models.Page[models.TournamentEntry], error)
+	QueryUnclaimedEntriesByUser(ctx context.Context, userId, sinceTournamentId string, page models.PageRequest) (models.Page[models.TournamentEntry], error)
+
+	AdjustCoinsTransaction(ctx context.Context, entry models.HistoryEntry) error
+	QueryUserHistory(ctx context.Context, userId string, page models.PageRequest) (models.Page[models.HistoryEntry], error)
+
+	PurchaseBoostersTransaction(ctx context.Context, entry models.HistoryEntry, maxOwned int) error
+	ConsumeBoosters(ctx context.Context, userId, boosterId string, quantity int) (int, error)
+	SetLives(ctx context.Context, userId string, from, to models.LivesRecord) error
+	RefillLivesTransaction(ctx context.Context, entry models.HistoryEntry, from, to models.LivesRecord) error
+
+	PutLevelSession(ctx context.Context, session models.LevelSession) error
+	GetLevelSession(ctx context.Context, sessionId string) (*models.LevelSession, error)
+	FinishLevelSession(ctx context.Context, session models.LevelSession) error
+	ScoreLevelSessionTransaction(ctx context.Context, tournamentId, userId, sessionId string, points int) error
+
+	CheckInTransaction(ctx context.Context, entry models.HistoryEntry, from, to models.CheckInState) error
+
+	AddQuestProgress(ctx context.Context, userId, questKey string, count int) error
+	QueryUserQuests(ctx context.Context, userId, day string) ([]models.QuestProgress, error)
+	ClaimQuestTransaction(ctx context.Context, entry models.HistoryEntry, questKey string, target int) error
+
+	AddAchievementStats(ctx context.Context, userId string, delta models.AchievementStats) (*models.User, error)
+	UnlockAchievement(ctx context.Context, a models.UnlockedAchievement) error
+	QueryUserAchievements(ctx context.Context, userId string) ([]models.UnlockedAchievement, error)
+
+	GetSeasonProgress(ctx context.Context, userId, seasonId string) (*models.SeasonProgress, error)
+	AddSeasonXP(ctx context.Context, userId, seasonId string, xp int) error
+	ClaimSeasonTierTransaction(ctx context.Context, entry models.HistoryEntry, seasonId string, tier, xp int, track string) error
+	PurchaseSeasonPremiumTransaction(ctx context.Context, entry models.HistoryEntry, seasonId string) error
+
+	ApplyLeagueResultTransaction(ctx context.Context, result models.LeagueResult) error
+	QueryLeagueHistory(ctx context.Context, userId string, page models.PageRequest) (models.Page[models.LeagueResult], error)
+
+	CreateClanTransaction(ctx context.Context, clan models.Clan, leader models.ClanMember) error
+	GetClan(ctx context.Context, clanId string) (*models.Clan, error)
+	GetClanMember(ctx context.Context, clanId, userId string) (*models.ClanMember, error)
+	QueryClanMembers(ctx context.Context, clanId string) ([]models.ClanMember, error)
+	JoinClanTransaction(ctx context.Context, member models.ClanMember, maxMembers int) error
+	RemoveClanMemberTransaction(ctx context.Context, member models.ClanMember, by *models.ClanMember) error
+	DisbandClanTransaction(ctx context.Context, leader models.ClanMember) error
+	SetClanRoleTransaction(ctx context.Context, member models.ClanMember, role string, by models.ClanMember) error
+	SyncClanMemberLevel(ctx context.Context, member models.ClanMember, level int) error
+	SearchClans(ctx context.Context, namePrefix, country string, page models.PageRequest) (models.Page[models.Clan], error)
+	QueryClanLeaderboard(ctx context.Context, page models.PageRequest) (models.Page[models.Clan], error)
+
+	GetClanTournamentEntry(ctx context.Context, tournamentId, clanId string) (*models.ClanTournamentEntry, error)
+	EnterClanTournament(ctx context.Context, entry models.ClanTournamentEntry, t *models.Tournament) (*models.ClanTournamentEntry, error)
+	AddClanContribution(ctx context.Context, tournamentId, clanId, userId string, increment int) error
+	QueryClanTournamentGroup(ctx context.Context, groupId string) ([]models.ClanTournamentEntry, error)
+	QueryClanTournamentEntries(ctx context.Context, tournamentId string, page models.PageRequest) (models.Page[models.ClanTournamentEntry], error)
+	QueryClanContributions(ctx context.Context, tournamentId, clanId string) ([]models.ClanContribution, error)
+	PayClanRewardTransaction(ctx context.Context, entry models.HistoryEntry, clanId string) error
+
+	GetFriendship(ctx context.Context, userId, friendId string) (*models.Friendship, error)
+	QueryFriendships(ctx context.Context, userId string) ([]models.Friendship, error)
+	SendFriendRequestTransaction(ctx context.Context, request, incoming models.Friendship) error
+	AcceptFriendRequestTransaction(ctx context.Context, userId, friendId string, maxFriends int) error
+	RemoveFriendshipTransaction(ctx context.Context, f models.Friendship) error
+	BlockUserTransaction(ctx context.Context, block models.Friendship, existing *models.Friendship) error
+	BatchGetUsers(ctx context.Context, userIds []string) ([]models.User, error)
+	BatchGetTournamentEntries(ctx context.Context, tournamentId string, userIds []string) ([]models.TournamentEntry, error)
 }
@@ -0,0 +1,285 @@
+// database/inventory.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/google/uuid"
+)
+
+// PurchaseBoostersTransaction debits -entry.Coins from the user, credits the boosters in
+// entry.Bundle and records the history entry, atomically. The entry must buy exactly one booster
+// type. The balance can't go below zero, and when maxOwned > 0 the user can't end up holding
+// more than maxOwned boosters of that type.
+func (db *DynamoDB) PurchaseBoostersTransaction(ctx context.Context, entry models.HistoryEntry, maxOwned int) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+	if entry.Coins >= 0 || entry.Bundle == nil || len(entry.Bundle.Boosters) != 1 {
+		return fmt.Errorf("PurchaseBoostersTransaction: entry for %s must spend coins on one booster type", entry.UserID)
+	}
+	fillHistoryEntry(&entry)
+
+	var quantity int
+	for _, n := range entry.Bundle.Boosters {
+		quantity = n
+	}
+	cost := -entry.Coins
+
+	purchase := models.RewardBundle{Coins: entry.Coins, Boosters: entry.Bundle.Boosters}
+	if err := ensureInventoryMaps(ctx, entry.UserID, purchase); err != nil {
+		return err
+	}
+
+	// bundleUpdate names the single booster #b.#b0; the purchase adds its own conditions
+	update := bundleUpdate(entry.UserID, purchase)
+	condition := "attribute_exists(userId) AND #c >= :cost"
+	update.ExpressionAttributeValues[":cost"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(cost))}
+	if maxOwned > 0 {
+		condition += " AND (attribute_not_exists(#b.#b0) OR #b.#b0 <= :maxBefore)"
+		update.ExpressionAttributeValues[":maxBefore"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(maxOwned - quantity))}
+	}
+	update.ConditionExpression = aws.String(condition)
+	update.ReturnValuesOnConditionCheckFailure = aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld)
+
+	entryMap, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{Update: update},
+			{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(userHistoryTable),
+					Item:                entryMap,
+					ConditionExpression: aws.String("attribute_not_exists(entryId)"),
+				},
+			},
+		},
+	}
+
+	err = withRetry(ctx, "PurchaseBoostersTransaction", true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if cancellationReason(err, 0) == reasonConditionalCheck {
+			old := cancellationItem(err, 0)
+			if old == nil {
+				return errors.ErrUserNotFound
+			}
+			var user models.User
+			if err := dynamodbattribute.UnmarshalMap(old, &user); err == nil && user.Coins < cost {
+				return errors.ErrNotEnoughCoins
+			}
+			return errors.ErrBoosterLimitReached
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("PurchaseBoostersTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// ConsumeBoosters removes quantity boosters of one type from the user and returns how many are
+// left. It returns ErrNotEnoughBoosters when the user holds fewer than quantity.
+func (db *DynamoDB) ConsumeBoosters(ctx context.Context, userId, boosterId string, quantity int) (int, error) {
+	if svc == nil {
+		return 0, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(usersTable),
+		Key:                 map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(userId)}},
+		UpdateExpression:    aws.String("SET #bm.#b = #bm.#b - :q"),
+		ConditionExpression: aws.String("attribute_exists(userId) AND #bm.#b >= :q"),
+		ExpressionAttributeNames: map[string]*string{
+			"#bm": aws.String("boosters"),
+			"#b":  aws.String(boosterId),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":q": {N: aws.String(strconv.Itoa(quantity))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	}
+
+	// Not idempotent: a retried decrement could consume twice
+	var result *dynamodb.UpdateItemOutput
+	err := withRetry(ctx, "ConsumeBoosters", false, func(ctx context.Context) error {
+		var err error
+		result, err = svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return 0, errors.ErrNotEnoughBoosters
+		}
+		if errors.IsRetryable(err) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to consume boosters: %w", err)
+	}
+
+	var updated struct {
+		Boosters map[string]int `dynamodbav:"boosters"`
+	}
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &updated); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal boosters: %w", err)
+	}
+	return updated.Boosters[boosterId], nil
+}
+
+// livesUpdate builds the user update that replaces the stored lives record from with to. The
+// condition only holds while the stored record still equals from, so a concurrent change (a life
+// spent elsewhere, lives granted by a reward) makes the write fail instead of being lost.
+func livesUpdate(userId string, from, to models.LivesRecord) *dynamodb.Update {
+	condition := "attribute_exists(userId)"
+	values := map[string]*dynamodb.AttributeValue{
+		":lives": {N: aws.String(strconv.Itoa(to.Lives))},
+		":at":    {N: aws.String(strconv.FormatInt(to.UpdatedAt, 10))},
+	}
+	// Zero values are not stored (omitempty), so zero matches a missing attribute too
+	if from.Lives == 0 {
+		condition += " AND (attribute_not_exists(#lv) OR #lv = :zero)"
+	} else {
+		condition += " AND #lv = :oldLives"
+		values[":oldLives"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(from.Lives))}
+	}
+	if from.UpdatedAt == 0 {
+		condition += " AND (attribute_not_exists(#la) OR #la = :zero)"
+	} else {
+		condition += " AND #la = :oldAt"
+		values[":oldAt"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(from.UpdatedAt, 10))}
+	}
+	if from.Lives == 0 || from.UpdatedAt == 0 {
+		values[":zero"] = &dynamodb.AttributeValue{N: aws.String("0")}
+	}
+
+	return &dynamodb.Update{
+		TableName:           aws.String(usersTable),
+		Key:                 map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(userId)}},
+		UpdateExpression:    aws.String("SET #lv = :lives, #la = :at"),
+		ConditionExpression: aws.String(condition),
+		ExpressionAttributeNames: map[string]*string{
+			"#lv": aws.String("lives"),
+			"#la": aws.String("livesUpdatedAt"),
+		},
+		ExpressionAttributeValues: values,
+	}
+}
+
+// SetLives replaces the user's stored lives record from with to. It returns
+// ErrTransactionConflict when the stored record no longer equals from, and ErrUserNotFound
+// when the user does not exist.
+func (db *DynamoDB) SetLives(ctx context.Context, userId string, from, to models.LivesRecord) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	u := livesUpdate(userId, from, to)
+	input := &dynamodb.UpdateItemInput{
+		TableName:                           u.TableName,
+		Key:                                 u.Key,
+		UpdateExpression:                    u.UpdateExpression,
+		ConditionExpression:                 u.ConditionExpression,
+		ExpressionAttributeNames:            u.ExpressionAttributeNames,
+		ExpressionAttributeValues:           u.ExpressionAttributeValues,
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+
+	// Not idempotent: a write that landed before a network error would fail its condition on
+	// retry and be reported as a conflict, and the caller would spend another life
+	err := withRetry(ctx, "SetLives", false, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if ccErr, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
+			if ccErr.Item == nil {
+				return errors.ErrUserNotFound
+			}
+			return errors.ErrTransactionConflict
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		return fmt.Errorf("failed to update lives: %w", err)
+	}
+	return nil
+}
+
+// RefillLivesTransaction debits -entry.Coins from the user, replaces the stored lives record
+// from with to and records the history entry, atomically. It returns ErrNotEnoughCoins when the
+// balance is too low and ErrTransactionConflict when the stored lives no longer equal from.
+func (db *DynamoDB) RefillLivesTransaction(ctx context.Context, entry models.HistoryEntry, from, to models.LivesRecord) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+	fillHistoryEntry(&entry)
+	cost := -entry.Coins
+
+	update := livesUpdate(entry.UserID, from, to)
+	update.UpdateExpression = aws.String(aws.StringValue(update.UpdateExpression) + ", #c = #c - :cost")
+	update.ConditionExpression = aws.String(aws.StringValue(update.ConditionExpression) + " AND #c >= :cost")
+	update.ExpressionAttributeNames["#c"] = aws.String("coins")
+	update.ExpressionAttributeValues[":cost"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(cost))}
+	update.ReturnValuesOnConditionCheckFailure = aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld)
+
+	entryMap, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{Update: update},
+			{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(userHistoryTable),
+					Item:                entryMap,
+					ConditionExpression: aws.String("attribute_not_exists(entryId)"),
+				},
+			},
+		},
+	}
+
+	err = withRetry(ctx, "RefillLivesTransaction", true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if cancellationReason(err, 0) == reasonConditionalCheck {
+			old := cancellationItem(err, 0)
+			if old == nil {
+				return errors.ErrUserNotFound
+			}
+			var user models.User
+			if err := dynamodbattribute.UnmarshalMap(old, &user); err == nil && user.LivesRecord() == from && user.Coins < cost {
+				return errors.ErrNotEnoughCoins
+			}
+			return errors.ErrTransactionConflict
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("RefillLivesTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// database/leagues.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/google/uuid"
+)
+
+// Page sizes for league history queries
+const (
+	defaultLeagueHistoryPageSize = 30
+	maxLeagueHistoryPageSize     = 100
+)
+
+// leagueMoveUpdate builds the user update that moves a user to the league a tournament result
+// puts them in. It is conditioned on no result of this or a later tournament having been
+// applied; tournament IDs are dates, so they compare in order.
+func leagueMoveUpdate(result models.LeagueResult) *dynamodb.Update {
+	return &dynamodb.Update{
+		TableName:           aws.String(usersTable),
+		Key:                 map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(result.UserID)}},
+		UpdateExpression:    aws.String("SET #lg = :to, #lt = :tid"),
+		ConditionExpression: aws.String("attribute_exists(userId) AND (attribute_not_exists(#lt) OR #lt < :tid)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#lg": aws.String("league"),
+			"#lt": aws.String("leagueTournament"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":to":  {S: aws.String(result.To)},
+			":tid": {S: aws.String(result.TournamentID)},
+		},
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+}
+
+// ApplyLeagueResultTransaction moves a user to result.To and records the result in their league
+// history, atomically. A result is applied at most once: it returns ErrLeagueResultApplied when
+// this or a later tournament's result was applied already, and ErrUserNotFound when the user
+// does not exist.
+func (db *DynamoDB) ApplyLeagueResultTransaction(ctx context.Context, result models.LeagueResult) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	item, err := dynamodbattribute.MarshalMap(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal league result: %w", err)
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{Update: leagueMoveUpdate(result)},
+			{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(leagueHistoryTable),
+					Item:                item,
+					ConditionExpression: aws.String("attribute_not_exists(tournamentId)"),
+				},
+			},
+		},
+	}
+
+	err = withRetry(ctx, "ApplyLeagueResultTransaction", true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if cancellationReason(err, 0) == reasonConditionalCheck {
+			if cancellationItem(err, 0) == nil {
+				return errors.ErrUserNotFound
+			}
+			return errors.ErrLeagueResultApplied
+		}
+		if cancellationReason(err, 1) == reasonConditionalCheck {
+			return errors.ErrLeagueResultApplied
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("ApplyLeagueResultTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// QueryLeagueHistory retrieves one page of a user's league results, newest tournament first
+func (db *DynamoDB) QueryLeagueHistory(ctx context.Context, userId string, page models.PageRequest) (models.Page[models.LeagueResult], error) {
+	var out models.Page[models.LeagueResult]
+	if svc == nil {
+		return out, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(leagueHistoryTable),
+		KeyConditionExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userId)},
+		},
+		ScanIndexForward: aws.Bool(false), // newest tournament first
+	}
+
+	items, next, err := queryPage(ctx, input, "leagues:"+userId, page, defaultLeagueHistoryPageSize, maxLeagueHistoryPageSize)
+	if err == errors.ErrInvalidCursor {
+		return out, err
+	}
+	if err != nil {
+		return out, fmt.Errorf("failed to query league history: %w", err)
+	}
+
+	results := make([]models.LeagueResult, 0, len(items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &results); err != nil {
+		return out, fmt.Errorf("failed to unmarshal league history: %w", err)
+	}
+	out.Items = results
+	out.NextCursor = next
+	return out, nil
+}
@@ -0,0 +1,262 @@
+// database/levels.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/google/uuid"
+)
+
+// PutLevelSession stores a new level session. Session IDs are random, so an existing
+// session is never overwritten.
+func (db *DynamoDB) PutLevelSession(ctx context.Context, session models.LevelSession) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	item, err := dynamodbattribute.MarshalMap(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal level session: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(levelSessionsTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(sessionId)"),
+	}
+
+	err = withRetry(ctx, "PutLevelSession", true, func(ctx context.Context) error {
+		_, err := svc.PutItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return err
+		}
+		return fmt.Errorf("failed to put level session: %w", err)
+	}
+	return nil
+}
+
+// GetLevelSession retrieves a level session; nil when there is none.
+func (db *DynamoDB) GetLevelSession(ctx context.Context, sessionId string) (*models.LevelSession, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(levelSessionsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"sessionId": {S: aws.String(sessionId)},
+		},
+	}
+
+	var result *dynamodb.GetItemOutput
+	err := withRetry(ctx, "GetLevelSession", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.GetItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get level session: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var session models.LevelSession
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal level session: %w", err)
+	}
+	return &session, nil
+}
+
+// FinishLevelSession records the result of a started session. A session can only be finished
+// once; later attempts return ErrLevelSessionFinished.
+func (db *DynamoDB) FinishLevelSession(ctx context.Context, session models.LevelSession) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	boosters, err := dynamodbattribute.Marshal(session.BoostersUsed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal boosters used: %w", err)
+	}
+	if len(session.BoostersUsed) == 0 {
+		boosters = &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{}}
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(levelSessionsTable),
+		Key:                 map[string]*dynamodb.AttributeValue{"sessionId": {S: aws.String(session.SessionID)}},
+		UpdateExpression:    aws.String("SET #st = :status, #fa = :finishedAt, #mv = :moves, #sc = :score, #bu = :boosters"),
+		ConditionExpression: aws.String("#st = :started"),
+		ExpressionAttributeNames: map[string]*string{
+			"#st": aws.String("status"),
+			"#fa": aws.String("finishedAt"),
+			"#mv": aws.String("moves"),
+			"#sc": aws.String("score"),
+			"#bu": aws.String("boostersUsed"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":status":     {S: aws.String(session.Status)},
+			":finishedAt": {S: aws.String(session.FinishedAt)},
+			":moves":      {N: aws.String(fmt.Sprintf("%d", session.Moves))},
+			":score":      {N: aws.String(fmt.Sprintf("%d", session.Score))},
+			":boosters":   boosters,
+			":started":    {S: aws.String(models.LevelSessionStarted)},
+		},
+	}
+
+	err = withRetry(ctx, "FinishLevelSession", false, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return errors.ErrLevelSessionFinished
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		return fmt.Errorf("failed to finish level session: %w", err)
+	}
+	return nil
+}
+
+// ScoreLevelSessionTransaction adds points to the user's tournament score for a won level session
+// and marks the session scored, atomically, so a session scores at most once however often the
+// result is retried. It returns ErrTournamentEntryNotFound when the user has not entered the
+// tournament, ErrLevelSessionScored when the session already scored, and ErrTournamentNotActive
+// once the tournament has ended or was cancelled.
+func (db *DynamoDB) ScoreLevelSessionTransaction(ctx context.Context, tournamentId, userId, sessionId string, points int) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Update: &dynamodb.Update{
+					TableName: aws.String(tournamentEntriesTable),
+					Key: map[string]*dynamodb.AttributeValue{
+						"tournamentId": {S: aws.String(tournamentId)},
+						"userId":       {S: aws.String(userId)},
+					},
+					UpdateExpression:          aws.String("SET #scr = #scr + :inc"),
+					ConditionExpression:       aws.String("attribute_exists(userId)"),
+					ExpressionAttributeNames:  map[string]*string{"#scr": aws.String("score")},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":inc": {N: aws.String(strconv.Itoa(points))}},
+				},
+			},
+			{
+				Update: &dynamodb.Update{
+					TableName:                 aws.String(levelSessionsTable),
+					Key:                       map[string]*dynamodb.AttributeValue{"sessionId": {S: aws.String(sessionId)}},
+					UpdateExpression:          aws.String("SET #sd = :true"),
+					ConditionExpression:       aws.String("attribute_exists(sessionId) AND attribute_not_exists(#sd)"),
+					ExpressionAttributeNames:  map[string]*string{"#sd": aws.String("scored")},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":true": {BOOL: aws.Bool(true)}},
+				},
+			},
+			{
+				ConditionCheck: &dynamodb.ConditionCheck{
+					TableName:                 aws.String(tournamentsTable),
+					Key:                       map[string]*dynamodb.AttributeValue{"tournamentId": {S: aws.String(tournamentId)}},
+					ConditionExpression:       aws.String("#act = :true AND attribute_not_exists(#cx)"),
+					ExpressionAttributeNames:  map[string]*string{"#act": aws.String("active"), "#cx": aws.String("cancelled")},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":true": {BOOL: aws.Bool(true)}},
+				},
+			},
+		},
+	}
+
+	err := withRetry(ctx, "ScoreLevelSessionTransaction", true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		switch {
+		case cancellationReason(err, 0) == reasonConditionalCheck:
+			return errors.ErrTournamentEntryNotFound
+		case cancellationReason(err, 1) == reasonConditionalCheck:
+			return errors.ErrLevelSessionScored
+		case cancellationReason(err, 2) == reasonConditionalCheck:
+			return errors.ErrTournamentNotActive
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("ScoreLevelSessionTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// levelUpdate builds the user update that moves a user from fromLevel to toLevel and applies
+// the reward, conditioned on the stored level so progress is never applied twice.
+func levelUpdate(userId string, fromLevel, toLevel int, reward models.RewardBundle) *dynamodb.Update {
+	u := bundleUpdate(userId, reward)
+
+	prependSet(u, "#lvl = :toLevel")
+	u.ConditionExpression = aws.String("attribute_exists(userId) AND #lvl = :fromLevel")
+	u.ExpressionAttributeNames["#lvl"] = aws.String("level")
+	u.ExpressionAttributeValues[":toLevel"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(toLevel))}
+	u.ExpressionAttributeValues[":fromLevel"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(fromLevel))}
+	return u
+}
+
+// AdvanceLevel moves the user from fromLevel to toLevel and applies the reward for the levels
+// cleared, in a single update. It returns ErrUserNotFound when the user does not exist and
+// ErrTransactionConflict when their level is no longer fromLevel.
+func (db *DynamoDB) AdvanceLevel(ctx context.Context, userId string, fromLevel, toLevel int, reward models.RewardBundle) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	if err := prepareBundle(ctx, userId, reward); err != nil {
+		return err
+	}
+
+	u := levelUpdate(userId, fromLevel, toLevel, reward)
+	input := &dynamodb.UpdateItemInput{
+		TableName:                           u.TableName,
+		Key:                                 u.Key,
+		UpdateExpression:                    u.UpdateExpression,
+		ConditionExpression:                 u.ConditionExpression,
+		ExpressionAttributeNames:            u.ExpressionAttributeNames,
+		ExpressionAttributeValues:           u.ExpressionAttributeValues,
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+
+	// Not idempotent: a retry after a write that landed would fail the level condition
+	err := withRetry(ctx, "AdvanceLevel", false, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if ccErr, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
+			if ccErr.Item == nil {
+				return errors.ErrUserNotFound
+			}
+			return errors.ErrTransactionConflict
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+// database/migrate/migrate.go
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"good_blast/config"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Tables holds the table names migrations operate on.
+type Tables struct {
+	Users             string
+	Tournaments       string
+	TournamentEntries string
+	UserHistory       string
+	LevelSessions     string
+	UserQuests        string
+	UserAchievements  string
+	SeasonProgress    string
+	LeagueHistory     string
+	Clans             string
+	ClanMembers       string
+	ClanEntries       string
+	ClanContributions string
+	Friendships       string
+	Migrations        string // applied schema versions
+}
+
+// TablesFromConfig returns the table names configured for the application.
+func TablesFromConfig(cfg config.DynamoDBConfig) Tables {
+	return Tables{
+		Users:             cfg.UsersTable,
+		Tournaments:       cfg.TournamentsTable,
+		TournamentEntries: cfg.TournamentEntriesTable,
+		UserHistory:       cfg.UserHistoryTable,
+		LevelSessions:     cfg.LevelSessionsTable,
+		UserQuests:        cfg.UserQuestsTable,
+		UserAchievements:  cfg.UserAchievementsTable,
+		SeasonProgress:    cfg.SeasonProgressTable,
+		LeagueHistory:     cfg.LeagueHistoryTable,
+		Clans:             cfg.ClansTable,
+		ClanMembers:       cfg.ClanMembersTable,
+		ClanEntries:       cfg.ClanTournamentEntriesTable,
+		ClanContributions: cfg.ClanContributionsTable,
+		Friendships:       cfg.FriendshipsTable,
+		Migrations:        cfg.MigrationsTable,
+	}
+}
+
+// Migration is one schema or data change. Up must be idempotent: if it fails halfway
+// the version is not recorded and the whole migration runs again next time.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, m *Migrator) error
+}
+
+// Applied is a migration recorded in the migrations table.
+type Applied struct {
+	Version     int    `dynamodbav:"version"`
+	Description string `dynamodbav:"description"`
+	AppliedAt   string `dynamodbav:"appliedAt"`
+}
+
+// Migrator applies migrations against a DynamoDB endpoint.
+type Migrator struct {
+	DB           dynamodbiface.DynamoDBAPI
+	Tables       Tables
+	Log          *log.Logger   // optional; defaults to the standard logger
+	PollInterval time.Duration // how often to check table/index status; 0 means 2s
+}
+
+// NewMigrator creates a Migrator for the given client and tables.
+func NewMigrator(db dynamodbiface.DynamoDBAPI, tables Tables) *Migrator {
+	return &Migrator{DB: db, Tables: tables}
+}
+
+func (m *Migrator) pollInterval() time.Duration {
+	if m.PollInterval > 0 {
+		return m.PollInterval
+	}
+	return 2 * time.Second
+}
+
+// migrationsTable is created before anything else so versions can be recorded.
+func (m *Migrator) migrationsTable() Table {
+	return Table{Name: m.Tables.Migrations, Hash: Key{"version", dynamodb.ScalarAttributeTypeN}}
+}
+
+// AppliedVersions returns the recorded migrations keyed by version.
+// A missing migrations table means nothing has been applied yet.
+func (m *Migrator) AppliedVersions(ctx context.Context) (map[int]Applied, error) {
+	applied := make(map[int]Applied)
+	err := m.ScanEach(ctx, &dynamodb.ScanInput{
+		TableName:      aws.String(m.Tables.Migrations),
+		ConsistentRead: aws.Bool(true),
+	}, func(item map[string]*dynamodb.AttributeValue) error {
+		a, err := parseApplied(item)
+		if err != nil {
+			return err
+		}
+		applied[a.Version] = a
+		return nil
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeResourceNotFoundException {
+		return applied, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// Pending returns the migrations that have not been applied, in order.
+func (m *Migrator) Pending(ctx context.Context, migrations []Migration) ([]Migration, error) {
+	if err := validate(migrations); err != nil {
+		return nil, err
+	}
+	applied, err := m.AppliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, mg := range migrations {
+		if _, ok := applied[mg.Version]; !ok {
+			pending = append(pending, mg)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration in order and records each one after it succeeds.
+// It returns the number of migrations applied.
+func (m *Migrator) Up(ctx context.Context, migrations []Migration) (int, error) {
+	if err := m.EnsureTable(ctx, m.migrationsTable()); err != nil {
+		return 0, err
+	}
+
+	pending, err := m.Pending(ctx, migrations)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, mg := range pending {
+		m.logf("applying migration %d: %s", mg.Version, mg.Description)
+		if err := mg.Up(ctx, m); err != nil {
+			return i, fmt.Errorf("migration %d (%s) failed: %w", mg.Version, mg.Description, err)
+		}
+		if err := m.record(ctx, mg); err != nil {
+			return i, err
+		}
+	}
+	return len(pending), nil
+}
+
+// record stores a successfully applied migration. If a concurrent run already recorded
+// it, the earlier record is kept.
+func (m *Migrator) record(ctx context.Context, mg Migration) error {
+	_, err := m.DB.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(m.Tables.Migrations),
+		Item: map[string]*dynamodb.AttributeValue{
+			"version":     {N: aws.String(strconv.Itoa(mg.Version))},
+			"description": {S: aws.String(mg.Description)},
+			"appliedAt":   {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
+		},
+		ConditionExpression:      aws.String("attribute_not_exists(#v)"),
+		ExpressionAttributeNames: map[string]*string{"#v": aws.String("version")},
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", mg.Version, err)
+	}
+	return nil
+}
+
+// ScanEach calls fn for every item matched by input, following pagination.
+// Backfills use it to walk existing data.
+func (m *Migrator) ScanEach(ctx context.Context, input *dynamodb.ScanInput, fn func(item map[string]*dynamodb.AttributeValue) error) error {
+	in := *input
+	for {
+		out, err := m.DB.ScanWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+		for _, item := range out.Items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		in.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+func parseApplied(item map[string]*dynamodb.AttributeValue) (Applied, error) {
+	var a Applied
+	if err := dynamodbattribute.UnmarshalMap(item, &a); err != nil {
+		return a, fmt.Errorf("invalid migration record: %w", err)
+	}
+	if a.Version <= 0 {
+		return a, fmt.Errorf("migration record without a version")
+	}
+	return a, nil
+}
+
+// validate checks that versions are positive and strictly increasing.
+func validate(migrations []Migration) error {
+	prev := 0
+	for _, mg := range migrations {
+		if mg.Version <= prev {
+			return fmt.Errorf("migration versions must be positive and strictly increasing (got %d after %d)", mg.Version, prev)
+		}
+		if mg.Up == nil {
+			return fmt.Errorf("migration %d has no Up function", mg.Version)
+		}
+		prev = mg.Version
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package migrate
+
+import (
+	"context"
+	"io"
+	"log"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+)
+
+type item = map[string]*dynamodb.AttributeValue
+
+// fakeDynamo is a tiny in-memory stand-in for the calls the migrator makes.
+// Tables become ACTIVE immediately and scans return one item per page.
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+
+	tables       map[string]*dynamodb.TableDescription
+	items        map[string][]item
+	creates      int
+	indexCreates []string
+	updates      int
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{
+		tables: make(map[string]*dynamodb.TableDescription),
+		items:  make(map[string][]item),
+	}
+}
+
+func (f *fakeDynamo) DescribeTableWithContext(_ aws.Context, in *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	t, ok := f.tables[aws.StringValue(in.TableName)]
+	if !ok {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	return &dynamodb.DescribeTableOutput{Table: t}, nil
+}
+
+func (f *fakeDynamo) CreateTableWithContext(_ aws.Context, in *dynamodb.CreateTableInput, _ ...request.Option) (*dynamodb.CreateTableOutput, error) {
+	f.creates++
+	desc := &dynamodb.TableDescription{
+		TableName:   in.TableName,
+		TableStatus: aws.String(dynamodb.TableStatusActive),
+		KeySchema:   in.KeySchema,
+	}
+	for _, gsi := range in.GlobalSecondaryIndexes {
+		desc.GlobalSecondaryIndexes = append(desc.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndexDescription{
+			IndexName:   gsi.IndexName,
+			KeySchema:   gsi.KeySchema,
+			IndexStatus: aws.String(dynamodb.IndexStatusActive),
+		})
+	}
+	f.tables[aws.StringValue(in.TableName)] = desc
+	return &dynamodb.CreateTableOutput{TableDescription: desc}, nil
+}
+
+func (f *fakeDynamo) UpdateTableWithContext(_ aws.Context, in *dynamodb.UpdateTableInput, _ ...request.Option) (*dynamodb.UpdateTableOutput, error) {
+	desc := f.tables[aws.StringValue(in.TableName)]
+	for _, u := range in.GlobalSecondaryIndexUpdates {
+		f.indexCreates = append(f.indexCreates, aws.StringValue(u.Create.IndexName))
+		desc.GlobalSecondaryIndexes = append(desc.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndexDescription{
+			IndexName:   u.Create.IndexName,
+			KeySchema:   u.Create.KeySchema,
+			IndexStatus: aws.String(dynamodb.IndexStatusActive),
+		})
+	}
+	return &dynamodb.UpdateTableOutput{TableDescription: desc}, nil
+}
+
+func (f *fakeDynamo) ScanWithContext(_ aws.Context, in *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	name := aws.StringValue(in.TableName)
+	if _, ok := f.tables[name]; !ok {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	start := 0
+	if in.ExclusiveStartKey != nil {
+		start, _ = strconv.Atoi(aws.StringValue(in.ExclusiveStartKey["i"].N))
+	}
+
+	out := &dynamodb.ScanOutput{}
+	rows := f.items[name]
+	if start < len(rows) {
+		// Only the globalPK backfill filters, so that's the only filter understood here
+		if in.FilterExpression == nil || rows[start]["globalPK"] == nil {
+			out.Items = []item{rows[start]}
+		}
+		if start+1 < len(rows) {
+			out.LastEvaluatedKey = item{"i": {N: aws.String(strconv.Itoa(start + 1))}}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeDynamo) PutItemWithContext(_ aws.Context, in *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	name := aws.StringValue(in.TableName)
+	for _, row := range f.items[name] {
+		if aws.StringValue(row["version"].N) == aws.StringValue(in.Item["version"].N) {
+			return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "exists", nil)
+		}
+	}
+	f.items[name] = append(f.items[name], in.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamo) UpdateItemWithContext(_ aws.Context, in *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	f.updates++
+	for _, row := range f.items[aws.StringValue(in.TableName)] {
+		if aws.StringValue(row["userId"].S) == aws.StringValue(in.Key["userId"].S) {
+			row["globalPK"] = in.ExpressionAttributeValues[":g"]
+		}
+	}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+var testTables = Tables{
+	Users:             "Users",
+	Tournaments:       "Tournaments",
+	TournamentEntries: "TournamentEntries",
+	UserHistory:       "UserHistory",
+	LevelSessions:     "LevelSessions",
+	UserQuests:        "UserQuests",
+	UserAchievements:  "UserAchievements",
+	SeasonProgress:    "SeasonProgress",
+	LeagueHistory:     "LeagueHistory",
+	Clans:             "Clans",
+	ClanMembers:       "ClanMembers",
+	ClanEntries:       "ClanTournamentEntries",
+	ClanContributions: "ClanContributions",
+	Friendships:       "Friendships",
+	Migrations:        "SchemaMigrations",
+}
+
+func newTestMigrator(db *fakeDynamo) *Migrator {
+	m := NewMigrator(db, testTables)
+	m.Log = log.New(io.Discard, "", 0)
+	return m
+}
+
+func TestUp_CreatesEverythingOnEmptyEndpoint(t *testing.T) {
+	db := newFakeDynamo()
+	m := newTestMigrator(db)
+
+	n, err := m.Up(context.Background(), All)
+	assert.NoError(t, err)
+	assert.Equal(t, len(All), n)
+	assert.Equal(t, 15, db.creates) // fourteen app tables plus the migrations table
+
+	users := db.tables["Users"]
+	assert.Len(t, users.GlobalSecondaryIndexes, 2)
+	assert.Len(t, db.tables["TournamentEntries"].GlobalSecondaryIndexes, 2)
+	assert.Len(t, db.tables["Clans"].GlobalSecondaryIndexes, 3)
+	assert.Len(t, db.tables["ClanTournamentEntries"].GlobalSecondaryIndexes, 1)
+
+	applied, err := m.AppliedVersions(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(All))
+	assert.NotEmpty(t, applied[1].AppliedAt)
+}
+
+func TestUp_IsIdempotent(t *testing.T) {
+	db := newFakeDynamo()
+	m := newTestMigrator(db)
+
+	_, err := m.Up(context.Background(), All)
+	assert.NoError(t, err)
+	creates := db.creates
+
+	n, err := m.Up(context.Background(), All)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, creates, db.creates)
+}
+
+func TestEnsureTable_AddsMissingIndexToExistingTable(t *testing.T) {
+	db := newFakeDynamo()
+	m := newTestMigrator(db)
+	// A hand-made Users table that only has the global index
+	_, _ = db.CreateTableWithContext(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("Users"),
+		KeySchema: keySchema(Key{"userId", keyS}, nil),
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{{
+			IndexName: aws.String("GlobalLevelIndex"),
+			KeySchema: keySchema(Key{"globalPK", keyS}, &Key{"level", keyN}),
+		}},
+	})
+
+	_, err := m.Up(context.Background(), All)
+	assert.NoError(t, err)
+	// UserEntriesIndex is added to the TournamentEntries table created by an earlier migration
+	assert.Equal(t, []string{"CountryLevelIndex", "UserEntriesIndex"}, db.indexCreates)
+}
+
+func TestEnsureTable_RejectsIncompatibleKeys(t *testing.T) {
+	db := newFakeDynamo()
+	m := newTestMigrator(db)
+	_, _ = db.CreateTableWithContext(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("Users"),
+		KeySchema: keySchema(Key{"id", keyS}, nil),
+	})
+
+	n, err := m.Up(context.Background(), All)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "incompatible key schema")
+	assert.Zero(t, n)
+
+	// Nothing is recorded, so the migration runs again once the table is fixed
+	pending, err := m.Pending(context.Background(), All)
+	assert.NoError(t, err)
+	assert.Len(t, pending, len(All))
+}
+
+func TestBackfillGlobalPK_OnlyTouchesUsersWithoutIt(t *testing.T) {
+	db := newFakeDynamo()
+	m := newTestMigrator(db)
+	_, err := m.Up(context.Background(), All[:3])
+	assert.NoError(t, err)
+
+	db.items["Users"] = []item{
+		{"userId": {S: aws.String("u1")}},
+		{"userId": {S: aws.String("u2")}, "globalPK": {S: aws.String("GLOBAL")}},
+		{"userId": {S: aws.String("u3")}},
+	}
+
+	n, err := m.Up(context.Background(), All[:4])
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 2, db.updates)
+	for _, u := range db.items["Users"] {
+		assert.Equal(t, "GLOBAL", aws.StringValue(u["globalPK"].S))
+	}
+}
+
+func TestPending_RejectsUnorderedVersions(t *testing.T) {
+	m := newTestMigrator(newFakeDynamo())
+	noop := func(context.Context, *Migrator) error { return nil }
+
+	_, err := m.Pending(context.Background(), []Migration{{Version: 2, Up: noop}, {Version: 1, Up: noop}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,239 @@
+// database/migrate/migrations.go
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var (
+	keyS = dynamodb.ScalarAttributeTypeS
+	keyN = dynamodb.ScalarAttributeTypeN
+)
+
+// All lists every migration in the order it must be applied. Append new ones at the end;
+// never renumber or edit a migration that has shipped.
+var All = []Migration{
+	{
+		Version:     1,
+		Description: "create Users table with GlobalLevelIndex and CountryLevelIndex",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name: m.Tables.Users,
+				Hash: Key{"userId", keyS},
+				Indexes: []Index{
+					{Name: "GlobalLevelIndex", Hash: Key{"globalPK", keyS}, Range: &Key{"level", keyN}},
+					{Name: "CountryLevelIndex", Hash: Key{"country", keyS}, Range: &Key{"level", keyN}},
+				},
+			})
+		},
+	},
+	{
+		Version:     2,
+		Description: "create Tournaments table",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name: m.Tables.Tournaments,
+				Hash: Key{"tournamentId", keyS},
+			})
+		},
+	},
+	{
+		Version:     3,
+		Description: "create TournamentEntries table with GroupScoreIndex",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.TournamentEntries,
+				Hash:  Key{"tournamentId", keyS},
+				Range: &Key{"userId", keyS},
+				Indexes: []Index{
+					{Name: "GroupScoreIndex", Hash: Key{"groupId", keyS}, Range: &Key{"score", keyN}},
+				},
+			})
+		},
+	},
+	{
+		Version:     4,
+		Description: "backfill globalPK on users so they appear on the global leaderboard",
+		Up:          backfillGlobalPK,
+	},
+	{
+		Version:     5,
+		Description: "create UserHistory table",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.UserHistory,
+				Hash:  Key{"userId", keyS},
+				Range: &Key{"entryId", keyS},
+			})
+		},
+	},
+	{
+		Version:     6,
+		Description: "add UserEntriesIndex to TournamentEntries for pending rewards",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.TournamentEntries,
+				Hash:  Key{"tournamentId", keyS},
+				Range: &Key{"userId", keyS},
+				Indexes: []Index{
+					{Name: "GroupScoreIndex", Hash: Key{"groupId", keyS}, Range: &Key{"score", keyN}},
+					{Name: "UserEntriesIndex", Hash: Key{"userId", keyS}, Range: &Key{"tournamentId", keyS}},
+				},
+			})
+		},
+	},
+	{
+		Version:     7,
+		Description: "create LevelSessions table",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name: m.Tables.LevelSessions,
+				Hash: Key{"sessionId", keyS},
+			})
+		},
+	},
+	{
+		Version:     8,
+		Description: "create UserQuests table",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.UserQuests,
+				Hash:  Key{"userId", keyS},
+				Range: &Key{"questKey", keyS},
+			})
+		},
+	},
+	{
+		Version:     9,
+		Description: "create UserAchievements table",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.UserAchievements,
+				Hash:  Key{"userId", keyS},
+				Range: &Key{"achievementId", keyS},
+			})
+		},
+	},
+	{
+		Version:     10,
+		Description: "create SeasonProgress table",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.SeasonProgress,
+				Hash:  Key{"userId", keyS},
+				Range: &Key{"seasonId", keyS},
+			})
+		},
+	},
+	{
+		Version:     11,
+		Description: "create LeagueHistory table",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.LeagueHistory,
+				Hash:  Key{"userId", keyS},
+				Range: &Key{"tournamentId", keyS},
+			})
+		},
+	},
+	{
+		Version:     12,
+		Description: "create Clans table with ClanNameIndex, CountryClanIndex and ClanLevelIndex",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name: m.Tables.Clans,
+				Hash: Key{"clanId", keyS},
+				Indexes: []Index{
+					{Name: "ClanNameIndex", Hash: Key{"clanPK", keyS}, Range: &Key{"nameKey", keyS}},
+					{Name: "CountryClanIndex", Hash: Key{"country", keyS}, Range: &Key{"nameKey", keyS}},
+					{Name: "ClanLevelIndex", Hash: Key{"clanPK", keyS}, Range: &Key{"totalLevel", keyN}},
+				},
+			})
+		},
+	},
+	{
+		Version:     13,
+		Description: "create ClanMembers table",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.ClanMembers,
+				Hash:  Key{"clanId", keyS},
+				Range: &Key{"userId", keyS},
+			})
+		},
+	},
+	{
+		Version:     14,
+		Description: "create ClanTournamentEntries table with ClanGroupScoreIndex",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.ClanEntries,
+				Hash:  Key{"tournamentId", keyS},
+				Range: &Key{"clanId", keyS},
+				Indexes: []Index{
+					{Name: "ClanGroupScoreIndex", Hash: Key{"groupId", keyS}, Range: &Key{"score", keyN}},
+				},
+			})
+		},
+	},
+	{
+		Version:     15,
+		Description: "create ClanContributions table",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.ClanContributions,
+				Hash:  Key{"entryKey", keyS},
+				Range: &Key{"userId", keyS},
+			})
+		},
+	},
+	{
+		Version:     16,
+		Description: "create Friendships table",
+		Up: func(ctx context.Context, m *Migrator) error {
+			return m.EnsureTable(ctx, Table{
+				Name:  m.Tables.Friendships,
+				Hash:  Key{"userId", keyS},
+				Range: &Key{"friendId", keyS},
+			})
+		},
+	},
+}
+
+// backfillGlobalPK sets globalPK = "GLOBAL" on users created without it.
+func backfillGlobalPK(ctx context.Context, m *Migrator) error {
+	updated := 0
+	err := m.ScanEach(ctx, &dynamodb.ScanInput{
+		TableName:                aws.String(m.Tables.Users),
+		FilterExpression:         aws.String("attribute_not_exists(#g)"),
+		ProjectionExpression:     aws.String("userId"),
+		ExpressionAttributeNames: map[string]*string{"#g": aws.String("globalPK")},
+	}, func(item map[string]*dynamodb.AttributeValue) error {
+		_, err := m.DB.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(m.Tables.Users),
+			Key:                       map[string]*dynamodb.AttributeValue{"userId": item["userId"]},
+			UpdateExpression:          aws.String("SET #g = :g"),
+			ConditionExpression:       aws.String("attribute_exists(userId) AND attribute_not_exists(#g)"),
+			ExpressionAttributeNames:  map[string]*string{"#g": aws.String("globalPK")},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":g": {S: aws.String("GLOBAL")}},
+		})
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil // deleted or already set since the scan
+		}
+		if err != nil {
+			return fmt.Errorf("failed to backfill user %s: %w", aws.StringValue(item["userId"].S), err)
+		}
+		updated++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	m.logf("backfilled globalPK on %d users", updated)
+	return nil
+}
@@ -0,0 +1,227 @@
+// database/migrate/schema.go
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Key is one key attribute of a table or index.
+type Key struct {
+	Name string
+	Type string // dynamodb.ScalarAttributeTypeS or dynamodb.ScalarAttributeTypeN
+}
+
+// Index describes a global secondary index. Indexes always project all attributes.
+type Index struct {
+	Name  string
+	Hash  Key
+	Range *Key
+}
+
+// Table describes the desired shape of a table.
+type Table struct {
+	Name    string
+	Hash    Key
+	Range   *Key
+	Indexes []Index
+}
+
+// EnsureTable creates the table if it doesn't exist and adds any missing indexes.
+// It never drops or changes anything, so it is safe to run repeatedly.
+func (m *Migrator) EnsureTable(ctx context.Context, t Table) error {
+	desc, err := m.describeTable(ctx, t.Name)
+	if err != nil {
+		return err
+	}
+	if desc == nil {
+		return m.createTable(ctx, t)
+	}
+
+	if err := checkKeySchema(t.Name, desc.KeySchema, t.Hash, t.Range); err != nil {
+		return err
+	}
+
+	existing := make(map[string]*dynamodb.GlobalSecondaryIndexDescription, len(desc.GlobalSecondaryIndexes))
+	for _, gsi := range desc.GlobalSecondaryIndexes {
+		existing[aws.StringValue(gsi.IndexName)] = gsi
+	}
+	for _, idx := range t.Indexes {
+		if gsi, ok := existing[idx.Name]; ok {
+			if err := checkKeySchema(t.Name+"/"+idx.Name, gsi.KeySchema, idx.Hash, idx.Range); err != nil {
+				return err
+			}
+			continue
+		}
+		// DynamoDB only accepts one index creation per UpdateTable call
+		if err := m.createIndex(ctx, t.Name, idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describeTable returns nil when the table doesn't exist.
+func (m *Migrator) describeTable(ctx context.Context, name string) (*dynamodb.TableDescription, error) {
+	out, err := m.DB.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeResourceNotFoundException {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe table %s: %w", name, err)
+	}
+	return out.Table, nil
+}
+
+func (m *Migrator) createTable(ctx context.Context, t Table) error {
+	attrs := newAttributeSet()
+	attrs.add(t.Hash)
+	if t.Range != nil {
+		attrs.add(*t.Range)
+	}
+
+	input := &dynamodb.CreateTableInput{
+		TableName:   aws.String(t.Name),
+		KeySchema:   keySchema(t.Hash, t.Range),
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+	}
+	for _, idx := range t.Indexes {
+		attrs.add(idx.Hash)
+		if idx.Range != nil {
+			attrs.add(*idx.Range)
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{
+			IndexName:  aws.String(idx.Name),
+			KeySchema:  keySchema(idx.Hash, idx.Range),
+			Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+		})
+	}
+	input.AttributeDefinitions = attrs.definitions
+
+	m.logf("creating table %s", t.Name)
+	if _, err := m.DB.CreateTableWithContext(ctx, input); err != nil {
+		// Another migrator got there first
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != dynamodb.ErrCodeResourceInUseException {
+			return fmt.Errorf("failed to create table %s: %w", t.Name, err)
+		}
+	}
+	return m.waitUntilActive(ctx, t.Name, "")
+}
+
+func (m *Migrator) createIndex(ctx context.Context, table string, idx Index) error {
+	attrs := newAttributeSet()
+	attrs.add(idx.Hash)
+	if idx.Range != nil {
+		attrs.add(*idx.Range)
+	}
+
+	m.logf("creating index %s on %s", idx.Name, table)
+	_, err := m.DB.UpdateTableWithContext(ctx, &dynamodb.UpdateTableInput{
+		TableName:            aws.String(table),
+		AttributeDefinitions: attrs.definitions,
+		GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{{
+			Create: &dynamodb.CreateGlobalSecondaryIndexAction{
+				IndexName:  aws.String(idx.Name),
+				KeySchema:  keySchema(idx.Hash, idx.Range),
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+			},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create index %s on %s: %w", idx.Name, table, err)
+	}
+	return m.waitUntilActive(ctx, table, idx.Name)
+}
+
+// waitUntilActive polls until the table (and the named index, if any) is ACTIVE.
+// Index backfills on large tables can take a while; the context bounds the wait.
+func (m *Migrator) waitUntilActive(ctx context.Context, table, index string) error {
+	for {
+		desc, err := m.describeTable(ctx, table)
+		if err != nil {
+			return err
+		}
+		if desc != nil && aws.StringValue(desc.TableStatus) == dynamodb.TableStatusActive && indexActive(desc, index) {
+			return nil
+		}
+
+		select {
+		case <-time.After(m.pollInterval()):
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s to become active: %w", table, ctx.Err())
+		}
+	}
+}
+
+func indexActive(desc *dynamodb.TableDescription, index string) bool {
+	if index == "" {
+		return true
+	}
+	for _, gsi := range desc.GlobalSecondaryIndexes {
+		if aws.StringValue(gsi.IndexName) == index {
+			return aws.StringValue(gsi.IndexStatus) == dynamodb.IndexStatusActive
+		}
+	}
+	return false
+}
+
+// checkKeySchema fails when an existing table or index has different keys than declared.
+// Keys can't be changed in place, so this needs a manual data migration.
+func checkKeySchema(name string, actual []*dynamodb.KeySchemaElement, hash Key, rng *Key) error {
+	want := keySchema(hash, rng)
+	if len(actual) != len(want) {
+		return fmt.Errorf("%s has an incompatible key schema", name)
+	}
+	for i := range want {
+		if aws.StringValue(actual[i].AttributeName) != aws.StringValue(want[i].AttributeName) ||
+			aws.StringValue(actual[i].KeyType) != aws.StringValue(want[i].KeyType) {
+			return fmt.Errorf("%s has an incompatible key schema", name)
+		}
+	}
+	return nil
+}
+
+func keySchema(hash Key, rng *Key) []*dynamodb.KeySchemaElement {
+	ks := []*dynamodb.KeySchemaElement{
+		{AttributeName: aws.String(hash.Name), KeyType: aws.String(dynamodb.KeyTypeHash)},
+	}
+	if rng != nil {
+		ks = append(ks, &dynamodb.KeySchemaElement{AttributeName: aws.String(rng.Name), KeyType: aws.String(dynamodb.KeyTypeRange)})
+	}
+	return ks
+}
+
+// attributeSet collects attribute definitions without duplicates.
+type attributeSet struct {
+	seen        map[string]bool
+	definitions []*dynamodb.AttributeDefinition
+}
+
+func newAttributeSet() *attributeSet {
+	return &attributeSet{seen: make(map[string]bool)}
+}
+
+func (a *attributeSet) add(k Key) {
+	if a.seen[k.Name] {
+		return
+	}
+	a.seen[k.Name] = true
+	a.definitions = append(a.definitions, &dynamodb.AttributeDefinition{
+		AttributeName: aws.String(k.Name),
+		AttributeType: aws.String(k.Type),
+	})
+}
+
+func (m *Migrator) logf(format string, args ...interface{}) {
+	if m.Log != nil {
+		m.Log.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
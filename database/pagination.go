@@ -0,0 +1,126 @@
+// database/pagination.go
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// cursorToken is the decoded form of an opaque pagination cursor. It carries the
+// LastEvaluatedKey of the previous page plus the query it belongs to, so a cursor
+// from one leaderboard can't be replayed against another.
+type cursorToken struct {
+	Query string                 `json:"q"`
+	Key   map[string]cursorValue `json:"k"`
+}
+
+// cursorValue holds one key attribute. Table and index keys are only strings or numbers.
+type cursorValue struct {
+	S *string `json:"s,omitempty"`
+	N *string `json:"n,omitempty"`
+}
+
+// encodeCursor turns a LastEvaluatedKey into an opaque, URL-safe token.
+func encodeCursor(query string, key map[string]*dynamodb.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	token := cursorToken{Query: query, Key: make(map[string]cursorValue, len(key))}
+	for name, av := range key {
+		if av.S == nil && av.N == nil {
+			return "", fmt.Errorf("unsupported key attribute type for %s", name)
+		}
+		token.Key[name] = cursorValue{S: av.S, N: av.N}
+	}
+
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor turns a token produced by encodeCursor back into an ExclusiveStartKey.
+// An empty cursor means "start from the beginning".
+func decodeCursor(query, cursor string) (map[string]*dynamodb.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.ErrInvalidCursor
+	}
+	var token cursorToken
+	if err := json.Unmarshal(raw, &token); err != nil || token.Query != query || len(token.Key) == 0 {
+		return nil, errors.ErrInvalidCursor
+	}
+
+	key := make(map[string]*dynamodb.AttributeValue, len(token.Key))
+	for name, v := range token.Key {
+		if (v.S == nil) == (v.N == nil) {
+			return nil, errors.ErrInvalidCursor
+		}
+		key[name] = &dynamodb.AttributeValue{S: v.S, N: v.N}
+	}
+	return key, nil
+}
+
+// clampLimit applies the default for a zero limit and caps it at max.
+func clampLimit(limit, def, max int) int {
+	if limit <= 0 {
+		return def
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
+
+// queryPage runs input until it has collected limit items or the query is exhausted,
+// following LastEvaluatedKey across DynamoDB's 1 MB response pages. The returned cursor
+// resumes right after the last returned item. Because index keys in LastEvaluatedKey
+// include the table's primary key, items with equal sort keys are never skipped or repeated.
+func queryPage(ctx context.Context, input *dynamodb.QueryInput, query string, page models.PageRequest, def, max int) ([]map[string]*dynamodb.AttributeValue, string, error) {
+	startKey, err := decodeCursor(query, page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	input.ExclusiveStartKey = startKey
+
+	limit := clampLimit(page.Limit, def, max)
+	items := make([]map[string]*dynamodb.AttributeValue, 0, limit)
+
+	for {
+		input.Limit = aws.Int64(int64(limit - len(items)))
+
+		var result *dynamodb.QueryOutput
+		err := withRetry(ctx, "Query "+query, true, func(ctx context.Context) error {
+			var err error
+			result, err = svc.QueryWithContext(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		items = append(items, result.Items...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return items, "", nil
+		}
+		if len(items) >= limit {
+			next, err := encodeCursor(query, result.LastEvaluatedKey)
+			return items, next, err
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
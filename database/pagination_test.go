@@ -0,0 +1,59 @@
+package database
+
+import (
+	"testing"
+
+	"good_blast/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	key := map[string]*dynamodb.AttributeValue{
+		"userId":   {S: aws.String("u-42")},
+		"globalPK": {S: aws.String("GLOBAL")},
+		"level":    {N: aws.String("17")},
+	}
+
+	cursor, err := encodeCursor("global", key)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cursor)
+
+	decoded, err := decodeCursor("global", cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, key, decoded)
+}
+
+func TestCursor_EmptyKeyMeansLastPage(t *testing.T) {
+	cursor, err := encodeCursor("global", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, cursor)
+
+	decoded, err := decodeCursor("global", "")
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+}
+
+func TestCursor_RejectsTamperedOrForeignTokens(t *testing.T) {
+	cursor, err := encodeCursor("country:DE", map[string]*dynamodb.AttributeValue{
+		"userId": {S: aws.String("u-1")},
+	})
+	assert.NoError(t, err)
+
+	_, err = decodeCursor("country:FR", cursor)
+	assert.Equal(t, errors.ErrInvalidCursor, err)
+
+	_, err = decodeCursor("country:DE", "not base64!")
+	assert.Equal(t, errors.ErrInvalidCursor, err)
+
+	_, err = decodeCursor("country:DE", "e30") // "{}"
+	assert.Equal(t, errors.ErrInvalidCursor, err)
+}
+
+func TestClampLimit(t *testing.T) {
+	assert.Equal(t, 100, clampLimit(0, 100, 1000))
+	assert.Equal(t, 5, clampLimit(5, 100, 1000))
+	assert.Equal(t, 1000, clampLimit(5000, 100, 1000))
+}
@@ -0,0 +1,159 @@
+// database/quests.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// AddQuestProgress adds count to the progress of a user's quest, creating the quest item on
+// the first event of the day.
+func (db *DynamoDB) AddQuestProgress(ctx context.Context, userId, questKey string, count int) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(userQuestsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"userId":   {S: aws.String(userId)},
+			"questKey": {S: aws.String(questKey)},
+		},
+		UpdateExpression:         aws.String("ADD #p :n"),
+		ExpressionAttributeNames: map[string]*string{"#p": aws.String("progress")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":n": {N: aws.String(strconv.Itoa(count))},
+		},
+	}
+
+	// Not idempotent: a retry after a write that landed would count the event twice
+	err := withRetry(ctx, "AddQuestProgress", false, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return err
+		}
+		return fmt.Errorf("failed to add quest progress: %w", err)
+	}
+	return nil
+}
+
+// QueryUserQuests retrieves a user's stored quests for a UTC day. Quests without any progress
+// have no item yet.
+func (db *DynamoDB) QueryUserQuests(ctx context.Context, userId, day string) ([]models.QuestProgress, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(userQuestsTable),
+		KeyConditionExpression: aws.String("userId = :u AND begins_with(questKey, :day)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u":   {S: aws.String(userId)},
+			":day": {S: aws.String(models.QuestKey(day, ""))},
+		},
+	}
+
+	var result *dynamodb.QueryOutput
+	err := withRetry(ctx, "QueryUserQuests", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.QueryWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to query user quests: %w", err)
+	}
+
+	var quests []models.QuestProgress
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &quests); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user quests: %w", err)
+	}
+	return quests, nil
+}
+
+// questClaimUpdate builds the update that marks a user's quest claimed, conditioned on the
+// quest being completed and not claimed yet.
+func questClaimUpdate(userId, questKey string, target int, now time.Time) *dynamodb.Update {
+	return &dynamodb.Update{
+		TableName: aws.String(userQuestsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"userId":   {S: aws.String(userId)},
+			"questKey": {S: aws.String(questKey)},
+		},
+		UpdateExpression:    aws.String("SET #ca = :claimedAt"),
+		ConditionExpression: aws.String("#p >= :target AND attribute_not_exists(#ca)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#p":  aws.String("progress"),
+			"#ca": aws.String("claimedAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":target":    {N: aws.String(strconv.Itoa(target))},
+			":claimedAt": {S: aws.String(now.UTC().Format(time.RFC3339))},
+		},
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+}
+
+// ClaimQuestTransaction marks a user's quest claimed, grants entry.Coins and entry.Bundle and
+// records the history entry, atomically. It returns ErrQuestNotCompleted when the quest's
+// progress is below target and ErrQuestAlreadyClaimed when it was claimed before.
+func (db *DynamoDB) ClaimQuestTransaction(ctx context.Context, entry models.HistoryEntry, questKey string, target int) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+	fillHistoryEntry(&entry)
+
+	var bundle models.RewardBundle
+	if entry.Bundle != nil {
+		bundle = *entry.Bundle
+	}
+	bundle.Coins = entry.Coins
+
+	entryMap, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	err = grantBundle(ctx, "ClaimQuestTransaction", entry.UserID, bundle,
+		&dynamodb.TransactWriteItem{Update: questClaimUpdate(entry.UserID, questKey, target, time.Now())},
+		&dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName:           aws.String(userHistoryTable),
+				Item:                entryMap,
+				ConditionExpression: aws.String("attribute_not_exists(entryId)"),
+			},
+		},
+	)
+	if err != nil {
+		if err == errors.ErrUserNotFound || cancellationReason(err, 0) == reasonConditionalCheck {
+			return errors.ErrUserNotFound
+		}
+		if cancellationReason(err, 1) == reasonConditionalCheck {
+			if _, claimed := cancellationItem(err, 1)["claimedAt"]; claimed {
+				return errors.ErrQuestAlreadyClaimed
+			}
+			return errors.ErrQuestNotCompleted
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("ClaimQuestTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// database/retry.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"good_blast/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/google/uuid"
+)
+
+// RetryPolicy controls how DynamoDB calls are retried and how long each attempt may take.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first one
+	BaseDelay   time.Duration // backoff before the first retry; doubles per attempt
+	MaxDelay    time.Duration // cap for a single backoff
+	CallTimeout time.Duration // deadline for a single attempt; 0 means only the caller's context applies
+}
+
+// DefaultRetryPolicy is used until InitDynamoDB applies the configured policy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   25 * time.Millisecond,
+	MaxDelay:    1 * time.Second,
+	CallTimeout: 5 * time.Second,
+}
+
+var retryPolicy = DefaultRetryPolicy
+
+// errorClass tells the retry loop what to do with a failed attempt.
+type errorClass int
+
+const (
+	classFatal     errorClass = iota // not retryable; return as is
+	classThrottled                   // capacity exceeded; the request was rejected
+	classConflict                    // transaction conflict; the request was rejected
+	classTransient                   // server or network error; the outcome is unknown
+)
+
+// Cancellation reason codes reported in TransactionCanceledException.
+const (
+	reasonNone                  = "None"
+	reasonConditionalCheck      = "ConditionalCheckFailed"
+	reasonTransactionConflict   = "TransactionConflict"
+	reasonThrottling            = "ThrottlingError"
+	reasonProvisionedThroughput = "ProvisionedThroughputExceeded"
+)
+
+// classify maps an AWS error to an errorClass.
+func classify(err error) errorClass {
+	if tcErr, ok := err.(*dynamodb.TransactionCanceledException); ok {
+		return classifyCancellation(tcErr.CancellationReasons)
+	}
+
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return classFatal
+	}
+	switch aerr.Code() {
+	case dynamodb.ErrCodeProvisionedThroughputExceededException,
+		dynamodb.ErrCodeRequestLimitExceeded,
+		"ThrottlingException":
+		return classThrottled
+	case dynamodb.ErrCodeTransactionConflictException,
+		dynamodb.ErrCodeTransactionInProgressException:
+		return classConflict
+	case dynamodb.ErrCodeInternalServerError,
+		"ServiceUnavailable",
+		"RequestError":
+		return classTransient
+	}
+	return classFatal
+}
+
+// classifyCancellation decides whether a cancelled transaction can be retried as is:
+// only when every reason is a conflict or a throttle (or "None" for untouched items).
+// Condition failures are business outcomes and must be mapped by the caller.
+func classifyCancellation(reasons []*dynamodb.CancellationReason) errorClass {
+	class := classFatal
+	for _, r := range reasons {
+		switch aws.StringValue(r.Code) {
+		case reasonNone, "":
+		case reasonTransactionConflict:
+			if class == classFatal {
+				class = classConflict
+			}
+		case reasonThrottling, reasonProvisionedThroughput:
+			class = classThrottled
+		default:
+			return classFatal
+		}
+	}
+	return class
+}
+
+// cancellationReason returns the code for the i-th item of a cancelled transaction, or "".
+func cancellationReason(err error, i int) string {
+	tcErr, ok := err.(*dynamodb.TransactionCanceledException)
+	if !ok || i >= len(tcErr.CancellationReasons) {
+		return ""
+	}
+	return aws.StringValue(tcErr.CancellationReasons[i].Code)
+}
+
+// cancellationItem returns the old item reported for the i-th item of a cancelled transaction,
+// if the request asked for it with ReturnValuesOnConditionCheckFailure.
+func cancellationItem(err error, i int) map[string]*dynamodb.AttributeValue {
+	tcErr, ok := err.(*dynamodb.TransactionCanceledException)
+	if !ok || i >= len(tcErr.CancellationReasons) {
+		return nil
+	}
+	return tcErr.CancellationReasons[i].Item
+}
+
+// runTransaction runs a write transaction with a fresh request token and passes a cancellation
+// to classify, which returns the error to report for it or nil when it doesn't recognise the
+// failure.
+func runTransaction(ctx context.Context, op string, items []*dynamodb.TransactWriteItem, classify func(err error) error) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
+		TransactItems:      items,
+	}
+	err := withRetry(ctx, op, true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if classified := classify(err); classified != nil {
+			return classified
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Printf("%s DynamoDB error: %v", op, err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// backoff returns a full-jitter delay for the given retry (0-based).
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	ceiling := p.BaseDelay << uint(retry)
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// withRetry runs fn under the retry policy. Each attempt gets its own deadline.
+// Throttles and conflicts are always retried because DynamoDB rejected the request;
+// transient server/network errors are only retried for idempotent operations.
+// When retries are exhausted, throttles surface as ErrThrottled and conflicts as
+// ErrTransactionConflict so callers can answer 503 instead of 500.
+func withRetry(ctx context.Context, op string, idempotent bool, fn func(ctx context.Context) error) error {
+	p := retryPolicy
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay := p.backoff(attempt - 1)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if p.CallTimeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, p.CallTimeout)
+		}
+		err = fn(callCtx)
+		// The attempt's own deadline fired while the caller still has time: treat like a network error
+		timedOut := callCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		class := classify(err)
+		if timedOut {
+			class = classTransient
+		}
+		switch {
+		case class == classThrottled, class == classConflict:
+		case class == classTransient && idempotent:
+		default:
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("%s: attempt %d/%d failed, retrying: %v", op, attempt+1, attempts, err)
+	}
+
+	// Retries exhausted
+	switch classify(err) {
+	case classThrottled:
+		return errors.ErrThrottled
+	case classConflict:
+		return errors.ErrTransactionConflict
+	}
+	return err
+}
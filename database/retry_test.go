@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"good_blast/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// useFastRetries swaps in a policy with tiny delays for the duration of a test.
+func useFastRetries(t *testing.T, attempts int) {
+	t.Helper()
+	prev := retryPolicy
+	retryPolicy = RetryPolicy{
+		MaxAttempts: attempts,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    2 * time.Millisecond,
+		CallTimeout: 50 * time.Millisecond,
+	}
+	t.Cleanup(func() { retryPolicy = prev })
+}
+
+func cancelled(codes ...string) error {
+	reasons := make([]*dynamodb.CancellationReason, len(codes))
+	for i, c := range codes {
+		reasons[i] = &dynamodb.CancellationReason{Code: aws.String(c)}
+	}
+	return &dynamodb.TransactionCanceledException{CancellationReasons: reasons}
+}
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, classThrottled, classify(awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "slow down", nil)))
+	assert.Equal(t, classConflict, classify(awserr.New(dynamodb.ErrCodeTransactionConflictException, "conflict", nil)))
+	assert.Equal(t, classTransient, classify(awserr.New(dynamodb.ErrCodeInternalServerError, "oops", nil)))
+	assert.Equal(t, classFatal, classify(awserr.New(dynamodb.ErrCodeResourceNotFoundException, "no table", nil)))
+
+	assert.Equal(t, classConflict, classify(cancelled("None", "TransactionConflict", "None")))
+	assert.Equal(t, classThrottled, classify(cancelled("TransactionConflict", "ThrottlingError")))
+	// A condition failure is a business outcome, never retried blindly
+	assert.Equal(t, classFatal, classify(cancelled("None", "ConditionalCheckFailed", "TransactionConflict")))
+}
+
+func TestCancellationReason(t *testing.T) {
+	err := cancelled("None", "ConditionalCheckFailed")
+	assert.Equal(t, "ConditionalCheckFailed", cancellationReason(err, 1))
+	assert.Equal(t, "None", cancellationReason(err, 0))
+	assert.Equal(t, "", cancellationReason(err, 5))
+	assert.Equal(t, "", cancellationReason(context.Canceled, 0))
+}
+
+func TestWithRetry_RetriesThrottlingThenSucceeds(t *testing.T) {
+	useFastRetries(t, 5)
+	calls := 0
+
+	err := withRetry(context.Background(), "test", false, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "slow down", nil)
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithRetry_ExhaustedThrottlingIsClassified(t *testing.T) {
+	useFastRetries(t, 3)
+	calls := 0
+
+	err := withRetry(context.Background(), "test", true, func(ctx context.Context) error {
+		calls++
+		return awserr.New("ThrottlingException", "slow down", nil)
+	})
+
+	assert.Equal(t, errors.ErrThrottled, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithRetry_ExhaustedConflictIsClassified(t *testing.T) {
+	useFastRetries(t, 2)
+
+	err := withRetry(context.Background(), "test", true, func(ctx context.Context) error {
+		return cancelled("TransactionConflict", "None")
+	})
+
+	assert.Equal(t, errors.ErrTransactionConflict, err)
+	assert.True(t, errors.IsRetryable(err))
+}
+
+func TestWithRetry_TransientOnlyRetriedWhenIdempotent(t *testing.T) {
+	useFastRetries(t, 4)
+	transient := awserr.New(dynamodb.ErrCodeInternalServerError, "oops", nil)
+
+	calls := 0
+	err := withRetry(context.Background(), "test", false, func(ctx context.Context) error {
+		calls++
+		return transient
+	})
+	assert.Equal(t, transient, err)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = withRetry(context.Background(), "test", true, func(ctx context.Context) error {
+		calls++
+		return transient
+	})
+	assert.Equal(t, transient, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestWithRetry_FatalErrorsReturnImmediately(t *testing.T) {
+	useFastRetries(t, 5)
+	calls := 0
+	condErr := cancelled("ConditionalCheckFailed")
+
+	err := withRetry(context.Background(), "test", true, func(ctx context.Context) error {
+		calls++
+		return condErr
+	})
+
+	assert.Equal(t, condErr, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithRetry_PerCallDeadline(t *testing.T) {
+	useFastRetries(t, 2)
+	calls := 0
+
+	err := withRetry(context.Background(), "test", true, func(ctx context.Context) error {
+		calls++
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		<-ctx.Done() // simulate a hung request
+		return ctx.Err()
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls) // the timed-out attempt was retried once
+}
+
+func TestWithRetry_StopsWhenCallerContextEnds(t *testing.T) {
+	useFastRetries(t, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	err := withRetry(ctx, "test", true, func(ctx context.Context) error {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return awserr.New(dynamodb.ErrCodeRequestLimitExceeded, "busy", nil)
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestBackoff_BoundedByMaxDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 40 * time.Millisecond}
+	for retry := 0; retry < 10; retry++ {
+		d := p.backoff(retry)
+		assert.True(t, d > 0 && d <= 40*time.Millisecond, "retry %d gave %s", retry, d)
+	}
+}
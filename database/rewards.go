@@ -0,0 +1,253 @@
+// database/rewards.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/google/uuid"
+)
+
+// bundleUpdate builds the single user update that applies a reward bundle: coins, lives and
+// cosmetics are added to top-level attributes, boosters and currencies to entries of the
+// boosters and currencies maps (created with ensureInventoryMaps), and an unlimited-lives boost
+// extends unlimitedLivesUntil (brought up to now with startLivesBoost). Coins granted, unlike
+// coins charged, are also added to the coinsEarned achievement stat, so every reward counts
+// towards it in the same write that pays it.
+func bundleUpdate(userID string, b models.RewardBundle) *dynamodb.Update {
+	var set, add []string
+	names := map[string]*string{}
+	values := map[string]*dynamodb.AttributeValue{}
+
+	if b.Coins != 0 {
+		set = append(set, "#c = #c + :coins")
+		names["#c"] = aws.String("coins")
+		values[":coins"] = &dynamodb.AttributeValue{N: aws.String(fmt.Sprintf("%d", b.Coins))}
+	}
+	if b.Coins > 0 {
+		add = append(add, "#ce :coins")
+		names["#ce"] = aws.String("coinsEarned")
+	}
+	if b.Lives != 0 {
+		add = append(add, "#lv :lives")
+		names["#lv"] = aws.String("lives")
+		values[":lives"] = &dynamodb.AttributeValue{N: aws.String(fmt.Sprintf("%d", b.Lives))}
+	}
+	if b.UnlimitedLivesMinutes != 0 {
+		set = append(set, "#ul = #ul + :ulsecs")
+		names["#ul"] = aws.String("unlimitedLivesUntil")
+		values[":ulsecs"] = &dynamodb.AttributeValue{N: aws.String(fmt.Sprintf("%d", b.UnlimitedLivesMinutes*60))}
+	}
+	if len(b.Cosmetics) > 0 {
+		add = append(add, "#cos :cosmetics")
+		names["#cos"] = aws.String("cosmetics")
+		values[":cosmetics"] = &dynamodb.AttributeValue{SS: aws.StringSlice(b.Cosmetics)}
+	}
+
+	// Map entries go through if_not_exists so the first grant of an item starts from zero
+	addToMap := func(attr, prefix string, m map[string]int) {
+		if len(m) == 0 {
+			return
+		}
+		mapName := "#" + prefix
+		names[mapName] = aws.String(attr)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys) // stable expressions make failures easier to compare
+		for i, k := range keys {
+			name, value := fmt.Sprintf("#%s%d", prefix, i), fmt.Sprintf(":%s%d", prefix, i)
+			set = append(set, fmt.Sprintf("%s.%s = if_not_exists(%s.%s, :zero) + %s", mapName, name, mapName, name, value))
+			names[name] = aws.String(k)
+			values[value] = &dynamodb.AttributeValue{N: aws.String(fmt.Sprintf("%d", m[k]))}
+		}
+		values[":zero"] = &dynamodb.AttributeValue{N: aws.String("0")}
+	}
+	addToMap("boosters", "b", b.Boosters)
+	addToMap("currencies", "cur", b.Currencies)
+
+	var expr []string
+	if len(set) > 0 {
+		expr = append(expr, "SET "+strings.Join(set, ", "))
+	}
+	if len(add) > 0 {
+		expr = append(expr, "ADD "+strings.Join(add, ", "))
+	}
+
+	return &dynamodb.Update{
+		TableName:                 aws.String(usersTable),
+		Key:                       map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(userID)}},
+		UpdateExpression:          aws.String(strings.Join(expr, " ")),
+		ConditionExpression:       aws.String("attribute_exists(userId)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+}
+
+// ensureInventoryMaps creates the boosters and currencies maps on a user that has none yet,
+// since DynamoDB can only set entries of a map that exists. It runs before, not inside, the grant
+// transaction (one transaction cannot update the same item twice) and changes nothing once
+// the maps exist.
+func ensureInventoryMaps(ctx context.Context, userID string, b models.RewardBundle) error {
+	if len(b.Boosters) == 0 && len(b.Currencies) == 0 {
+		return nil
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(usersTable),
+		Key:                 map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(userID)}},
+		UpdateExpression:    aws.String("SET #bm = if_not_exists(#bm, :empty), #cm = if_not_exists(#cm, :empty)"),
+		ConditionExpression: aws.String("attribute_exists(userId)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#bm": aws.String("boosters"),
+			"#cm": aws.String("currencies"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":empty": {M: map[string]*dynamodb.AttributeValue{}},
+		},
+	}
+
+	err := withRetry(ctx, "EnsureInventoryMaps", true, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return errors.ErrUserNotFound
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		return fmt.Errorf("failed to prepare inventory: %w", err)
+	}
+	return nil
+}
+
+// startLivesBoost sets a user's unlimitedLivesUntil to now unless a boost is still running, so
+// that bundleUpdate can extend it: a new boost starts now, a running one is lengthened. Like
+// ensureInventoryMaps it runs before the grant transaction. A running boost or a missing user
+// makes the condition fail, which is ignored; the grant transaction reports a missing user.
+func startLivesBoost(ctx context.Context, userID string, now time.Time) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName:                aws.String(usersTable),
+		Key:                      map[string]*dynamodb.AttributeValue{"userId": {S: aws.String(userID)}},
+		UpdateExpression:         aws.String("SET #ul = :now"),
+		ConditionExpression:      aws.String("attribute_exists(userId) AND (attribute_not_exists(#ul) OR #ul < :now)"),
+		ExpressionAttributeNames: map[string]*string{"#ul": aws.String("unlimitedLivesUntil")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":now": {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+		},
+	}
+
+	err := withRetry(ctx, "StartLivesBoost", true, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		return fmt.Errorf("failed to start lives boost: %w", err)
+	}
+	return nil
+}
+
+// prepareBundle runs the pre-steps a bundle update needs: ensureInventoryMaps, and
+// startLivesBoost when the bundle grants unlimited lives.
+func prepareBundle(ctx context.Context, userID string, b models.RewardBundle) error {
+	if err := ensureInventoryMaps(ctx, userID, b); err != nil {
+		return err
+	}
+	if b.UnlimitedLivesMinutes > 0 {
+		return startLivesBoost(ctx, userID, time.Now())
+	}
+	return nil
+}
+
+// prependSet adds assignments to the front of an update's SET clause, creating the clause
+// when the update has none, so other attributes of the user item can change together with
+// a bundle.
+func prependSet(u *dynamodb.Update, assignments string) {
+	expr := aws.StringValue(u.UpdateExpression)
+	if strings.HasPrefix(expr, "SET ") {
+		expr = "SET " + assignments + ", " + strings.TrimPrefix(expr, "SET ")
+	} else {
+		expr = strings.TrimSpace("SET " + assignments + " " + expr)
+	}
+	u.UpdateExpression = aws.String(expr)
+}
+
+// grantBundle applies a reward bundle to a user together with extra transaction items, all or
+// nothing. The bundle update is item 0, so extra items start at index 1 in cancellation reasons.
+// Errors are returned unclassified for the caller to map.
+func grantBundle(ctx context.Context, op, userID string, b models.RewardBundle, extra ...*dynamodb.TransactWriteItem) error {
+	if b.IsEmpty() {
+		return fmt.Errorf("%s: reward bundle for %s is empty", op, userID)
+	}
+	if err := prepareBundle(ctx, userID, b); err != nil {
+		return err
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
+		TransactItems:      append([]*dynamodb.TransactWriteItem{{Update: bundleUpdate(userID, b)}}, extra...),
+	}
+	return withRetry(ctx, op, true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, input)
+		return err
+	})
+}
+
+// GrantRewardTransaction applies entry.Bundle and entry.Coins to the user and records the
+// history entry, atomically. It returns ErrUserNotFound when the user does not exist.
+func (db *DynamoDB) GrantRewardTransaction(ctx context.Context, entry models.HistoryEntry) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+	fillHistoryEntry(&entry)
+
+	var bundle models.RewardBundle
+	if entry.Bundle != nil {
+		bundle = *entry.Bundle
+	}
+	bundle.Coins = entry.Coins
+
+	entryMap, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	err = grantBundle(ctx, "GrantRewardTransaction", entry.UserID, bundle, &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{
+			TableName: aws.String(userHistoryTable),
+			Item:      entryMap,
+		},
+	})
+	if err != nil {
+		if err == errors.ErrUserNotFound || cancellationReason(err, 0) == reasonConditionalCheck {
+			return errors.ErrUserNotFound
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("GrantRewardTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundleUpdate_AppliesEveryPartInOneExpression(t *testing.T) {
+	u := bundleUpdate("u1", models.RewardBundle{
+		Coins:      100,
+		Lives:      2,
+		Boosters:   map[string]int{"rocket": 1, "bomb": 3},
+		Currencies: map[string]int{"gems": 5},
+		Cosmetics:  []string{"crown"},
+	})
+
+	assert.Equal(t, "SET #c = #c + :coins, "+
+		"#b.#b0 = if_not_exists(#b.#b0, :zero) + :b0, #b.#b1 = if_not_exists(#b.#b1, :zero) + :b1, "+
+		"#cur.#cur0 = if_not_exists(#cur.#cur0, :zero) + :cur0 "+
+		"ADD #ce :coins, #lv :lives, #cos :cosmetics", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "attribute_exists(userId)", aws.StringValue(u.ConditionExpression))
+
+	// Map keys are sorted, so the expression is the same on every call
+	assert.Equal(t, "bomb", aws.StringValue(u.ExpressionAttributeNames["#b0"]))
+	assert.Equal(t, "3", aws.StringValue(u.ExpressionAttributeValues[":b0"].N))
+	assert.Equal(t, "rocket", aws.StringValue(u.ExpressionAttributeNames["#b1"]))
+	assert.Equal(t, "gems", aws.StringValue(u.ExpressionAttributeNames["#cur0"]))
+	assert.Equal(t, []string{"crown"}, aws.StringValueSlice(u.ExpressionAttributeValues[":cosmetics"].SS))
+}
+
+func TestBundleUpdate_CoinsOnly(t *testing.T) {
+	u := bundleUpdate("u1", models.RewardBundle{Coins: 5000})
+
+	assert.Equal(t, "SET #c = #c + :coins ADD #ce :coins", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "coinsEarned", aws.StringValue(u.ExpressionAttributeNames["#ce"]))
+	assert.Len(t, u.ExpressionAttributeNames, 2)
+	assert.Len(t, u.ExpressionAttributeValues, 1)
+}
+
+func TestBundleUpdate_ChargedCoinsAreNotEarned(t *testing.T) {
+	u := bundleUpdate("u1", models.RewardBundle{Coins: -900})
+
+	assert.Equal(t, "SET #c = #c + :coins", aws.StringValue(u.UpdateExpression))
+	assert.NotContains(t, u.ExpressionAttributeNames, "#ce")
+}
+
+func TestBundleUpdate_ExtendsUnlimitedLives(t *testing.T) {
+	u := bundleUpdate("u1", models.RewardBundle{UnlimitedLivesMinutes: 90})
+
+	assert.Equal(t, "SET #ul = #ul + :ulsecs", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "unlimitedLivesUntil", aws.StringValue(u.ExpressionAttributeNames["#ul"]))
+	assert.Equal(t, "5400", aws.StringValue(u.ExpressionAttributeValues[":ulsecs"].N))
+}
+
+func TestLivesUpdate_ConditionsOnStoredRecord(t *testing.T) {
+	u := livesUpdate("u1", models.LivesRecord{Lives: 3, UpdatedAt: 1700000000}, models.LivesRecord{Lives: 2, UpdatedAt: 1700000600})
+
+	assert.Equal(t, "SET #lv = :lives, #la = :at", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "attribute_exists(userId) AND #lv = :oldLives AND #la = :oldAt", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "2", aws.StringValue(u.ExpressionAttributeValues[":lives"].N))
+	assert.Equal(t, "1700000000", aws.StringValue(u.ExpressionAttributeValues[":oldAt"].N))
+
+	// A user who never spent a life has neither attribute, or zero lives left by a grant
+	u = livesUpdate("u1", models.LivesRecord{}, models.LivesRecord{Lives: 4, UpdatedAt: 1700000000})
+	assert.Equal(t, "attribute_exists(userId) AND (attribute_not_exists(#lv) OR #lv = :zero) AND (attribute_not_exists(#la) OR #la = :zero)",
+		aws.StringValue(u.ConditionExpression))
+}
+
+func TestLevelUpdate_JoinsBundleAndConditionsOnLevel(t *testing.T) {
+	u := levelUpdate("u1", 9, 11, models.RewardBundle{Coins: 250, Lives: 1})
+
+	assert.Equal(t, "SET #lvl = :toLevel, #c = #c + :coins ADD #ce :coins, #lv :lives", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "attribute_exists(userId) AND #lvl = :fromLevel", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "11", aws.StringValue(u.ExpressionAttributeValues[":toLevel"].N))
+	assert.Equal(t, "9", aws.StringValue(u.ExpressionAttributeValues[":fromLevel"].N))
+
+	// A catalog may make a level worth nothing
+	u = levelUpdate("u1", 3, 4, models.RewardBundle{})
+	assert.Equal(t, "SET #lvl = :toLevel", aws.StringValue(u.UpdateExpression))
+
+	u = levelUpdate("u1", 3, 4, models.RewardBundle{Cosmetics: []string{"crown"}})
+	assert.Equal(t, "SET #lvl = :toLevel ADD #cos :cosmetics", aws.StringValue(u.UpdateExpression))
+}
+
+func TestCheckInUpdate_ConditionsOnLastCheckIn(t *testing.T) {
+	u := checkInUpdate("u1", models.CheckInState{}, models.CheckInState{CheckInStreak: 1, LastCheckIn: "2024-11-02"}, models.RewardBundle{Coins: 100})
+
+	assert.Equal(t, "SET #cs = :streak, #lc = :day, #c = #c + :coins ADD #ce :coins", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "attribute_exists(userId) AND attribute_not_exists(#lc)", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "2024-11-02", aws.StringValue(u.ExpressionAttributeValues[":day"].S))
+
+	// Restoring a streak is paid for, so the balance must cover it
+	u = checkInUpdate("u1",
+		models.CheckInState{CheckInStreak: 5, LastCheckIn: "2024-10-30"},
+		models.CheckInState{CheckInStreak: 5, LastCheckIn: "2024-11-01"},
+		models.RewardBundle{Coins: -400})
+	assert.Equal(t, "attribute_exists(userId) AND #lc = :oldDay AND #c >= :cost", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "2024-10-30", aws.StringValue(u.ExpressionAttributeValues[":oldDay"].S))
+	assert.Equal(t, "400", aws.StringValue(u.ExpressionAttributeValues[":cost"].N))
+	assert.Equal(t, "-400", aws.StringValue(u.ExpressionAttributeValues[":coins"].N))
+}
+
+func TestQuestClaimUpdate_ConditionsOnProgressAndClaim(t *testing.T) {
+	u := questClaimUpdate("u1", "2024-11-02#clear_3_levels", 3, time.Date(2024, 11, 2, 18, 30, 0, 0, time.UTC))
+
+	assert.Equal(t, "SET #ca = :claimedAt", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "#p >= :target AND attribute_not_exists(#ca)", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "3", aws.StringValue(u.ExpressionAttributeValues[":target"].N))
+	assert.Equal(t, "2024-11-02T18:30:00Z", aws.StringValue(u.ExpressionAttributeValues[":claimedAt"].S))
+	assert.Equal(t, "2024-11-02#clear_3_levels", aws.StringValue(u.Key["questKey"].S))
+	assert.Equal(t, dynamodb.ReturnValuesOnConditionCheckFailureAllOld, aws.StringValue(u.ReturnValuesOnConditionCheckFailure))
+}
+
+func TestAchievementStatsUpdate_AddsOnlyNonZeroCounters(t *testing.T) {
+	u := achievementStatsUpdate("u1", models.AchievementStats{TournamentWins: 1, PodiumFinishes: 1, CoinsEarned: 500})
+	assert.Equal(t, "ADD #tw :tw, #pf :pf, #ce :ce", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "attribute_exists(userId)", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "500", aws.StringValue(u.ExpressionAttributeValues[":ce"].N))
+
+	u = achievementStatsUpdate("u1", models.AchievementStats{PodiumFinishes: 1})
+	assert.Equal(t, "ADD #pf :pf", aws.StringValue(u.UpdateExpression))
+	assert.Len(t, u.ExpressionAttributeNames, 1)
+}
+
+func TestSeasonClaimUpdate_ConditionsOnXPClaimsAndPremium(t *testing.T) {
+	u := seasonClaimUpdate("u1", "s1", 3, 1500, models.SeasonTrackFree)
+	assert.Equal(t, "ADD #cl :tier", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "#xp >= :xp AND NOT contains(#cl, :tierN)", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "claimedFree", aws.StringValue(u.ExpressionAttributeNames["#cl"]))
+	assert.Equal(t, "1500", aws.StringValue(u.ExpressionAttributeValues[":xp"].N))
+	assert.Equal(t, "3", aws.StringValue(u.ExpressionAttributeValues[":tier"].NS[0]))
+	assert.Equal(t, "s1", aws.StringValue(u.Key["seasonId"].S))
+
+	u = seasonClaimUpdate("u1", "s1", 3, 1500, models.SeasonTrackPremium)
+	assert.Equal(t, "#xp >= :xp AND NOT contains(#cl, :tierN) AND #pr = :trueVal", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "claimedPremium", aws.StringValue(u.ExpressionAttributeNames["#cl"]))
+	assert.Equal(t, dynamodb.ReturnValuesOnConditionCheckFailureAllOld, aws.StringValue(u.ReturnValuesOnConditionCheckFailure))
+}
+
+func TestSeasonProgress_ClaimsRoundTripAsNumberSets(t *testing.T) {
+	item, err := dynamodbattribute.MarshalMap(models.SeasonProgress{UserID: "u1", SeasonID: "s1", XP: 900, ClaimedFree: []int{1, 2}})
+	assert.NoError(t, err)
+	assert.Len(t, item["claimedFree"].NS, 2)
+	assert.NotContains(t, item, "claimedPremium") // an empty set cannot be stored
+
+	var p models.SeasonProgress
+	assert.NoError(t, dynamodbattribute.UnmarshalMap(item, &p))
+	assert.True(t, p.Claimed(models.SeasonTrackFree, 2))
+	assert.False(t, p.Claimed(models.SeasonTrackPremium, 2))
+}
+
+func TestLeagueMoveUpdate_OnlyAppliesNewerTournaments(t *testing.T) {
+	u := leagueMoveUpdate(models.LeagueResult{UserID: "u1", TournamentID: "2024-01-02", From: "silver", To: "gold"})
+	assert.Equal(t, "SET #lg = :to, #lt = :tid", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "attribute_exists(userId) AND (attribute_not_exists(#lt) OR #lt < :tid)", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "gold", aws.StringValue(u.ExpressionAttributeValues[":to"].S))
+	assert.Equal(t, "2024-01-02", aws.StringValue(u.ExpressionAttributeValues[":tid"].S))
+	assert.Equal(t, dynamodb.ReturnValuesOnConditionCheckFailureAllOld, aws.StringValue(u.ReturnValuesOnConditionCheckFailure))
+}
+
+func TestUserJoinClanUpdate_RequiresNoClanAndTheCountedLevel(t *testing.T) {
+	u := userJoinClanUpdate(models.ClanMember{ClanID: "c1", UserID: "u1", Level: 12})
+	assert.Equal(t, "SET #cl = :c", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "attribute_exists(userId) AND attribute_not_exists(#cl) AND #lvl = :lvl", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "12", aws.StringValue(u.ExpressionAttributeValues[":lvl"].N))
+
+	// The old user item tells the failures apart
+	assert.Equal(t, errors.ErrUserNotFound, userJoinFailure(nil))
+	assert.Equal(t, errors.ErrAlreadyInClan, userJoinFailure(map[string]*dynamodb.AttributeValue{"clanId": {S: aws.String("c2")}}))
+	assert.Equal(t, errors.ErrTransactionConflict, userJoinFailure(map[string]*dynamodb.AttributeValue{"level": {N: aws.String("13")}}))
+}
+
+func TestClanTotalsUpdate_AddsMembersAndLevels(t *testing.T) {
+	u := clanTotalsUpdate("c1", -1, -12)
+	assert.Equal(t, "ADD #mc :m, #tl :l", aws.StringValue(u.UpdateExpression))
+	assert.Equal(t, "-1", aws.StringValue(u.ExpressionAttributeValues[":m"].N))
+	assert.Equal(t, "-12", aws.StringValue(u.ExpressionAttributeValues[":l"].N))
+}
+
+func TestClanRewardPaidUpdate_PaysEachContributionOnce(t *testing.T) {
+	u := clanRewardPaidUpdate("2024-01-15", "c1", "u1")
+	assert.Equal(t, "attribute_exists(userId) AND attribute_not_exists(#rp)", aws.StringValue(u.ConditionExpression))
+	assert.Equal(t, "2024-01-15#c1", aws.StringValue(u.Key["entryKey"].S))
+	assert.Equal(t, "u1", aws.StringValue(u.Key["userId"].S))
+}
+
+func TestFriendCountUpdate_LimitsOnlyAddedFriends(t *testing.T) {
+	add := friendCountUpdate("u1", 1, 100)
+	assert.Equal(t, "attribute_exists(userId) AND (attribute_not_exists(#fc) OR #fc < :max)", aws.StringValue(add.ConditionExpression))
+	assert.Equal(t, "100", aws.StringValue(add.ExpressionAttributeValues[":max"].N))
+
+	remove := friendCountUpdate("u1", -1, 0)
+	assert.Equal(t, "attribute_exists(userId)", aws.StringValue(remove.ConditionExpression))
+	assert.Equal(t, "-1", aws.StringValue(remove.ExpressionAttributeValues[":d"].N))
+	assert.NotContains(t, remove.ExpressionAttributeValues, ":max")
+}
@@ -0,0 +1,250 @@
+// database/seasons.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/google/uuid"
+)
+
+// seasonProgressKey is the key of a user's progress item for a season.
+func seasonProgressKey(userId, seasonId string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"userId":   {S: aws.String(userId)},
+		"seasonId": {S: aws.String(seasonId)},
+	}
+}
+
+// GetSeasonProgress retrieves a user's progress in a season; nil when they have none yet.
+func (db *DynamoDB) GetSeasonProgress(ctx context.Context, userId, seasonId string) (*models.SeasonProgress, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.GetItemInput{
+		TableName:      aws.String(seasonProgressTable),
+		Key:            seasonProgressKey(userId, seasonId),
+		ConsistentRead: aws.Bool(true),
+	}
+
+	var result *dynamodb.GetItemOutput
+	err := withRetry(ctx, "GetSeasonProgress", true, func(ctx context.Context) error {
+		var err error
+		result, err = svc.GetItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get season progress: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var progress models.SeasonProgress
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &progress); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal season progress: %w", err)
+	}
+	return &progress, nil
+}
+
+// AddSeasonXP adds xp to a user's progress in a season, creating it on the first XP earned.
+func (db *DynamoDB) AddSeasonXP(ctx context.Context, userId, seasonId string, xp int) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                aws.String(seasonProgressTable),
+		Key:                      seasonProgressKey(userId, seasonId),
+		UpdateExpression:         aws.String("ADD #xp :xp"),
+		ExpressionAttributeNames: map[string]*string{"#xp": aws.String("xp")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":xp": {N: aws.String(strconv.Itoa(xp))},
+		},
+	}
+
+	// Not idempotent: a retry after a write that landed would count the XP twice
+	err := withRetry(ctx, "AddSeasonXP", false, func(ctx context.Context) error {
+		_, err := svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if errors.IsRetryable(err) {
+			return err
+		}
+		return fmt.Errorf("failed to add season XP: %w", err)
+	}
+	return nil
+}
+
+// seasonClaimUpdate builds the update that records a tier's reward on a track as claimed,
+// conditioned on the user having the tier's XP, not having claimed it yet and, on the premium
+// track, owning the premium pass.
+func seasonClaimUpdate(userId, seasonId string, tier, xp int, track string) *dynamodb.Update {
+	claimed := "claimedFree"
+	condition := "#xp >= :xp AND NOT contains(#cl, :tierN)"
+	values := map[string]*dynamodb.AttributeValue{
+		":xp":    {N: aws.String(strconv.Itoa(xp))},
+		":tier":  {NS: []*string{aws.String(strconv.Itoa(tier))}},
+		":tierN": {N: aws.String(strconv.Itoa(tier))},
+	}
+	names := map[string]*string{"#xp": aws.String("xp")}
+	if track == models.SeasonTrackPremium {
+		claimed = "claimedPremium"
+		condition += " AND #pr = :trueVal"
+		names["#pr"] = aws.String("premium")
+		values[":trueVal"] = &dynamodb.AttributeValue{BOOL: aws.Bool(true)}
+	}
+	names["#cl"] = aws.String(claimed)
+
+	return &dynamodb.Update{
+		TableName:                           aws.String(seasonProgressTable),
+		Key:                                 seasonProgressKey(userId, seasonId),
+		UpdateExpression:                    aws.String("ADD #cl :tier"),
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeNames:            names,
+		ExpressionAttributeValues:           values,
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+}
+
+// ClaimSeasonTierTransaction grants a season tier's reward on a track, marks it claimed and
+// records the history entry, atomically. xp is the XP the tier needs. It returns
+// ErrSeasonTierClaimed, ErrSeasonPremiumRequired or ErrSeasonTierLocked when the stored
+// progress doesn't allow the claim.
+func (db *DynamoDB) ClaimSeasonTierTransaction(ctx context.Context, entry models.HistoryEntry, seasonId string, tier, xp int, track string) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+	fillHistoryEntry(&entry)
+
+	var bundle models.RewardBundle
+	if entry.Bundle != nil {
+		bundle = *entry.Bundle
+	}
+	bundle.Coins = entry.Coins
+
+	entryMap, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	err = grantBundle(ctx, "ClaimSeasonTierTransaction", entry.UserID, bundle,
+		&dynamodb.TransactWriteItem{Update: seasonClaimUpdate(entry.UserID, seasonId, tier, xp, track)},
+		&dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName:           aws.String(userHistoryTable),
+				Item:                entryMap,
+				ConditionExpression: aws.String("attribute_not_exists(entryId)"),
+			},
+		},
+	)
+	if err != nil {
+		if err == errors.ErrUserNotFound || cancellationReason(err, 0) == reasonConditionalCheck {
+			return errors.ErrUserNotFound
+		}
+		if cancellationReason(err, 1) == reasonConditionalCheck {
+			var old models.SeasonProgress
+			if item := cancellationItem(err, 1); item != nil {
+				if err := dynamodbattribute.UnmarshalMap(item, &old); err != nil {
+					return fmt.Errorf("failed to unmarshal season progress: %w", err)
+				}
+			}
+			switch {
+			case old.Claimed(track, tier):
+				return errors.ErrSeasonTierClaimed
+			case track == models.SeasonTrackPremium && !old.Premium:
+				return errors.ErrSeasonPremiumRequired
+			default:
+				return errors.ErrSeasonTierLocked
+			}
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("ClaimSeasonTierTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// PurchaseSeasonPremiumTransaction charges -entry.Coins for a season's premium pass, grants the
+// pass and records the history entry, atomically. It returns ErrNotEnoughCoins when the
+// balance doesn't cover the cost and ErrSeasonPremiumOwned when the user has the pass already.
+func (db *DynamoDB) PurchaseSeasonPremiumTransaction(ctx context.Context, entry models.HistoryEntry, seasonId string) error {
+	if svc == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+	fillHistoryEntry(&entry)
+
+	charge := bundleUpdate(entry.UserID, models.RewardBundle{Coins: entry.Coins})
+	charge.ConditionExpression = aws.String("attribute_exists(userId) AND #c >= :cost")
+	charge.ExpressionAttributeValues[":cost"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(-entry.Coins))}
+	charge.ReturnValuesOnConditionCheckFailure = aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld)
+
+	entryMap, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		ClientRequestToken: aws.String(uuid.New().String()),
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{Update: charge},
+			{
+				Update: &dynamodb.Update{
+					TableName:                aws.String(seasonProgressTable),
+					Key:                      seasonProgressKey(entry.UserID, seasonId),
+					UpdateExpression:         aws.String("SET #pr = :trueVal"),
+					ConditionExpression:      aws.String("attribute_not_exists(#pr) OR #pr = :falseVal"),
+					ExpressionAttributeNames: map[string]*string{"#pr": aws.String("premium")},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+						":trueVal":  {BOOL: aws.Bool(true)},
+						":falseVal": {BOOL: aws.Bool(false)},
+					},
+				},
+			},
+			{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(userHistoryTable),
+					Item:                entryMap,
+					ConditionExpression: aws.String("attribute_not_exists(entryId)"),
+				},
+			},
+		},
+	}
+
+	err = withRetry(ctx, "PurchaseSeasonPremiumTransaction", true, func(ctx context.Context) error {
+		_, err := svc.TransactWriteItemsWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		if cancellationReason(err, 0) == reasonConditionalCheck {
+			if cancellationItem(err, 0) == nil {
+				return errors.ErrUserNotFound
+			}
+			return errors.ErrNotEnoughCoins
+		}
+		if cancellationReason(err, 1) == reasonConditionalCheck {
+			return errors.ErrSeasonPremiumOwned
+		}
+		if errors.IsRetryable(err) {
+			return err
+		}
+		log.Println("PurchaseSeasonPremiumTransaction DynamoDB error:", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// database/seats.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Group assignment
+//
+// Each tournament has SeatShards independent seat counters stored as extra items in the
+// Tournaments table (tournamentId = "<id>#seats#<shard>"). A join picks a random shard and
+// atomically ADDs 1 to its counter; the returned number is the user's seat in that shard.
+// Seats 1-35 form group 1 of the shard, 36-70 group 2, and so on. Because ADD never fails
+// on a condition, concurrent joins never conflict, and because every seat number in a shard
+// is handed out exactly once, no group can receive more than GroupCapacity users.
+//
+// A seat whose entry transaction later fails is simply never used, so a group may end up
+// with fewer than GroupCapacity members but never more.
+//
+// Players are only grouped with players of their own league: each league has its own seat
+// counter and groups, keyed by the pool "<id>-<league>" instead of the tournament ID. A league
+// pool has a single shard, so it fills one group before starting the next and only its last
+// group is partly filled; random shards would leave every league with SeatShards small groups.
+// The joins are already spread over one counter per league. SeatShards applies to tournaments
+// played without leagues. Clans entering a tournament are grouped the same way,
+// ClanGroupCapacity to a group, in the pool "<id>#clans", which also has a single shard.
+
+// seatCounter hands out strictly increasing seat numbers per pool shard, starting at 1.
+type seatCounter interface {
+	next(ctx context.Context, pool string, shard int) (int64, error)
+}
+
+// dynamoSeatCounter keeps the counters in the Tournaments table.
+type dynamoSeatCounter struct{}
+
+// seats is the counter used by EnterTournamentTransaction; tests replace it.
+var seats seatCounter = dynamoSeatCounter{}
+
+// seatPool returns the pool a league's players are grouped in; tournaments played without
+// leagues have one pool named after the tournament.
+func seatPool(tournamentID, league string) string {
+	if league == "" {
+		return tournamentID
+	}
+	return tournamentID + "-" + league
+}
+
+// clanSeatPool returns the pool the clans entering a tournament are grouped in. The '#' keeps it
+// apart from the "<id>-<league>" pools whatever the league IDs are.
+func clanSeatPool(tournamentID string) string {
+	return tournamentID + "#clans"
+}
+
+// seatCounterKey is the Tournaments table key holding one shard's counter.
+func seatCounterKey(pool string, shard int) string {
+	return fmt.Sprintf("%s#seats#%d", pool, shard)
+}
+
+func (dynamoSeatCounter) next(ctx context.Context, pool string, shard int) (int64, error) {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(tournamentsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"tournamentId": {S: aws.String(seatCounterKey(pool, shard))},
+		},
+		UpdateExpression:          aws.String("ADD #s :one"),
+		ExpressionAttributeNames:  map[string]*string{"#s": aws.String("seatsTaken")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":one": {N: aws.String("1")}},
+		ReturnValues:              aws.String(dynamodb.ReturnValueUpdatedNew),
+	}
+
+	var out *dynamodb.UpdateItemOutput
+	// A retried ADD whose first attempt did land only skips a seat, which is safe
+	err := withRetry(ctx, "AllocateSeat", true, func(ctx context.Context) error {
+		var err error
+		out, err = svc.UpdateItemWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	attr, ok := out.Attributes["seatsTaken"]
+	if !ok || attr.N == nil {
+		return 0, fmt.Errorf("seat counter %s returned no value", seatCounterKey(pool, shard))
+	}
+	seat, err := strconv.ParseInt(*attr.N, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid seat number %q: %w", *attr.N, err)
+	}
+	return seat, nil
+}
+
+// groupForSeat returns the group identifier for a seat in the given shard of a pool whose
+// groups hold capacity seats.
+func groupForSeat(pool string, shard int, seat, capacity int64) string {
+	group := (seat-1)/capacity + 1
+	return fmt.Sprintf("%s-group-%d-%d", pool, shard, group)
+}
+
+// assignGroup reserves a seat in the tournament's pool for a league and returns its group.
+// League pools have a single shard; a tournament without leagues uses a random one of its
+// SeatShards.
+func assignGroup(ctx context.Context, counter seatCounter, t *models.Tournament, league string) (string, error) {
+	shards := t.SeatShards
+	if league != "" {
+		shards = 1
+	}
+	return assignPoolGroup(ctx, counter, seatPool(t.TournamentID, league), shards, models.GroupCapacity)
+}
+
+// assignClanGroup reserves a seat in the tournament's clan pool and returns its group. Clans
+// enter far less often than players, so the pool has a single counter and fills one group
+// before starting the next.
+func assignClanGroup(ctx context.Context, counter seatCounter, t *models.Tournament) (string, error) {
+	return assignPoolGroup(ctx, counter, clanSeatPool(t.TournamentID), 1, models.ClanGroupCapacity)
+}
+
+// assignPoolGroup reserves a seat on a random one of a pool's shards and returns its group of
+// capacity seats.
+func assignPoolGroup(ctx context.Context, counter seatCounter, pool string, shards int, capacity int64) (string, error) {
+	if shards < 1 {
+		shards = 1 // tournaments created before seat sharding
+	}
+	shard := rand.Intn(shards)
+
+	seat, err := counter.next(ctx, pool, shard)
+	if err != nil {
+		return "", err
+	}
+	if seat < 1 {
+		return "", fmt.Errorf("invalid seat number %d", seat)
+	}
+	return groupForSeat(pool, shard, seat, capacity), nil
+}
@@ -0,0 +1,202 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"good_blast/config"
+	"good_blast/database/migrate"
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// The tests in this file run against a real DynamoDB endpoint, usually DynamoDB Local:
+//
+//	docker run -d -p 8000:8000 amazon/dynamodb-local
+//	DYNAMODB_ENDPOINT=http://localhost:8000 go test -tags integration -run Integration ./database/
+//
+// Every run creates its own tables, named with a unique prefix, and deletes them afterwards.
+
+// setupIntegrationTables creates a fresh set of tables on DYNAMODB_ENDPOINT and points the
+// package client at them. The test is skipped when no endpoint is configured.
+func setupIntegrationTables(t *testing.T) {
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("DYNAMODB_ENDPOINT not set")
+	}
+	region := os.Getenv("DYNAMODB_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	cfg := config.Default().DynamoDB
+	cfg.Region = region
+	cfg.Endpoint = endpoint
+	prefix := fmt.Sprintf("it%d_", time.Now().UnixNano())
+	for _, name := range []*string{
+		&cfg.UsersTable, &cfg.TournamentsTable, &cfg.TournamentEntriesTable, &cfg.UserHistoryTable,
+		&cfg.LevelSessionsTable, &cfg.UserQuestsTable, &cfg.UserAchievementsTable, &cfg.SeasonProgressTable,
+		&cfg.LeagueHistoryTable, &cfg.ClansTable, &cfg.ClanMembersTable, &cfg.ClanTournamentEntriesTable,
+		&cfg.ClanContributionsTable, &cfg.FriendshipsTable, &cfg.MigrationsTable,
+	} {
+		*name = prefix + *name
+	}
+
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region), Endpoint: aws.String(endpoint)})
+	if err != nil {
+		t.Fatalf("creating AWS session: %v", err)
+	}
+	client := dynamodb.New(sess)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	m := migrate.NewMigrator(client, migrate.TablesFromConfig(cfg))
+	m.PollInterval = 100 * time.Millisecond
+	t.Cleanup(func() {
+		out, err := client.ListTables(&dynamodb.ListTablesInput{})
+		if err != nil {
+			t.Logf("listing tables for cleanup: %v", err)
+			return
+		}
+		for _, name := range out.TableNames {
+			if strings.HasPrefix(*name, prefix) {
+				if _, err := client.DeleteTable(&dynamodb.DeleteTableInput{TableName: name}); err != nil {
+					t.Logf("deleting table %s: %v", *name, err)
+				}
+			}
+		}
+	})
+	if _, err := m.Up(ctx, migrate.All); err != nil {
+		t.Fatalf("creating tables: %v", err)
+	}
+	if err := InitDynamoDB(cfg); err != nil {
+		t.Fatalf("initializing client: %v", err)
+	}
+}
+
+// TestIntegration_ConcurrentEntriesNeverOverfillGroups enters a tournament from many goroutines
+// at once against real seat counters and transactions: a league's groups fill one at a time,
+// players without a league are spread over the shards, and no group ever holds more than
+// GroupCapacity users.
+func TestIntegration_ConcurrentEntriesNeverOverfillGroups(t *testing.T) {
+	setupIntegrationTables(t)
+	ctx := context.Background()
+	db := &DynamoDB{}
+
+	const (
+		leaguePlayers = 250
+		shardPlayers  = 250
+		workers       = 50
+	)
+	tournament := &models.Tournament{TournamentID: "2024-01-15", Active: true, SeatShards: 8}
+	if err := db.PutTournament(ctx, *tournament); err != nil {
+		t.Fatalf("creating tournament: %v", err)
+	}
+	for i := 0; i < leaguePlayers+shardPlayers; i++ {
+		user := models.User{
+			UserID:   fmt.Sprintf("user-%d", i),
+			Username: fmt.Sprintf("player%d", i),
+			Level:    models.MinEntryLevel,
+			Coins:    models.EntryFee,
+			GlobalPK: "GLOBAL",
+		}
+		if err := db.PutUser(ctx, user); err != nil {
+			t.Fatalf("creating user: %v", err)
+		}
+	}
+
+	// Players without a league join twice at once: exactly one join may succeed, and any seat
+	// the other one took must stay empty
+	type join struct {
+		userID string
+		league string
+	}
+	joins := make(chan join)
+	go func() {
+		defer close(joins)
+		for i := 0; i < leaguePlayers+shardPlayers; i++ {
+			userID := fmt.Sprintf("user-%d", i)
+			if i < leaguePlayers {
+				joins <- join{userID, "gold"}
+				continue
+			}
+			joins <- join{userID, ""}
+			joins <- join{userID, ""}
+		}
+	}()
+
+	var (
+		mu       sync.Mutex
+		entered  = make(map[string]int)
+		failures []error
+		wg       sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range joins {
+				err := db.EnterTournamentTransaction(ctx, j.userID, models.MinEntryLevel, models.EntryFee, tournament, j.league)
+				mu.Lock()
+				switch {
+				case err == nil:
+					entered[j.userID]++
+				case j.league == "" && (err == errors.ErrAlreadyInTournament || err == errors.ErrRequirementsNotMetForEntry):
+				default:
+					failures = append(failures, fmt.Errorf("%s: %w", j.userID, err))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, failures)
+	assert.Len(t, entered, leaguePlayers+shardPlayers)
+	for userID, n := range entered {
+		assert.Equal(t, 1, n, "user %s entered %d times", userID, n)
+	}
+
+	members := make(map[string]int)
+	// Read every entry of the tournament; the group index query stops at GroupCapacity
+	var page models.PageRequest
+	for {
+		result, err := db.QueryTournamentEntries(ctx, tournament.TournamentID, page)
+		if err != nil {
+			t.Fatalf("reading entries: %v", err)
+		}
+		for _, entry := range result.Items {
+			members[entry.GroupID]++
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		page.Cursor = result.NextCursor
+	}
+
+	total := 0
+	for groupID, n := range members {
+		assert.LessOrEqual(t, n, models.GroupCapacity, "group %s is over capacity", groupID)
+		total += n
+	}
+	assert.Equal(t, leaguePlayers+shardPlayers, total)
+
+	// Every gold join succeeded, so every gold group but the last is full
+	goldGroups := (leaguePlayers + models.GroupCapacity - 1) / models.GroupCapacity
+	for g := 1; g < goldGroups; g++ {
+		groupID := fmt.Sprintf("2024-01-15-gold-group-0-%d", g)
+		assert.Equal(t, models.GroupCapacity, members[groupID], "group %s is not full", groupID)
+	}
+}
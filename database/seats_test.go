@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"good_blast/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memSeatCounter behaves like the DynamoDB ADD counter: one atomic counter per shard.
+type memSeatCounter struct {
+	mu       sync.Mutex
+	counters map[string]*int64
+	calls    int64
+}
+
+func newMemSeatCounter() *memSeatCounter {
+	return &memSeatCounter{counters: make(map[string]*int64)}
+}
+
+func (m *memSeatCounter) next(_ context.Context, tournamentID string, shard int) (int64, error) {
+	atomic.AddInt64(&m.calls, 1)
+	key := seatCounterKey(tournamentID, shard)
+	m.mu.Lock()
+	c, ok := m.counters[key]
+	if !ok {
+		c = new(int64)
+		m.counters[key] = c
+	}
+	m.mu.Unlock()
+	return atomic.AddInt64(c, 1), nil
+}
+
+func TestGroupForSeat(t *testing.T) {
+	assert.Equal(t, "t1-group-0-1", groupForSeat("t1", 0, 1, models.GroupCapacity))
+	assert.Equal(t, "t1-group-0-1", groupForSeat("t1", 0, models.GroupCapacity, models.GroupCapacity))
+	assert.Equal(t, "t1-group-0-2", groupForSeat("t1", 0, models.GroupCapacity+1, models.GroupCapacity))
+	assert.Equal(t, "t1-group-3-3", groupForSeat("t1", 3, 2*models.GroupCapacity+1, models.GroupCapacity))
+}
+
+func TestAssignGroup_LegacyTournamentUsesOneShard(t *testing.T) {
+	counter := newMemSeatCounter()
+	tournament := &models.Tournament{TournamentID: "2024-01-15"} // SeatShards unset
+
+	groupID, err := assignGroup(context.Background(), counter, tournament, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-15-group-0-1", groupID)
+}
+
+func TestAssignGroup_LeaguesHaveSeparateGroups(t *testing.T) {
+	counter := newMemSeatCounter()
+	tournament := &models.Tournament{TournamentID: "2024-01-15", SeatShards: 8}
+
+	gold, err := assignGroup(context.Background(), counter, tournament, "gold")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-15-gold-group-0-1", gold)
+
+	// The first silver player takes seat 1 of the silver pool, not seat 2 of gold's
+	silver, err := assignGroup(context.Background(), counter, tournament, "silver")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-15-silver-group-0-1", silver)
+	assert.Contains(t, counter.counters, seatCounterKey("2024-01-15-gold", 0))
+}
+
+// TestAssignGroup_LeagueGroupsFillInOrder enters a realistically sized league concurrently:
+// every group but the last must be full, so every group but the last moves players.
+func TestAssignGroup_LeagueGroupsFillInOrder(t *testing.T) {
+	const joins = 1000
+	counter := newMemSeatCounter()
+	tournament := &models.Tournament{TournamentID: "2024-01-15", SeatShards: 8}
+
+	var (
+		mu      sync.Mutex
+		members = make(map[string]int)
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < joins; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			groupID, err := assignGroup(context.Background(), counter, tournament, "gold")
+			assert.NoError(t, err)
+			mu.Lock()
+			members[groupID]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	groups := (joins + models.GroupCapacity - 1) / models.GroupCapacity
+	assert.Len(t, members, groups)
+	for g := 1; g <= groups; g++ {
+		groupID := fmt.Sprintf("2024-01-15-gold-group-0-%d", g)
+		size := members[groupID]
+		if g < groups {
+			assert.Equal(t, models.GroupCapacity, size, "group %s is not full", groupID)
+		} else {
+			assert.Equal(t, joins-(groups-1)*models.GroupCapacity, size)
+		}
+
+		// The winner of a full group is promoted; only the last, partly filled group may be too
+		// small to move anyone
+		_, movement := models.DefaultLeagueLadder.Move("gold", 1, size, 10)
+		if g < groups {
+			assert.Equal(t, models.LeaguePromoted, movement, "group %s", groupID)
+		}
+	}
+}
+
+func TestAssignClanGroup_FillsOneGroupAtATime(t *testing.T) {
+	counter := newMemSeatCounter()
+	// Sharded for players without leagues; clans still share a single counter
+	tournament := &models.Tournament{TournamentID: "2024-01-15", SeatShards: 8}
+
+	var groups []string
+	for i := 0; i <= models.ClanGroupCapacity; i++ {
+		groupID, err := assignClanGroup(context.Background(), counter, tournament)
+		assert.NoError(t, err)
+		groups = append(groups, groupID)
+	}
+	assert.Equal(t, "2024-01-15#clans-group-0-1", groups[0])
+	assert.Equal(t, "2024-01-15#clans-group-0-1", groups[models.ClanGroupCapacity-1])
+	assert.Equal(t, "2024-01-15#clans-group-0-2", groups[models.ClanGroupCapacity])
+}
+
+// TestAssignGroup_ConcurrentJoinsNeverOverfillGroups simulates a midnight rush: thousands of
+// concurrent joins, some of which fail after taking a seat, must never put more than
+// GroupCapacity users in a group and must never be rejected for contention.
+func TestAssignGroup_ConcurrentJoinsNeverOverfillGroups(t *testing.T) {
+	const joins = 20000
+	counter := newMemSeatCounter()
+	tournament := &models.Tournament{TournamentID: "2024-01-15", SeatShards: 8}
+
+	var (
+		mu      sync.Mutex
+		members = make(map[string]int)
+		wg      sync.WaitGroup
+		failed  int64
+	)
+	for i := 0; i < joins; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			groupID, err := assignGroup(context.Background(), counter, tournament, "")
+			if err != nil {
+				atomic.AddInt64(&failed, 1)
+				return
+			}
+			if i%10 == 0 {
+				return // the entry transaction failed after the seat was taken
+			}
+			mu.Lock()
+			members[groupID]++
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Zero(t, failed)
+	assert.Equal(t, int64(joins), counter.calls, "every join allocates exactly one seat")
+
+	total := 0
+	for groupID, n := range members {
+		assert.LessOrEqual(t, n, models.GroupCapacity, "group %s is over capacity", groupID)
+		total += n
+	}
+	assert.Equal(t, joins-joins/10, total)
+
+	// Groups stay densely packed: at most one partially used group per shard beyond the full ones
+	maxGroups := (joins+models.GroupCapacity-1)/models.GroupCapacity + tournament.SeatShards
+	assert.LessOrEqual(t, len(members), maxGroups)
+}
+
+func BenchmarkAssignGroup(b *testing.B) {
+	for _, shards := range []int{1, 8, 32} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			counter := newMemSeatCounter()
+			tournament := &models.Tournament{TournamentID: "bench", SeatShards: shards}
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := assignGroup(context.Background(), counter, tournament, ""); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
@@ -22,4 +22,65 @@ var (
 	ErrUserNotFoundInLeaderboard  = errors.New("user not found in the leaderboard")
 	ErrInvalidLevelIncrease       = errors.New("newLevel must be greater than current level")
 	ErrRequirementsNotMetForEntry = errors.New("you do not meet the requirements to enter the tournament")
+	ErrInvalidCursor              = errors.New("invalid pagination cursor")
+	ErrThrottled                  = errors.New("database is throttling requests, please retry")
+	ErrTransactionConflict        = errors.New("concurrent update conflict, please retry")
+	ErrTournamentCancelled        = errors.New("the tournament was cancelled")
+	ErrInvalidCoinAdjustment      = errors.New("a coin adjustment needs a non-zero amount and a reason")
+	ErrNegativeBalance            = errors.New("adjustment would make the coin balance negative")
+	ErrEntryAlreadyRefunded       = errors.New("the entry fee has already been refunded")
+	ErrRewardExpired              = errors.New("the claim window for this reward has closed")
+	ErrClaimWindowOpen            = errors.New("the claim window is still open")
+	ErrInvalidRewardGrant         = errors.New("a reward grant needs a reason and a non-empty bundle of positive amounts")
+	ErrUnknownBooster             = errors.New("unknown booster")
+	ErrInvalidQuantity            = errors.New("quantity must be between 1 and 99")
+	ErrNotEnoughCoins             = errors.New("not enough coins for this purchase")
+	ErrNotEnoughBoosters          = errors.New("not enough boosters")
+	ErrBoosterLimitReached        = errors.New("purchase would exceed the most boosters of this type you can hold")
+	ErrNoLivesLeft                = errors.New("no lives left")
+	ErrLivesFull                  = errors.New("lives are already full")
+	ErrLevelLocked                = errors.New("level is not unlocked yet")
+	ErrLevelSessionNotFound       = errors.New("level session not found")
+	ErrLevelSessionFinished       = errors.New("level session has already been finished")
+	ErrLevelSessionExpired        = errors.New("level session has expired")
+	ErrLevelSessionScored         = errors.New("level session has already been scored")
+	ErrInvalidLevelResult         = errors.New("moves, score and boosters used must not be negative, and a win needs at least one move")
+	ErrAlreadyCheckedIn           = errors.New("already checked in today")
+	ErrStreakNotLapsed            = errors.New("check-in streak has not lapsed")
+	ErrStreakNotRestorable        = errors.New("check-in streak can no longer be restored")
+	ErrQuestNotFound              = errors.New("quest is not one of today's quests")
+	ErrQuestNotCompleted          = errors.New("quest is not completed yet")
+	ErrQuestAlreadyClaimed        = errors.New("quest reward has already been claimed")
+	ErrNoActiveSeason             = errors.New("no season is running")
+	ErrInvalidSeasonTrack         = errors.New("track must be free or premium")
+	ErrSeasonTierNotFound         = errors.New("season tier not found")
+	ErrSeasonTierLocked           = errors.New("not enough season XP for this tier")
+	ErrSeasonTierClaimed          = errors.New("season tier reward has already been claimed")
+	ErrSeasonPremiumRequired      = errors.New("premium season pass required")
+	ErrSeasonPremiumOwned         = errors.New("premium season pass already owned")
+	ErrLeagueResultApplied        = errors.New("league result already applied")
+	ErrClanNotFound               = errors.New("clan not found")
+	ErrClanFull                   = errors.New("the clan is full")
+	ErrAlreadyInClan              = errors.New("you are already in a clan")
+	ErrNotInClan                  = errors.New("you are not a member of this clan")
+	ErrClanMemberNotFound         = errors.New("clan member not found")
+	ErrClanPermissionDenied       = errors.New("your clan role does not allow this")
+	ErrClanLeaderMustTransfer     = errors.New("the clan leader must hand over leadership before leaving")
+	ErrInvalidClanName            = errors.New("clan name must be 3 to 24 characters")
+	ErrInvalidClanRole            = errors.New("role must be leader, officer or member")
+	ErrClanAlreadyEntered         = errors.New("clan has already entered this tournament")
+	ErrClanNotEntered             = errors.New("clan has not entered this tournament")
+	ErrCannotFriendSelf           = errors.New("you cannot befriend or block yourself")
+	ErrFriendshipExists           = errors.New("you are already friends or a request is pending")
+	ErrFriendRequestNotFound      = errors.New("friend request not found")
+	ErrFriendNotFound             = errors.New("friend not found")
+	ErrFriendListFull             = errors.New("friend list is full")
+	ErrUserBlocked                = errors.New("you have blocked this user")
+	ErrFriendRequestNotAllowed    = errors.New("this user is not accepting friend requests from you")
 )
+
+// IsRetryable reports whether err (or an error it wraps) means the request was rejected
+// because of load or contention and the client may safely retry later.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrThrottled) || errors.Is(err, ErrTransactionConflict)
+}
@@ -2,14 +2,22 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"good_blast/api"
 	"good_blast/api/handlers"
+	"good_blast/config"
 	"good_blast/database"
+	"good_blast/models"
 	"good_blast/services"
+	"good_blast/services/cache"
 	redisclient "good_blast/services/redis_client" // give it a distinct alias
 
 	"github.com/gin-gonic/gin"
@@ -22,34 +30,142 @@ type APIResponse struct {
 	Error   string      `json:"error,omitempty"`
 }
 
-func initializeApp() (*handlers.UserHandler, *handlers.TournamentHandler, *handlers.LeaderboardHandler, *gin.Engine, error) {
+// application holds everything main needs to serve requests and shut down cleanly.
+type application struct {
+	router *gin.Engine
+	cache  cache.Cache
+}
+
+// newCache builds the configured cache backend. An unreachable Redis is not fatal:
+// the client reconnects lazily and leaderboards are served uncached until it is back.
+func newCache(cfg *config.Config) cache.Cache {
+	switch cfg.Cache.Backend {
+	case config.CacheBackendMemory:
+		return cache.NewLRU(cfg.Cache.MaxEntries)
+	case config.CacheBackendNone:
+		return cache.NewNoop()
+	default:
+		client, err := redisclient.NewClient(cfg.Redis)
+		if err != nil {
+			log.Printf("newCache: %v; continuing without cache until Redis is reachable", err)
+		}
+		return cache.NewRedis(client)
+	}
+}
+
+func initializeApp(cfg *config.Config) (*application, error) {
 	log.Println("initializeApp: Starting application initialization...")
 
-	if err := database.InitDynamoDB(); err != nil {
+	if err := database.InitDynamoDB(cfg.DynamoDB); err != nil {
 		log.Printf("initializeApp: failed to initialize DynamoDB: %v", err)
-		return nil, nil, nil, nil, fmt.Errorf("failed to initialize DynamoDB: %w", err)
+		return nil, fmt.Errorf("failed to initialize DynamoDB: %w", err)
 	}
 	log.Println("initializeApp: DynamoDB initialized successfully")
 
 	db := &database.DynamoDB{}
 	log.Println("initializeApp: DynamoDB struct created")
 
-	// Initialize Redis
-	if err := redisclient.InitRedis(); err != nil {
-		log.Fatalf("initializeApp: failed to initialize Redis: %v", err)
+	levelCatalog, err := cfg.LoadLevelCatalog()
+	if err != nil {
+		log.Printf("initializeApp: %v", err)
+		return nil, err
 	}
+	log.Printf("initializeApp: level catalog %q loaded", levelCatalog.Version)
 
-	log.Println("initializeApp: Redis initialized successfully")
+	// Initialize the leaderboard cache (Redis by default)
+	leaderboardCache := newCache(cfg)
+	log.Printf("initializeApp: %s cache initialized", cfg.Cache.Backend)
 
 	userService := services.NewUserService(db)
+	userService.Catalog = levelCatalog
 	log.Println("initializeApp: UserService initialized")
 
 	tournamentService := services.NewTournamentService(db)
+	tournamentService.SeatShards = cfg.Tournament.SeatShards
+	tournamentService.ClaimWindow = cfg.Tournament.ClaimWindow.D()
+	tournamentService.Rewards = cfg.Tournament.Rewards
+	tournamentService.Leagues = cfg.Tournament.Leagues
 	log.Println("initializeApp: TournamentService initialized")
 
-	leaderboardService := services.NewLeaderboardService(db)
+	inventoryService := services.NewInventoryService(db)
+	inventoryService.Catalog = cfg.Inventory.Boosters
+	inventoryService.Lives = models.LivesPolicy{Max: cfg.Inventory.MaxLives, RegenInterval: cfg.Inventory.LifeRegenInterval.D()}
+	inventoryService.RefillCost = cfg.Inventory.LivesRefillCost
+	log.Println("initializeApp: InventoryService initialized")
+
+	levelService := services.NewLevelService(db, userService, tournamentService, inventoryService)
+	levelService.MaxSessionDuration = cfg.Levels.MaxSessionDuration.D()
+	levelService.Catalog = levelCatalog
+	log.Println("initializeApp: LevelService initialized")
+
+	checkInService := services.NewCheckInService(db)
+	checkInService.Calendar = cfg.CheckIn.Calendar
+	checkInService.RestoreCost = cfg.CheckIn.RestoreCost
+	checkInService.MaxRestoreDays = cfg.CheckIn.MaxRestoreDays
+	log.Println("initializeApp: CheckInService initialized")
+
+	questService := services.NewQuestService(db)
+	questService.Pool = cfg.Quests.Pool
+	questService.PerDay = cfg.Quests.PerDay
+	log.Println("initializeApp: QuestService initialized")
+
+	achievementService := services.NewAchievementService(db)
+	log.Println("initializeApp: AchievementService initialized")
+
+	seasonService := services.NewSeasonService(db)
+	seasonService.Seasons = cfg.SeasonPass.Seasons
+	log.Println("initializeApp: SeasonService initialized")
+
+	leagueService := services.NewLeagueService(db)
+	leagueService.Leagues = cfg.Tournament.Leagues
+	log.Println("initializeApp: LeagueService initialized")
+
+	clanService := services.NewClanService(db)
+	clanService.MaxMembers = cfg.Clans.MaxMembers
+	log.Println("initializeApp: ClanService initialized")
+
+	clanTournamentService := services.NewClanTournamentService(db)
+	clanTournamentService.Rewards = cfg.Clans.TournamentRewards
+	log.Println("initializeApp: ClanTournamentService initialized")
+
+	friendService := services.NewFriendService(db)
+	friendService.MaxFriends = cfg.Friends.MaxFriends
+	log.Println("initializeApp: FriendService initialized")
+
+	leaderboardService := services.NewLeaderboardService(db, leaderboardCache)
+	leaderboardService.TTLs = services.LeaderboardCacheTTLs{
+		Global:     cfg.Cache.GlobalLeaderboardTTL.D(),
+		Country:    cfg.Cache.CountryLeaderboardTTL.D(),
+		Tournament: cfg.Cache.TournamentLeaderboardTTL.D(),
+	}
+	// Validated by config.Load, so parse errors cannot occur here
+	leaderboardService.Policies.Global, _ = services.ParseCachePolicy(cfg.Cache.GlobalLeaderboardPolicy)
+	leaderboardService.Policies.Country, _ = services.ParseCachePolicy(cfg.Cache.CountryLeaderboardPolicy)
+	leaderboardService.Policies.Tournament, _ = services.ParseCachePolicy(cfg.Cache.TournamentLeaderboardPolicy)
 	log.Println("initializeApp: LeaderboardService initialized")
 
+	// Writes that change rankings keep the leaderboard cache fresh
+	userService.Leaderboards = leaderboardService
+	tournamentService.Leaderboards = leaderboardService
+
+	// Progress and tournament play count towards daily quests
+	userService.Quests = questService
+	tournamentService.Quests = questService
+
+	// Achievements are evaluated when progress is made and tournament rewards are paid
+	userService.Achievements = achievementService
+	tournamentService.Achievements = achievementService
+
+	// Cleared levels and rewarded tournament ranks earn season pass XP
+	userService.Seasons = seasonService
+	tournamentService.Seasons = seasonService
+
+	// Level changes are counted in the clan leaderboard totals
+	userService.Clans = clanService
+
+	// Tournament scores count towards the player's clan's tournament entry
+	tournamentService.Clans = clanTournamentService
+
 	userHandler := handlers.NewUserHandler(userService)
 	log.Println("initializeApp: UserHandler initialized")
 
@@ -59,6 +175,36 @@ func initializeApp() (*handlers.UserHandler, *handlers.TournamentHandler, *handl
 	leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardService)
 	log.Println("initializeApp: LeaderboardHandler initialized")
 
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	log.Println("initializeApp: InventoryHandler initialized")
+
+	levelHandler := handlers.NewLevelHandler(levelService)
+	log.Println("initializeApp: LevelHandler initialized")
+
+	checkInHandler := handlers.NewCheckInHandler(checkInService)
+	log.Println("initializeApp: CheckInHandler initialized")
+
+	questHandler := handlers.NewQuestHandler(questService)
+	log.Println("initializeApp: QuestHandler initialized")
+
+	achievementHandler := handlers.NewAchievementHandler(achievementService)
+	log.Println("initializeApp: AchievementHandler initialized")
+
+	seasonHandler := handlers.NewSeasonHandler(seasonService)
+	log.Println("initializeApp: SeasonHandler initialized")
+
+	leagueHandler := handlers.NewLeagueHandler(leagueService)
+	log.Println("initializeApp: LeagueHandler initialized")
+
+	clanHandler := handlers.NewClanHandler(clanService)
+	log.Println("initializeApp: ClanHandler initialized")
+
+	clanTournamentHandler := handlers.NewClanTournamentHandler(clanTournamentService)
+	log.Println("initializeApp: ClanTournamentHandler initialized")
+
+	friendHandler := handlers.NewFriendHandler(friendService)
+	log.Println("initializeApp: FriendHandler initialized")
+
 	router := gin.Default()
 	log.Println("initializeApp: Gin router created")
 
@@ -77,27 +223,87 @@ func initializeApp() (*handlers.UserHandler, *handlers.TournamentHandler, *handl
 	log.Println("initializeApp: CORS middleware set")
 
 	// Setup routes
-	api.SetupRoutes(router, userHandler, tournamentHandler, leaderboardHandler)
+	api.SetupRoutes(router, userHandler, tournamentHandler, leaderboardHandler, inventoryHandler, levelHandler, checkInHandler, questHandler, achievementHandler, seasonHandler, leagueHandler, clanHandler, clanTournamentHandler, friendHandler)
 	log.Println("initializeApp: Routes set up successfully")
 
-	return userHandler, tournamentHandler, leaderboardHandler, router, nil
+	return &application{
+		router: router,
+		cache:  leaderboardCache,
+	}, nil
 }
 
 func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a JSON config file (env vars override it)")
+	dumpConfig := flag.Bool("dump-config", false, "print the effective configuration and exit")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("main: Failed to load configuration: %v", err)
+	}
+
+	if *dumpConfig {
+		out, err := cfg.Dump()
+		if err != nil {
+			log.Fatalf("main: Failed to dump configuration: %v", err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 	log.Println("main: Initializing application...")
-	_, _, _, router, err := initializeApp()
+	app, err := initializeApp(cfg)
 	if err != nil {
 		log.Fatalf("main: Failed to initialize application: %v", err)
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	port := cfg.Server.Port
+
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           app.router,
+		ReadTimeout:       cfg.Server.ReadTimeout.D(),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout.D(),
+		WriteTimeout:      cfg.Server.WriteTimeout.D(),
+		IdleTimeout:       cfg.Server.IdleTimeout.D(),
 	}
-	log.Printf("main: Starting server on port %s...", port)
+	shutdownTimeout := cfg.Server.ShutdownTimeout.D()
+
+	// Stop on SIGINT (local Ctrl+C) and SIGTERM (Fly.io deploys/stops)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("main: Failed to run server: %v", err)
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("main: Starting server on port %s...", port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			log.Fatalf("main: Failed to run server: %v", err)
+		}
+	case <-ctx.Done():
+		log.Println("main: Shutdown signal received, draining in-flight requests...")
 	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// 1. Stop accepting new connections and wait for in-flight requests (e.g. entry transactions)
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("main: HTTP server shutdown error: %v", err)
+	}
+
+	// 2. Release external connections
+	if err := app.cache.Close(); err != nil {
+		log.Printf("main: failed to close cache: %v", err)
+	}
+
+	log.Println("main: Server stopped")
 }
@@ -0,0 +1,117 @@
+package models
+
+// Achievement metrics: the lifetime figure an achievement is measured on.
+const (
+	AchievementMetricLevel          = "level"           // level reached
+	AchievementMetricTournamentWins = "tournament_wins" // tournament rewards paid for rank 1
+	AchievementMetricPodiums        = "podium_finishes" // tournament rewards paid for ranks 1 to 3
+	AchievementMetricCoinsEarned    = "coins_earned"    // coins paid by any reward; charges don't count
+)
+
+// AchievementStats are the lifetime counters achievements are measured on, besides level. They
+// are stored as top-level attributes of the user item and only ever grow.
+type AchievementStats struct {
+	TournamentWins int `json:"tournamentWins,omitempty" dynamodbav:"tournamentWins,omitempty"`
+	PodiumFinishes int `json:"podiumFinishes,omitempty" dynamodbav:"podiumFinishes,omitempty"`
+	CoinsEarned    int `json:"coinsEarned,omitempty" dynamodbav:"coinsEarned,omitempty"`
+}
+
+// IsEmpty reports whether the stats count nothing.
+func (s AchievementStats) IsEmpty() bool {
+	return s == AchievementStats{}
+}
+
+// AchievementProgress is everything achievements are measured on for one user.
+type AchievementProgress struct {
+	Level int
+	AchievementStats
+}
+
+// ProgressOf returns the achievement progress of a user.
+func ProgressOf(u User) AchievementProgress {
+	return AchievementProgress{Level: u.Level, AchievementStats: u.AchievementStats}
+}
+
+// Value returns the figure for a metric; 0 for an unknown one.
+func (p AchievementProgress) Value(metric string) int {
+	switch metric {
+	case AchievementMetricLevel:
+		return p.Level
+	case AchievementMetricTournamentWins:
+		return p.TournamentWins
+	case AchievementMetricPodiums:
+		return p.PodiumFinishes
+	case AchievementMetricCoinsEarned:
+		return p.CoinsEarned
+	}
+	return 0
+}
+
+// AchievementDefinition is one achievement users can unlock.
+type AchievementDefinition struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Metric      string `json:"metric"` // One of the AchievementMetric* constants
+	Target      int    `json:"target"` // Value of the metric that unlocks it
+}
+
+// AchievementList is the set of achievements users can unlock, in display order.
+type AchievementList []AchievementDefinition
+
+// DefaultAchievements are the achievements of the game.
+var DefaultAchievements = AchievementList{
+	{ID: "first_tournament_win", Name: "Champion", Description: "Win a tournament group", Metric: AchievementMetricTournamentWins, Target: 1},
+	{ID: "podium_10", Name: "Podium Regular", Description: "Finish in the top 3 of a tournament group 10 times", Metric: AchievementMetricPodiums, Target: 10},
+	{ID: "level_100", Name: "Centurion", Description: "Reach level 100", Metric: AchievementMetricLevel, Target: 100},
+	{ID: "level_500", Name: "Blast Master", Description: "Reach level 500", Metric: AchievementMetricLevel, Target: 500},
+	{ID: "coins_10000", Name: "Saver", Description: "Earn 10,000 coins", Metric: AchievementMetricCoinsEarned, Target: 10000},
+	{ID: "coins_100000", Name: "Tycoon", Description: "Earn 100,000 coins", Metric: AchievementMetricCoinsEarned, Target: 100000},
+}
+
+// Reached returns the achievements whose target p meets.
+func (l AchievementList) Reached(p AchievementProgress) []AchievementDefinition {
+	var out []AchievementDefinition
+	for _, a := range l {
+		if p.Value(a.Metric) >= a.Target {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// Crossed returns the achievements whose target was met by going from before to after.
+func (l AchievementList) Crossed(before, after AchievementProgress) []AchievementDefinition {
+	var out []AchievementDefinition
+	for _, a := range l {
+		if before.Value(a.Metric) < a.Target && after.Value(a.Metric) >= a.Target {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// UnlockedAchievement records that a user unlocked an achievement.
+type UnlockedAchievement struct {
+	UserID        string `json:"userId" dynamodbav:"userId"`               // Partition Key
+	AchievementID string `json:"achievementId" dynamodbav:"achievementId"` // Sort Key
+	UnlockedAt    string `json:"unlockedAt" dynamodbav:"unlockedAt"`       // RFC3339 timestamp
+}
+
+// Achievement is an achievement as shown to a user.
+type Achievement struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Progress    int    `json:"progress"` // Capped at Target
+	Target      int    `json:"target"`
+	Unlocked    bool   `json:"unlocked"`
+	UnlockedAt  string `json:"unlockedAt,omitempty"`
+}
+
+// UserAchievements lists every achievement with a user's progress towards it.
+type UserAchievements struct {
+	UserID       string        `json:"userId"`
+	Unlocked     int           `json:"unlocked"` // How many are unlocked
+	Achievements []Achievement `json:"achievements"`
+}
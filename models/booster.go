@@ -0,0 +1,69 @@
+package models
+
+import "fmt"
+
+// MaxBoosterPurchase is the most boosters of one type that can be bought in a single purchase.
+const MaxBoosterPurchase = 99
+
+// BoosterItem is a booster offered in the shop.
+type BoosterItem struct {
+	ID       string `json:"id"`                 // Key in the user's boosters map, e.g. "hammer"
+	Name     string `json:"name"`               // Display name
+	Price    int    `json:"price"`              // Coins per booster
+	MaxOwned int    `json:"maxOwned,omitempty"` // Most a user may hold through purchases; 0 means no limit
+}
+
+// BoosterCatalog lists the boosters that can be bought.
+type BoosterCatalog []BoosterItem
+
+// DefaultBoosterCatalog is the shop used when operators configure none.
+var DefaultBoosterCatalog = BoosterCatalog{
+	{ID: "hammer", Name: "Hammer", Price: 300, MaxOwned: 99},
+	{ID: "rocket", Name: "Rocket", Price: 250, MaxOwned: 99},
+	{ID: "color_bomb", Name: "Color Bomb", Price: 500, MaxOwned: 99},
+}
+
+// Find returns the catalog entry for a booster ID.
+func (c BoosterCatalog) Find(id string) (BoosterItem, bool) {
+	for _, item := range c {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return BoosterItem{}, false
+}
+
+// Validate checks that every booster has a unique ID, a name and a positive price.
+func (c BoosterCatalog) Validate() error {
+	seen := make(map[string]bool, len(c))
+	for _, item := range c {
+		if item.ID == "" || item.Name == "" {
+			return fmt.Errorf("every booster needs an id and a name")
+		}
+		if seen[item.ID] {
+			return fmt.Errorf("booster %q is listed twice", item.ID)
+		}
+		seen[item.ID] = true
+		if item.Price <= 0 || item.MaxOwned < 0 {
+			return fmt.Errorf("booster %q needs a positive price and a non-negative maxOwned", item.ID)
+		}
+	}
+	return nil
+}
+
+// BoosterStack is how many boosters of one type a user holds.
+type BoosterStack struct {
+	BoosterID string `json:"boosterId"`
+	Name      string `json:"name,omitempty"`
+	Count     int    `json:"count"`
+	Price     int    `json:"price,omitempty"` // Absent when the booster is no longer sold
+}
+
+// BoosterPurchase is the outcome of buying boosters.
+type BoosterPurchase struct {
+	BoosterID string `json:"boosterId"`
+	Quantity  int    `json:"quantity"`
+	Cost      int    `json:"cost"`  // Coins spent
+	Coins     int    `json:"coins"` // Coin balance after the purchase
+	Count     int    `json:"count"` // Boosters of this type held after the purchase
+}
@@ -0,0 +1,81 @@
+package models
+
+import "fmt"
+
+// CheckInState is a user's daily check-in streak. It is stored as top-level attributes of the
+// user item, so a check-in and its reward are applied with a single update.
+type CheckInState struct {
+	CheckInStreak int    `json:"checkInStreak,omitempty" dynamodbav:"checkInStreak,omitempty"` // Consecutive days checked in, ending on LastCheckIn
+	LastCheckIn   string `json:"lastCheckIn,omitempty" dynamodbav:"lastCheckIn,omitempty"`     // UTC day of the latest check-in, formatted like tournament IDs
+}
+
+// CheckInCalendar is the reward for each day of a check-in streak. Streaks longer than the
+// calendar start it over.
+type CheckInCalendar []RewardBundle
+
+// DefaultCheckInCalendar is the 7-day calendar used when operators configure none. It grants
+// no boosters, so it fits any booster catalog.
+var DefaultCheckInCalendar = CheckInCalendar{
+	{Coins: 100},
+	{Coins: 150},
+	{Coins: 200},
+	{Coins: 250},
+	{Coins: 300},
+	{Coins: 400},
+	{Coins: 500, Lives: 1},
+}
+
+// Day returns the 1-based calendar day a streak of the given length is on.
+func (c CheckInCalendar) Day(streak int) int {
+	if len(c) == 0 || streak < 1 {
+		return 0
+	}
+	return (streak-1)%len(c) + 1
+}
+
+// ForStreak returns the reward for the check-in that brings a streak to the given length.
+func (c CheckInCalendar) ForStreak(streak int) RewardBundle {
+	if day := c.Day(streak); day > 0 {
+		return c[day-1]
+	}
+	return RewardBundle{}
+}
+
+// Validate checks that the calendar has between 1 and 31 days and every day grants something.
+func (c CheckInCalendar) Validate() error {
+	if len(c) == 0 || len(c) > 31 {
+		return fmt.Errorf("must have between 1 and 31 days")
+	}
+	for i, b := range c {
+		if b.IsEmpty() {
+			return fmt.Errorf("day %d grants nothing", i+1)
+		}
+		if err := b.Validate(); err != nil {
+			return fmt.Errorf("day %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// CheckInStatus describes a user's streak as of today.
+type CheckInStatus struct {
+	Streak         int             `json:"streak"`                // Current streak; 0 once it has lapsed
+	LastCheckIn    string          `json:"lastCheckIn,omitempty"` // UTC day of the latest check-in
+	CheckedInToday bool            `json:"checkedInToday"`
+	NextDay        int             `json:"nextDay"`    // Calendar day the next check-in is on
+	NextReward     RewardBundle    `json:"nextReward"` // What the next check-in pays
+	Calendar       CheckInCalendar `json:"calendar"`
+
+	// A lapsed streak that can still be restored
+	LapsedStreak int `json:"lapsedStreak,omitempty"` // Streak that was lost
+	MissedDays   int `json:"missedDays,omitempty"`   // Days missed since the latest check-in
+	RestoreCost  int `json:"restoreCost,omitempty"`  // Coins to restore it
+}
+
+// CheckInResult is the outcome of checking in.
+type CheckInResult struct {
+	Streak int          `json:"streak"`
+	Day    int          `json:"day"` // Calendar day that was paid
+	Reward RewardBundle `json:"reward"`
+	Coins  int          `json:"coins"` // Coin balance afterwards
+}
@@ -0,0 +1,86 @@
+package models
+
+import "strings"
+
+// Clan roles, from most to least privileged.
+const (
+	ClanRoleLeader  = "leader"
+	ClanRoleOfficer = "officer"
+	ClanRoleMember  = "member"
+)
+
+// DefaultClanMaxMembers is how many members a clan can have, leader included.
+const DefaultClanMaxMembers = 50
+
+// Clan name length limits, counted in characters after trimming spaces.
+const (
+	MinClanNameLength = 3
+	MaxClanNameLength = 24
+)
+
+// ClanState is the clan a user belongs to. It is stored as a top-level attribute of the user
+// item, so a user can only be in one clan at a time.
+type ClanState struct {
+	ClanID string `json:"clanId,omitempty" dynamodbav:"clanId,omitempty"` // Empty when the user is in no clan
+}
+
+// Clan represents a clan in the Clans table.
+type Clan struct {
+	ClanID      string `json:"clanId" dynamodbav:"clanId"`                       // Partition Key
+	Name        string `json:"name" dynamodbav:"name"`                           // Display name
+	NameKey     string `json:"-" dynamodbav:"nameKey"`                           // Lower-case name, sort key of the name indexes
+	Country     string `json:"country,omitempty" dynamodbav:"country,omitempty"` // Optional ISO country code
+	LeaderID    string `json:"leaderId" dynamodbav:"leaderId"`                   // User ID of the leader
+	MemberCount int    `json:"memberCount" dynamodbav:"memberCount"`             // Members, leader included
+	TotalLevel  int    `json:"totalLevel" dynamodbav:"totalLevel"`               // Sum of the members' levels; the clan leaderboard sorts by it
+	ClanPK      string `json:"-" dynamodbav:"clanPK"`                            // Constant partition key of ClanNameIndex and ClanLevelIndex
+	CreatedAt   string `json:"createdAt" dynamodbav:"createdAt"`                 // RFC3339
+}
+
+// ClanMember is a user's membership in a clan, stored in the ClanMembers table.
+type ClanMember struct {
+	ClanID   string `json:"clanId" dynamodbav:"clanId"`     // Partition Key
+	UserID   string `json:"userId" dynamodbav:"userId"`     // Sort Key
+	Username string `json:"username" dynamodbav:"username"` // Username when the user joined
+	Role     string `json:"role" dynamodbav:"role"`         // leader, officer or member
+	Level    int    `json:"level" dynamodbav:"level"`       // Level counted in the clan's TotalLevel
+	JoinedAt string `json:"joinedAt" dynamodbav:"joinedAt"` // RFC3339
+}
+
+// ClanDetails is a clan with its members, as shown to players.
+type ClanDetails struct {
+	Clan
+	MaxMembers int          `json:"maxMembers"`
+	Members    []ClanMember `json:"members"` // Highest level first
+}
+
+// ClanGlobalPK is the partition key every clan shares in ClanNameIndex and ClanLevelIndex.
+const ClanGlobalPK = "CLAN"
+
+// ClanNameKey returns the key clan names are searched by: trimmed and lower-case.
+func ClanNameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ValidClanRole reports whether role is one of the clan roles.
+func ValidClanRole(role string) bool {
+	return role == ClanRoleLeader || role == ClanRoleOfficer || role == ClanRoleMember
+}
+
+// clanRoleRank orders the roles; higher ranks may manage lower ones.
+func clanRoleRank(role string) int {
+	switch role {
+	case ClanRoleLeader:
+		return 3
+	case ClanRoleOfficer:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// CanKick reports whether m may remove target from the clan: leaders can remove anyone else,
+// officers only members.
+func (m ClanMember) CanKick(target ClanMember) bool {
+	return m.Role != ClanRoleMember && m.UserID != target.UserID && clanRoleRank(m.Role) > clanRoleRank(target.Role)
+}
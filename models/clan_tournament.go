@@ -0,0 +1,124 @@
+package models
+
+import "fmt"
+
+// ClanGroupCapacity is the maximum number of clans placed in one clan tournament group.
+const ClanGroupCapacity = 10
+
+// ClanTournamentEntry is a clan's entry in a daily tournament, stored in the
+// ClanTournamentEntries table. Its score is the sum of its members' contributions.
+type ClanTournamentEntry struct {
+	TournamentID string `json:"tournamentId" dynamodbav:"tournamentId"` // Partition Key
+	ClanID       string `json:"clanId" dynamodbav:"clanId"`             // Sort Key
+	ClanName     string `json:"clanName" dynamodbav:"clanName"`         // Name when the clan entered
+	GroupID      string `json:"groupId" dynamodbav:"groupId"`           // Partition key of ClanGroupScoreIndex
+	Score        int    `json:"score" dynamodbav:"score"`               // Sort key of ClanGroupScoreIndex
+	EnteredBy    string `json:"enteredBy" dynamodbav:"enteredBy"`       // Leader or officer who entered the clan
+	EnteredAt    string `json:"enteredAt" dynamodbav:"enteredAt"`       // RFC3339
+}
+
+// ClanContribution is the score one member added to their clan's tournament entry, stored in
+// the ClanContributions table.
+type ClanContribution struct {
+	EntryKey     string `json:"-" dynamodbav:"entryKey"`                                // Partition Key; see ClanEntryKey
+	UserID       string `json:"userId" dynamodbav:"userId"`                             // Sort Key
+	TournamentID string `json:"tournamentId" dynamodbav:"tournamentId"`                 // Tournament the score was added in
+	ClanID       string `json:"clanId" dynamodbav:"clanId"`                             // Clan the score was added to
+	Score        int    `json:"score" dynamodbav:"score"`                               // Tournament score added while the clan was entered
+	RewardPaid   bool   `json:"rewardPaid,omitempty" dynamodbav:"rewardPaid,omitempty"` // The clan reward for this contribution has been paid
+}
+
+// ClanEntryKey is the partition key of a clan's contributions to a tournament.
+func ClanEntryKey(tournamentID, clanID string) string {
+	return tournamentID + "#" + clanID
+}
+
+// ClanRewardTier grants Bundle to every member who contributed at least MinContribution to a
+// clan finishing from MinRank to MaxRank (1-based, inclusive) in its group.
+type ClanRewardTier struct {
+	MinRank         int          `json:"minRank"`
+	MaxRank         int          `json:"maxRank"`
+	MinContribution int          `json:"minContribution"`
+	Bundle          RewardBundle `json:"bundle"`
+}
+
+// ClanRewardTable maps a clan's rank within its group and a member's contribution to the
+// member's reward bundle.
+type ClanRewardTable []ClanRewardTier
+
+// DefaultClanRewardTable pays the members of the top 3 clans of every group, more to those who
+// contributed more.
+var DefaultClanRewardTable = ClanRewardTable{
+	{MinRank: 1, MaxRank: 1, MinContribution: 1, Bundle: RewardBundle{Coins: 1000}},
+	{MinRank: 1, MaxRank: 1, MinContribution: 20, Bundle: RewardBundle{Coins: 3000}},
+	{MinRank: 1, MaxRank: 1, MinContribution: 50, Bundle: RewardBundle{Coins: 5000}},
+	{MinRank: 2, MaxRank: 2, MinContribution: 1, Bundle: RewardBundle{Coins: 600}},
+	{MinRank: 2, MaxRank: 2, MinContribution: 20, Bundle: RewardBundle{Coins: 1800}},
+	{MinRank: 2, MaxRank: 2, MinContribution: 50, Bundle: RewardBundle{Coins: 3000}},
+	{MinRank: 3, MaxRank: 3, MinContribution: 1, Bundle: RewardBundle{Coins: 400}},
+	{MinRank: 3, MaxRank: 3, MinContribution: 20, Bundle: RewardBundle{Coins: 1200}},
+	{MinRank: 3, MaxRank: 3, MinContribution: 50, Bundle: RewardBundle{Coins: 2000}},
+}
+
+// For returns the bundle earned by a contribution to a clan of a 1-based rank: that of the
+// highest contribution threshold reached among the tiers covering the rank. It is the zero
+// bundle when no threshold is reached.
+func (t ClanRewardTable) For(rank, contribution int) RewardBundle {
+	var best *ClanRewardTier
+	for i, tier := range t {
+		if rank < tier.MinRank || rank > tier.MaxRank || contribution < tier.MinContribution {
+			continue
+		}
+		if best == nil || tier.MinContribution > best.MinContribution {
+			best = &t[i]
+		}
+	}
+	if best == nil {
+		return RewardBundle{}
+	}
+	return best.Bundle
+}
+
+// Validate checks that tiers cover valid rank ranges within a clan group, need a positive
+// contribution and grant something, and that no two tiers share a threshold for the same rank.
+func (t ClanRewardTable) Validate() error {
+	for i, tier := range t {
+		if tier.MinRank < 1 || tier.MaxRank < tier.MinRank || tier.MaxRank > ClanGroupCapacity {
+			return fmt.Errorf("ranks %d-%d must satisfy 1 <= minRank <= maxRank <= %d", tier.MinRank, tier.MaxRank, ClanGroupCapacity)
+		}
+		if tier.MinContribution < 1 {
+			return fmt.Errorf("ranks %d-%d: minContribution must be at least 1", tier.MinRank, tier.MaxRank)
+		}
+		if tier.Bundle.IsEmpty() {
+			return fmt.Errorf("ranks %d-%d from contribution %d grant nothing", tier.MinRank, tier.MaxRank, tier.MinContribution)
+		}
+		if err := tier.Bundle.Validate(); err != nil {
+			return fmt.Errorf("ranks %d-%d from contribution %d: %v", tier.MinRank, tier.MaxRank, tier.MinContribution, err)
+		}
+		for _, prev := range t[:i] {
+			if prev.MinContribution == tier.MinContribution && tier.MinRank <= prev.MaxRank && prev.MinRank <= tier.MaxRank {
+				return fmt.Errorf("ranks %d-%d from contribution %d overlap another tier", tier.MinRank, tier.MaxRank, tier.MinContribution)
+			}
+		}
+	}
+	return nil
+}
+
+// ClanTournamentStanding is a clan's view of its tournament entry.
+type ClanTournamentStanding struct {
+	Entry         ClanTournamentEntry   `json:"entry"`
+	Rank          int                   `json:"rank"`          // 1-based rank within the group; 0 while the clan has no standing yet
+	Group         []ClanTournamentEntry `json:"group"`         // Clans of the group, highest score first
+	Contributions []ClanContribution    `json:"contributions"` // Members' contributions, highest first
+}
+
+// ClanRewardReport summarises the clan rewards paid for a tournament.
+type ClanRewardReport struct {
+	TournamentID  string `json:"tournamentId"`
+	Clans         int    `json:"clans"`         // Clan entries examined
+	Contributions int    `json:"contributions"` // Member contributions examined
+	Paid          int    `json:"paid"`          // Rewards paid by this run
+	CoinsPaid     int    `json:"coinsPaid"`     // Total coins paid by this run
+	AlreadyPaid   int    `json:"alreadyPaid"`   // Rewards paid by an earlier, interrupted run
+	NoReward      int    `json:"noReward"`      // Contributions whose clan rank or size earns nothing
+}
@@ -8,4 +8,7 @@ type TournamentEntry struct {
 	GroupID       string `json:"groupId" dynamodbav:"groupId"`                         // Group identifier for partitioning users (max 35)
 	ClaimedReward bool   `json:"claimedReward,omitempty" dynamodbav:"claimedReward"`   // Indicates if reward has been claimed
 	ClaimedAt     string `json:"claimedAt,omitempty" dynamodbav:"claimedAt,omitempty"` // Timestamp of when reward was claimed
+	Refunded      bool   `json:"refunded,omitempty" dynamodbav:"refunded,omitempty"`   // Entry fee returned because the tournament was cancelled
+	Expired       bool   `json:"expired,omitempty" dynamodbav:"expired,omitempty"`     // Reward left unclaimed past the claim deadline; can no longer be claimed
+	League        string `json:"league,omitempty" dynamodbav:"league,omitempty"`       // League the user played in; groups only hold one league
 }
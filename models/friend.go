@@ -0,0 +1,72 @@
+package models
+
+// Friendship statuses, as seen by the user owning the friendship item.
+const (
+	FriendStatusRequested = "requested" // the user asked the other user to be friends
+	FriendStatusIncoming  = "incoming"  // the other user asked the user to be friends
+	FriendStatusAccepted  = "accepted"  // the users are friends
+	FriendStatusBlocked   = "blocked"   // the user blocked the other user
+)
+
+// DefaultMaxFriends is how many accepted friends a user can have.
+const DefaultMaxFriends = 100
+
+// FriendState counts a user's accepted friends. It is stored as a top-level attribute of the user
+// item, so the friend limit can be checked in the transaction that accepts a request.
+type FriendState struct {
+	FriendCount int `json:"friendCount,omitempty" dynamodbav:"friendCount,omitempty"`
+}
+
+// Friendship is one side of a relation between two users, stored in the Friendships table. Every
+// relation but a block has an item on both sides: a request is "requested" for the sender and
+// "incoming" for the recipient, and a friendship is "accepted" for both. A block only has the
+// blocking user's item.
+type Friendship struct {
+	UserID    string `json:"userId" dynamodbav:"userId"`       // Partition Key
+	FriendID  string `json:"friendId" dynamodbav:"friendId"`   // Sort Key
+	Username  string `json:"username" dynamodbav:"username"`   // Friend's username when the relation was created
+	Status    string `json:"status" dynamodbav:"status"`       // requested, incoming, accepted or blocked
+	CreatedAt string `json:"createdAt" dynamodbav:"createdAt"` // RFC3339
+}
+
+// Counterpart returns the status of the other user's item for a relation of this status, or ""
+// for a block, which has no counterpart.
+func (f Friendship) Counterpart() string {
+	switch f.Status {
+	case FriendStatusRequested:
+		return FriendStatusIncoming
+	case FriendStatusIncoming:
+		return FriendStatusRequested
+	case FriendStatusAccepted:
+		return FriendStatusAccepted
+	default:
+		return ""
+	}
+}
+
+// FriendList is a user's relations grouped by status.
+type FriendList struct {
+	Friends    []Friendship `json:"friends"`   // Accepted friends
+	Incoming   []Friendship `json:"incoming"`  // Requests waiting for the user's answer
+	Requested  []Friendship `json:"requested"` // Requests the user sent
+	Blocked    []Friendship `json:"blocked"`   // Users the user blocked
+	MaxFriends int          `json:"maxFriends"`
+}
+
+// FriendTournamentStanding is a friend's entry in a tournament, as shown to the user.
+type FriendTournamentStanding struct {
+	UserID    string `json:"userId"`
+	Username  string `json:"username"`
+	GroupID   string `json:"groupId"`
+	League    string `json:"league,omitempty"`
+	Score     int    `json:"score"`
+	Rank      int    `json:"rank"`      // 1-based rank within the friend's group; 0 while it is not ranked yet
+	SameGroup bool   `json:"sameGroup"` // The friend plays in the user's group
+}
+
+// FriendsTournament shows which of a user's friends entered a tournament.
+type FriendsTournament struct {
+	TournamentID string                     `json:"tournamentId"`
+	GroupID      string                     `json:"groupId,omitempty"` // The user's group; empty when the user has not entered
+	Friends      []FriendTournamentStanding `json:"friends"`           // Friends who entered, highest score first
+}
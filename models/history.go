@@ -0,0 +1,30 @@
+package models
+
+// History entry types.
+const (
+	HistoryCoinAdjustment  = "coin_adjustment"  // manual change by an operator
+	HistoryEntryFeeRefund  = "entry_fee_refund" // tournament cancelled
+	HistoryRewardGrant     = "reward_grant"     // reward bundle granted by an operator
+	HistoryBoosterPurchase = "booster_purchase" // boosters bought with coins
+	HistoryLivesRefill     = "lives_refill"     // lives refilled with coins
+	HistoryLevelReward     = "level_reward"     // coins for winning a replayed level
+	HistoryCheckIn         = "check_in"         // daily check-in reward
+	HistoryCheckInRestore  = "check_in_restore" // lapsed check-in streak restored with coins
+	HistoryQuestReward     = "quest_reward"     // reward for a completed daily quest
+	HistorySeasonReward    = "season_reward"    // season pass tier reward
+	HistorySeasonPremium   = "season_premium"   // premium season pass bought with coins
+	HistoryClanReward      = "clan_reward"      // reward for contributing to a clan's tournament placement
+)
+
+// HistoryEntry records one change to a user's balance, for support and auditing.
+type HistoryEntry struct {
+	UserID       string        `json:"userId" dynamodbav:"userId"`                                 // Partition Key
+	EntryID      string        `json:"entryId" dynamodbav:"entryId"`                               // Sort Key; starts with the creation time so entries sort chronologically
+	Type         string        `json:"type" dynamodbav:"type"`                                     // One of the History* constants
+	Coins        int           `json:"coins" dynamodbav:"coins"`                                   // Signed change to the coin balance
+	Reason       string        `json:"reason,omitempty" dynamodbav:"reason,omitempty"`             // Free-text explanation
+	Actor        string        `json:"actor,omitempty" dynamodbav:"actor,omitempty"`               // Operator or process that made the change
+	TournamentID string        `json:"tournamentId,omitempty" dynamodbav:"tournamentId,omitempty"` // Related tournament, if any
+	CreatedAt    string        `json:"createdAt" dynamodbav:"createdAt"`                           // RFC3339 timestamp
+	Bundle       *RewardBundle `json:"bundle,omitempty" dynamodbav:"bundle,omitempty"`             // Items granted besides coins, if any
+}
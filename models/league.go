@@ -0,0 +1,148 @@
+package models
+
+import "fmt"
+
+// League movements recorded for a tournament result.
+const (
+	LeaguePromoted  = "promoted"
+	LeagueRelegated = "relegated"
+	LeagueStayed    = "stayed"
+)
+
+// LeagueState is a user's place on the league ladder. It is stored as top-level attributes of
+// the user item.
+type LeagueState struct {
+	League           string `json:"league,omitempty" dynamodbav:"league,omitempty"`                     // League ID; empty means the lowest league
+	LeagueTournament string `json:"leagueTournament,omitempty" dynamodbav:"leagueTournament,omitempty"` // Last tournament whose result was applied
+}
+
+// LeagueDefinition is one rung of the league ladder.
+type LeagueDefinition struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	RewardPercent  int    `json:"rewardPercent"`  // Tournament reward coins are scaled to this percentage
+	PromoteTop     int    `json:"promoteTop"`     // Ranks 1 to PromoteTop of a group move up a league
+	RelegateBottom int    `json:"relegateBottom"` // The last RelegateBottom ranks of a group move down a league
+	MinGroupSize   int    `json:"minGroupSize"`   // Groups with fewer entrants move nobody; 0 means any group moves players
+}
+
+// LeagueLadder is every league, lowest first.
+type LeagueLadder []LeagueDefinition
+
+// DefaultLeagueLadder is the league ladder of the game.
+var DefaultLeagueLadder = LeagueLadder{
+	{ID: "bronze", Name: "Bronze", RewardPercent: 100, PromoteTop: 5, MinGroupSize: 15},
+	{ID: "silver", Name: "Silver", RewardPercent: 125, PromoteTop: 5, RelegateBottom: 5, MinGroupSize: 15},
+	{ID: "gold", Name: "Gold", RewardPercent: 150, PromoteTop: 5, RelegateBottom: 5, MinGroupSize: 15},
+	{ID: "platinum", Name: "Platinum", RewardPercent: 200, PromoteTop: 5, RelegateBottom: 5, MinGroupSize: 15},
+	{ID: "diamond", Name: "Diamond", RewardPercent: 300, RelegateBottom: 5, MinGroupSize: 15},
+}
+
+// index returns the position of a league on the ladder. Users without a league, or in one the
+// ladder no longer has, are placed in the lowest league.
+func (l LeagueLadder) index(id string) int {
+	for i, d := range l {
+		if d.ID == id {
+			return i
+		}
+	}
+	return 0
+}
+
+// Position returns the 1-based position of a user's league on the ladder, lowest first.
+func (l LeagueLadder) Position(id string) int {
+	return l.index(id) + 1
+}
+
+// Of returns the league a user with the stored league ID plays in.
+func (l LeagueLadder) Of(id string) LeagueDefinition {
+	if len(l) == 0 {
+		return LeagueDefinition{RewardPercent: 100}
+	}
+	return l[l.index(id)]
+}
+
+// Move returns the league a player ends up in after finishing at a 1-based rank among size
+// entrants of a group in league id, and how they moved. Only players who scored can be
+// promoted; the top league promotes nobody and the lowest relegates nobody. Nobody moves in a
+// group smaller than the league's MinGroupSize, where a rank says little about a player.
+func (l LeagueLadder) Move(id string, rank, size, score int) (string, string) {
+	if len(l) == 0 {
+		return id, LeagueStayed
+	}
+	i := l.index(id)
+	d := l[i]
+	if rank < 1 || size < d.MinGroupSize {
+		return d.ID, LeagueStayed
+	}
+	switch {
+	case rank <= d.PromoteTop && score > 0 && i < len(l)-1:
+		return l[i+1].ID, LeaguePromoted
+	case rank > d.PromoteTop && rank > size-d.RelegateBottom && i > 0:
+		return l[i-1].ID, LeagueRelegated
+	}
+	return d.ID, LeagueStayed
+}
+
+// Validate checks that the ladder has leagues with unique IDs and sensible movement rules.
+func (l LeagueLadder) Validate() error {
+	if len(l) == 0 {
+		return fmt.Errorf("must have at least one league")
+	}
+	ids := make(map[string]bool, len(l))
+	for _, d := range l {
+		if d.ID == "" {
+			return fmt.Errorf("league has no id")
+		}
+		if ids[d.ID] {
+			return fmt.Errorf("duplicate league %q", d.ID)
+		}
+		ids[d.ID] = true
+		if d.RewardPercent < 1 {
+			return fmt.Errorf("league %q: rewardPercent must be positive", d.ID)
+		}
+		if d.PromoteTop < 0 || d.RelegateBottom < 0 || d.PromoteTop+d.RelegateBottom > GroupCapacity {
+			return fmt.Errorf("league %q: promoteTop and relegateBottom must not be negative or exceed a group", d.ID)
+		}
+		if d.MinGroupSize < 0 || d.MinGroupSize > GroupCapacity {
+			return fmt.Errorf("league %q: minGroupSize must be between 0 and %d", d.ID, GroupCapacity)
+		}
+	}
+	return nil
+}
+
+// LeagueResult records how a tournament result moved a user on the league ladder.
+type LeagueResult struct {
+	UserID       string `json:"userId" dynamodbav:"userId"`             // Partition Key
+	TournamentID string `json:"tournamentId" dynamodbav:"tournamentId"` // Sort Key
+	GroupID      string `json:"groupId" dynamodbav:"groupId"`
+	Rank         int    `json:"rank" dynamodbav:"rank"`           // 1-based rank within the group
+	GroupSize    int    `json:"groupSize" dynamodbav:"groupSize"` // Entrants in the group
+	From         string `json:"from" dynamodbav:"from"`           // League played in
+	To           string `json:"to" dynamodbav:"to"`               // League after the result
+	Movement     string `json:"movement" dynamodbav:"movement"`   // One of the League* movement constants
+	AppliedAt    string `json:"appliedAt" dynamodbav:"appliedAt"` // RFC3339 timestamp
+}
+
+// LeagueReport summarises applying the league results of a tournament.
+type LeagueReport struct {
+	TournamentID   string `json:"tournamentId"`
+	Entries        int    `json:"entries"`        // Entries examined
+	Promoted       int    `json:"promoted"`       // Users moved up by this run
+	Relegated      int    `json:"relegated"`      // Users moved down by this run
+	Stayed         int    `json:"stayed"`         // Users kept in their league by this run
+	AlreadyApplied int    `json:"alreadyApplied"` // Results applied by an earlier run, or superseded by a later tournament
+}
+
+// LeagueStatus is a user's view of their league.
+type LeagueStatus struct {
+	UserID           string `json:"userId"`
+	League           string `json:"league"`
+	Name             string `json:"name"`
+	Tier             int    `json:"tier"` // 1-based position on the ladder, lowest first
+	Tiers            int    `json:"tiers"`
+	RewardPercent    int    `json:"rewardPercent"`
+	PromoteTop       int    `json:"promoteTop"`
+	RelegateBottom   int    `json:"relegateBottom"`
+	LastTournamentID string `json:"lastTournamentId,omitempty"` // Last tournament whose result was applied
+}
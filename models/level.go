@@ -0,0 +1,41 @@
+package models
+
+// Level session statuses.
+const (
+	LevelSessionStarted = "started"
+	LevelSessionWon     = "won"
+	LevelSessionLost    = "lost"
+)
+
+// LevelSession is one attempt at a level, from start to the reported result. Finished
+// sessions are kept as the record of what was played.
+type LevelSession struct {
+	SessionID    string         `json:"sessionId" dynamodbav:"sessionId"` // Partition Key
+	UserID       string         `json:"userId" dynamodbav:"userId"`
+	Level        int            `json:"level" dynamodbav:"level"`
+	Status       string         `json:"status" dynamodbav:"status"`                     // One of the LevelSession* constants
+	Replay       bool           `json:"replay,omitempty" dynamodbav:"replay,omitempty"` // Started below the user's level; a win pays the level's coin reward only
+	StartedAt    string         `json:"startedAt" dynamodbav:"startedAt"`               // RFC3339 timestamp
+	FinishedAt   string         `json:"finishedAt,omitempty" dynamodbav:"finishedAt,omitempty"`
+	Moves        int            `json:"moves,omitempty" dynamodbav:"moves,omitempty"`
+	Score        int            `json:"score,omitempty" dynamodbav:"score,omitempty"`
+	BoostersUsed map[string]int `json:"boostersUsed,omitempty" dynamodbav:"boostersUsed,omitempty"`
+	Scored       bool           `json:"scored,omitempty" dynamodbav:"scored,omitempty"` // The win was added to the user's tournament score
+}
+
+// LevelResult is what the client reports when a level attempt ends.
+type LevelResult struct {
+	Won          bool           `json:"won"`
+	Moves        int            `json:"moves"`
+	Score        int            `json:"score"`
+	BoostersUsed map[string]int `json:"boostersUsed,omitempty"`
+}
+
+// LevelOutcome is the effect of a finished level attempt.
+type LevelOutcome struct {
+	Session         LevelSession  `json:"session"`
+	Level           int           `json:"level"`                     // User's level afterwards
+	Coins           int           `json:"coins"`                     // User's coin balance afterwards
+	Reward          *RewardBundle `json:"reward,omitempty"`          // What the win earned, if anything
+	TournamentScore *int          `json:"tournamentScore,omitempty"` // New tournament score when the win counted in today's tournament
+}
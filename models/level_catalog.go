@@ -0,0 +1,151 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Level difficulties.
+const (
+	DifficultyNormal    = "normal"
+	DifficultyHard      = "hard"
+	DifficultySuperHard = "super_hard"
+)
+
+// LevelDefinition is what a level is worth.
+type LevelDefinition struct {
+	Level           int          `json:"level,omitempty"`      // Absent on the catalog's default definition
+	Difficulty      string       `json:"difficulty"`           // One of the Difficulty* constants
+	CoinReward      int          `json:"coinReward,omitempty"` // Coins for every win, replays included
+	FirstClearBonus RewardBundle `json:"firstClearBonus"`      // Granted on top of CoinReward the first time the level is won
+}
+
+// LevelMilestone grants Bundle for clearing every level that is a multiple of Every.
+type LevelMilestone struct {
+	Every  int          `json:"every"`
+	Bundle RewardBundle `json:"bundle"`
+}
+
+// LevelCatalog defines the rewards of every level. Levels not listed use Default.
+type LevelCatalog struct {
+	Version    string            `json:"version"` // Changes whenever the catalog does, so clients know to refetch it
+	Default    LevelDefinition   `json:"default"`
+	Levels     []LevelDefinition `json:"levels,omitempty"`
+	Milestones []LevelMilestone  `json:"milestones,omitempty"`
+}
+
+// DefaultLevelCatalog pays 100 coins for clearing each level, and nothing for replays.
+var DefaultLevelCatalog = &LevelCatalog{
+	Version: "default",
+	Default: LevelDefinition{Difficulty: DifficultyNormal, FirstClearBonus: RewardBundle{Coins: 100}},
+}
+
+// Level returns the definition of a level.
+func (c *LevelCatalog) Level(level int) LevelDefinition {
+	for _, def := range c.Levels {
+		if def.Level == level {
+			return def
+		}
+	}
+	def := c.Default
+	def.Level = level
+	return def
+}
+
+// ClearReward returns everything earned by clearing levels from up to (but not including) to
+// for the first time: each level's coin reward and first-clear bonus, and the milestones passed.
+func (c *LevelCatalog) ClearReward(from, to int) RewardBundle {
+	var total RewardBundle
+	if to <= from {
+		return total
+	}
+
+	// Levels are summed arithmetically, so a large jump costs no more than a small one
+	listed := 0
+	for _, def := range c.Levels {
+		if def.Level >= from && def.Level < to {
+			listed++
+			total = total.Add(def.FirstClearBonus.Add(RewardBundle{Coins: def.CoinReward}))
+		}
+	}
+	unlisted := to - from - listed
+	total = total.Add(c.Default.FirstClearBonus.Add(RewardBundle{Coins: c.Default.CoinReward}).Scale(unlisted))
+
+	for _, m := range c.Milestones {
+		// Multiples of Every in [from, to)
+		n := (to-1)/m.Every - (from-1)/m.Every
+		total = total.Add(m.Bundle.Scale(n))
+	}
+	return total
+}
+
+// Validate checks that levels are listed once with a known difficulty and non-negative rewards,
+// and that milestones have a positive interval and grant something.
+func (c *LevelCatalog) Validate() error {
+	if c.Version == "" {
+		return fmt.Errorf("version is required")
+	}
+	if err := c.Default.validate(); err != nil {
+		return fmt.Errorf("default: %v", err)
+	}
+	seen := make(map[int]bool, len(c.Levels))
+	for _, def := range c.Levels {
+		if def.Level < 1 {
+			return fmt.Errorf("level numbers must be positive")
+		}
+		if seen[def.Level] {
+			return fmt.Errorf("level %d is listed twice", def.Level)
+		}
+		seen[def.Level] = true
+		if err := def.validate(); err != nil {
+			return fmt.Errorf("level %d: %v", def.Level, err)
+		}
+	}
+	for _, m := range c.Milestones {
+		if m.Every < 1 {
+			return fmt.Errorf("milestones need a positive interval")
+		}
+		if m.Bundle.IsEmpty() {
+			return fmt.Errorf("milestone every %d levels grants nothing", m.Every)
+		}
+		if err := m.Bundle.Validate(); err != nil {
+			return fmt.Errorf("milestone every %d levels: %v", m.Every, err)
+		}
+	}
+	return nil
+}
+
+func (d LevelDefinition) validate() error {
+	switch d.Difficulty {
+	case DifficultyNormal, DifficultyHard, DifficultySuperHard:
+	default:
+		return fmt.Errorf("difficulty must be one of normal, hard, super_hard")
+	}
+	if d.CoinReward < 0 {
+		return fmt.Errorf("coinReward must not be negative")
+	}
+	return d.FirstClearBonus.Validate()
+}
+
+// Boosters returns the IDs of every booster the catalog grants, sorted.
+func (c *LevelCatalog) Boosters() []string {
+	ids := map[string]bool{}
+	bundles := []RewardBundle{c.Default.FirstClearBonus}
+	for _, def := range c.Levels {
+		bundles = append(bundles, def.FirstClearBonus)
+	}
+	for _, m := range c.Milestones {
+		bundles = append(bundles, m.Bundle)
+	}
+	for _, b := range bundles {
+		for id := range b.Boosters {
+			ids[id] = true
+		}
+	}
+	out := make([]string, 0, len(ids))
+	for id := range ids {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
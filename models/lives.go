@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// LivesPolicy is how many lives regenerate and how fast.
+type LivesPolicy struct {
+	Max           int           // Lives regenerate up to this count
+	RegenInterval time.Duration // Time to regenerate one life
+}
+
+// DefaultLivesPolicy gives five lives, one back every 30 minutes.
+var DefaultLivesPolicy = LivesPolicy{Max: 5, RegenInterval: 30 * time.Minute}
+
+// LivesRecord is how lives are stored on the user: a count and the time regeneration is
+// counted from (Unix seconds). Regenerated lives are never written; they are derived from
+// the elapsed time whenever lives are read or spent.
+type LivesRecord struct {
+	Lives     int
+	UpdatedAt int64 // 0 while the user has never spent a life
+}
+
+// LivesRecord returns the stored lives of the inventory.
+func (inv Inventory) LivesRecord() LivesRecord {
+	return LivesRecord{Lives: inv.Lives, UpdatedAt: inv.LivesUpdatedAt}
+}
+
+// Regenerate returns the record as of now, with every life regenerated since UpdatedAt added.
+// While lives are below Max, UpdatedAt advances by whole intervals so a partly regenerated life
+// is kept; at or above Max the clock is reset to now. Lives granted as rewards may exceed Max,
+// but never regenerate past it.
+func (p LivesPolicy) Regenerate(r LivesRecord, now time.Time) LivesRecord {
+	if r.UpdatedAt == 0 {
+		// Users start with full lives, plus any granted before they ever played
+		return LivesRecord{Lives: p.Max + r.Lives, UpdatedAt: now.Unix()}
+	}
+	if r.Lives >= p.Max || p.RegenInterval <= 0 {
+		return LivesRecord{Lives: r.Lives, UpdatedAt: now.Unix()}
+	}
+
+	interval := int64(p.RegenInterval / time.Second)
+	regenerated := (now.Unix() - r.UpdatedAt) / interval
+	if regenerated < 0 {
+		regenerated = 0
+	}
+	if r.Lives+int(regenerated) >= p.Max {
+		return LivesRecord{Lives: p.Max, UpdatedAt: now.Unix()}
+	}
+	return LivesRecord{Lives: r.Lives + int(regenerated), UpdatedAt: r.UpdatedAt + regenerated*interval}
+}
+
+// NextLifeAt returns when the next life regenerates for a record returned by Regenerate;
+// the zero time when lives are full.
+func (p LivesPolicy) NextLifeAt(r LivesRecord) time.Time {
+	if r.Lives >= p.Max {
+		return time.Time{}
+	}
+	return time.Unix(r.UpdatedAt, 0).UTC().Add(p.RegenInterval)
+}
+
+// LivesStatus is a user's lives as shown to the client.
+type LivesStatus struct {
+	Lives               int        `json:"lives"`
+	MaxLives            int        `json:"maxLives"`
+	NextLifeAt          *time.Time `json:"nextLifeAt,omitempty"`          // Absent when lives are full
+	UnlimitedLivesUntil *time.Time `json:"unlimitedLivesUntil,omitempty"` // Present while an unlimited-lives boost runs
+	Unlimited           bool       `json:"unlimited"`                     // Playing costs no life right now
+}
@@ -0,0 +1,13 @@
+package models
+
+// PageRequest asks for one page of a paginated query.
+type PageRequest struct {
+	Limit  int    // Maximum number of items; 0 means the query's default
+	Cursor string // Opaque token from a previous Page.NextCursor; empty for the first page
+}
+
+// Page is one page of a paginated query. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// Quest event types: what a quest counts.
+const (
+	QuestEventLevelCleared      = "level_cleared"      // levels cleared for the first time
+	QuestEventTournamentEntered = "tournament_entered" // tournaments entered
+	QuestEventTournamentRank    = "tournament_rank"    // tournament rewards claimed at or above a rank
+)
+
+// QuestDefinition is one mission users can be given for a day.
+type QuestDefinition struct {
+	ID          string       `json:"id"`
+	Description string       `json:"description"`
+	Event       string       `json:"event"`             // One of the QuestEvent* constants
+	Target      int          `json:"target"`            // Events needed to complete the quest
+	MaxRank     int          `json:"maxRank,omitempty"` // For tournament_rank: the lowest rank that counts
+	Reward      RewardBundle `json:"reward"`
+}
+
+// Counts returns how much an event advances the quest.
+func (q QuestDefinition) Counts(e QuestEvent) int {
+	if e.Type != q.Event || e.Count < 1 {
+		return 0
+	}
+	if q.Event == QuestEventTournamentRank && (e.Rank < 1 || e.Rank > q.MaxRank) {
+		return 0
+	}
+	return e.Count
+}
+
+// QuestEvent is something a user did that quests may count.
+type QuestEvent struct {
+	Type  string // One of the QuestEvent* constants
+	Count int    // How many times it happened, e.g. levels cleared at once
+	Rank  int    // For tournament_rank: the rank within the group
+}
+
+// QuestPool is the set of quests daily missions are drawn from.
+type QuestPool []QuestDefinition
+
+// DefaultQuestPool is used when operators configure none. It grants no boosters, so it fits
+// any booster catalog.
+var DefaultQuestPool = QuestPool{
+	{ID: "clear_3_levels", Description: "Clear 3 levels", Event: QuestEventLevelCleared, Target: 3, Reward: RewardBundle{Coins: 150}},
+	{ID: "clear_10_levels", Description: "Clear 10 levels", Event: QuestEventLevelCleared, Target: 10, Reward: RewardBundle{Coins: 400, Lives: 1}},
+	{ID: "enter_tournament", Description: "Enter today's tournament", Event: QuestEventTournamentEntered, Target: 1, Reward: RewardBundle{Coins: 200}},
+	{ID: "top_10_in_group", Description: "Reach top 10 in your tournament group", Event: QuestEventTournamentRank, Target: 1, MaxRank: 10, Reward: RewardBundle{Coins: 300}},
+	{ID: "top_3_in_group", Description: "Reach top 3 in your tournament group", Event: QuestEventTournamentRank, Target: 1, MaxRank: 3, Reward: RewardBundle{Coins: 500, UnlimitedLivesMinutes: 30}},
+}
+
+// DefaultQuestsPerDay is how many quests each user gets per day unless configured otherwise.
+const DefaultQuestsPerDay = 3
+
+// Find returns the quest with the given ID.
+func (p QuestPool) Find(id string) (QuestDefinition, bool) {
+	for _, q := range p {
+		if q.ID == id {
+			return q, true
+		}
+	}
+	return QuestDefinition{}, false
+}
+
+// ForDay returns the n quests a user has on a UTC day. The choice is a pure function of user,
+// day and pool, so it needs no storage and every server agrees on it.
+func (p QuestPool) ForDay(userID, day string, n int) []QuestDefinition {
+	weight := func(q QuestDefinition) uint64 {
+		h := fnv.New64a()
+		h.Write([]byte(userID + "|" + day + "|" + q.ID))
+		return h.Sum64()
+	}
+	quests := append([]QuestDefinition(nil), p...)
+	sort.SliceStable(quests, func(i, j int) bool { return weight(quests[i]) < weight(quests[j]) })
+	if n < len(quests) {
+		quests = quests[:n]
+	}
+	// Show them in pool order, which operators control
+	sort.SliceStable(quests, func(i, j int) bool { return p.index(quests[i].ID) < p.index(quests[j].ID) })
+	return quests
+}
+
+func (p QuestPool) index(id string) int {
+	for i, q := range p {
+		if q.ID == id {
+			return i
+		}
+	}
+	return len(p)
+}
+
+// Validate checks that quest IDs are unique and non-empty and every quest counts a known event
+// towards a positive target for a non-empty reward.
+func (p QuestPool) Validate() error {
+	if len(p) == 0 {
+		return fmt.Errorf("must have at least one quest")
+	}
+	seen := map[string]bool{}
+	for i, q := range p {
+		switch {
+		case q.ID == "":
+			return fmt.Errorf("quest %d has no id", i+1)
+		case seen[q.ID]:
+			return fmt.Errorf("duplicate quest %q", q.ID)
+		case q.Target < 1:
+			return fmt.Errorf("quest %q: target must be positive", q.ID)
+		case q.Reward.IsEmpty():
+			return fmt.Errorf("quest %q grants nothing", q.ID)
+		}
+		seen[q.ID] = true
+		switch q.Event {
+		case QuestEventLevelCleared, QuestEventTournamentEntered:
+		case QuestEventTournamentRank:
+			if q.MaxRank < 1 {
+				return fmt.Errorf("quest %q: maxRank must be positive", q.ID)
+			}
+		default:
+			return fmt.Errorf("quest %q: unknown event %q", q.ID, q.Event)
+		}
+		if err := q.Reward.Validate(); err != nil {
+			return fmt.Errorf("quest %q: %v", q.ID, err)
+		}
+	}
+	return nil
+}
+
+// QuestProgress is a stored quest of one user on one day.
+type QuestProgress struct {
+	UserID    string `json:"userId" dynamodbav:"userId"`                           // Partition Key
+	QuestKey  string `json:"questKey" dynamodbav:"questKey"`                       // Sort Key; "<day>#<questId>"
+	Progress  int    `json:"progress" dynamodbav:"progress"`                       // Events counted so far
+	ClaimedAt string `json:"claimedAt,omitempty" dynamodbav:"claimedAt,omitempty"` // RFC3339 timestamp of the claim
+}
+
+// QuestKey returns the sort key of a user's quest on a UTC day.
+func QuestKey(day, questID string) string {
+	return day + "#" + questID
+}
+
+// Quest is a daily quest as shown to its user.
+type Quest struct {
+	ID          string       `json:"id"`
+	Description string       `json:"description"`
+	Progress    int          `json:"progress"` // Capped at Target
+	Target      int          `json:"target"`
+	Completed   bool         `json:"completed"`
+	Claimed     bool         `json:"claimed"`
+	Reward      RewardBundle `json:"reward"`
+}
+
+// DailyQuests is a user's quests for today.
+type DailyQuests struct {
+	Day      string  `json:"day"`
+	ResetsAt string  `json:"resetsAt"` // RFC3339 time the next day's quests start
+	Quests   []Quest `json:"quests"`
+}
@@ -0,0 +1,80 @@
+package models
+
+// TournamentResult is a user's final standing in a tournament group.
+type TournamentResult struct {
+	TournamentID  string       `json:"tournamentId"`
+	GroupID       string       `json:"groupId"`
+	UserID        string       `json:"userId"`
+	League        string       `json:"league,omitempty"`
+	Score         int          `json:"score"`
+	Rank          int          `json:"rank"`                // 1-based rank within the group
+	GroupSize     int          `json:"groupSize,omitempty"` // Entrants in the group
+	Reward        int          `json:"reward"`              // Coins earned for the rank; 0 when none
+	Bundle        RewardBundle `json:"bundle"`              // Everything earned for the rank, coins included
+	ClaimedReward bool         `json:"claimedReward"`
+}
+
+// SettlementReport summarises a settlement run over a tournament.
+type SettlementReport struct {
+	TournamentID   string `json:"tournamentId"`
+	Entries        int    `json:"entries"`        // Entries examined
+	Paid           int    `json:"paid"`           // Rewards paid by this run
+	CoinsPaid      int    `json:"coinsPaid"`      // Total coins paid by this run
+	AlreadyClaimed int    `json:"alreadyClaimed"` // Rewards the user (or an earlier run) had already collected
+	NoReward       int    `json:"noReward"`       // Entries whose rank earns nothing
+}
+
+// CancellationReport summarises the entry-fee refunds of a cancelled tournament.
+type CancellationReport struct {
+	TournamentID    string `json:"tournamentId"`
+	Entries         int    `json:"entries"`         // Entries examined
+	Refunded        int    `json:"refunded"`        // Entry fees refunded by this run
+	CoinsRefunded   int    `json:"coinsRefunded"`   // Total coins refunded by this run
+	AlreadyRefunded int    `json:"alreadyRefunded"` // Refunded by an earlier, interrupted run
+	RewardClaimed   int    `json:"rewardClaimed"`   // Not refunded: the entrant was already paid the tournament reward
+	Completed       bool   `json:"completed"`       // Every entrant has been refunded
+}
+
+// PendingReward is a reward a user has earned but not claimed yet.
+type PendingReward struct {
+	TournamentID  string       `json:"tournamentId"`
+	GroupID       string       `json:"groupId"`
+	Rank          int          `json:"rank"`
+	Reward        int          `json:"reward"` // Coins in the bundle
+	Bundle        RewardBundle `json:"bundle"`
+	ClaimDeadline string       `json:"claimDeadline,omitempty"` // RFC3339; absent when the reward never expires
+}
+
+// ExpiryReport summarises an expiry run over a tournament whose claim window has closed.
+type ExpiryReport struct {
+	TournamentID   string `json:"tournamentId"`
+	Entries        int    `json:"entries"`        // Entries examined
+	Expired        int    `json:"expired"`        // Unclaimed entries marked expired by this run
+	AlreadyExpired int    `json:"alreadyExpired"` // Marked expired by an earlier run
+	AlreadyClaimed int    `json:"alreadyClaimed"` // Claimed (or refunded) before the deadline
+}
+
+// Outcomes of a single claim in ClaimAllResult.
+const (
+	ClaimStatusClaimed        = "claimed"
+	ClaimStatusAlreadyClaimed = "already_claimed" // claimed concurrently, e.g. from another device or by settlement
+	ClaimStatusExpired        = "expired"
+	ClaimStatusCancelled      = "cancelled"
+)
+
+// RewardClaim is the outcome of claiming one tournament's reward.
+type RewardClaim struct {
+	TournamentID string        `json:"tournamentId"`
+	GroupID      string        `json:"groupId"`
+	Rank         int           `json:"rank"`
+	Reward       int           `json:"reward"`           // Coins credited; 0 unless Status is claimed
+	Bundle       *RewardBundle `json:"bundle,omitempty"` // Everything granted; set only when Status is claimed
+	Status       string        `json:"status"`
+}
+
+// ClaimAllResult summarises claiming every pending reward of a user.
+type ClaimAllResult struct {
+	UserID      string        `json:"userId"`
+	Claims      []RewardClaim `json:"claims"`      // One per tournament, newest first
+	TotalReward int           `json:"totalReward"` // Coins credited by this call
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RewardBundle is a set of items granted together, e.g. for a tournament rank.
+// Every part is optional; a bundle is applied to the user atomically.
+type RewardBundle struct {
+	Coins      int            `json:"coins,omitempty" dynamodbav:"coins,omitempty"`
+	Lives      int            `json:"lives,omitempty" dynamodbav:"lives,omitempty"`
+	Boosters   map[string]int `json:"boosters,omitempty" dynamodbav:"boosters,omitempty"`             // Booster type -> count
+	Currencies map[string]int `json:"currencies,omitempty" dynamodbav:"currencies,omitempty"`         // Currency type (e.g. "gems") -> amount
+	Cosmetics  []string       `json:"cosmetics,omitempty" dynamodbav:"cosmetics,stringset,omitempty"` // Cosmetic item IDs; owning one twice is the same as once
+
+	UnlimitedLivesMinutes int `json:"unlimitedLivesMinutes,omitempty" dynamodbav:"unlimitedLivesMinutes,omitempty"` // Playing costs no life for this long; extends a running boost
+}
+
+// IsEmpty reports whether the bundle grants nothing.
+func (b RewardBundle) IsEmpty() bool {
+	return b.Coins == 0 && b.Lives == 0 && len(b.Boosters) == 0 && len(b.Currencies) == 0 && len(b.Cosmetics) == 0 &&
+		b.UnlimitedLivesMinutes == 0
+}
+
+// Validate checks that every amount in the bundle is positive and every item is named.
+func (b RewardBundle) Validate() error {
+	if b.Coins < 0 || b.Lives < 0 || b.UnlimitedLivesMinutes < 0 {
+		return fmt.Errorf("coins, lives and unlimitedLivesMinutes must not be negative")
+	}
+	for _, m := range []map[string]int{b.Boosters, b.Currencies} {
+		for name, n := range m {
+			if name == "" || n <= 0 {
+				return fmt.Errorf("item %q needs a name and a positive amount", name)
+			}
+		}
+	}
+	seen := make(map[string]bool, len(b.Cosmetics))
+	for _, id := range b.Cosmetics {
+		if id == "" || seen[id] {
+			return fmt.Errorf("cosmetic item IDs must be non-empty and unique")
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// Scaled returns the bundle with its coins scaled to percent, rounded down. Items are kept as
+// they are.
+func (b RewardBundle) Scaled(percent int) RewardBundle {
+	b.Coins = b.Coins * percent / 100
+	return b
+}
+
+// Add returns the bundle granting both b and o.
+func (b RewardBundle) Add(o RewardBundle) RewardBundle {
+	sum := RewardBundle{
+		Coins:                 b.Coins + o.Coins,
+		Lives:                 b.Lives + o.Lives,
+		UnlimitedLivesMinutes: b.UnlimitedLivesMinutes + o.UnlimitedLivesMinutes,
+		Boosters:              addCounts(b.Boosters, o.Boosters),
+		Currencies:            addCounts(b.Currencies, o.Currencies),
+	}
+	seen := make(map[string]bool, len(b.Cosmetics)+len(o.Cosmetics))
+	for _, id := range append(append([]string(nil), b.Cosmetics...), o.Cosmetics...) {
+		if !seen[id] {
+			seen[id] = true
+			sum.Cosmetics = append(sum.Cosmetics, id)
+		}
+	}
+	return sum
+}
+
+// Scale returns the bundle granted by receiving b n times. Cosmetics are owned once however
+// often they are granted; scaling by zero or less grants nothing.
+func (b RewardBundle) Scale(n int) RewardBundle {
+	if n <= 0 {
+		return RewardBundle{}
+	}
+	scaled := RewardBundle{
+		Coins:                 b.Coins * n,
+		Lives:                 b.Lives * n,
+		UnlimitedLivesMinutes: b.UnlimitedLivesMinutes * n,
+		Cosmetics:             b.Cosmetics,
+	}
+	for id, count := range b.Boosters {
+		if scaled.Boosters == nil {
+			scaled.Boosters = make(map[string]int, len(b.Boosters))
+		}
+		scaled.Boosters[id] = count * n
+	}
+	for id, amount := range b.Currencies {
+		if scaled.Currencies == nil {
+			scaled.Currencies = make(map[string]int, len(b.Currencies))
+		}
+		scaled.Currencies[id] = amount * n
+	}
+	return scaled
+}
+
+// addCounts merges two item maps, summing shared keys; nil when both are empty.
+func addCounts(a, b map[string]int) map[string]int {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	sum := make(map[string]int, len(a)+len(b))
+	for k, v := range a {
+		sum[k] += v
+	}
+	for k, v := range b {
+		sum[k] += v
+	}
+	return sum
+}
+
+// Inventory is what a user owns besides coins. It is stored as top-level attributes of
+// the user item, so a whole RewardBundle can be applied with a single update.
+type Inventory struct {
+	Lives      int            `json:"lives,omitempty" dynamodbav:"lives,omitempty"`
+	Boosters   map[string]int `json:"boosters,omitempty" dynamodbav:"boosters,omitempty"`
+	Currencies map[string]int `json:"currencies,omitempty" dynamodbav:"currencies,omitempty"`
+	Cosmetics  []string       `json:"cosmetics,omitempty" dynamodbav:"cosmetics,stringset,omitempty"`
+
+	LivesUpdatedAt      int64 `json:"livesUpdatedAt,omitempty" dynamodbav:"livesUpdatedAt,omitempty"`           // Unix seconds regeneration is counted from, see LivesPolicy.Regenerate
+	UnlimitedLivesUntil int64 `json:"unlimitedLivesUntil,omitempty" dynamodbav:"unlimitedLivesUntil,omitempty"` // Unix seconds an unlimited-lives boost runs until
+}
+
+// RewardTier grants Bundle to every rank from MinRank to MaxRank (1-based, inclusive).
+type RewardTier struct {
+	MinRank int          `json:"minRank"`
+	MaxRank int          `json:"maxRank"`
+	Bundle  RewardBundle `json:"bundle"`
+}
+
+// RewardTable maps ranks within a tournament group to reward bundles.
+type RewardTable []RewardTier
+
+// DefaultRewardTable pays coins to the top 10 of every group.
+var DefaultRewardTable = RewardTable{
+	{MinRank: 1, MaxRank: 1, Bundle: RewardBundle{Coins: 5000}},
+	{MinRank: 2, MaxRank: 2, Bundle: RewardBundle{Coins: 3000}},
+	{MinRank: 3, MaxRank: 3, Bundle: RewardBundle{Coins: 2000}},
+	{MinRank: 4, MaxRank: 10, Bundle: RewardBundle{Coins: 1000}},
+}
+
+// ForRank returns the bundle earned by a 1-based rank; the zero bundle when it earns nothing.
+func (t RewardTable) ForRank(rank int) RewardBundle {
+	for _, tier := range t {
+		if rank >= tier.MinRank && rank <= tier.MaxRank {
+			return tier.Bundle
+		}
+	}
+	return RewardBundle{}
+}
+
+// Validate checks that tiers cover valid, non-overlapping rank ranges within a group
+// and grant something.
+func (t RewardTable) Validate() error {
+	tiers := make(RewardTable, len(t))
+	copy(tiers, t)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinRank < tiers[j].MinRank })
+
+	for i, tier := range tiers {
+		if tier.MinRank < 1 || tier.MaxRank < tier.MinRank || tier.MaxRank > GroupCapacity {
+			return fmt.Errorf("ranks %d-%d must satisfy 1 <= minRank <= maxRank <= %d", tier.MinRank, tier.MaxRank, GroupCapacity)
+		}
+		if i > 0 && tier.MinRank <= tiers[i-1].MaxRank {
+			return fmt.Errorf("ranks %d-%d overlap the previous tier", tier.MinRank, tier.MaxRank)
+		}
+		if tier.Bundle.IsEmpty() {
+			return fmt.Errorf("ranks %d-%d grant nothing", tier.MinRank, tier.MaxRank)
+		}
+		if err := tier.Bundle.Validate(); err != nil {
+			return fmt.Errorf("ranks %d-%d: %v", tier.MinRank, tier.MaxRank, err)
+		}
+	}
+	return nil
+}
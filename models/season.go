@@ -0,0 +1,236 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Season pass reward tracks.
+const (
+	SeasonTrackFree    = "free"
+	SeasonTrackPremium = "premium"
+)
+
+// SeasonTier is one step of a season pass: reaching XP unlocks its free reward, and its premium
+// reward for users who bought the premium pass.
+type SeasonTier struct {
+	XP      int          `json:"xp"` // Season XP needed; increases from tier to tier
+	Free    RewardBundle `json:"free"`
+	Premium RewardBundle `json:"premium"`
+}
+
+// PlacementXP grants XP to every tournament rank from MinRank to MaxRank (1-based, inclusive).
+type PlacementXP struct {
+	MinRank int `json:"minRank"`
+	MaxRank int `json:"maxRank"`
+	XP      int `json:"xp"`
+}
+
+// Season is one run of the season pass.
+type Season struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	StartsAt    string        `json:"startsAt"`    // RFC3339; inclusive
+	EndsAt      string        `json:"endsAt"`      // RFC3339; exclusive
+	LevelXP     int           `json:"levelXP"`     // XP per level cleared
+	PlacementXP []PlacementXP `json:"placementXP"` // XP per tournament rank whose reward is paid
+	PremiumCost int           `json:"premiumCost"` // Coins to buy the premium pass
+	Tiers       []SeasonTier  `json:"tiers"`
+}
+
+// Window returns the start and end of the season.
+func (s Season) Window() (start, end time.Time, err error) {
+	if start, err = time.Parse(time.RFC3339, s.StartsAt); err != nil {
+		return start, end, fmt.Errorf("startsAt: %v", err)
+	}
+	if end, err = time.Parse(time.RFC3339, s.EndsAt); err != nil {
+		return start, end, fmt.Errorf("endsAt: %v", err)
+	}
+	return start, end, nil
+}
+
+// Contains reports whether t falls within the season.
+func (s Season) Contains(t time.Time) bool {
+	start, end, err := s.Window()
+	return err == nil && !t.Before(start) && t.Before(end)
+}
+
+// XPForRank returns the XP a tournament rank earns.
+func (s Season) XPForRank(rank int) int {
+	for _, p := range s.PlacementXP {
+		if rank >= p.MinRank && rank <= p.MaxRank {
+			return p.XP
+		}
+	}
+	return 0
+}
+
+// Tier returns the 1-based tier n.
+func (s Season) Tier(n int) (SeasonTier, bool) {
+	if n < 1 || n > len(s.Tiers) {
+		return SeasonTier{}, false
+	}
+	return s.Tiers[n-1], true
+}
+
+// TierFor returns how many tiers xp has reached.
+func (s Season) TierFor(xp int) int {
+	return sort.Search(len(s.Tiers), func(i int) bool { return s.Tiers[i].XP > xp })
+}
+
+// Validate checks the season's dates, XP rules and tiers.
+func (s Season) Validate() error {
+	if s.ID == "" {
+		return fmt.Errorf("season has no id")
+	}
+	start, end, err := s.Window()
+	if err != nil {
+		return err
+	}
+	if !start.Before(end) {
+		return fmt.Errorf("startsAt must be before endsAt")
+	}
+	if s.LevelXP < 0 || s.PremiumCost < 0 {
+		return fmt.Errorf("levelXP and premiumCost must not be negative")
+	}
+	for _, p := range s.PlacementXP {
+		if p.MinRank < 1 || p.MaxRank < p.MinRank || p.XP < 1 {
+			return fmt.Errorf("placementXP needs 1 <= minRank <= maxRank and positive xp")
+		}
+	}
+	if len(s.Tiers) == 0 {
+		return fmt.Errorf("must have at least one tier")
+	}
+	premium := false
+	for i, t := range s.Tiers {
+		if t.XP < 1 || (i > 0 && t.XP <= s.Tiers[i-1].XP) {
+			return fmt.Errorf("tier %d: xp must be positive and greater than the tier before", i+1)
+		}
+		if t.Free.IsEmpty() && t.Premium.IsEmpty() {
+			return fmt.Errorf("tier %d grants nothing", i+1)
+		}
+		if err := t.Free.Validate(); err != nil {
+			return fmt.Errorf("tier %d free: %v", i+1, err)
+		}
+		if err := t.Premium.Validate(); err != nil {
+			return fmt.Errorf("tier %d premium: %v", i+1, err)
+		}
+		premium = premium || !t.Premium.IsEmpty()
+	}
+	if premium && s.PremiumCost == 0 {
+		return fmt.Errorf("premiumCost must be positive when tiers have premium rewards")
+	}
+	return nil
+}
+
+// Boosters returns the IDs of the boosters the season's tiers grant, sorted.
+func (s Season) Boosters() []string {
+	ids := map[string]bool{}
+	for _, t := range s.Tiers {
+		for id := range t.Free.Boosters {
+			ids[id] = true
+		}
+		for id := range t.Premium.Boosters {
+			ids[id] = true
+		}
+	}
+	out := make([]string, 0, len(ids))
+	for id := range ids {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SeasonSchedule is every configured season, in any order.
+type SeasonSchedule []Season
+
+// At returns the season running at t.
+func (ss SeasonSchedule) At(t time.Time) (Season, bool) {
+	for _, s := range ss {
+		if s.Contains(t) {
+			return s, true
+		}
+	}
+	return Season{}, false
+}
+
+// Validate checks every season and that seasons have unique IDs and don't overlap.
+func (ss SeasonSchedule) Validate() error {
+	ids := map[string]bool{}
+	for _, s := range ss {
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("season %q: %v", s.ID, err)
+		}
+		if ids[s.ID] {
+			return fmt.Errorf("duplicate season %q", s.ID)
+		}
+		ids[s.ID] = true
+	}
+	for i, a := range ss {
+		aStart, aEnd, _ := a.Window()
+		for _, b := range ss[i+1:] {
+			bStart, bEnd, _ := b.Window()
+			if aStart.Before(bEnd) && bStart.Before(aEnd) {
+				return fmt.Errorf("seasons %q and %q overlap", a.ID, b.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// SeasonProgress is a user's progress in one season.
+type SeasonProgress struct {
+	UserID         string `json:"userId" dynamodbav:"userId"`     // Partition Key
+	SeasonID       string `json:"seasonId" dynamodbav:"seasonId"` // Sort Key
+	XP             int    `json:"xp" dynamodbav:"xp"`
+	Premium        bool   `json:"premium" dynamodbav:"premium"`
+	ClaimedFree    []int  `json:"claimedFree,omitempty" dynamodbav:"claimedFree,numberset,omitempty"`       // Tiers whose free reward was claimed
+	ClaimedPremium []int  `json:"claimedPremium,omitempty" dynamodbav:"claimedPremium,numberset,omitempty"` // Tiers whose premium reward was claimed
+}
+
+// Claimed reports whether a tier's reward on a track was claimed.
+func (p SeasonProgress) Claimed(track string, tier int) bool {
+	claimed := p.ClaimedFree
+	if track == SeasonTrackPremium {
+		claimed = p.ClaimedPremium
+	}
+	for _, n := range claimed {
+		if n == tier {
+			return true
+		}
+	}
+	return false
+}
+
+// SeasonTierStatus is one tier of a season pass as shown to a user.
+type SeasonTierStatus struct {
+	Tier           int          `json:"tier"`
+	XP             int          `json:"xp"`
+	Reached        bool         `json:"reached"`
+	Free           RewardBundle `json:"free"`
+	FreeClaimed    bool         `json:"freeClaimed"`
+	Premium        RewardBundle `json:"premium"`
+	PremiumClaimed bool         `json:"premiumClaimed"`
+}
+
+// SeasonStatus is a user's view of the running season.
+type SeasonStatus struct {
+	SeasonID    string             `json:"seasonId"`
+	Name        string             `json:"name"`
+	EndsAt      string             `json:"endsAt"`
+	XP          int                `json:"xp"`
+	Tier        int                `json:"tier"` // Tiers reached
+	Premium     bool               `json:"premium"`
+	PremiumCost int                `json:"premiumCost"`
+	Tiers       []SeasonTierStatus `json:"tiers"`
+}
+
+// SeasonClaim is the outcome of claiming a season pass reward.
+type SeasonClaim struct {
+	SeasonID string       `json:"seasonId"`
+	Tier     int          `json:"tier"`
+	Track    string       `json:"track"`
+	Reward   RewardBundle `json:"reward"`
+}
@@ -1,11 +1,24 @@
 package models
 
+// GroupCapacity is the maximum number of users placed in one tournament group.
+const GroupCapacity = 35
+
+// EntryFee is the number of coins a user pays to enter a tournament.
+const EntryFee = 500
+
+// MinEntryLevel is the lowest level at which a user can enter a tournament.
+const MinEntryLevel = 10
+
 // Tournament represents a daily tournament.
 type Tournament struct {
-	TournamentID      string `json:"tournamentId" dynamodbav:"tournamentId"`
-	StartTime         string `json:"startTime" dynamodbav:"startTime"`
-	EndTime           string `json:"endTime" dynamodbav:"endTime"`
-	Active            bool   `json:"active" dynamodbav:"active"`
-	CurrentGroupIndex int    `json:"currentGroupIndex" dynamodbav:"currentGroupIndex"`
-	CurrentGroupCount int    `json:"currentGroupCount" dynamodbav:"currentGroupCount"`
+	TournamentID string `json:"tournamentId" dynamodbav:"tournamentId"`
+	StartTime    string `json:"startTime" dynamodbav:"startTime"`
+	EndTime      string `json:"endTime" dynamodbav:"endTime"`
+	Active       bool   `json:"active" dynamodbav:"active"`
+	Cancelled    bool   `json:"cancelled,omitempty" dynamodbav:"cancelled,omitempty"`     // Voided by an operator; no rewards are paid
+	RefundsDone  bool   `json:"refundsDone,omitempty" dynamodbav:"refundsDone,omitempty"` // Every entrant of a cancelled tournament got the entry fee back
+	SeatShards   int    `json:"seatShards" dynamodbav:"seatShards"`                       // Number of independent seat counters used to assign groups
+	// ClaimDeadline (RFC3339) is set when the tournament ends; unclaimed rewards expire after it.
+	// Tournaments ended before claim windows existed have none and never expire.
+	ClaimDeadline string `json:"claimDeadline,omitempty" dynamodbav:"claimDeadline,omitempty"`
 }
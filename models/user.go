@@ -8,4 +8,11 @@ type User struct {
 	Coins    int    `json:"coins" dynamodbav:"coins"`                         // User's coin balance
 	Country  string `json:"country,omitempty" dynamodbav:"country,omitempty"` // Optional ISO country code
 	GlobalPK string `json:"globalPK" dynamodbav:"globalPK"`                   // Global Leaderboard Partition Key
+
+	Inventory        // Boosters, lives, currencies and cosmetics
+	CheckInState     // Daily check-in streak
+	AchievementStats // Lifetime counters achievements are measured on
+	LeagueState      // Place on the league ladder
+	ClanState        // Clan the user belongs to
+	FriendState      // Number of accepted friends
 }
@@ -0,0 +1,159 @@
+// services/achievement_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"good_blast/database"
+	"good_blast/errors"
+	"good_blast/models"
+)
+
+// AchievementService implements AchievementServiceInterface and AchievementTracker.
+type AchievementService struct {
+	DB           database.DatabaseInterface
+	Achievements models.AchievementList
+}
+
+// NewAchievementService creates a new instance of AchievementService with the default achievements.
+func NewAchievementService(db database.DatabaseInterface) *AchievementService {
+	return &AchievementService{
+		DB:           db,
+		Achievements: models.DefaultAchievements,
+	}
+}
+
+// unlock records achievements as unlocked now. Failures are logged; GetAchievements unlocks
+// anything reached but not recorded.
+func (s *AchievementService) unlock(ctx context.Context, userID string, achievements []models.AchievementDefinition, now time.Time) {
+	for _, a := range achievements {
+		err := s.DB.UnlockAchievement(ctx, models.UnlockedAchievement{
+			UserID:        userID,
+			AchievementID: a.ID,
+			UnlockedAt:    now.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			log.Printf("Error unlocking achievement %s for user %s: %v", a.ID, userID, err)
+			continue
+		}
+		log.Printf("User %s unlocked achievement %s", userID, a.ID)
+	}
+}
+
+// addStats adds delta to the user's stats and returns their progress without and with it.
+// Failures are logged and reported as !ok.
+func (s *AchievementService) addStats(ctx context.Context, userID string, delta models.AchievementStats) (before, after models.AchievementProgress, ok bool) {
+	user, err := s.DB.AddAchievementStats(ctx, userID, delta)
+	if err != nil {
+		log.Printf("Error recording achievement stats for user %s: %v", userID, err)
+		return before, after, false
+	}
+	after = models.ProgressOf(*user)
+	before = after
+	before.TournamentWins -= delta.TournamentWins
+	before.PodiumFinishes -= delta.PodiumFinishes
+	before.CoinsEarned -= delta.CoinsEarned
+	return before, after, true
+}
+
+// LevelReached evaluates achievements after user, as updated, progressed from fromLevel and was
+// paid reward for it. The reward's coins were counted by the write that paid them.
+func (s *AchievementService) LevelReached(ctx context.Context, user models.User, fromLevel int, reward models.RewardBundle) {
+	after := models.ProgressOf(user)
+	before := after
+	before.Level = fromLevel
+	before.CoinsEarned -= reward.Coins
+	s.unlock(ctx, user.UserID, s.Achievements.Crossed(before, after), time.Now())
+}
+
+// TournamentRewardPaid evaluates achievements after a tournament reward was paid for a rank. The
+// reward's coins were counted by the claim that paid them.
+func (s *AchievementService) TournamentRewardPaid(ctx context.Context, userID string, rank int, reward models.RewardBundle) {
+	var delta models.AchievementStats
+	if rank == 1 {
+		delta.TournamentWins = 1
+	}
+	if rank >= 1 && rank <= 3 {
+		delta.PodiumFinishes = 1
+	}
+
+	var before, after models.AchievementProgress
+	switch {
+	case !delta.IsEmpty():
+		var ok bool
+		if before, after, ok = s.addStats(ctx, userID, delta); !ok {
+			return
+		}
+	case reward.Coins > 0:
+		user, err := s.DB.GetUser(ctx, userID)
+		if err != nil || user == nil {
+			log.Printf("Error fetching user %s for achievements: %v", userID, err)
+			return
+		}
+		after = models.ProgressOf(*user)
+		before = after
+	default:
+		return
+	}
+	before.CoinsEarned -= reward.Coins
+	s.unlock(ctx, userID, s.Achievements.Crossed(before, after), time.Now())
+}
+
+// GetAchievements lists every achievement with the user's progress towards it. Achievements
+// reached but not recorded yet, e.g. because recording failed or the achievement is new, are
+// unlocked on the way.
+func (s *AchievementService) GetAchievements(ctx context.Context, userID string) (*models.UserAchievements, error) {
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching user:", err)
+		return nil, fmt.Errorf("could not fetch user data: %w", err)
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	stored, err := s.DB.QueryUserAchievements(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching achievements:", err)
+		return nil, fmt.Errorf("could not fetch achievements: %w", err)
+	}
+	unlockedAt := make(map[string]string, len(stored))
+	for _, a := range stored {
+		unlockedAt[a.AchievementID] = a.UnlockedAt
+	}
+
+	now := time.Now()
+	progress := models.ProgressOf(*user)
+	var missing []models.AchievementDefinition
+	for _, a := range s.Achievements.Reached(progress) {
+		if _, ok := unlockedAt[a.ID]; !ok {
+			missing = append(missing, a)
+			unlockedAt[a.ID] = now.UTC().Format(time.RFC3339)
+		}
+	}
+	s.unlock(ctx, userID, missing, now)
+
+	result := &models.UserAchievements{UserID: userID, Achievements: make([]models.Achievement, 0, len(s.Achievements))}
+	for _, a := range s.Achievements {
+		achievement := models.Achievement{
+			ID:          a.ID,
+			Name:        a.Name,
+			Description: a.Description,
+			Progress:    progress.Value(a.Metric),
+			Target:      a.Target,
+			UnlockedAt:  unlockedAt[a.ID],
+		}
+		achievement.Unlocked = achievement.UnlockedAt != ""
+		if achievement.Progress > a.Target {
+			achievement.Progress = a.Target
+		}
+		if achievement.Unlocked {
+			result.Unlocked++
+		}
+		result.Achievements = append(result.Achievements, achievement)
+	}
+	return result, nil
+}
@@ -0,0 +1,149 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	apperrors "good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+	"good_blast/services/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// unlocked matches an UnlockedAchievement by user and achievement.
+func unlocked(userID, achievementID string) interface{} {
+	return mock.MatchedBy(func(a models.UnlockedAchievement) bool {
+		return a.UserID == userID && a.AchievementID == achievementID && a.UnlockedAt != ""
+	})
+}
+
+func TestTournamentRewardPaid_FirstWinUnlocksChampion(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	achievementService := services.NewAchievementService(mockDB)
+
+	delta := models.AchievementStats{TournamentWins: 1, PodiumFinishes: 1}
+	mockDB.On("AddAchievementStats", mock.Anything, "u1", delta).Return(&models.User{
+		UserID:           "u1",
+		Level:            40,
+		AchievementStats: models.AchievementStats{TournamentWins: 1, PodiumFinishes: 4, CoinsEarned: 12000},
+	}, nil).Once()
+	// The claim counted the 5000 coins, from 7000 past the 10,000 mark; the podium count is still short of 10
+	mockDB.On("UnlockAchievement", mock.Anything, unlocked("u1", "first_tournament_win")).Return(nil).Once()
+	mockDB.On("UnlockAchievement", mock.Anything, unlocked("u1", "coins_10000")).Return(nil).Once()
+
+	achievementService.TournamentRewardPaid(context.Background(), "u1", 1, models.RewardBundle{Coins: 5000})
+	mockDB.AssertExpectations(t)
+}
+
+func TestTournamentRewardPaid_SecondWinUnlocksNothing(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	achievementService := services.NewAchievementService(mockDB)
+
+	mockDB.On("AddAchievementStats", mock.Anything, "u1", models.AchievementStats{PodiumFinishes: 1}).Return(&models.User{
+		UserID:           "u1",
+		AchievementStats: models.AchievementStats{TournamentWins: 2, PodiumFinishes: 9, CoinsEarned: 90000},
+	}, nil).Once()
+
+	achievementService.TournamentRewardPaid(context.Background(), "u1", 2, models.RewardBundle{Coins: 3000})
+	mockDB.AssertNotCalled(t, "UnlockAchievement", mock.Anything, mock.Anything)
+
+	// Ranks outside the podium that pay nothing count for nothing
+	achievementService.TournamentRewardPaid(context.Background(), "u1", 8, models.RewardBundle{})
+	mockDB.AssertExpectations(t)
+}
+
+func TestTournamentRewardPaid_CoinsOutsideThePodium(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	achievementService := services.NewAchievementService(mockDB)
+
+	// No win or podium to add; the claim already counted the coins on the user
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:           "u1",
+		AchievementStats: models.AchievementStats{PodiumFinishes: 2, CoinsEarned: 10200},
+	}, nil).Once()
+	mockDB.On("UnlockAchievement", mock.Anything, unlocked("u1", "coins_10000")).Return(nil).Once()
+
+	achievementService.TournamentRewardPaid(context.Background(), "u1", 5, models.RewardBundle{Coins: 500})
+	mockDB.AssertNotCalled(t, "AddAchievementStats", mock.Anything, mock.Anything, mock.Anything)
+	mockDB.AssertExpectations(t)
+}
+
+func TestLevelReached(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	achievementService := services.NewAchievementService(mockDB)
+	// As updated: the level reward's 200 coins are already counted
+	user := models.User{UserID: "u1", Level: 101, AchievementStats: models.AchievementStats{CoinsEarned: 10100}}
+
+	mockDB.On("UnlockAchievement", mock.Anything, unlocked("u1", "level_100")).Return(nil).Once()
+	mockDB.On("UnlockAchievement", mock.Anything, unlocked("u1", "coins_10000")).Return(nil).Once()
+
+	achievementService.LevelReached(context.Background(), user, 99, models.RewardBundle{Coins: 200})
+	mockDB.AssertNotCalled(t, "AddAchievementStats", mock.Anything, mock.Anything, mock.Anything)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetAchievements_UnlocksReachedButUnrecorded(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	achievementService := services.NewAchievementService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:           "u1",
+		Level:            120,
+		AchievementStats: models.AchievementStats{TournamentWins: 1, PodiumFinishes: 3},
+	}, nil)
+	mockDB.On("QueryUserAchievements", mock.Anything, "u1").Return([]models.UnlockedAchievement{
+		{UserID: "u1", AchievementID: "first_tournament_win", UnlockedAt: "2024-11-02T10:00:00Z"},
+	}, nil)
+	mockDB.On("UnlockAchievement", mock.Anything, unlocked("u1", "level_100")).Return(nil).Once()
+
+	result, err := achievementService.GetAchievements(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Unlocked)
+	assert.Len(t, result.Achievements, len(models.DefaultAchievements))
+
+	byID := map[string]models.Achievement{}
+	for _, a := range result.Achievements {
+		byID[a.ID] = a
+	}
+	assert.Equal(t, "2024-11-02T10:00:00Z", byID["first_tournament_win"].UnlockedAt)
+	assert.True(t, byID["level_100"].Unlocked)
+	assert.Equal(t, 100, byID["level_100"].Progress) // capped at the target
+	assert.Equal(t, 3, byID["podium_10"].Progress)
+	assert.False(t, byID["podium_10"].Unlocked)
+	mockDB.AssertExpectations(t)
+
+	mockDB.On("GetUser", mock.Anything, "ghost").Return(nil, nil)
+	_, err = achievementService.GetAchievements(context.Background(), "ghost")
+	assert.Equal(t, apperrors.ErrUserNotFound, err)
+}
+
+func TestSettleTournament_EvaluatesAchievements(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	tournamentService := services.NewTournamentService(mockDB)
+	tournamentService.Achievements = services.NewAchievementService(mockDB)
+
+	tID := "2024-01-02"
+	group := tID + "-group-0-1"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID}, nil)
+	mockDB.On("QueryTournamentEntries", mock.Anything, tID, models.PageRequest{}).Return(models.Page[models.TournamentEntry]{
+		Items: []models.TournamentEntry{{TournamentID: tID, UserID: "u1", GroupID: group, Score: 30}},
+	}, nil).Once()
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, group).Return([]models.TournamentEntry{
+		{UserID: "u1", Score: 30},
+	}, nil).Once()
+	mockDB.On("ClaimRewardTransaction", mock.Anything, "u1", models.RewardBundle{Coins: 5000}, tID).Return(nil).Once()
+
+	// A reward paid by settlement counts like one the user claimed
+	delta := models.AchievementStats{TournamentWins: 1, PodiumFinishes: 1}
+	mockDB.On("AddAchievementStats", mock.Anything, "u1", delta).Return(&models.User{
+		UserID:           "u1",
+		AchievementStats: models.AchievementStats{TournamentWins: 2, PodiumFinishes: 2, CoinsEarned: 6000},
+	}, nil).Once()
+
+	_, err := tournamentService.SettleTournament(context.Background(), tID)
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+}
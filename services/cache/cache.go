@@ -0,0 +1,28 @@
+// services/cache/cache.go
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a byte-oriented key/value cache with per-key expiry.
+// Implementations must be safe for concurrent use. A cache is an optimisation only:
+// callers treat any error as a miss and fall back to the source of truth.
+type Cache interface {
+	// Get returns the cached value and true, or nil and false on a miss.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl. A ttl <= 0 means the value does not expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the given keys. Missing keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+	// Close releases any underlying connection.
+	Close() error
+}
+
+// PrefixDeleter is implemented by caches that can drop every key with a given prefix.
+// Used for operator flushes; it may be slow on large caches.
+type PrefixDeleter interface {
+	// DeletePrefix removes all keys starting with prefix and returns how many were removed.
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_SetGetDelete(t *testing.T) {
+	c := NewLRU(10)
+	ctx := context.Background()
+
+	assert.NoError(t, c.Set(ctx, "k", []byte("v"), time.Minute))
+	val, found, err := c.Get(ctx, "k")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("v"), val)
+
+	assert.NoError(t, c.Delete(ctx, "k", "missing"))
+	_, found, _ = c.Get(ctx, "k")
+	assert.False(t, found)
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(2)
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	c.Get(ctx, "a") // a is now most recently used
+	c.Set(ctx, "c", []byte("3"), 0)
+
+	_, foundA, _ := c.Get(ctx, "a")
+	_, foundB, _ := c.Get(ctx, "b")
+	_, foundC, _ := c.Get(ctx, "c")
+	assert.True(t, foundA)
+	assert.False(t, foundB)
+	assert.True(t, foundC)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRU_Expiry(t *testing.T) {
+	c := NewLRU(10)
+	now := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	c.Set(ctx, "k", []byte("v"), 60*time.Second)
+
+	now = now.Add(59 * time.Second)
+	_, found, _ := c.Get(ctx, "k")
+	assert.True(t, found)
+
+	now = now.Add(time.Second)
+	_, found, _ = c.Get(ctx, "k")
+	assert.False(t, found)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_ReturnsCopies(t *testing.T) {
+	c := NewLRU(10)
+	ctx := context.Background()
+	src := []byte("abc")
+
+	c.Set(ctx, "k", src, 0)
+	src[0] = 'x'
+	val, _, _ := c.Get(ctx, "k")
+	val[1] = 'y'
+
+	again, _, _ := c.Get(ctx, "k")
+	assert.Equal(t, []byte("abc"), again)
+}
+
+func TestLRU_DeletePrefix(t *testing.T) {
+	c := NewLRU(10)
+	ctx := context.Background()
+	c.Set(ctx, "leaderboard:global", []byte("g"), 0)
+	c.Set(ctx, "leaderboard:country:TR", []byte("tr"), 0)
+	c.Set(ctx, "session:1", []byte("s"), 0)
+
+	n, err := c.DeletePrefix(ctx, "leaderboard:")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 1, c.Len())
+	_, found, _ := c.Get(ctx, "session:1")
+	assert.True(t, found)
+}
@@ -0,0 +1,45 @@
+// services/cache/noop.go
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Noop is a Cache that stores nothing; every Get is a miss.
+type Noop struct{}
+
+var (
+	_ Cache         = Noop{}
+	_ PrefixDeleter = Noop{}
+)
+
+// NewNoop creates a cache that never caches.
+func NewNoop() Noop {
+	return Noop{}
+}
+
+// Get always reports a miss.
+func (Noop) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+// Set discards the value.
+func (Noop) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+// Delete does nothing.
+func (Noop) Delete(ctx context.Context, keys ...string) error {
+	return nil
+}
+
+// DeletePrefix does nothing.
+func (Noop) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	return 0, nil
+}
+
+// Close does nothing.
+func (Noop) Close() error {
+	return nil
+}
@@ -0,0 +1,95 @@
+// services/cache/redis.go
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Cache backed by a Redis server.
+type Redis struct {
+	client *redis.Client
+}
+
+var (
+	_ Cache         = (*Redis)(nil)
+	_ PrefixDeleter = (*Redis)(nil)
+)
+
+// NewRedis wraps an existing Redis client. The cache takes ownership and closes it on Close.
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{client: client}
+}
+
+// Get fetches key from Redis. redis.Nil is reported as a miss, not an error.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	val, err := r.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("redis get %s: %v", key, err)
+	}
+	return val, true, nil
+}
+
+// Set stores key in Redis with the given expiry.
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	if err := r.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("redis set %s: %v", key, err)
+	}
+	return nil
+}
+
+// Delete removes keys from Redis.
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("redis del: %v", err)
+	}
+	return nil
+}
+
+// DeletePrefix removes matching keys using SCAN, so Redis is never blocked by KEYS.
+func (r *Redis) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	deleted := 0
+	iter := r.client.Scan(ctx, 0, prefix+"*", 500).Iterator()
+	batch := make([]string, 0, 500)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := r.client.Del(ctx, batch...).Result()
+		if err != nil {
+			return fmt.Errorf("redis del: %v", err)
+		}
+		deleted += int(n)
+		batch = batch[:0]
+		return nil
+	}
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == cap(batch) {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, fmt.Errorf("redis scan %s*: %v", prefix, err)
+	}
+	return deleted, flush()
+}
+
+// Close closes the Redis connection pool.
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,181 @@
+// services/checkin_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"good_blast/database"
+	"good_blast/errors"
+	"good_blast/models"
+)
+
+// Defaults for restoring a lapsed check-in streak.
+const (
+	DefaultStreakRestoreCost = 200 // coins per missed day
+	DefaultMaxRestoreDays    = 3
+)
+
+// dayFormat names UTC days, like tournament IDs, so check-in days roll over with tournaments.
+const dayFormat = "2006-01-02"
+
+// CheckInService implements CheckInServiceInterface.
+type CheckInService struct {
+	DB             database.DatabaseInterface
+	Calendar       models.CheckInCalendar
+	RestoreCost    int // coins per missed day to restore a lapsed streak; 0 disables restores
+	MaxRestoreDays int // most missed days a streak can be restored across
+}
+
+// NewCheckInService creates a new instance of CheckInService with the default calendar.
+func NewCheckInService(db database.DatabaseInterface) *CheckInService {
+	return &CheckInService{
+		DB:             db,
+		Calendar:       models.DefaultCheckInCalendar,
+		RestoreCost:    DefaultStreakRestoreCost,
+		MaxRestoreDays: DefaultMaxRestoreDays,
+	}
+}
+
+// missedDays returns how many days were missed between the latest check-in and today; 0 when
+// the streak is still alive or the user never checked in.
+func missedDays(state models.CheckInState, today time.Time) int {
+	if state.LastCheckIn == "" {
+		return 0
+	}
+	last, err := time.Parse(dayFormat, state.LastCheckIn)
+	if err != nil {
+		return 0
+	}
+	if missed := int(today.Sub(last).Hours()/24) - 1; missed > 0 {
+		return missed
+	}
+	return 0
+}
+
+// status describes a user's streak as of today.
+func (s *CheckInService) status(state models.CheckInState, today time.Time) *models.CheckInStatus {
+	status := &models.CheckInStatus{
+		Streak:         state.CheckInStreak,
+		LastCheckIn:    state.LastCheckIn,
+		CheckedInToday: state.LastCheckIn == today.Format(dayFormat),
+		Calendar:       s.Calendar,
+	}
+	if missed := missedDays(state, today); missed > 0 {
+		status.Streak = 0
+		if s.RestoreCost > 0 && missed <= s.MaxRestoreDays {
+			status.LapsedStreak = state.CheckInStreak
+			status.MissedDays = missed
+			status.RestoreCost = s.RestoreCost * missed
+		}
+	}
+	next := status.Streak + 1
+	status.NextDay = s.Calendar.Day(next)
+	status.NextReward = s.Calendar.ForStreak(next)
+	return status
+}
+
+// getUser fetches a user, returning ErrUserNotFound when there is none.
+func (s *CheckInService) getUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching user:", err)
+		return nil, fmt.Errorf("could not fetch user data: %w", err)
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// GetCheckIn returns the user's check-in streak as of today.
+func (s *CheckInService) GetCheckIn(ctx context.Context, userID string) (*models.CheckInStatus, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.status(user.CheckInState, time.Now().UTC()), nil
+}
+
+// CheckIn claims today's reward. Checking in the day after the latest check-in extends the
+// streak; after a missed day the streak starts over at day 1 of the calendar.
+func (s *CheckInService) CheckIn(ctx context.Context, userID string) (*models.CheckInResult, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Now().UTC()
+	from := user.CheckInState
+	if from.LastCheckIn == today.Format(dayFormat) {
+		return nil, errors.ErrAlreadyCheckedIn
+	}
+
+	to := models.CheckInState{CheckInStreak: 1, LastCheckIn: today.Format(dayFormat)}
+	if from.LastCheckIn != "" && missedDays(from, today) == 0 {
+		to.CheckInStreak = from.CheckInStreak + 1
+	}
+	reward := s.Calendar.ForStreak(to.CheckInStreak)
+
+	entry := models.HistoryEntry{
+		UserID: userID,
+		Type:   models.HistoryCheckIn,
+		Coins:  reward.Coins,
+		Reason: fmt.Sprintf("check-in day %d", s.Calendar.Day(to.CheckInStreak)),
+		Actor:  userID,
+	}
+	items := reward
+	items.Coins = 0
+	if !items.IsEmpty() {
+		entry.Bundle = &items
+	}
+	if err := s.DB.CheckInTransaction(ctx, entry, from, to); err != nil {
+		log.Println("Error checking in:", err)
+		return nil, err
+	}
+
+	return &models.CheckInResult{
+		Streak: to.CheckInStreak,
+		Day:    s.Calendar.Day(to.CheckInStreak),
+		Reward: reward,
+		Coins:  user.Coins + reward.Coins,
+	}, nil
+}
+
+// RestoreStreak pays coins to keep a streak that lapsed at most MaxRestoreDays days ago, as if
+// the user had checked in yesterday. Missed days pay nothing; today's check-in then continues
+// the streak.
+func (s *CheckInService) RestoreStreak(ctx context.Context, userID string) (*models.CheckInStatus, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Now().UTC()
+	from := user.CheckInState
+	missed := missedDays(from, today)
+	if missed == 0 {
+		return nil, errors.ErrStreakNotLapsed
+	}
+	if s.RestoreCost <= 0 || missed > s.MaxRestoreDays {
+		return nil, errors.ErrStreakNotRestorable
+	}
+
+	cost := s.RestoreCost * missed
+	to := models.CheckInState{
+		CheckInStreak: from.CheckInStreak,
+		LastCheckIn:   today.AddDate(0, 0, -1).Format(dayFormat),
+	}
+	entry := models.HistoryEntry{
+		UserID: userID,
+		Type:   models.HistoryCheckInRestore,
+		Coins:  -cost,
+		Reason: fmt.Sprintf("restored a %d-day streak after missing %d days", from.CheckInStreak, missed),
+		Actor:  userID,
+	}
+	if err := s.DB.CheckInTransaction(ctx, entry, from, to); err != nil {
+		log.Println("Error restoring check-in streak:", err)
+		return nil, err
+	}
+	return s.status(to, today), nil
+}
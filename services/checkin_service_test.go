@@ -0,0 +1,167 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apperrors "good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+	"good_blast/services/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// daysAgo returns the UTC day n days before today, formatted like tournament IDs.
+func daysAgo(n int) string {
+	return time.Now().UTC().AddDate(0, 0, -n).Format("2006-01-02")
+}
+
+func TestCheckIn_FirstDay(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	checkInService := services.NewCheckInService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Coins: 1000}, nil).Once()
+	mockDB.On("CheckInTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.Type == models.HistoryCheckIn && e.Coins == 100 && e.Bundle == nil
+	}), models.CheckInState{}, models.CheckInState{CheckInStreak: 1, LastCheckIn: daysAgo(0)}).Return(nil).Once()
+
+	result, err := checkInService.CheckIn(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Streak)
+	assert.Equal(t, 1, result.Day)
+	assert.Equal(t, 1100, result.Coins)
+	mockDB.AssertExpectations(t)
+}
+
+func TestCheckIn_ExtendsStreakAndWrapsCalendar(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	checkInService := services.NewCheckInService(mockDB)
+
+	// Day 7 pays coins and a life; the 8th day starts the calendar over
+	from := models.CheckInState{CheckInStreak: 6, LastCheckIn: daysAgo(1)}
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Coins: 0, CheckInState: from}, nil).Once()
+	mockDB.On("CheckInTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.Coins == 500 && e.Bundle != nil && e.Bundle.Lives == 1
+	}), from, models.CheckInState{CheckInStreak: 7, LastCheckIn: daysAgo(0)}).Return(nil).Once()
+
+	result, err := checkInService.CheckIn(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, result.Day)
+
+	from = models.CheckInState{CheckInStreak: 7, LastCheckIn: daysAgo(1)}
+	mockDB.On("GetUser", mock.Anything, "u2").Return(&models.User{UserID: "u2", CheckInState: from}, nil).Once()
+	mockDB.On("CheckInTransaction", mock.Anything, mock.Anything, from, models.CheckInState{CheckInStreak: 8, LastCheckIn: daysAgo(0)}).Return(nil).Once()
+
+	result, err = checkInService.CheckIn(context.Background(), "u2")
+	assert.NoError(t, err)
+	assert.Equal(t, 8, result.Streak)
+	assert.Equal(t, 1, result.Day)
+	assert.Equal(t, models.RewardBundle{Coins: 100}, result.Reward)
+	mockDB.AssertExpectations(t)
+}
+
+func TestCheckIn_AfterMissedDayStartsOver(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	checkInService := services.NewCheckInService(mockDB)
+
+	from := models.CheckInState{CheckInStreak: 4, LastCheckIn: daysAgo(2)}
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", CheckInState: from}, nil).Once()
+	mockDB.On("CheckInTransaction", mock.Anything, mock.Anything, from, models.CheckInState{CheckInStreak: 1, LastCheckIn: daysAgo(0)}).Return(nil).Once()
+
+	result, err := checkInService.CheckIn(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Streak)
+	mockDB.AssertExpectations(t)
+}
+
+func TestCheckIn_OncePerDay(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	checkInService := services.NewCheckInService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:       "u1",
+		CheckInState: models.CheckInState{CheckInStreak: 3, LastCheckIn: daysAgo(0)},
+	}, nil)
+
+	_, err := checkInService.CheckIn(context.Background(), "u1")
+	assert.Equal(t, apperrors.ErrAlreadyCheckedIn, err)
+
+	status, err := checkInService.GetCheckIn(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.True(t, status.CheckedInToday)
+	assert.Equal(t, 3, status.Streak)
+	assert.Equal(t, 4, status.NextDay)
+	assert.Equal(t, models.RewardBundle{Coins: 250}, status.NextReward)
+
+	mockDB.AssertNotCalled(t, "CheckInTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetCheckIn_LapsedStreakOffersRestore(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	checkInService := services.NewCheckInService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:       "u1",
+		CheckInState: models.CheckInState{CheckInStreak: 5, LastCheckIn: daysAgo(3)},
+	}, nil)
+
+	status, err := checkInService.GetCheckIn(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, status.Streak)
+	assert.Equal(t, 1, status.NextDay)
+	assert.Equal(t, 5, status.LapsedStreak)
+	assert.Equal(t, 2, status.MissedDays)
+	assert.Equal(t, 400, status.RestoreCost)
+}
+
+func TestRestoreStreak(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	checkInService := services.NewCheckInService(mockDB)
+
+	from := models.CheckInState{CheckInStreak: 5, LastCheckIn: daysAgo(3)}
+	to := models.CheckInState{CheckInStreak: 5, LastCheckIn: daysAgo(1)}
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Coins: 1000, CheckInState: from}, nil).Once()
+	mockDB.On("CheckInTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.Type == models.HistoryCheckInRestore && e.Coins == -400
+	}), from, to).Return(nil).Once()
+
+	status, err := checkInService.RestoreStreak(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, status.Streak)
+	assert.Equal(t, 6, status.NextDay)
+	assert.False(t, status.CheckedInToday)
+	mockDB.AssertExpectations(t)
+}
+
+func TestRestoreStreak_Rejected(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	checkInService := services.NewCheckInService(mockDB)
+	ctx := context.Background()
+
+	mockDB.On("GetUser", mock.Anything, "alive").Return(&models.User{
+		UserID:       "alive",
+		CheckInState: models.CheckInState{CheckInStreak: 2, LastCheckIn: daysAgo(1)},
+	}, nil)
+	_, err := checkInService.RestoreStreak(ctx, "alive")
+	assert.Equal(t, apperrors.ErrStreakNotLapsed, err)
+
+	mockDB.On("GetUser", mock.Anything, "old").Return(&models.User{
+		UserID:       "old",
+		CheckInState: models.CheckInState{CheckInStreak: 9, LastCheckIn: daysAgo(5)},
+	}, nil)
+	_, err = checkInService.RestoreStreak(ctx, "old")
+	assert.Equal(t, apperrors.ErrStreakNotRestorable, err)
+
+	from := models.CheckInState{CheckInStreak: 2, LastCheckIn: daysAgo(2)}
+	mockDB.On("GetUser", mock.Anything, "poor").Return(&models.User{UserID: "poor", Coins: 50, CheckInState: from}, nil)
+	mockDB.On("CheckInTransaction", mock.Anything, mock.Anything, from, mock.Anything).Return(apperrors.ErrNotEnoughCoins).Once()
+	_, err = checkInService.RestoreStreak(ctx, "poor")
+	assert.Equal(t, apperrors.ErrNotEnoughCoins, err)
+
+	checkInService.RestoreCost = 0
+	_, err = checkInService.RestoreStreak(ctx, "poor")
+	assert.Equal(t, apperrors.ErrStreakNotRestorable, err)
+}
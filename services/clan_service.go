@@ -0,0 +1,268 @@
+// services/clan_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"good_blast/database"
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/google/uuid"
+)
+
+// ClanService implements ClanServiceInterface and ClanTracker.
+type ClanService struct {
+	DB         database.DatabaseInterface
+	MaxMembers int // most members a clan can have, leader included
+}
+
+// NewClanService creates a new instance of ClanService with the default clan size.
+func NewClanService(db database.DatabaseInterface) *ClanService {
+	return &ClanService{
+		DB:         db,
+		MaxMembers: models.DefaultClanMaxMembers,
+	}
+}
+
+// user fetches a user, returning ErrUserNotFound when they don't exist.
+func (s *ClanService) user(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching user:", err)
+		return nil, fmt.Errorf("could not fetch user data: %w", err)
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// member fetches a user's membership in a clan, returning notFound when they are not a member.
+func (s *ClanService) member(ctx context.Context, clanID, userID string, notFound error) (*models.ClanMember, error) {
+	member, err := s.DB.GetClanMember(ctx, clanID, userID)
+	if err != nil {
+		log.Println("Error fetching clan member:", err)
+		return nil, fmt.Errorf("could not fetch clan member: %w", err)
+	}
+	if member == nil {
+		return nil, notFound
+	}
+	return member, nil
+}
+
+// CreateClan creates a clan led by the user. The clan's country defaults to the leader's.
+func (s *ClanService) CreateClan(ctx context.Context, userID, name, country string) (*models.ClanDetails, error) {
+	name = strings.TrimSpace(name)
+	if n := utf8.RuneCountInString(name); n < models.MinClanNameLength || n > models.MaxClanNameLength {
+		return nil, errors.ErrInvalidClanName
+	}
+
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ClanID != "" {
+		return nil, errors.ErrAlreadyInClan
+	}
+	if country == "" {
+		country = user.Country
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	clan := models.Clan{
+		ClanID:      uuid.New().String(),
+		Name:        name,
+		NameKey:     models.ClanNameKey(name),
+		Country:     country,
+		LeaderID:    userID,
+		MemberCount: 1,
+		TotalLevel:  user.Level,
+		ClanPK:      models.ClanGlobalPK,
+		CreatedAt:   now,
+	}
+	leader := models.ClanMember{
+		ClanID:   clan.ClanID,
+		UserID:   userID,
+		Username: user.Username,
+		Role:     models.ClanRoleLeader,
+		Level:    user.Level,
+		JoinedAt: now,
+	}
+	if err := s.DB.CreateClanTransaction(ctx, clan, leader); err != nil {
+		log.Println("Error creating clan:", err)
+		return nil, err
+	}
+
+	return &models.ClanDetails{Clan: clan, MaxMembers: s.MaxMembers, Members: []models.ClanMember{leader}}, nil
+}
+
+// GetClan returns a clan with its members, highest level first.
+func (s *ClanService) GetClan(ctx context.Context, clanID string) (*models.ClanDetails, error) {
+	clan, err := s.DB.GetClan(ctx, clanID)
+	if err != nil {
+		log.Println("Error fetching clan:", err)
+		return nil, fmt.Errorf("could not fetch clan: %w", err)
+	}
+	if clan == nil {
+		return nil, errors.ErrClanNotFound
+	}
+
+	members, err := s.DB.QueryClanMembers(ctx, clanID)
+	if err != nil {
+		log.Println("Error fetching clan members:", err)
+		return nil, fmt.Errorf("could not fetch clan members: %w", err)
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].Level != members[j].Level {
+			return members[i].Level > members[j].Level
+		}
+		return members[i].JoinedAt < members[j].JoinedAt
+	})
+
+	return &models.ClanDetails{Clan: *clan, MaxMembers: s.MaxMembers, Members: members}, nil
+}
+
+// SearchClans returns one page of clans whose name starts with name, ignoring case, optionally
+// only those of one country.
+func (s *ClanService) SearchClans(ctx context.Context, name, country string, page models.PageRequest) (models.Page[models.Clan], error) {
+	clans, err := s.DB.SearchClans(ctx, name, country, page)
+	if err != nil && err != errors.ErrInvalidCursor {
+		log.Println("Error searching clans:", err)
+		return clans, fmt.Errorf("could not search clans: %w", err)
+	}
+	return clans, err
+}
+
+// JoinClan adds the user to a clan as a member.
+func (s *ClanService) JoinClan(ctx context.Context, clanID, userID string) (*models.ClanDetails, error) {
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ClanID != "" {
+		return nil, errors.ErrAlreadyInClan
+	}
+
+	member := models.ClanMember{
+		ClanID:   clanID,
+		UserID:   userID,
+		Username: user.Username,
+		Role:     models.ClanRoleMember,
+		Level:    user.Level,
+		JoinedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := s.DB.JoinClanTransaction(ctx, member, s.MaxMembers); err != nil {
+		log.Println("Error joining clan:", err)
+		return nil, err
+	}
+
+	return s.GetClan(ctx, clanID)
+}
+
+// LeaveClan takes the user out of a clan. The leader can only leave once they are the last
+// member, which disbands the clan; before that they must hand leadership over.
+func (s *ClanService) LeaveClan(ctx context.Context, clanID, userID string) error {
+	member, err := s.member(ctx, clanID, userID, errors.ErrNotInClan)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == models.ClanRoleLeader {
+		err = s.DB.DisbandClanTransaction(ctx, *member)
+	} else {
+		err = s.DB.RemoveClanMemberTransaction(ctx, *member, nil)
+	}
+	if err == errors.ErrClanMemberNotFound {
+		return errors.ErrNotInClan
+	}
+	if err != nil {
+		log.Println("Error leaving clan:", err)
+		return err
+	}
+	return nil
+}
+
+// KickMember removes memberID from a clan on behalf of userID. Leaders can remove anyone else,
+// officers only members.
+func (s *ClanService) KickMember(ctx context.Context, clanID, userID, memberID string) error {
+	by, err := s.member(ctx, clanID, userID, errors.ErrNotInClan)
+	if err != nil {
+		return err
+	}
+	target, err := s.member(ctx, clanID, memberID, errors.ErrClanMemberNotFound)
+	if err != nil {
+		return err
+	}
+	if !by.CanKick(*target) {
+		return errors.ErrClanPermissionDenied
+	}
+
+	if err := s.DB.RemoveClanMemberTransaction(ctx, *target, by); err != nil {
+		log.Println("Error kicking clan member:", err)
+		return err
+	}
+	return nil
+}
+
+// SetMemberRole gives memberID a new role on behalf of userID, who must be the leader. Making
+// someone leader hands leadership over and makes the old leader an officer.
+func (s *ClanService) SetMemberRole(ctx context.Context, clanID, userID, memberID, role string) (*models.ClanMember, error) {
+	if !models.ValidClanRole(role) {
+		return nil, errors.ErrInvalidClanRole
+	}
+
+	by, err := s.member(ctx, clanID, userID, errors.ErrNotInClan)
+	if err != nil {
+		return nil, err
+	}
+	if by.Role != models.ClanRoleLeader || memberID == userID {
+		return nil, errors.ErrClanPermissionDenied
+	}
+	target, err := s.member(ctx, clanID, memberID, errors.ErrClanMemberNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.SetClanRoleTransaction(ctx, *target, role, *by); err != nil {
+		log.Println("Error setting clan role:", err)
+		return nil, err
+	}
+	target.Role = role
+	return target, nil
+}
+
+// GetClanLeaderboard returns one page of clans by the total level of their members.
+func (s *ClanService) GetClanLeaderboard(ctx context.Context, page models.PageRequest) (models.Page[models.Clan], error) {
+	clans, err := s.DB.QueryClanLeaderboard(ctx, page)
+	if err != nil && err != errors.ErrInvalidCursor {
+		log.Println("Error fetching clan leaderboard:", err)
+		return clans, fmt.Errorf("could not fetch clan leaderboard: %w", err)
+	}
+	return clans, err
+}
+
+// MemberLevelChanged counts a user's new level in their clan's total. A failed or skipped sync
+// is caught up by the member's next level change.
+func (s *ClanService) MemberLevelChanged(ctx context.Context, user models.User) {
+	if user.ClanID == "" {
+		return
+	}
+	member, err := s.DB.GetClanMember(ctx, user.ClanID, user.UserID)
+	if err != nil {
+		log.Printf("Error fetching clan member %s of clan %s: %v", user.UserID, user.ClanID, err)
+		return
+	}
+	if member == nil || member.Level >= user.Level {
+		return
+	}
+	if err := s.DB.SyncClanMemberLevel(ctx, *member, user.Level); err != nil {
+		log.Printf("Error syncing level %d of user %s in clan %s: %v", user.Level, user.UserID, user.ClanID, err)
+	}
+}
@@ -0,0 +1,155 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	apperrors "good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+	"good_blast/services/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateClan(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanService := services.NewClanService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Username: "ann", Level: 12, Country: "TR"}, nil)
+	mockDB.On("CreateClanTransaction", mock.Anything,
+		mock.MatchedBy(func(c models.Clan) bool {
+			return c.ClanID != "" && c.Name == "Blast Crew" && c.NameKey == "blast crew" && c.Country == "TR" &&
+				c.LeaderID == "u1" && c.MemberCount == 1 && c.TotalLevel == 12 && c.ClanPK == models.ClanGlobalPK
+		}),
+		mock.MatchedBy(func(m models.ClanMember) bool {
+			return m.UserID == "u1" && m.Role == models.ClanRoleLeader && m.Level == 12 && m.Username == "ann"
+		}),
+	).Return(nil).Once()
+
+	clan, err := clanService.CreateClan(context.Background(), "u1", "  Blast Crew ", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Blast Crew", clan.Name)
+	assert.Equal(t, 50, clan.MaxMembers)
+	assert.Len(t, clan.Members, 1)
+	mockDB.AssertExpectations(t)
+
+	_, err = clanService.CreateClan(context.Background(), "u1", "ab", "")
+	assert.Equal(t, apperrors.ErrInvalidClanName, err)
+}
+
+func TestCreateClan_AlreadyInClan(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanService := services.NewClanService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", ClanState: models.ClanState{ClanID: "c1"}}, nil)
+
+	_, err := clanService.CreateClan(context.Background(), "u1", "Blast Crew", "")
+	assert.Equal(t, apperrors.ErrAlreadyInClan, err)
+	mockDB.AssertNotCalled(t, "CreateClanTransaction", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestJoinClan(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanService := services.NewClanService(mockDB)
+	clanService.MaxMembers = 2
+
+	mockDB.On("GetUser", mock.Anything, "u2").Return(&models.User{UserID: "u2", Username: "bob", Level: 30}, nil)
+	mockDB.On("JoinClanTransaction", mock.Anything, mock.MatchedBy(func(m models.ClanMember) bool {
+		return m.ClanID == "c1" && m.UserID == "u2" && m.Role == models.ClanRoleMember && m.Level == 30
+	}), 2).Return(nil).Once()
+	mockDB.On("GetClan", mock.Anything, "c1").Return(&models.Clan{ClanID: "c1", MemberCount: 2, TotalLevel: 42}, nil)
+	mockDB.On("QueryClanMembers", mock.Anything, "c1").Return([]models.ClanMember{
+		{UserID: "u1", Role: models.ClanRoleLeader, Level: 12},
+		{UserID: "u2", Role: models.ClanRoleMember, Level: 30},
+	}, nil)
+
+	clan, err := clanService.JoinClan(context.Background(), "c1", "u2")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, clan.TotalLevel)
+	// Highest level first
+	assert.Equal(t, "u2", clan.Members[0].UserID)
+	mockDB.AssertExpectations(t)
+}
+
+func TestLeaveClan(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanService := services.NewClanService(mockDB)
+
+	member := models.ClanMember{ClanID: "c1", UserID: "u2", Role: models.ClanRoleMember, Level: 30}
+	leader := models.ClanMember{ClanID: "c1", UserID: "u1", Role: models.ClanRoleLeader, Level: 12}
+	mockDB.On("GetClanMember", mock.Anything, "c1", "u2").Return(&member, nil)
+	mockDB.On("GetClanMember", mock.Anything, "c1", "u1").Return(&leader, nil)
+	mockDB.On("GetClanMember", mock.Anything, "c1", "u3").Return(nil, nil)
+	mockDB.On("RemoveClanMemberTransaction", mock.Anything, member, (*models.ClanMember)(nil)).Return(nil).Once()
+	// The leader leaves by disbanding, which fails while others are still in the clan
+	mockDB.On("DisbandClanTransaction", mock.Anything, leader).Return(apperrors.ErrClanLeaderMustTransfer).Once()
+
+	assert.NoError(t, clanService.LeaveClan(context.Background(), "c1", "u2"))
+	assert.Equal(t, apperrors.ErrClanLeaderMustTransfer, clanService.LeaveClan(context.Background(), "c1", "u1"))
+	assert.Equal(t, apperrors.ErrNotInClan, clanService.LeaveClan(context.Background(), "c1", "u3"))
+	mockDB.AssertExpectations(t)
+}
+
+func TestKickMember_RespectsRoles(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanService := services.NewClanService(mockDB)
+
+	leader := models.ClanMember{ClanID: "c1", UserID: "lead", Role: models.ClanRoleLeader}
+	officer := models.ClanMember{ClanID: "c1", UserID: "off", Role: models.ClanRoleOfficer}
+	officer2 := models.ClanMember{ClanID: "c1", UserID: "off2", Role: models.ClanRoleOfficer}
+	member := models.ClanMember{ClanID: "c1", UserID: "mem", Role: models.ClanRoleMember}
+	for _, m := range []models.ClanMember{leader, officer, officer2, member} {
+		m := m
+		mockDB.On("GetClanMember", mock.Anything, "c1", m.UserID).Return(&m, nil)
+	}
+	mockDB.On("RemoveClanMemberTransaction", mock.Anything, member, &officer).Return(nil).Once()
+	mockDB.On("RemoveClanMemberTransaction", mock.Anything, officer2, &leader).Return(nil).Once()
+
+	assert.NoError(t, clanService.KickMember(context.Background(), "c1", "off", "mem"))
+	assert.NoError(t, clanService.KickMember(context.Background(), "c1", "lead", "off2"))
+	assert.Equal(t, apperrors.ErrClanPermissionDenied, clanService.KickMember(context.Background(), "c1", "off", "off2"))
+	assert.Equal(t, apperrors.ErrClanPermissionDenied, clanService.KickMember(context.Background(), "c1", "mem", "off"))
+	assert.Equal(t, apperrors.ErrClanPermissionDenied, clanService.KickMember(context.Background(), "c1", "off", "lead"))
+	mockDB.AssertExpectations(t)
+}
+
+func TestSetMemberRole(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanService := services.NewClanService(mockDB)
+
+	leader := models.ClanMember{ClanID: "c1", UserID: "lead", Role: models.ClanRoleLeader}
+	officer := models.ClanMember{ClanID: "c1", UserID: "off", Role: models.ClanRoleOfficer}
+	member := models.ClanMember{ClanID: "c1", UserID: "mem", Role: models.ClanRoleMember}
+	mockDB.On("GetClanMember", mock.Anything, "c1", "lead").Return(&leader, nil)
+	mockDB.On("GetClanMember", mock.Anything, "c1", "off").Return(&officer, nil)
+	mockDB.On("GetClanMember", mock.Anything, "c1", "mem").Return(&member, nil)
+	mockDB.On("SetClanRoleTransaction", mock.Anything, member, models.ClanRoleOfficer, leader).Return(nil).Once()
+
+	promoted, err := clanService.SetMemberRole(context.Background(), "c1", "lead", "mem", models.ClanRoleOfficer)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClanRoleOfficer, promoted.Role)
+
+	_, err = clanService.SetMemberRole(context.Background(), "c1", "off", "mem", models.ClanRoleOfficer)
+	assert.Equal(t, apperrors.ErrClanPermissionDenied, err)
+	_, err = clanService.SetMemberRole(context.Background(), "c1", "lead", "mem", "king")
+	assert.Equal(t, apperrors.ErrInvalidClanRole, err)
+	mockDB.AssertExpectations(t)
+}
+
+func TestClanTracker_SyncsNewLevels(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanService := services.NewClanService(mockDB)
+
+	member := models.ClanMember{ClanID: "c1", UserID: "u1", Level: 12}
+	mockDB.On("GetClanMember", mock.Anything, "c1", "u1").Return(&member, nil)
+	mockDB.On("SyncClanMemberLevel", mock.Anything, member, 15).Return(nil).Once()
+
+	clanService.MemberLevelChanged(context.Background(), models.User{UserID: "u1", Level: 15, ClanState: models.ClanState{ClanID: "c1"}})
+	// A level already counted is not synced again
+	clanService.MemberLevelChanged(context.Background(), models.User{UserID: "u1", Level: 12, ClanState: models.ClanState{ClanID: "c1"}})
+	// Users in no clan are skipped
+	clanService.MemberLevelChanged(context.Background(), models.User{UserID: "u2", Level: 15})
+	mockDB.AssertExpectations(t)
+}
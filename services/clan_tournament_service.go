@@ -0,0 +1,244 @@
+// services/clan_tournament_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"good_blast/database"
+	"good_blast/errors"
+	"good_blast/models"
+)
+
+// ClanTournamentService implements ClanTournamentServiceInterface and ClanScoreTracker.
+type ClanTournamentService struct {
+	DB      database.DatabaseInterface
+	Rewards models.ClanRewardTable // reward bundle per clan rank and member contribution; nil means models.DefaultClanRewardTable
+}
+
+// NewClanTournamentService creates a new instance of ClanTournamentService.
+func NewClanTournamentService(db database.DatabaseInterface) *ClanTournamentService {
+	return &ClanTournamentService{
+		DB:      db,
+		Rewards: models.DefaultClanRewardTable,
+	}
+}
+
+// EnterTournament enters a clan in an active tournament on behalf of userID, who must be its
+// leader or an officer. The clan is placed in a group of up to ClanGroupCapacity clans.
+func (s *ClanTournamentService) EnterTournament(ctx context.Context, clanID, tournamentID, userID string) (*models.ClanTournamentStanding, error) {
+	member, err := s.DB.GetClanMember(ctx, clanID, userID)
+	if err != nil {
+		log.Println("Error fetching clan member:", err)
+		return nil, fmt.Errorf("could not fetch clan member: %w", err)
+	}
+	if member == nil {
+		return nil, errors.ErrNotInClan
+	}
+	if member.Role != models.ClanRoleLeader && member.Role != models.ClanRoleOfficer {
+		return nil, errors.ErrClanPermissionDenied
+	}
+
+	t, err := s.DB.GetTournament(ctx, tournamentID)
+	if err != nil {
+		log.Println("Error fetching tournament:", err)
+		return nil, err
+	}
+	if t == nil || !t.Active {
+		return nil, errors.ErrTournamentNotActive
+	}
+
+	clan, err := s.DB.GetClan(ctx, clanID)
+	if err != nil {
+		log.Println("Error fetching clan:", err)
+		return nil, fmt.Errorf("could not fetch clan: %w", err)
+	}
+	if clan == nil {
+		return nil, errors.ErrClanNotFound
+	}
+
+	entry, err := s.DB.EnterClanTournament(ctx, models.ClanTournamentEntry{
+		ClanID:    clanID,
+		ClanName:  clan.Name,
+		EnteredBy: userID,
+		EnteredAt: time.Now().UTC().Format(time.RFC3339),
+	}, t)
+	if err != nil {
+		log.Println("EnterClanTournament error:", err)
+		return nil, err
+	}
+
+	return s.standing(ctx, *entry)
+}
+
+// GetStanding returns a clan's rank in its tournament group together with its members'
+// contributions.
+func (s *ClanTournamentService) GetStanding(ctx context.Context, clanID, tournamentID string) (*models.ClanTournamentStanding, error) {
+	entry, err := s.DB.GetClanTournamentEntry(ctx, tournamentID, clanID)
+	if err != nil {
+		log.Println("Error fetching clan tournament entry:", err)
+		return nil, fmt.Errorf("could not fetch clan tournament entry: %w", err)
+	}
+	if entry == nil {
+		return nil, errors.ErrClanNotEntered
+	}
+	return s.standing(ctx, *entry)
+}
+
+// standing builds the standing of a clan entry.
+func (s *ClanTournamentService) standing(ctx context.Context, entry models.ClanTournamentEntry) (*models.ClanTournamentStanding, error) {
+	group, err := s.DB.QueryClanTournamentGroup(ctx, entry.GroupID)
+	if err != nil {
+		log.Println("Error querying ClanGroupScoreIndex:", err)
+		return nil, fmt.Errorf("could not fetch clan tournament group: %w", err)
+	}
+	contributions, err := s.DB.QueryClanContributions(ctx, entry.TournamentID, entry.ClanID)
+	if err != nil {
+		log.Println("Error fetching clan contributions:", err)
+		return nil, fmt.Errorf("could not fetch clan contributions: %w", err)
+	}
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].Score > contributions[j].Score
+	})
+
+	standing := &models.ClanTournamentStanding{Entry: entry, Group: group, Contributions: contributions}
+	for i, e := range group {
+		if e.ClanID == entry.ClanID {
+			standing.Rank = i + 1
+			break
+		}
+	}
+	return standing, nil
+}
+
+// ScoreAdded adds a user's tournament score to their clan's entry in the tournament. Scores of
+// users in no clan, or whose clan has not entered, count for no clan.
+func (s *ClanTournamentService) ScoreAdded(ctx context.Context, tournamentID, userID string, increment int) {
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Printf("Error fetching user %s for clan contribution: %v", userID, err)
+		return
+	}
+	if user == nil || user.ClanID == "" {
+		return
+	}
+	err = s.DB.AddClanContribution(ctx, tournamentID, user.ClanID, userID, increment)
+	if err != nil && err != errors.ErrClanNotEntered {
+		log.Printf("Error adding score %d of user %s to clan %s in tournament %s: %v", increment, userID, user.ClanID, tournamentID, err)
+	}
+}
+
+// DistributeRewards pays every member who contributed to a clan's entry in an ended tournament
+// the reward for their clan's rank within its group and their contribution, recording each
+// payment in the member's history. Payments are conditional, so running it again never pays
+// twice.
+func (s *ClanTournamentService) DistributeRewards(ctx context.Context, tournamentID, actor string) (*models.ClanRewardReport, error) {
+	t, err := s.DB.GetTournament(ctx, tournamentID)
+	if err != nil {
+		log.Println("Error fetching tournament:", err)
+		return nil, err
+	}
+	if t == nil {
+		return nil, errors.ErrTournamentNotFound
+	}
+	if t.Cancelled {
+		return nil, errors.ErrTournamentCancelled
+	}
+	if t.Active {
+		return nil, errors.ErrTournamentStillActive
+	}
+
+	rewards := s.Rewards
+	if rewards == nil {
+		rewards = models.DefaultClanRewardTable
+	}
+	ranks := make(map[string]map[string]int) // groupId -> clanId -> rank
+	report := &models.ClanRewardReport{TournamentID: tournamentID}
+
+	err = s.forEachClanEntry(ctx, tournamentID, func(e models.ClanTournamentEntry) error {
+		report.Clans++
+		groupRanks, ok := ranks[e.GroupID]
+		if !ok {
+			group, err := s.DB.QueryClanTournamentGroup(ctx, e.GroupID)
+			if err != nil {
+				return err
+			}
+			groupRanks = make(map[string]int, len(group))
+			for i, g := range group {
+				groupRanks[g.ClanID] = i + 1
+			}
+			ranks[e.GroupID] = groupRanks
+		}
+		rank := groupRanks[e.ClanID]
+
+		contributions, err := s.DB.QueryClanContributions(ctx, tournamentID, e.ClanID)
+		if err != nil {
+			return err
+		}
+		for _, c := range contributions {
+			report.Contributions++
+			if c.RewardPaid {
+				report.AlreadyPaid++
+				continue
+			}
+			reward := rewards.For(rank, c.Score)
+			if rank == 0 || reward.IsEmpty() {
+				report.NoReward++
+				continue
+			}
+
+			entry := models.HistoryEntry{
+				UserID:       c.UserID,
+				Type:         models.HistoryClanReward,
+				Coins:        reward.Coins,
+				Reason:       fmt.Sprintf("clan %s rank %d with %d points", e.ClanName, rank, c.Score),
+				Actor:        actor,
+				TournamentID: tournamentID,
+			}
+			items := reward
+			items.Coins = 0
+			if !items.IsEmpty() {
+				entry.Bundle = &items
+			}
+			err := s.DB.PayClanRewardTransaction(ctx, entry, e.ClanID)
+			if err == errors.ErrRewardAlreadyClaimed {
+				report.AlreadyPaid++
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to pay clan reward to %s: %w", c.UserID, err)
+			}
+			report.Paid++
+			report.CoinsPaid += reward.Coins
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("Error distributing clan rewards:", err)
+		return report, err
+	}
+	return report, nil
+}
+
+// forEachClanEntry calls fn for every clan entered in a tournament, following pagination.
+func (s *ClanTournamentService) forEachClanEntry(ctx context.Context, tournamentID string, fn func(models.ClanTournamentEntry) error) error {
+	page := models.PageRequest{}
+	for {
+		entries, err := s.DB.QueryClanTournamentEntries(ctx, tournamentID, page)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries.Items {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		if entries.NextCursor == "" {
+			return nil
+		}
+		page.Cursor = entries.NextCursor
+	}
+}
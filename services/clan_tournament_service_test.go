@@ -0,0 +1,150 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	apperrors "good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+	"good_blast/services/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEnterClanTournament(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanTournamentService := services.NewClanTournamentService(mockDB)
+
+	tournament := &models.Tournament{TournamentID: "2024-01-15", Active: true}
+	mockDB.On("GetClanMember", mock.Anything, "c1", "off").Return(&models.ClanMember{ClanID: "c1", UserID: "off", Role: models.ClanRoleOfficer}, nil)
+	mockDB.On("GetClanMember", mock.Anything, "c1", "mem").Return(&models.ClanMember{ClanID: "c1", UserID: "mem", Role: models.ClanRoleMember}, nil)
+	mockDB.On("GetTournament", mock.Anything, "2024-01-15").Return(tournament, nil)
+	mockDB.On("GetClan", mock.Anything, "c1").Return(&models.Clan{ClanID: "c1", Name: "Blast Crew"}, nil)
+	entered := &models.ClanTournamentEntry{TournamentID: "2024-01-15", ClanID: "c1", ClanName: "Blast Crew", GroupID: "2024-01-15#clans-group-0-1"}
+	mockDB.On("EnterClanTournament", mock.Anything, mock.MatchedBy(func(e models.ClanTournamentEntry) bool {
+		return e.ClanID == "c1" && e.ClanName == "Blast Crew" && e.EnteredBy == "off" && e.EnteredAt != ""
+	}), tournament).Return(entered, nil).Once()
+	mockDB.On("QueryClanTournamentGroup", mock.Anything, entered.GroupID).Return([]models.ClanTournamentEntry{
+		{ClanID: "c2", Score: 0},
+		*entered,
+	}, nil)
+	mockDB.On("QueryClanContributions", mock.Anything, "2024-01-15", "c1").Return([]models.ClanContribution(nil), nil)
+
+	standing, err := clanTournamentService.EnterTournament(context.Background(), "c1", "2024-01-15", "off")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, standing.Rank)
+	assert.Len(t, standing.Group, 2)
+
+	// Plain members cannot enter their clan
+	_, err = clanTournamentService.EnterTournament(context.Background(), "c1", "2024-01-15", "mem")
+	assert.Equal(t, apperrors.ErrClanPermissionDenied, err)
+	mockDB.AssertExpectations(t)
+}
+
+func TestEnterClanTournament_NotActive(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanTournamentService := services.NewClanTournamentService(mockDB)
+
+	mockDB.On("GetClanMember", mock.Anything, "c1", "lead").Return(&models.ClanMember{ClanID: "c1", UserID: "lead", Role: models.ClanRoleLeader}, nil)
+	mockDB.On("GetTournament", mock.Anything, "2024-01-15").Return(&models.Tournament{TournamentID: "2024-01-15"}, nil)
+
+	_, err := clanTournamentService.EnterTournament(context.Background(), "c1", "2024-01-15", "lead")
+	assert.Equal(t, apperrors.ErrTournamentNotActive, err)
+	mockDB.AssertNotCalled(t, "EnterClanTournament", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClanScoreTracker_AddsToTheUsersClan(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanTournamentService := services.NewClanTournamentService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", ClanState: models.ClanState{ClanID: "c1"}}, nil)
+	mockDB.On("GetUser", mock.Anything, "u2").Return(&models.User{UserID: "u2"}, nil)
+	mockDB.On("AddClanContribution", mock.Anything, "2024-01-15", "c1", "u1", 3).Return(nil).Once()
+	// A clan that has not entered collects nothing; that is not an error
+	mockDB.On("AddClanContribution", mock.Anything, "2024-01-16", "c1", "u1", 1).Return(apperrors.ErrClanNotEntered).Once()
+
+	clanTournamentService.ScoreAdded(context.Background(), "2024-01-15", "u1", 3)
+	clanTournamentService.ScoreAdded(context.Background(), "2024-01-16", "u1", 1)
+	// Users in no clan are skipped
+	clanTournamentService.ScoreAdded(context.Background(), "2024-01-15", "u2", 5)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateScore_NotifiesClanTracker(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	tournamentService := services.NewTournamentService(mockDB)
+	tournamentService.Clans = services.NewClanTournamentService(mockDB)
+
+	mockDB.On("GetTournament", mock.Anything, "2024-01-15").Return(&models.Tournament{TournamentID: "2024-01-15", Active: true}, nil)
+	mockDB.On("GetTournamentEntry", mock.Anything, "2024-01-15", "u1").Return(&models.TournamentEntry{TournamentID: "2024-01-15", UserID: "u1", Score: 4}, nil)
+	mockDB.On("UpdateTournamentScore", mock.Anything, "2024-01-15", "u1", 2).Return(nil)
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", ClanState: models.ClanState{ClanID: "c1"}}, nil)
+	mockDB.On("AddClanContribution", mock.Anything, "2024-01-15", "c1", "u1", 2).Return(nil).Once()
+
+	score, err := tournamentService.UpdateScore(context.Background(), "2024-01-15", "u1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, score)
+	mockDB.AssertExpectations(t)
+}
+
+func TestDistributeClanRewards_ByRankAndContribution(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanTournamentService := services.NewClanTournamentService(mockDB)
+
+	mockDB.On("GetTournament", mock.Anything, "2024-01-15").Return(&models.Tournament{TournamentID: "2024-01-15"}, nil)
+	group := []models.ClanTournamentEntry{
+		{ClanID: "c1", ClanName: "First", GroupID: "g1", Score: 70},
+		{ClanID: "c2", ClanName: "Second", GroupID: "g1", Score: 30},
+		{ClanID: "c3", ClanName: "Third", GroupID: "g1", Score: 10},
+		{ClanID: "c4", ClanName: "Fourth", GroupID: "g1", Score: 5},
+	}
+	mockDB.On("QueryClanTournamentEntries", mock.Anything, "2024-01-15", models.PageRequest{}).
+		Return(models.Page[models.ClanTournamentEntry]{Items: group}, nil)
+	mockDB.On("QueryClanTournamentGroup", mock.Anything, "g1").Return(group, nil).Once()
+	mockDB.On("QueryClanContributions", mock.Anything, "2024-01-15", "c1").Return([]models.ClanContribution{
+		{UserID: "a", Score: 55},
+		{UserID: "b", Score: 15},
+		{UserID: "c", Score: 0, RewardPaid: true},
+	}, nil)
+	mockDB.On("QueryClanContributions", mock.Anything, "2024-01-15", "c2").Return([]models.ClanContribution{{UserID: "d", Score: 30}}, nil)
+	mockDB.On("QueryClanContributions", mock.Anything, "2024-01-15", "c3").Return([]models.ClanContribution{{UserID: "e", Score: 10}}, nil)
+	mockDB.On("QueryClanContributions", mock.Anything, "2024-01-15", "c4").Return([]models.ClanContribution{{UserID: "f", Score: 5}}, nil)
+
+	paid := func(userID string, coins int) interface{} {
+		return mock.MatchedBy(func(e models.HistoryEntry) bool {
+			return e.UserID == userID && e.Coins == coins && e.Type == models.HistoryClanReward &&
+				e.TournamentID == "2024-01-15" && e.Actor == "ops" && e.Bundle == nil
+		})
+	}
+	mockDB.On("PayClanRewardTransaction", mock.Anything, paid("a", 5000), "c1").Return(nil).Once()
+	mockDB.On("PayClanRewardTransaction", mock.Anything, paid("b", 1000), "c1").Return(nil).Once()
+	mockDB.On("PayClanRewardTransaction", mock.Anything, paid("d", 1800), "c2").Return(nil).Once()
+	// Paid by an earlier run that was interrupted before recording it here
+	mockDB.On("PayClanRewardTransaction", mock.Anything, paid("e", 400), "c3").Return(apperrors.ErrRewardAlreadyClaimed).Once()
+
+	report, err := clanTournamentService.DistributeRewards(context.Background(), "2024-01-15", "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ClanRewardReport{
+		TournamentID:  "2024-01-15",
+		Clans:         4,
+		Contributions: 6,
+		Paid:          3,
+		CoinsPaid:     7800,
+		AlreadyPaid:   2,
+		NoReward:      1, // the fourth clan earns nothing
+	}, report)
+	mockDB.AssertExpectations(t)
+}
+
+func TestDistributeClanRewards_StillActive(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	clanTournamentService := services.NewClanTournamentService(mockDB)
+
+	mockDB.On("GetTournament", mock.Anything, "2024-01-15").Return(&models.Tournament{TournamentID: "2024-01-15", Active: true}, nil)
+
+	_, err := clanTournamentService.DistributeRewards(context.Background(), "2024-01-15", "ops")
+	assert.Equal(t, apperrors.ErrTournamentStillActive, err)
+	mockDB.AssertNotCalled(t, "QueryClanTournamentEntries", mock.Anything, mock.Anything, mock.Anything)
+}
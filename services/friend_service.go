@@ -0,0 +1,208 @@
+// services/friend_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"good_blast/database"
+	"good_blast/errors"
+	"good_blast/models"
+)
+
+// FriendService implements FriendServiceInterface.
+type FriendService struct {
+	DB         database.DatabaseInterface
+	MaxFriends int // most accepted friends a user can have
+}
+
+// NewFriendService creates a new instance of FriendService with the default friend limit.
+func NewFriendService(db database.DatabaseInterface) *FriendService {
+	return &FriendService{
+		DB:         db,
+		MaxFriends: models.DefaultMaxFriends,
+	}
+}
+
+// user fetches a user, returning ErrUserNotFound when they don't exist.
+func (s *FriendService) user(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching user:", err)
+		return nil, fmt.Errorf("could not fetch user data: %w", err)
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// friendship fetches userID's relation with friendID; nil when there is none.
+func (s *FriendService) friendship(ctx context.Context, userID, friendID string) (*models.Friendship, error) {
+	f, err := s.DB.GetFriendship(ctx, userID, friendID)
+	if err != nil {
+		log.Println("Error fetching friendship:", err)
+		return nil, fmt.Errorf("could not fetch friendship: %w", err)
+	}
+	return f, nil
+}
+
+// GetFriends returns a user's friends, pending requests and blocked users, oldest first.
+func (s *FriendService) GetFriends(ctx context.Context, userID string) (*models.FriendList, error) {
+	friendships, err := s.DB.QueryFriendships(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching friendships:", err)
+		return nil, fmt.Errorf("could not fetch friends: %w", err)
+	}
+	sort.SliceStable(friendships, func(i, j int) bool {
+		return friendships[i].CreatedAt < friendships[j].CreatedAt
+	})
+
+	list := &models.FriendList{
+		Friends:    []models.Friendship{},
+		Incoming:   []models.Friendship{},
+		Requested:  []models.Friendship{},
+		Blocked:    []models.Friendship{},
+		MaxFriends: s.MaxFriends,
+	}
+	for _, f := range friendships {
+		switch f.Status {
+		case models.FriendStatusAccepted:
+			list.Friends = append(list.Friends, f)
+		case models.FriendStatusIncoming:
+			list.Incoming = append(list.Incoming, f)
+		case models.FriendStatusRequested:
+			list.Requested = append(list.Requested, f)
+		case models.FriendStatusBlocked:
+			list.Blocked = append(list.Blocked, f)
+		}
+	}
+	return list, nil
+}
+
+// SendRequest asks friendID to be friends with the user. When friendID already asked the user,
+// their request is accepted instead.
+func (s *FriendService) SendRequest(ctx context.Context, userID, friendID string) (*models.Friendship, error) {
+	if userID == friendID {
+		return nil, errors.ErrCannotFriendSelf
+	}
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	friend, err := s.user(ctx, friendID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.friendship(ctx, userID, friendID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		switch existing.Status {
+		case models.FriendStatusIncoming:
+			return s.AcceptRequest(ctx, userID, friendID)
+		case models.FriendStatusBlocked:
+			return nil, errors.ErrUserBlocked
+		default:
+			return nil, errors.ErrFriendshipExists
+		}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	request := models.Friendship{
+		UserID:    userID,
+		FriendID:  friendID,
+		Username:  friend.Username,
+		Status:    models.FriendStatusRequested,
+		CreatedAt: now,
+	}
+	incoming := models.Friendship{
+		UserID:    friendID,
+		FriendID:  userID,
+		Username:  user.Username,
+		Status:    models.FriendStatusIncoming,
+		CreatedAt: now,
+	}
+	if err := s.DB.SendFriendRequestTransaction(ctx, request, incoming); err != nil {
+		log.Println("Error sending friend request:", err)
+		return nil, err
+	}
+	return &request, nil
+}
+
+// AcceptRequest accepts the friend request friendID sent the user.
+func (s *FriendService) AcceptRequest(ctx context.Context, userID, friendID string) (*models.Friendship, error) {
+	f, err := s.friendship(ctx, userID, friendID)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil || f.Status != models.FriendStatusIncoming {
+		return nil, errors.ErrFriendRequestNotFound
+	}
+
+	if err := s.DB.AcceptFriendRequestTransaction(ctx, userID, friendID, s.MaxFriends); err != nil {
+		log.Println("Error accepting friend request:", err)
+		return nil, err
+	}
+	f.Status = models.FriendStatusAccepted
+	return f, nil
+}
+
+// RemoveFriend ends the user's relation with friendID, whatever it is: it unfriends them,
+// declines or withdraws a request, or lifts a block.
+func (s *FriendService) RemoveFriend(ctx context.Context, userID, friendID string) error {
+	f, err := s.friendship(ctx, userID, friendID)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		return errors.ErrFriendNotFound
+	}
+
+	if err := s.DB.RemoveFriendshipTransaction(ctx, *f); err != nil {
+		log.Println("Error removing friendship:", err)
+		return err
+	}
+	return nil
+}
+
+// BlockUser blocks friendID: any friendship or request between the users ends, and friendID can
+// no longer send the user requests. Blocking a blocked user changes nothing.
+func (s *FriendService) BlockUser(ctx context.Context, userID, friendID string) (*models.Friendship, error) {
+	if userID == friendID {
+		return nil, errors.ErrCannotFriendSelf
+	}
+	if _, err := s.user(ctx, userID); err != nil {
+		return nil, err
+	}
+	friend, err := s.user(ctx, friendID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.friendship(ctx, userID, friendID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Status == models.FriendStatusBlocked {
+		return existing, nil
+	}
+
+	block := models.Friendship{
+		UserID:    userID,
+		FriendID:  friendID,
+		Username:  friend.Username,
+		Status:    models.FriendStatusBlocked,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := s.DB.BlockUserTransaction(ctx, block, existing); err != nil {
+		log.Println("Error blocking user:", err)
+		return nil, err
+	}
+	return &block, nil
+}
@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	apperrors "good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+	"good_blast/services/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendFriendRequest(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	friendService := services.NewFriendService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Username: "alice"}, nil)
+	mockDB.On("GetUser", mock.Anything, "u2").Return(&models.User{UserID: "u2", Username: "bob"}, nil)
+	mockDB.On("GetFriendship", mock.Anything, "u1", "u2").Return(nil, nil)
+	mockDB.On("SendFriendRequestTransaction", mock.Anything,
+		mock.MatchedBy(func(f models.Friendship) bool {
+			return f.UserID == "u1" && f.FriendID == "u2" && f.Username == "bob" && f.Status == models.FriendStatusRequested
+		}),
+		mock.MatchedBy(func(f models.Friendship) bool {
+			return f.UserID == "u2" && f.FriendID == "u1" && f.Username == "alice" && f.Status == models.FriendStatusIncoming
+		}),
+	).Return(nil).Once()
+
+	f, err := friendService.SendRequest(context.Background(), "u1", "u2")
+	assert.NoError(t, err)
+	assert.Equal(t, models.FriendStatusRequested, f.Status)
+
+	_, err = friendService.SendRequest(context.Background(), "u1", "u1")
+	assert.Equal(t, apperrors.ErrCannotFriendSelf, err)
+	mockDB.AssertExpectations(t)
+}
+
+func TestSendFriendRequest_AcceptsTheirPendingRequest(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	friendService := services.NewFriendService(mockDB)
+	friendService.MaxFriends = 10
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1"}, nil)
+	mockDB.On("GetUser", mock.Anything, "u2").Return(&models.User{UserID: "u2"}, nil)
+	mockDB.On("GetFriendship", mock.Anything, "u1", "u2").Return(&models.Friendship{UserID: "u1", FriendID: "u2", Status: models.FriendStatusIncoming}, nil)
+	mockDB.On("AcceptFriendRequestTransaction", mock.Anything, "u1", "u2", 10).Return(nil).Once()
+
+	f, err := friendService.SendRequest(context.Background(), "u1", "u2")
+	assert.NoError(t, err)
+	assert.Equal(t, models.FriendStatusAccepted, f.Status)
+	mockDB.AssertNotCalled(t, "SendFriendRequestTransaction", mock.Anything, mock.Anything, mock.Anything)
+	mockDB.AssertExpectations(t)
+}
+
+func TestAcceptFriendRequest_OnlyIncomingRequests(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	friendService := services.NewFriendService(mockDB)
+
+	// A request the user sent can't be accepted by the user
+	mockDB.On("GetFriendship", mock.Anything, "u1", "u2").Return(&models.Friendship{UserID: "u1", FriendID: "u2", Status: models.FriendStatusRequested}, nil)
+
+	_, err := friendService.AcceptRequest(context.Background(), "u1", "u2")
+	assert.Equal(t, apperrors.ErrFriendRequestNotFound, err)
+	mockDB.AssertNotCalled(t, "AcceptFriendRequestTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRemoveFriend(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	friendService := services.NewFriendService(mockDB)
+
+	friendship := models.Friendship{UserID: "u1", FriendID: "u2", Status: models.FriendStatusAccepted}
+	mockDB.On("GetFriendship", mock.Anything, "u1", "u2").Return(&friendship, nil)
+	mockDB.On("GetFriendship", mock.Anything, "u1", "u3").Return(nil, nil)
+	mockDB.On("RemoveFriendshipTransaction", mock.Anything, friendship).Return(nil).Once()
+
+	assert.NoError(t, friendService.RemoveFriend(context.Background(), "u1", "u2"))
+	assert.Equal(t, apperrors.ErrFriendNotFound, friendService.RemoveFriend(context.Background(), "u1", "u3"))
+	mockDB.AssertExpectations(t)
+}
+
+func TestBlockUser_EndsTheFriendship(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	friendService := services.NewFriendService(mockDB)
+
+	existing := &models.Friendship{UserID: "u1", FriendID: "u2", Status: models.FriendStatusAccepted}
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1"}, nil)
+	mockDB.On("GetUser", mock.Anything, "u2").Return(&models.User{UserID: "u2", Username: "bob"}, nil)
+	mockDB.On("GetFriendship", mock.Anything, "u1", "u2").Return(existing, nil)
+	mockDB.On("BlockUserTransaction", mock.Anything, mock.MatchedBy(func(f models.Friendship) bool {
+		return f.UserID == "u1" && f.FriendID == "u2" && f.Username == "bob" && f.Status == models.FriendStatusBlocked
+	}), existing).Return(nil).Once()
+
+	f, err := friendService.BlockUser(context.Background(), "u1", "u2")
+	assert.NoError(t, err)
+	assert.Equal(t, models.FriendStatusBlocked, f.Status)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetFriends_GroupsByStatus(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	friendService := services.NewFriendService(mockDB)
+
+	mockDB.On("QueryFriendships", mock.Anything, "u1").Return([]models.Friendship{
+		{FriendID: "u2", Status: models.FriendStatusAccepted},
+		{FriendID: "u3", Status: models.FriendStatusIncoming},
+		{FriendID: "u4", Status: models.FriendStatusRequested},
+		{FriendID: "u5", Status: models.FriendStatusBlocked},
+	}, nil)
+
+	list, err := friendService.GetFriends(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Len(t, list.Friends, 1)
+	assert.Len(t, list.Incoming, 1)
+	assert.Len(t, list.Requested, 1)
+	assert.Len(t, list.Blocked, 1)
+	assert.Equal(t, models.DefaultMaxFriends, list.MaxFriends)
+}
@@ -8,10 +8,51 @@ import (
 
 // LeaderboardServiceInterface defines all the methods related to leaderboard operations.
 type LeaderboardServiceInterface interface {
-	GetGlobalLeaderboard(ctx context.Context) ([]models.User, error)
-	GetCountryLeaderboard(ctx context.Context, countryCode string) ([]models.User, error)
+	GetGlobalLeaderboard(ctx context.Context, page models.PageRequest) (models.Page[models.User], error)
+	GetCountryLeaderboard(ctx context.Context, countryCode string, page models.PageRequest) (models.Page[models.User], error)
 	GetTournamentLeaderboard(ctx context.Context, groupId string) ([]models.TournamentEntry, error)
 	GetTournamentRank(ctx context.Context, tournamentId string, userId string) (int, error)
+	GetFriendsLeaderboard(ctx context.Context, userID string) ([]models.User, error)
+	GetFriendsInTournament(ctx context.Context, userID, tournamentID string) (*models.FriendsTournament, error)
+}
+
+// LeaderboardInvalidator is notified by services whose writes change leaderboard rankings,
+// so cached leaderboards can be invalidated or refreshed.
+type LeaderboardInvalidator interface {
+	UserLevelChanged(ctx context.Context, user models.User)
+	GroupScoreChanged(ctx context.Context, groupId string)
+}
+
+// QuestTracker is told about user actions that daily quests count. Recording is best-effort
+// and never fails the action that caused it.
+type QuestTracker interface {
+	Record(ctx context.Context, userID string, event models.QuestEvent)
+}
+
+// AchievementTracker is told about the events achievements are measured on. Like QuestTracker
+// it is best-effort.
+type AchievementTracker interface {
+	LevelReached(ctx context.Context, user models.User, fromLevel int, reward models.RewardBundle)
+	TournamentRewardPaid(ctx context.Context, userID string, rank int, reward models.RewardBundle)
+}
+
+// SeasonTracker is told about the events that earn season pass XP. Like QuestTracker it is
+// best-effort.
+type SeasonTracker interface {
+	LevelsCleared(ctx context.Context, userID string, count int)
+	TournamentPlaced(ctx context.Context, userID string, rank int)
+}
+
+// ClanTracker is told when a user's level changes, so their clan's total level follows. Like
+// QuestTracker it is best-effort.
+type ClanTracker interface {
+	MemberLevelChanged(ctx context.Context, user models.User)
+}
+
+// ClanScoreTracker is told about tournament score users add, so it can count towards their
+// clan's tournament entry. Like QuestTracker it is best-effort.
+type ClanScoreTracker interface {
+	ScoreAdded(ctx context.Context, tournamentID, userID string, increment int)
 }
 
 // TournamentServiceInterface defines all the methods related to tournament operations.
@@ -20,7 +61,10 @@ type TournamentServiceInterface interface {
 	EndTournament(ctx context.Context, tournamentID string) error
 	EnterTournament(ctx context.Context, userID string, tournamentID string) (int, error)
 	UpdateScore(ctx context.Context, tournamentID string, userID string, increment int) (int, error)
-	ClaimReward(ctx context.Context, tournamentID string, userID string) (int, int, error)
+	ScoreLevelWin(ctx context.Context, tournamentID, userID, sessionID string, points int) (int, error)
+	ClaimReward(ctx context.Context, tournamentID string, userID string) (int, models.RewardBundle, error)
+	GetPendingRewards(ctx context.Context, userID string) ([]models.PendingReward, error)
+	ClaimAllRewards(ctx context.Context, userID string) (*models.ClaimAllResult, error)
 }
 
 // UserServiceInterface defines all the methods related to user operations.
@@ -29,3 +73,78 @@ type UserServiceInterface interface {
 	GetUser(ctx context.Context, userID string) (*models.User, error)
 	UpdateUserProgress(ctx context.Context, userID string, newLevel int) (*models.User, error)
 }
+
+// InventoryServiceInterface defines all the methods related to a user's items.
+type InventoryServiceInterface interface {
+	GetBoosters(ctx context.Context, userID string) ([]models.BoosterStack, error)
+	PurchaseBooster(ctx context.Context, userID, boosterID string, quantity int) (*models.BoosterPurchase, error)
+	ConsumeBooster(ctx context.Context, userID, boosterID string, quantity int) (int, error)
+	GetLives(ctx context.Context, userID string) (*models.LivesStatus, error)
+	ConsumeLife(ctx context.Context, userID string) (*models.LivesStatus, error)
+	RefillLives(ctx context.Context, userID string) (*models.LivesStatus, error)
+}
+
+// LevelServiceInterface defines all the methods related to playing levels.
+type LevelServiceInterface interface {
+	StartLevel(ctx context.Context, userID string, level int) (*models.LevelSession, *models.LivesStatus, error)
+	FinishLevel(ctx context.Context, userID, sessionID string, result models.LevelResult) (*models.LevelOutcome, error)
+	GetCatalog() *models.LevelCatalog
+}
+
+// CheckInServiceInterface defines all the methods related to daily check-ins.
+type CheckInServiceInterface interface {
+	GetCheckIn(ctx context.Context, userID string) (*models.CheckInStatus, error)
+	CheckIn(ctx context.Context, userID string) (*models.CheckInResult, error)
+	RestoreStreak(ctx context.Context, userID string) (*models.CheckInStatus, error)
+}
+
+// QuestServiceInterface defines all the methods related to daily quests.
+type QuestServiceInterface interface {
+	GetQuests(ctx context.Context, userID string) (*models.DailyQuests, error)
+	ClaimQuest(ctx context.Context, userID, questID string) (*models.Quest, error)
+}
+
+// AchievementServiceInterface defines all the methods related to achievements.
+type AchievementServiceInterface interface {
+	GetAchievements(ctx context.Context, userID string) (*models.UserAchievements, error)
+}
+
+// LeagueServiceInterface defines all the methods related to leagues.
+type LeagueServiceInterface interface {
+	GetLeague(ctx context.Context, userID string) (*models.LeagueStatus, error)
+	GetLeagueHistory(ctx context.Context, userID string, page models.PageRequest) (models.Page[models.LeagueResult], error)
+}
+
+// ClanServiceInterface defines all the methods related to clans.
+type ClanServiceInterface interface {
+	CreateClan(ctx context.Context, userID, name, country string) (*models.ClanDetails, error)
+	GetClan(ctx context.Context, clanID string) (*models.ClanDetails, error)
+	SearchClans(ctx context.Context, name, country string, page models.PageRequest) (models.Page[models.Clan], error)
+	JoinClan(ctx context.Context, clanID, userID string) (*models.ClanDetails, error)
+	LeaveClan(ctx context.Context, clanID, userID string) error
+	KickMember(ctx context.Context, clanID, userID, memberID string) error
+	SetMemberRole(ctx context.Context, clanID, userID, memberID, role string) (*models.ClanMember, error)
+	GetClanLeaderboard(ctx context.Context, page models.PageRequest) (models.Page[models.Clan], error)
+}
+
+// ClanTournamentServiceInterface defines all the methods related to clans competing in tournaments.
+type ClanTournamentServiceInterface interface {
+	EnterTournament(ctx context.Context, clanID, tournamentID, userID string) (*models.ClanTournamentStanding, error)
+	GetStanding(ctx context.Context, clanID, tournamentID string) (*models.ClanTournamentStanding, error)
+}
+
+// FriendServiceInterface defines all the methods related to the friend graph.
+type FriendServiceInterface interface {
+	GetFriends(ctx context.Context, userID string) (*models.FriendList, error)
+	SendRequest(ctx context.Context, userID, friendID string) (*models.Friendship, error)
+	AcceptRequest(ctx context.Context, userID, friendID string) (*models.Friendship, error)
+	RemoveFriend(ctx context.Context, userID, friendID string) error
+	BlockUser(ctx context.Context, userID, friendID string) (*models.Friendship, error)
+}
+
+// SeasonServiceInterface defines all the methods related to the season pass.
+type SeasonServiceInterface interface {
+	GetSeason(ctx context.Context, userID string) (*models.SeasonStatus, error)
+	ClaimTier(ctx context.Context, userID string, tier int, track string) (*models.SeasonClaim, error)
+	PurchasePremium(ctx context.Context, userID string) (*models.SeasonStatus, error)
+}
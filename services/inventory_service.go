@@ -0,0 +1,251 @@
+// services/inventory_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"good_blast/database"
+	"good_blast/errors"
+	"good_blast/models"
+)
+
+// DefaultLivesRefillCost is the coin price of refilling lives when none is configured.
+const DefaultLivesRefillCost = 900
+
+// livesAttempts bounds how often a lives change is retried after a concurrent change.
+const livesAttempts = 3
+
+// InventoryService implements InventoryServiceInterface.
+type InventoryService struct {
+	DB         database.DatabaseInterface
+	Catalog    models.BoosterCatalog // boosters that can be bought and used
+	Lives      models.LivesPolicy
+	RefillCost int // coins to refill lives to the maximum
+}
+
+// NewInventoryService creates a new instance of InventoryService with the default booster
+// catalog and lives policy.
+func NewInventoryService(db database.DatabaseInterface) *InventoryService {
+	return &InventoryService{
+		DB:         db,
+		Catalog:    models.DefaultBoosterCatalog,
+		Lives:      models.DefaultLivesPolicy,
+		RefillCost: DefaultLivesRefillCost,
+	}
+}
+
+// GetBoosters lists every booster in the catalog with how many the user holds, followed by
+// boosters the user still holds that are no longer sold.
+func (s *InventoryService) GetBoosters(ctx context.Context, userID string) ([]models.BoosterStack, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stacks := make([]models.BoosterStack, 0, len(s.Catalog))
+	for _, item := range s.Catalog {
+		stacks = append(stacks, models.BoosterStack{
+			BoosterID: item.ID,
+			Name:      item.Name,
+			Count:     user.Boosters[item.ID],
+			Price:     item.Price,
+		})
+	}
+
+	var retired []string
+	for id, n := range user.Boosters {
+		if _, sold := s.Catalog.Find(id); !sold && n > 0 {
+			retired = append(retired, id)
+		}
+	}
+	sort.Strings(retired)
+	for _, id := range retired {
+		stacks = append(stacks, models.BoosterStack{BoosterID: id, Count: user.Boosters[id]})
+	}
+	return stacks, nil
+}
+
+// PurchaseBooster buys quantity boosters of one type with coins. The coins are debited and the
+// boosters credited atomically, and the purchase is recorded in the user's history.
+func (s *InventoryService) PurchaseBooster(ctx context.Context, userID, boosterID string, quantity int) (*models.BoosterPurchase, error) {
+	item, ok := s.Catalog.Find(boosterID)
+	if !ok {
+		return nil, errors.ErrUnknownBooster
+	}
+	if quantity < 1 || quantity > models.MaxBoosterPurchase {
+		return nil, errors.ErrInvalidQuantity
+	}
+	if item.MaxOwned > 0 && quantity > item.MaxOwned {
+		return nil, errors.ErrBoosterLimitReached
+	}
+
+	cost := item.Price * quantity
+	entry := models.HistoryEntry{
+		UserID: userID,
+		Type:   models.HistoryBoosterPurchase,
+		Coins:  -cost,
+		Reason: fmt.Sprintf("bought %d %s", quantity, item.Name),
+		Actor:  userID,
+		Bundle: &models.RewardBundle{Boosters: map[string]int{boosterID: quantity}},
+	}
+	if err := s.DB.PurchaseBoostersTransaction(ctx, entry, item.MaxOwned); err != nil {
+		log.Println("Error purchasing boosters:", err)
+		return nil, err
+	}
+
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching updated user:", err)
+		return nil, fmt.Errorf("could not fetch updated user data: %w", err)
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	return &models.BoosterPurchase{
+		BoosterID: boosterID,
+		Quantity:  quantity,
+		Cost:      cost,
+		Coins:     user.Coins,
+		Count:     user.Boosters[boosterID],
+	}, nil
+}
+
+// ConsumeBooster uses quantity boosters of one type during a level and returns how many are left.
+func (s *InventoryService) ConsumeBooster(ctx context.Context, userID, boosterID string, quantity int) (int, error) {
+	if _, ok := s.Catalog.Find(boosterID); !ok {
+		return 0, errors.ErrUnknownBooster
+	}
+	if quantity < 1 || quantity > models.MaxBoosterPurchase {
+		return 0, errors.ErrInvalidQuantity
+	}
+
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	left, err := s.DB.ConsumeBoosters(ctx, userID, boosterID, quantity)
+	if err != nil {
+		log.Println("Error consuming boosters:", err)
+		return 0, err
+	}
+	return left, nil
+}
+
+// livesStatus describes the user's lives as of now.
+func (s *InventoryService) livesStatus(user *models.User, now time.Time) *models.LivesStatus {
+	current := s.Lives.Regenerate(user.LivesRecord(), now)
+	status := &models.LivesStatus{Lives: current.Lives, MaxLives: s.Lives.Max}
+	if next := s.Lives.NextLifeAt(current); !next.IsZero() {
+		status.NextLifeAt = &next
+	}
+	if until := time.Unix(user.UnlimitedLivesUntil, 0).UTC(); until.After(now) {
+		status.UnlimitedLivesUntil = &until
+		status.Unlimited = true
+	}
+	return status
+}
+
+// getUser fetches a user, returning ErrUserNotFound when there is none.
+func (s *InventoryService) getUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching user:", err)
+		return nil, fmt.Errorf("could not fetch user data: %w", err)
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// GetLives returns the user's lives with regeneration applied.
+func (s *InventoryService) GetLives(ctx context.Context, userID string) (*models.LivesStatus, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.livesStatus(user, time.Now()), nil
+}
+
+// ConsumeLife spends one life to start a level. While an unlimited-lives boost runs nothing is
+// spent. Regenerated lives are written only here and on refills, conditioned on the stored
+// record so concurrent changes are retried rather than lost.
+func (s *InventoryService) ConsumeLife(ctx context.Context, userID string) (*models.LivesStatus, error) {
+	var err error
+	for attempt := 0; attempt < livesAttempts; attempt++ {
+		var user *models.User
+		user, err = s.getUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		status := s.livesStatus(user, now)
+		if status.Unlimited {
+			return status, nil
+		}
+		if status.Lives < 1 {
+			return nil, errors.ErrNoLivesLeft
+		}
+
+		from := user.LivesRecord()
+		to := s.Lives.Regenerate(from, now)
+		to.Lives--
+		err = s.DB.SetLives(ctx, userID, from, to)
+		if err == errors.ErrTransactionConflict {
+			continue
+		}
+		if err != nil {
+			log.Println("Error consuming life:", err)
+			return nil, err
+		}
+
+		user.Lives, user.LivesUpdatedAt = to.Lives, to.UpdatedAt
+		return s.livesStatus(user, now), nil
+	}
+	return nil, err
+}
+
+// RefillLives buys lives back up to the maximum with coins. The coins are debited and the lives
+// set atomically, and the refill is recorded in the user's history.
+func (s *InventoryService) RefillLives(ctx context.Context, userID string) (*models.LivesStatus, error) {
+	var err error
+	for attempt := 0; attempt < livesAttempts; attempt++ {
+		var user *models.User
+		user, err = s.getUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		from := user.LivesRecord()
+		current := s.Lives.Regenerate(from, now)
+		if current.Lives >= s.Lives.Max {
+			return nil, errors.ErrLivesFull
+		}
+
+		to := models.LivesRecord{Lives: s.Lives.Max, UpdatedAt: now.Unix()}
+		entry := models.HistoryEntry{
+			UserID: userID,
+			Type:   models.HistoryLivesRefill,
+			Coins:  -s.RefillCost,
+			Reason: fmt.Sprintf("refilled lives from %d to %d", current.Lives, to.Lives),
+			Actor:  userID,
+		}
+		err = s.DB.RefillLivesTransaction(ctx, entry, from, to)
+		if err == errors.ErrTransactionConflict {
+			continue
+		}
+		if err != nil {
+			log.Println("Error refilling lives:", err)
+			return nil, err
+		}
+
+		user.Lives, user.LivesUpdatedAt = to.Lives, to.UpdatedAt
+		return s.livesStatus(user, now), nil
+	}
+	return nil, err
+}
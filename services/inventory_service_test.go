@@ -0,0 +1,254 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apperrors "good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+	"good_blast/services/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetBoosters_ListsCatalogAndRetiredBoosters(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	user := &models.User{UserID: "u1", Inventory: models.Inventory{Boosters: map[string]int{"rocket": 3, "laser": 1, "shovel": 0}}}
+	mockDB.On("GetUser", mock.Anything, "u1").Return(user, nil).Once()
+
+	stacks, err := inventoryService.GetBoosters(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BoosterStack{
+		{BoosterID: "hammer", Name: "Hammer", Count: 0, Price: 300},
+		{BoosterID: "rocket", Name: "Rocket", Count: 3, Price: 250},
+		{BoosterID: "color_bomb", Name: "Color Bomb", Count: 0, Price: 500},
+		{BoosterID: "laser", Count: 1},
+	}, stacks)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestPurchaseBooster_DebitsCoinsAndRecordsHistory(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	mockDB.On("PurchaseBoostersTransaction", mock.Anything, models.HistoryEntry{
+		UserID: "u1",
+		Type:   models.HistoryBoosterPurchase,
+		Coins:  -500,
+		Reason: "bought 2 Rocket",
+		Actor:  "u1",
+		Bundle: &models.RewardBundle{Boosters: map[string]int{"rocket": 2}},
+	}, 99).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:    "u1",
+		Coins:     700,
+		Inventory: models.Inventory{Boosters: map[string]int{"rocket": 5}},
+	}, nil).Once()
+
+	purchase, err := inventoryService.PurchaseBooster(context.Background(), "u1", "rocket", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.BoosterPurchase{BoosterID: "rocket", Quantity: 2, Cost: 500, Coins: 700, Count: 5}, purchase)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestPurchaseBooster_RejectsInvalidRequests(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+	inventoryService.Catalog = models.BoosterCatalog{{ID: "hammer", Name: "Hammer", Price: 300, MaxOwned: 5}}
+	ctx := context.Background()
+
+	_, err := inventoryService.PurchaseBooster(ctx, "u1", "rocket", 1)
+	assert.Equal(t, apperrors.ErrUnknownBooster, err)
+	_, err = inventoryService.PurchaseBooster(ctx, "u1", "hammer", 0)
+	assert.Equal(t, apperrors.ErrInvalidQuantity, err)
+	_, err = inventoryService.PurchaseBooster(ctx, "u1", "hammer", 6)
+	assert.Equal(t, apperrors.ErrBoosterLimitReached, err)
+
+	mockDB.AssertNotCalled(t, "PurchaseBoostersTransaction", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPurchaseBooster_NotEnoughCoins(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	mockDB.On("PurchaseBoostersTransaction", mock.Anything, mock.AnythingOfType("models.HistoryEntry"), 99).Return(apperrors.ErrNotEnoughCoins).Once()
+
+	_, err := inventoryService.PurchaseBooster(context.Background(), "u1", "color_bomb", 3)
+	assert.Equal(t, apperrors.ErrNotEnoughCoins, err)
+
+	mockDB.AssertNotCalled(t, "GetUser", mock.Anything, mock.Anything)
+}
+
+func TestConsumeBooster(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1"}, nil)
+	mockDB.On("ConsumeBoosters", mock.Anything, "u1", "hammer", 1).Return(2, nil).Once()
+	mockDB.On("ConsumeBoosters", mock.Anything, "u1", "rocket", 1).Return(0, apperrors.ErrNotEnoughBoosters).Once()
+
+	left, err := inventoryService.ConsumeBooster(context.Background(), "u1", "hammer", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, left)
+
+	_, err = inventoryService.ConsumeBooster(context.Background(), "u1", "rocket", 1)
+	assert.Equal(t, apperrors.ErrNotEnoughBoosters, err)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestConsumeBooster_UserNotFound(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "ghost").Return(nil, nil).Once()
+
+	_, err := inventoryService.ConsumeBooster(context.Background(), "ghost", "hammer", 1)
+	assert.Equal(t, apperrors.ErrUserNotFound, err)
+
+	mockDB.AssertNotCalled(t, "ConsumeBoosters", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetLives_RegeneratesFromTimestamps(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	// Two lives left 70 minutes ago: two have regenerated, the next is 20 minutes away
+	updatedAt := time.Now().Add(-70 * time.Minute).Unix()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:    "u1",
+		Inventory: models.Inventory{Lives: 2, LivesUpdatedAt: updatedAt},
+	}, nil).Once()
+
+	status, err := inventoryService.GetLives(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, status.Lives)
+	assert.Equal(t, 5, status.MaxLives)
+	assert.False(t, status.Unlimited)
+	if assert.NotNil(t, status.NextLifeAt) {
+		assert.Equal(t, time.Unix(updatedAt, 0).Add(90*time.Minute).UTC(), *status.NextLifeAt)
+	}
+}
+
+func TestGetLives_NewUserHasFullLivesPlusGranted(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Inventory: models.Inventory{Lives: 3}}, nil).Once()
+
+	status, err := inventoryService.GetLives(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 8, status.Lives)
+	assert.Nil(t, status.NextLifeAt)
+}
+
+func TestConsumeLife_KeepsPartialRegeneration(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	from := models.LivesRecord{Lives: 1, UpdatedAt: time.Now().Add(-40 * time.Minute).Unix()}
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:    "u1",
+		Inventory: models.Inventory{Lives: from.Lives, LivesUpdatedAt: from.UpdatedAt},
+	}, nil).Once()
+	// One life regenerated after 30 minutes; the 10 minutes towards the next one are kept
+	to := models.LivesRecord{Lives: 1, UpdatedAt: from.UpdatedAt + 30*60}
+	mockDB.On("SetLives", mock.Anything, "u1", from, to).Return(nil).Once()
+
+	status, err := inventoryService.ConsumeLife(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, status.Lives)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestConsumeLife_RetriesAfterConcurrentChange(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	at := time.Now().Unix()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Inventory: models.Inventory{Lives: 3, LivesUpdatedAt: at}}, nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Inventory: models.Inventory{Lives: 2, LivesUpdatedAt: at}}, nil).Once()
+	mockDB.On("SetLives", mock.Anything, "u1", models.LivesRecord{Lives: 3, UpdatedAt: at}, mock.Anything).Return(apperrors.ErrTransactionConflict).Once()
+	mockDB.On("SetLives", mock.Anything, "u1", models.LivesRecord{Lives: 2, UpdatedAt: at}, models.LivesRecord{Lives: 1, UpdatedAt: at}).Return(nil).Once()
+
+	status, err := inventoryService.ConsumeLife(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, status.Lives)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestConsumeLife_NoLivesLeft(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:    "u1",
+		Inventory: models.Inventory{LivesUpdatedAt: time.Now().Add(-time.Minute).Unix()},
+	}, nil).Once()
+
+	_, err := inventoryService.ConsumeLife(context.Background(), "u1")
+	assert.Equal(t, apperrors.ErrNoLivesLeft, err)
+
+	mockDB.AssertNotCalled(t, "SetLives", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestConsumeLife_UnlimitedBoostSpendsNothing(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	until := time.Now().Add(time.Hour).Unix()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:    "u1",
+		Inventory: models.Inventory{LivesUpdatedAt: time.Now().Unix(), UnlimitedLivesUntil: until},
+	}, nil).Once()
+
+	status, err := inventoryService.ConsumeLife(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.True(t, status.Unlimited)
+	assert.Equal(t, time.Unix(until, 0).UTC(), *status.UnlimitedLivesUntil)
+
+	mockDB.AssertNotCalled(t, "SetLives", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefillLives(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	from := models.LivesRecord{Lives: 1, UpdatedAt: time.Now().Unix()}
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:    "u1",
+		Coins:     2000,
+		Inventory: models.Inventory{Lives: from.Lives, LivesUpdatedAt: from.UpdatedAt},
+	}, nil).Once()
+	mockDB.On("RefillLivesTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.Type == models.HistoryLivesRefill && e.Coins == -900 && e.UserID == "u1"
+	}), from, mock.AnythingOfType("models.LivesRecord")).Return(nil).Once()
+
+	status, err := inventoryService.RefillLives(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, status.Lives)
+	assert.Nil(t, status.NextLifeAt)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestRefillLives_AlreadyFull(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	inventoryService := services.NewInventoryService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1"}, nil).Once()
+
+	_, err := inventoryService.RefillLives(context.Background(), "u1")
+	assert.Equal(t, apperrors.ErrLivesFull, err)
+
+	mockDB.AssertNotCalled(t, "RefillLivesTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
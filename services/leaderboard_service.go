@@ -5,102 +5,382 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
 	"good_blast/database"
 	"good_blast/errors"
-	"good_blast/models" // to access services.RDB
-	redisclient "good_blast/services/redis_client"
-	"time"
+	"good_blast/models"
+	"good_blast/services/cache"
+
+	"golang.org/x/sync/singleflight"
 )
 
-// LeaderboardService implements LeaderboardServiceInterface
+// leaderboardSize is the default (and cached) page size of the global and country leaderboards.
+const leaderboardSize = 1000
+
+// LeaderboardCacheTTLs controls how long each leaderboard type is served from cache.
+type LeaderboardCacheTTLs struct {
+	Global     time.Duration
+	Country    time.Duration
+	Tournament time.Duration
+}
+
+// DefaultLeaderboardCacheTTLs caches every leaderboard for 60 seconds.
+var DefaultLeaderboardCacheTTLs = LeaderboardCacheTTLs{
+	Global:     60 * time.Second,
+	Country:    60 * time.Second,
+	Tournament: 60 * time.Second,
+}
+
+// CachePolicy decides what happens to a cached leaderboard when its rankings change.
+type CachePolicy int
+
+const (
+	// InvalidateOnWrite drops the cached copy; the next read reloads it from DynamoDB.
+	InvalidateOnWrite CachePolicy = iota
+	// WriteThrough reloads the leaderboard right after the write and re-caches it.
+	WriteThrough
+)
+
+// ParseCachePolicy converts a config value ("invalidate" or "write-through") to a CachePolicy.
+func ParseCachePolicy(s string) (CachePolicy, error) {
+	switch s {
+	case "", "invalidate":
+		return InvalidateOnWrite, nil
+	case "write-through":
+		return WriteThrough, nil
+	default:
+		return InvalidateOnWrite, fmt.Errorf("unknown cache policy %q", s)
+	}
+}
+
+// LeaderboardCachePolicies holds the CachePolicy for each leaderboard type.
+type LeaderboardCachePolicies struct {
+	Global     CachePolicy
+	Country    CachePolicy
+	Tournament CachePolicy
+}
+
+// DefaultLeaderboardCachePolicies invalidates the large level leaderboards and
+// writes through the small (35 entry) tournament group leaderboards.
+var DefaultLeaderboardCachePolicies = LeaderboardCachePolicies{
+	Global:     InvalidateOnWrite,
+	Country:    InvalidateOnWrite,
+	Tournament: WriteThrough,
+}
+
+// leaderboardLoadTimeout bounds a coalesced DynamoDB load, which runs detached from any single request.
+const leaderboardLoadTimeout = 10 * time.Second
+
+// LeaderboardService implements LeaderboardServiceInterface and LeaderboardInvalidator
 type LeaderboardService struct {
-	DB database.DatabaseInterface
+	DB       database.DatabaseInterface
+	Cache    cache.Cache
+	TTLs     LeaderboardCacheTTLs
+	Policies LeaderboardCachePolicies
+
+	loads singleflight.Group // coalesces concurrent loads of the same cache key
+
+	tokenMu    sync.Mutex
+	nextToken  uint64
+	loadTokens map[string]uint64 // token of the running load per key; invalidation drops it so the load doesn't cache
 }
 
-// NewLeaderboardService creates a new LeaderboardService
-func NewLeaderboardService(db database.DatabaseInterface) *LeaderboardService {
+// NewLeaderboardService creates a new LeaderboardService.
+// A nil cache disables caching; leaderboards are then always read from DynamoDB.
+func NewLeaderboardService(db database.DatabaseInterface, c cache.Cache) *LeaderboardService {
+	if c == nil {
+		c = cache.NewNoop()
+	}
 	return &LeaderboardService{
-		DB: db,
+		DB:       db,
+		Cache:    c,
+		TTLs:     DefaultLeaderboardCacheTTLs,
+		Policies: DefaultLeaderboardCachePolicies,
 	}
 }
 
-func (s *LeaderboardService) GetGlobalLeaderboard(ctx context.Context) ([]models.User, error) {
-	// 1. Attempt to get from Redis
-	cachedData, err := redisclient.RDB.Get(ctx, "leaderboard:global").Result()
-	if err == nil && cachedData != "" {
-		// We got cached data
-		var users []models.User
-		if err := json.Unmarshal([]byte(cachedData), &users); err == nil {
-			return users, nil
-		}
-		// If unmarshal fails, we fall through and fetch fresh data
+// leaderboardKeyPrefix is shared by every leaderboard cache key.
+const leaderboardKeyPrefix = "leaderboard:"
+
+func globalLeaderboardKey() string {
+	return leaderboardKeyPrefix + "global"
+}
+
+func countryLeaderboardKey(countryCode string) string {
+	return leaderboardKeyPrefix + "country:" + countryCode
+}
+
+func tournamentLeaderboardKey(groupId string) string {
+	return leaderboardKeyPrefix + "tournament:" + groupId
+}
+
+// getCached decodes the cached JSON under key into dst. Cache errors are logged and treated as a miss.
+func (s *LeaderboardService) getCached(ctx context.Context, key string, dst interface{}) bool {
+	cachedData, found, err := s.Cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("LeaderboardService: cache read failed for %s: %v", key, err)
+		return false
 	}
+	if !found || len(cachedData) == 0 {
+		return false
+	}
+	// If unmarshal fails, we fall through and fetch fresh data
+	return json.Unmarshal(cachedData, dst) == nil
+}
 
-	// 2. If not in Redis or error, fetch from DynamoDB
-	users, err := s.DB.QueryGlobalLeaderboard(ctx)
+// setCached stores v as JSON under key. Failures only cost a future cache miss, so they are logged.
+func (s *LeaderboardService) setCached(ctx context.Context, key string, v interface{}, ttl time.Duration) {
+	data, err := json.Marshal(v)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get global leaderboard: %w", err)
+		return
 	}
+	if err := s.Cache.Set(ctx, key, data, ttl); err != nil {
+		log.Printf("LeaderboardService: cache write failed for %s: %v", key, err)
+	}
+}
 
-	// 3. Cache the result in Redis for, say, 60 seconds
-	userBytes, err := json.Marshal(users)
-	if err == nil {
-		redisclient.RDB.Set(ctx, "leaderboard:global", string(userBytes), 60*time.Second)
+// beginLoad registers a load of key and returns its token. Only keys with a load running are
+// tracked, so the set stays as small as the number of concurrent loads.
+func (s *LeaderboardService) beginLoad(key string) uint64 {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+	if s.loadTokens == nil {
+		s.loadTokens = make(map[string]uint64)
 	}
+	s.nextToken++
+	s.loadTokens[key] = s.nextToken
+	return s.nextToken
+}
 
-	return users, nil
+// endLoad unregisters the load of key holding token and reports whether its result may be
+// cached, which is only when key was not invalidated while it ran.
+func (s *LeaderboardService) endLoad(key string, token uint64) bool {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+	if s.loadTokens[key] != token {
+		return false
+	}
+	delete(s.loadTokens, key)
+	return true
+}
+
+// deleteCached drops key from the cache and forgets any in-flight load for it, so the next
+// reader does not receive a result loaded before the write. Dropping the load's token stops it
+// from caching its result.
+func (s *LeaderboardService) deleteCached(ctx context.Context, key string) {
+	s.tokenMu.Lock()
+	delete(s.loadTokens, key)
+	s.tokenMu.Unlock()
+
+	s.loads.Forget(key)
+	if err := s.Cache.Delete(ctx, key); err != nil {
+		log.Printf("LeaderboardService: cache delete failed for %s: %v", key, err)
+	}
+}
+
+// invalidateLoads stops every running load from caching its result and forgets them, so the
+// next reader of any key starts a fresh load.
+func (s *LeaderboardService) invalidateLoads() {
+	s.tokenMu.Lock()
+	running := s.loadTokens
+	s.loadTokens = nil
+	s.tokenMu.Unlock()
+
+	for key := range running {
+		s.loads.Forget(key)
+	}
+}
+
+// loadAndCache runs load at most once per key at a time (singleflight) and caches the result.
+// Concurrent callers for the same key wait for the shared load, so a cache expiry costs a
+// single DynamoDB query instead of one per request. A result loaded across an invalidation of
+// key is returned but not cached, since it may predate the write.
+func loadAndCache[T any](ctx context.Context, s *LeaderboardService, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	ch := s.loads.DoChan(key, func() (interface{}, error) {
+		// Detached from the caller so one cancelled request doesn't fail every waiter
+		loadCtx, cancel := context.WithTimeout(context.Background(), leaderboardLoadTimeout)
+		defer cancel()
+
+		token := s.beginLoad(key)
+		v, err := load(loadCtx)
+		if !s.endLoad(key, token) || err != nil {
+			return v, err
+		}
+		s.setCached(loadCtx, key, v, ttl)
+		return v, nil
+	})
+
+	var zero T
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(T), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
 }
 
-func (s *LeaderboardService) GetCountryLeaderboard(ctx context.Context, countryCode string) ([]models.User, error) {
-	cacheKey := "leaderboard:country:" + countryCode
+// cacheablePage reports whether page is the first page at the default size, the only
+// page that is cached, and returns it normalised to an explicit limit.
+func cacheablePage(page models.PageRequest) (models.PageRequest, bool) {
+	if page.Cursor == "" && (page.Limit == 0 || page.Limit == leaderboardSize) {
+		return models.PageRequest{Limit: leaderboardSize}, true
+	}
+	return page, false
+}
 
-	// Try Redis first
-	cachedData, err := redisclient.RDB.Get(ctx, "leaderboard:global").Result()
-	if err == nil && cachedData != "" {
-		var users []models.User
-		if err := json.Unmarshal([]byte(cachedData), &users); err == nil {
-			return users, nil
+// GetGlobalLeaderboard retrieves one page of users globally ordered by level.
+func (s *LeaderboardService) GetGlobalLeaderboard(ctx context.Context, page models.PageRequest) (models.Page[models.User], error) {
+	cacheKey := globalLeaderboardKey()
+
+	page, cacheable := cacheablePage(page)
+	if !cacheable {
+		users, err := s.DB.QueryGlobalLeaderboard(ctx, page)
+		if err != nil {
+			return users, s.wrapQueryError("failed to get global leaderboard", err)
 		}
+		return users, nil
+	}
+
+	// 1. Attempt to get from cache
+	var users models.Page[models.User]
+	if s.getCached(ctx, cacheKey, &users) {
+		return users, nil
 	}
 
-	// Fetch from DB if Redis miss
-	users, err := s.DB.QueryUsersByCountryLevel(ctx, countryCode)
+	// 2. If not cached or cache unavailable, fetch from DynamoDB and cache for the configured TTL
+	users, err := loadAndCache(ctx, s, cacheKey, s.TTLs.Global, func(ctx context.Context) (models.Page[models.User], error) {
+		return s.DB.QueryGlobalLeaderboard(ctx, page)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get country leaderboard: %w", err)
+		return users, fmt.Errorf("failed to get global leaderboard: %w", err)
 	}
 
-	// Cache result
-	userBytes, err := json.Marshal(users)
-	if err == nil {
-		redisclient.RDB.Set(ctx, cacheKey, string(userBytes), 60*time.Second)
+	return users, nil
+}
+
+// GetCountryLeaderboard retrieves one page of users in a country ordered by level.
+func (s *LeaderboardService) GetCountryLeaderboard(ctx context.Context, countryCode string, page models.PageRequest) (models.Page[models.User], error) {
+	cacheKey := countryLeaderboardKey(countryCode)
+
+	page, cacheable := cacheablePage(page)
+	if !cacheable {
+		users, err := s.DB.QueryUsersByCountryLevel(ctx, countryCode, page)
+		if err != nil {
+			return users, s.wrapQueryError("failed to get country leaderboard", err)
+		}
+		return users, nil
+	}
+
+	// Try cache first
+	var users models.Page[models.User]
+	if s.getCached(ctx, cacheKey, &users) {
+		return users, nil
+	}
+
+	// Fetch from DB if cache miss
+	users, err := loadAndCache(ctx, s, cacheKey, s.TTLs.Country, func(ctx context.Context) (models.Page[models.User], error) {
+		return s.DB.QueryUsersByCountryLevel(ctx, countryCode, page)
+	})
+	if err != nil {
+		return users, fmt.Errorf("failed to get country leaderboard: %w", err)
 	}
 
 	return users, nil
 }
 
+// wrapQueryError adds context to a query error, passing ErrInvalidCursor through unchanged
+// so handlers can report it as a client error.
+func (s *LeaderboardService) wrapQueryError(msg string, err error) error {
+	if err == errors.ErrInvalidCursor {
+		return err
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
 // GetTournamentLeaderboard retrieves the top 35 users in a specific tournament group based on score.
 func (s *LeaderboardService) GetTournamentLeaderboard(ctx context.Context, groupId string) ([]models.TournamentEntry, error) {
-	cacheKey := "leaderboard:tournament:" + groupId
+	cacheKey := tournamentLeaderboardKey(groupId)
 
-	cachedData, err := redisclient.RDB.Get(ctx, cacheKey).Result()
-	if err == nil && cachedData != "" {
-		var entries []models.TournamentEntry
-		if err := json.Unmarshal([]byte(cachedData), &entries); err == nil {
-			return entries, nil
-		}
+	var entries []models.TournamentEntry
+	if s.getCached(ctx, cacheKey, &entries) {
+		return entries, nil
 	}
 
-	entries, err := s.DB.QueryTournamentEntriesByGroupScore(ctx, groupId)
+	entries, err := loadAndCache(ctx, s, cacheKey, s.TTLs.Tournament, func(ctx context.Context) ([]models.TournamentEntry, error) {
+		return s.DB.QueryTournamentEntriesByGroupScore(ctx, groupId)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tournament leaderboard: %w", err)
 	}
 
-	entryBytes, err := json.Marshal(entries)
-	if err == nil {
-		redisclient.RDB.Set(ctx, cacheKey, string(entryBytes), 60*time.Second)
+	return entries, nil
+}
+
+// UserLevelChanged applies the cache policy to the global and country leaderboards after
+// a user's level changed. user is the state after the update.
+func (s *LeaderboardService) UserLevelChanged(ctx context.Context, user models.User) {
+	s.applyPolicy(ctx, globalLeaderboardKey(), s.Policies.Global, &user, func(ctx context.Context) error {
+		_, err := s.GetGlobalLeaderboard(ctx, models.PageRequest{})
+		return err
+	})
+
+	if user.Country == "" {
+		return
 	}
+	s.applyPolicy(ctx, countryLeaderboardKey(user.Country), s.Policies.Country, &user, func(ctx context.Context) error {
+		_, err := s.GetCountryLeaderboard(ctx, user.Country, models.PageRequest{})
+		return err
+	})
+}
 
-	return entries, nil
+// GroupScoreChanged applies the cache policy to a tournament group leaderboard after a score update.
+func (s *LeaderboardService) GroupScoreChanged(ctx context.Context, groupId string) {
+	s.applyPolicy(ctx, tournamentLeaderboardKey(groupId), s.Policies.Tournament, nil, func(ctx context.Context) error {
+		_, err := s.GetTournamentLeaderboard(ctx, groupId)
+		return err
+	})
+}
+
+// applyPolicy invalidates key and, for WriteThrough, reloads it. When changed is set and the
+// cached level leaderboard provably doesn't include it, the cached copy is left alone.
+func (s *LeaderboardService) applyPolicy(ctx context.Context, key string, policy CachePolicy, changed *models.User, reload func(ctx context.Context) error) {
+	if changed != nil && s.cachedUsersUnaffected(ctx, key, *changed) {
+		return
+	}
+
+	s.deleteCached(ctx, key)
+	if policy == WriteThrough {
+		if err := reload(ctx); err != nil {
+			log.Printf("LeaderboardService: write-through of %s failed: %v", key, err)
+		}
+	}
+}
+
+// cachedUsersUnaffected reports whether a cached, full level leaderboard provably doesn't
+// change when user levels up: the user isn't on it and still ranks below its last entry.
+// Anything uncertain (no cached copy, short board, decode error) returns false.
+func (s *LeaderboardService) cachedUsersUnaffected(ctx context.Context, key string, user models.User) bool {
+	var page models.Page[models.User]
+	if !s.getCached(ctx, key, &page) {
+		return false
+	}
+	users := page.Items
+	if len(users) < leaderboardSize {
+		return false
+	}
+	for _, u := range users {
+		if u.UserID == user.UserID {
+			return false
+		}
+	}
+	return user.Level < users[len(users)-1].Level
 }
 
 // GetTournamentRank retrieves a user's rank in a specific tournament group.
@@ -129,3 +409,138 @@ func (s *LeaderboardService) GetTournamentRank(ctx context.Context, tournamentId
 
 	return 0, errors.ErrUserNotFoundInLeaderboard
 }
+
+// acceptedFriendIDs returns the IDs of a user's accepted friends with the username each was
+// befriended under.
+func (s *LeaderboardService) acceptedFriendIDs(ctx context.Context, userID string) ([]string, map[string]string, error) {
+	friendships, err := s.DB.QueryFriendships(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get friends: %w", err)
+	}
+	var ids []string
+	names := make(map[string]string)
+	for _, f := range friendships {
+		if f.Status == models.FriendStatusAccepted {
+			ids = append(ids, f.FriendID)
+			names[f.FriendID] = f.Username
+		}
+	}
+	return ids, names, nil
+}
+
+// GetFriendsLeaderboard ranks a user and their accepted friends by level. Friends' boards are
+// small and personal, so they are read from DynamoDB rather than cached.
+func (s *LeaderboardService) GetFriendsLeaderboard(ctx context.Context, userID string) ([]models.User, error) {
+	ids, _, err := s.acceptedFriendIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.DB.BatchGetUsers(ctx, append([]string{userID}, ids...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get friends leaderboard: %w", err)
+	}
+	found := false
+	for _, u := range users {
+		if u.UserID == userID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.ErrUserNotFound
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		if users[i].Level != users[j].Level {
+			return users[i].Level > users[j].Level
+		}
+		return users[i].Username < users[j].Username
+	})
+	return users, nil
+}
+
+// GetFriendsInTournament shows which of a user's accepted friends entered a tournament, today's
+// when tournamentID is empty, with each friend's rank in their group. Group ranks come from the
+// cached tournament leaderboards.
+func (s *LeaderboardService) GetFriendsInTournament(ctx context.Context, userID, tournamentID string) (*models.FriendsTournament, error) {
+	if tournamentID == "" {
+		tournamentID = time.Now().UTC().Format("2006-01-02")
+	}
+	t, err := s.DB.GetTournament(ctx, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tournament: %w", err)
+	}
+	if t == nil {
+		return nil, errors.ErrTournamentNotFound
+	}
+
+	ids, names, err := s.acceptedFriendIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.DB.BatchGetTournamentEntries(ctx, tournamentID, append([]string{userID}, ids...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get friends' tournament entries: %w", err)
+	}
+
+	out := &models.FriendsTournament{TournamentID: tournamentID, Friends: []models.FriendTournamentStanding{}}
+	for _, e := range entries {
+		if e.UserID == userID {
+			out.GroupID = e.GroupID
+		}
+	}
+
+	ranks := make(map[string]map[string]int) // groupId -> userId -> rank
+	for _, e := range entries {
+		if e.UserID == userID {
+			continue
+		}
+		groupRanks, ok := ranks[e.GroupID]
+		if !ok {
+			group, err := s.GetTournamentLeaderboard(ctx, e.GroupID)
+			if err != nil {
+				return nil, err
+			}
+			groupRanks = make(map[string]int, len(group))
+			for i, g := range group {
+				groupRanks[g.UserID] = i + 1
+			}
+			ranks[e.GroupID] = groupRanks
+		}
+
+		out.Friends = append(out.Friends, models.FriendTournamentStanding{
+			UserID:    e.UserID,
+			Username:  names[e.UserID],
+			GroupID:   e.GroupID,
+			League:    e.League,
+			Score:     e.Score,
+			Rank:      groupRanks[e.UserID],
+			SameGroup: out.GroupID != "" && e.GroupID == out.GroupID,
+		})
+	}
+	sort.SliceStable(out.Friends, func(i, j int) bool {
+		if out.Friends[i].Score != out.Friends[j].Score {
+			return out.Friends[i].Score > out.Friends[j].Score
+		}
+		return out.Friends[i].Username < out.Friends[j].Username
+	})
+	return out, nil
+}
+
+// FlushCaches drops every cached leaderboard and returns how many keys were removed.
+// Caches that can't delete by prefix only lose the global leaderboard.
+func (s *LeaderboardService) FlushCaches(ctx context.Context) (int, error) {
+	// Loads running during the flush may have read data from before it
+	s.invalidateLoads()
+	pd, ok := s.Cache.(cache.PrefixDeleter)
+	if !ok {
+		s.deleteCached(ctx, globalLeaderboardKey())
+		return 1, nil
+	}
+	n, err := pd.DeletePrefix(ctx, leaderboardKeyPrefix)
+	if err != nil {
+		return n, fmt.Errorf("failed to flush leaderboard caches: %w", err)
+	}
+	return n, nil
+}
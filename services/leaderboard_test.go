@@ -3,64 +3,70 @@ package services_test
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
+	apperrors "good_blast/errors"
 	"good_blast/models"
 	"good_blast/services"
+	"good_blast/services/cache"
 	"good_blast/services/mocks"
-	redisclient "good_blast/services/redis_client"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-func init() {
-	redisclient.RDB = redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
-	})
+// firstPage is the normalised request the service sends for the default, cacheable page.
+var firstPage = models.PageRequest{Limit: 1000}
+
+// failingCache simulates Redis being down: every operation errors.
+type failingCache struct{}
+
+func (failingCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+func (failingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("connection refused")
 }
+func (failingCache) Delete(ctx context.Context, keys ...string) error {
+	return errors.New("connection refused")
+}
+func (failingCache) Close() error { return nil }
 
 func TestGetGlobalLeaderboard_DBError(t *testing.T) {
 	mockDB := new(mocks.MockDatabase)
-	service := services.NewLeaderboardService(mockDB)
+	service := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
 	ctx := context.Background()
 
-	// Clear cache
-	redisclient.RDB.Del(ctx, "leaderboard:global")
-
-	mockDB.On("QueryGlobalLeaderboard", mock.Anything).Return(nil, errors.New("db error"))
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, firstPage).Return(nil, errors.New("db error"))
 
-	result, err := service.GetGlobalLeaderboard(ctx)
+	result, err := service.GetGlobalLeaderboard(ctx, models.PageRequest{})
 	assert.Error(t, err)
-	assert.Nil(t, result)
+	assert.Empty(t, result.Items)
 	mockDB.AssertExpectations(t)
 }
 
 func TestGetCountryLeaderboard_DBError(t *testing.T) {
 	mockDB := new(mocks.MockDatabase)
-	service := services.NewLeaderboardService(mockDB)
+	service := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
 	ctx := context.Background()
 	countryCode := "DE"
 
-	redisclient.RDB.Del(ctx, "leaderboard:country:"+countryCode)
-
-	mockDB.On("QueryUsersByCountryLevel", mock.Anything, countryCode).Return(nil, errors.New("db error"))
+	mockDB.On("QueryUsersByCountryLevel", mock.Anything, countryCode, firstPage).Return(nil, errors.New("db error"))
 
-	result, err := service.GetCountryLeaderboard(ctx, countryCode)
+	result, err := service.GetCountryLeaderboard(ctx, countryCode, models.PageRequest{})
 	assert.Error(t, err)
-	assert.Nil(t, result)
+	assert.Empty(t, result.Items)
 	mockDB.AssertExpectations(t)
 }
 
 func TestGetTournamentLeaderboard_DBError(t *testing.T) {
 	mockDB := new(mocks.MockDatabase)
-	service := services.NewLeaderboardService(mockDB)
+	service := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
 	ctx := context.Background()
 	groupId := "g-err"
 
-	redisclient.RDB.Del(ctx, "leaderboard:tournament:"+groupId)
-
 	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, groupId).Return(nil, errors.New("db error"))
 
 	result, err := service.GetTournamentLeaderboard(ctx, groupId)
@@ -69,9 +75,65 @@ func TestGetTournamentLeaderboard_DBError(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+func TestGetGlobalLeaderboard_ServedFromCache(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
+	ctx := context.Background()
+	users := []models.User{
+		{UserID: "u1", Username: "alice", Level: 50, GlobalPK: "GLOBAL"},
+		{UserID: "u2", Username: "bob", Level: 40, GlobalPK: "GLOBAL"},
+	}
+
+	// Only the first call may reach DynamoDB
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, firstPage).Return(models.Page[models.User]{Items: users}, nil).Once()
+
+	first, err := service.GetGlobalLeaderboard(ctx, models.PageRequest{})
+	assert.NoError(t, err)
+	second, err := service.GetGlobalLeaderboard(ctx, models.PageRequest{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, users, first.Items)
+	assert.Equal(t, users, second.Items)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetTournamentLeaderboard_CacheUnavailable(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewLeaderboardService(mockDB, failingCache{})
+	ctx := context.Background()
+	groupId := "g-1"
+	entries := []models.TournamentEntry{
+		{TournamentID: "t-1", UserID: "u1", Score: 12, GroupID: groupId},
+	}
+
+	// With the cache down every request goes to DynamoDB instead of failing
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, groupId).Return(entries, nil).Twice()
+
+	for i := 0; i < 2; i++ {
+		result, err := service.GetTournamentLeaderboard(ctx, groupId)
+		assert.NoError(t, err)
+		assert.Equal(t, entries, result)
+	}
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetGlobalLeaderboard_NilCache(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewLeaderboardService(mockDB, nil)
+	ctx := context.Background()
+	users := []models.User{{UserID: "u1", Level: 3, GlobalPK: "GLOBAL"}}
+
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, firstPage).Return(models.Page[models.User]{Items: users}, nil)
+
+	result, err := service.GetGlobalLeaderboard(ctx, models.PageRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, users, result.Items)
+	mockDB.AssertExpectations(t)
+}
+
 func TestGetTournamentRank_Success(t *testing.T) {
 	mockDB := new(mocks.MockDatabase)
-	service := services.NewLeaderboardService(mockDB)
+	service := services.NewLeaderboardService(mockDB, nil)
 	ctx := context.Background()
 	tID := "t-2024"
 	userID := "user123"
@@ -100,7 +162,7 @@ func TestGetTournamentRank_Success(t *testing.T) {
 
 func TestGetTournamentRank_EntryNotFound(t *testing.T) {
 	mockDB := new(mocks.MockDatabase)
-	service := services.NewLeaderboardService(mockDB)
+	service := services.NewLeaderboardService(mockDB, nil)
 	ctx := context.Background()
 	tID := "t-2024"
 	userID := "unknown-user"
@@ -115,7 +177,7 @@ func TestGetTournamentRank_EntryNotFound(t *testing.T) {
 
 func TestGetTournamentRank_DBErrorOnEntry(t *testing.T) {
 	mockDB := new(mocks.MockDatabase)
-	service := services.NewLeaderboardService(mockDB)
+	service := services.NewLeaderboardService(mockDB, nil)
 	ctx := context.Background()
 	tID := "t-2024"
 	userID := "user123"
@@ -130,7 +192,7 @@ func TestGetTournamentRank_DBErrorOnEntry(t *testing.T) {
 
 func TestGetTournamentRank_DBErrorOnQuery(t *testing.T) {
 	mockDB := new(mocks.MockDatabase)
-	service := services.NewLeaderboardService(mockDB)
+	service := services.NewLeaderboardService(mockDB, nil)
 	ctx := context.Background()
 	tID := "t-2024"
 	userID := "user123"
@@ -152,7 +214,7 @@ func TestGetTournamentRank_DBErrorOnQuery(t *testing.T) {
 
 func TestGetTournamentRank_UserNotInList(t *testing.T) {
 	mockDB := new(mocks.MockDatabase)
-	service := services.NewLeaderboardService(mockDB)
+	service := services.NewLeaderboardService(mockDB, nil)
 	ctx := context.Background()
 	tID := "t-2024"
 	userID := "user123"
@@ -176,3 +238,314 @@ func TestGetTournamentRank_UserNotInList(t *testing.T) {
 	assert.Contains(t, err.Error(), "user not found in the leaderboard")
 	mockDB.AssertExpectations(t)
 }
+
+func TestGetCountryLeaderboard_UsesCountryCacheKey(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
+	ctx := context.Background()
+	global := []models.User{{UserID: "u1", Country: "US", Level: 90, GlobalPK: "GLOBAL"}}
+	german := []models.User{{UserID: "u2", Country: "DE", Level: 20, GlobalPK: "GLOBAL"}}
+
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, firstPage).Return(models.Page[models.User]{Items: global}, nil).Once()
+	mockDB.On("QueryUsersByCountryLevel", mock.Anything, "DE", firstPage).Return(models.Page[models.User]{Items: german}, nil).Once()
+
+	// A cached global leaderboard must not be served as a country leaderboard
+	_, err := service.GetGlobalLeaderboard(ctx, models.PageRequest{})
+	assert.NoError(t, err)
+	result, err := service.GetCountryLeaderboard(ctx, "DE", models.PageRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, german, result.Items)
+
+	// Second read is served from the country key
+	result, err = service.GetCountryLeaderboard(ctx, "DE", models.PageRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, german, result.Items)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateUserProgress_InvalidatesLevelLeaderboards(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	leaderboards := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
+	userService := services.NewUserService(mockDB)
+	userService.Leaderboards = leaderboards
+	ctx := context.Background()
+
+	before := []models.User{{UserID: "u1", Country: "DE", Level: 5, GlobalPK: "GLOBAL"}}
+	after := []models.User{{UserID: "u1", Country: "DE", Level: 6, GlobalPK: "GLOBAL"}}
+
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, firstPage).Return(models.Page[models.User]{Items: before}, nil).Once()
+	mockDB.On("QueryUsersByCountryLevel", mock.Anything, "DE", firstPage).Return(models.Page[models.User]{Items: before}, nil).Once()
+	_, _ = leaderboards.GetGlobalLeaderboard(ctx, models.PageRequest{})
+	_, _ = leaderboards.GetCountryLeaderboard(ctx, "DE", models.PageRequest{})
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&before[0], nil).Once()
+	mockDB.On("AdvanceLevel", mock.Anything, "u1", 5, 6, models.RewardBundle{Coins: 100}).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&after[0], nil).Once()
+	_, err := userService.UpdateUserProgress(ctx, "u1", 6)
+	assert.NoError(t, err)
+
+	// Both boards are reloaded instead of serving the stale ranking
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, firstPage).Return(models.Page[models.User]{Items: after}, nil).Once()
+	mockDB.On("QueryUsersByCountryLevel", mock.Anything, "DE", firstPage).Return(models.Page[models.User]{Items: after}, nil).Once()
+	global, err := leaderboards.GetGlobalLeaderboard(ctx, models.PageRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, after, global.Items)
+	country, err := leaderboards.GetCountryLeaderboard(ctx, "DE", models.PageRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, after, country.Items)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateScore_WritesThroughGroupLeaderboard(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	leaderboards := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
+	tournamentService := services.NewTournamentService(mockDB)
+	tournamentService.Leaderboards = leaderboards
+	ctx := context.Background()
+	groupId := "2024-01-02-group-1"
+
+	entry := &models.TournamentEntry{TournamentID: "2024-01-02", UserID: "u1", Score: 10, GroupID: groupId}
+	before := []models.TournamentEntry{*entry}
+	after := []models.TournamentEntry{{TournamentID: "2024-01-02", UserID: "u1", Score: 15, GroupID: groupId}}
+
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, groupId).Return(before, nil).Once()
+	_, _ = leaderboards.GetTournamentLeaderboard(ctx, groupId)
+
+	mockDB.On("GetTournament", mock.Anything, "2024-01-02").Return(&models.Tournament{TournamentID: "2024-01-02", Active: true}, nil)
+	mockDB.On("GetTournamentEntry", mock.Anything, "2024-01-02", "u1").Return(entry, nil).Once()
+	mockDB.On("UpdateTournamentScore", mock.Anything, "2024-01-02", "u1", 5).Return(nil).Once()
+	// Write-through reloads the group right after the score update
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, groupId).Return(after, nil).Once()
+
+	_, err := tournamentService.UpdateScore(ctx, "2024-01-02", "u1", 5)
+	assert.NoError(t, err)
+
+	// The refreshed ranking is served from cache without another query
+	result, err := leaderboards.GetTournamentLeaderboard(ctx, groupId)
+	assert.NoError(t, err)
+	assert.Equal(t, after, result)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetGlobalLeaderboard_CoalescesConcurrentMisses(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
+	ctx := context.Background()
+	users := []models.User{{UserID: "u1", Level: 9, GlobalPK: "GLOBAL"}}
+
+	// A slow query gives every goroutine time to pile onto the same load
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, firstPage).
+		After(100*time.Millisecond).
+		Return(models.Page[models.User]{Items: users}, nil).Once()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := service.GetGlobalLeaderboard(ctx, models.PageRequest{})
+			assert.NoError(t, err)
+			assert.Equal(t, users, result.Items)
+		}()
+	}
+	wg.Wait()
+
+	mockDB.AssertNumberOfCalls(t, "QueryGlobalLeaderboard", 1)
+}
+
+func TestGetGlobalLeaderboard_LaterPagesBypassCache(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
+	ctx := context.Background()
+	page := models.PageRequest{Limit: 2, Cursor: "abc"}
+	users := models.Page[models.User]{
+		Items:      []models.User{{UserID: "u3", Level: 7}, {UserID: "u4", Level: 6}},
+		NextCursor: "def",
+	}
+
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, page).Return(users, nil).Twice()
+
+	for i := 0; i < 2; i++ {
+		result, err := service.GetGlobalLeaderboard(ctx, page)
+		assert.NoError(t, err)
+		assert.Equal(t, users, result)
+	}
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetCountryLeaderboard_InvalidCursor(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewLeaderboardService(mockDB, nil)
+	ctx := context.Background()
+	page := models.PageRequest{Cursor: "garbage"}
+
+	mockDB.On("QueryUsersByCountryLevel", mock.Anything, "DE", page).Return(nil, apperrors.ErrInvalidCursor)
+
+	_, err := service.GetCountryLeaderboard(ctx, "DE", page)
+	assert.Equal(t, apperrors.ErrInvalidCursor, err)
+	mockDB.AssertExpectations(t)
+}
+
+func TestFlushCaches_DropsOnlyLeaderboards(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	c := cache.NewLRU(10)
+	ctx := context.Background()
+	c.Set(ctx, "leaderboard:global", []byte("[]"), 0)
+	c.Set(ctx, "leaderboard:tournament:g1", []byte("[]"), 0)
+	c.Set(ctx, "other", []byte("x"), 0)
+	service := services.NewLeaderboardService(mockDB, c)
+
+	n, err := service.FlushCaches(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestGetFriendsLeaderboard_RanksUserAndFriendsByLevel(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
+
+	mockDB.On("QueryFriendships", mock.Anything, "u1").Return([]models.Friendship{
+		{UserID: "u1", FriendID: "u2", Status: models.FriendStatusAccepted},
+		{UserID: "u1", FriendID: "u3", Status: models.FriendStatusRequested}, // not a friend yet
+		{UserID: "u1", FriendID: "u4", Status: models.FriendStatusAccepted},
+	}, nil)
+	mockDB.On("BatchGetUsers", mock.Anything, []string{"u1", "u2", "u4"}).Return([]models.User{
+		{UserID: "u1", Username: "alice", Level: 5},
+		{UserID: "u2", Username: "bob", Level: 9},
+		{UserID: "u4", Username: "carol", Level: 5},
+	}, nil)
+
+	users, err := service.GetFriendsLeaderboard(context.Background(), "u1")
+	assert.NoError(t, err)
+	var order []string
+	for _, u := range users {
+		order = append(order, u.UserID)
+	}
+	assert.Equal(t, []string{"u2", "u1", "u4"}, order)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetFriendsInTournament_RanksFriendsInTheirGroups(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
+
+	mockDB.On("GetTournament", mock.Anything, "2024-01-15").Return(&models.Tournament{TournamentID: "2024-01-15", Active: true}, nil)
+	mockDB.On("QueryFriendships", mock.Anything, "u1").Return([]models.Friendship{
+		{UserID: "u1", FriendID: "u2", Username: "bob", Status: models.FriendStatusAccepted},
+		{UserID: "u1", FriendID: "u3", Username: "carol", Status: models.FriendStatusAccepted},
+		{UserID: "u1", FriendID: "u4", Username: "dave", Status: models.FriendStatusAccepted},
+	}, nil)
+	mockDB.On("BatchGetTournamentEntries", mock.Anything, "2024-01-15", []string{"u1", "u2", "u3", "u4"}).Return([]models.TournamentEntry{
+		{UserID: "u1", GroupID: "g1", Score: 4},
+		{UserID: "u2", GroupID: "g1", Score: 6},
+		{UserID: "u3", GroupID: "g2", Score: 8},
+	}, nil)
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, "g1").Return([]models.TournamentEntry{
+		{UserID: "u2", Score: 6}, {UserID: "u1", Score: 4},
+	}, nil).Once()
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, "g2").Return([]models.TournamentEntry{
+		{UserID: "x", Score: 10}, {UserID: "u3", Score: 8},
+	}, nil).Once()
+
+	out, err := service.GetFriendsInTournament(context.Background(), "u1", "2024-01-15")
+	assert.NoError(t, err)
+	assert.Equal(t, "g1", out.GroupID)
+	assert.Equal(t, []models.FriendTournamentStanding{
+		{UserID: "u3", Username: "carol", GroupID: "g2", Score: 8, Rank: 2},
+		{UserID: "u2", Username: "bob", GroupID: "g1", Score: 6, Rank: 1, SameGroup: true},
+	}, out.Friends) // dave did not enter
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetFriendsInTournament_UnknownTournament(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewLeaderboardService(mockDB, cache.NewLRU(10))
+
+	mockDB.On("GetTournament", mock.Anything, "2024-01-15").Return(nil, nil)
+
+	_, err := service.GetFriendsInTournament(context.Background(), "u1", "2024-01-15")
+	assert.Equal(t, apperrors.ErrTournamentNotFound, err)
+}
+
+func TestGetGlobalLeaderboard_LoadAcrossInvalidationIsNotCached(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	lru := cache.NewLRU(10)
+	service := services.NewLeaderboardService(mockDB, lru)
+	ctx := context.Background()
+	stale := []models.User{{UserID: "u1", Level: 9, GlobalPK: "GLOBAL"}}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, firstPage).
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).
+		Return(models.Page[models.User]{Items: stale}, nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		result, err := service.GetGlobalLeaderboard(ctx, models.PageRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, stale, result.Items)
+	}()
+
+	// A level-up lands while the load is reading the old rankings
+	<-started
+	service.UserLevelChanged(ctx, models.User{UserID: "u2", Level: 12})
+	close(release)
+	<-done
+
+	_, found, err := lru.Get(ctx, "leaderboard:global")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	mockDB.AssertExpectations(t)
+}
+
+func TestFlushCaches_LoadAcrossFlushIsNotCached(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	lru := cache.NewLRU(10)
+	service := services.NewLeaderboardService(mockDB, lru)
+	ctx := context.Background()
+	stale := []models.User{{UserID: "u1", Level: 9, GlobalPK: "GLOBAL"}}
+	fresh := []models.User{{UserID: "u1", Level: 10, GlobalPK: "GLOBAL"}}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, firstPage).
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).
+		Return(models.Page[models.User]{Items: stale}, nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := service.GetGlobalLeaderboard(ctx, models.PageRequest{})
+		assert.NoError(t, err)
+	}()
+
+	// An operator flushes the caches while the load is reading the old rankings
+	<-started
+	_, err := service.FlushCaches(ctx)
+	assert.NoError(t, err)
+	close(release)
+	<-done
+
+	_, found, err := lru.Get(ctx, "leaderboard:global")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// The next load started after the flush is cached as usual
+	mockDB.On("QueryGlobalLeaderboard", mock.Anything, firstPage).Return(models.Page[models.User]{Items: fresh}, nil).Once()
+	result, err := service.GetGlobalLeaderboard(ctx, models.PageRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, fresh, result.Items)
+	_, found, err = lru.Get(ctx, "leaderboard:global")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	mockDB.AssertExpectations(t)
+}
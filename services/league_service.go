@@ -0,0 +1,62 @@
+// services/league_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"good_blast/database"
+	"good_blast/errors"
+	"good_blast/models"
+)
+
+// LeagueService implements LeagueServiceInterface. Leagues are applied to tournament results
+// by TournamentService.ApplyLeagues.
+type LeagueService struct {
+	DB      database.DatabaseInterface
+	Leagues models.LeagueLadder
+}
+
+// NewLeagueService creates a new instance of LeagueService with the default league ladder.
+func NewLeagueService(db database.DatabaseInterface) *LeagueService {
+	return &LeagueService{
+		DB:      db,
+		Leagues: models.DefaultLeagueLadder,
+	}
+}
+
+// GetLeague returns the user's league with its reward scaling and movement rules.
+func (s *LeagueService) GetLeague(ctx context.Context, userID string) (*models.LeagueStatus, error) {
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching user:", err)
+		return nil, fmt.Errorf("could not fetch user data: %w", err)
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	league := s.Leagues.Of(user.League)
+	return &models.LeagueStatus{
+		UserID:           userID,
+		League:           league.ID,
+		Name:             league.Name,
+		Tier:             s.Leagues.Position(league.ID),
+		Tiers:            len(s.Leagues),
+		RewardPercent:    league.RewardPercent,
+		PromoteTop:       league.PromoteTop,
+		RelegateBottom:   league.RelegateBottom,
+		LastTournamentID: user.LeagueTournament,
+	}, nil
+}
+
+// GetLeagueHistory returns one page of the user's league results, newest tournament first.
+func (s *LeagueService) GetLeagueHistory(ctx context.Context, userID string, page models.PageRequest) (models.Page[models.LeagueResult], error) {
+	history, err := s.DB.QueryLeagueHistory(ctx, userID, page)
+	if err != nil && err != errors.ErrInvalidCursor {
+		log.Println("Error fetching league history:", err)
+		return history, fmt.Errorf("could not fetch league history: %w", err)
+	}
+	return history, err
+}
@@ -0,0 +1,191 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	apperrors "good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+	"good_blast/services/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testLadder promotes the winner and relegates the last player of each group.
+var testLadder = models.LeagueLadder{
+	{ID: "bronze", Name: "Bronze", RewardPercent: 100, PromoteTop: 1},
+	{ID: "silver", Name: "Silver", RewardPercent: 150, PromoteTop: 1, RelegateBottom: 1},
+	{ID: "gold", Name: "Gold", RewardPercent: 200, RelegateBottom: 1},
+}
+
+// leagueResult matches the LeagueResult applied for a user.
+func leagueResult(userID, from, to, movement string, rank, size int) interface{} {
+	return mock.MatchedBy(func(r models.LeagueResult) bool {
+		return r.UserID == userID && r.From == from && r.To == to && r.Movement == movement &&
+			r.Rank == rank && r.GroupSize == size && r.TournamentID == "2024-01-02" && r.AppliedAt != ""
+	})
+}
+
+func TestApplyLeagues_MovesEntrantsByGroupRank(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+	service.Leagues = testLadder
+
+	tID := "2024-01-02"
+	silver, bronze := tID+"-silver-group-0-1", tID+"-bronze-group-0-1"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID}, nil)
+	mockDB.On("QueryTournamentEntries", mock.Anything, tID, models.PageRequest{}).Return(models.Page[models.TournamentEntry]{
+		Items: []models.TournamentEntry{
+			{TournamentID: tID, UserID: "first", GroupID: silver, League: "silver", Score: 30},
+			{TournamentID: tID, UserID: "second", GroupID: silver, League: "silver", Score: 20},
+			{TournamentID: tID, UserID: "third", GroupID: silver, League: "silver", Score: 10},
+			{TournamentID: tID, UserID: "idle", GroupID: bronze, League: "bronze"},
+			{TournamentID: tID, UserID: "again", GroupID: bronze, League: "bronze"},
+		},
+	}, nil).Once()
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, silver).Return([]models.TournamentEntry{
+		{UserID: "first", Score: 30}, {UserID: "second", Score: 20}, {UserID: "third", Score: 10},
+	}, nil).Once()
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, bronze).Return([]models.TournamentEntry{
+		{UserID: "idle"}, {UserID: "again"},
+	}, nil).Once()
+
+	mockDB.On("ApplyLeagueResultTransaction", mock.Anything, leagueResult("first", "silver", "gold", models.LeaguePromoted, 1, 3)).Return(nil).Once()
+	mockDB.On("ApplyLeagueResultTransaction", mock.Anything, leagueResult("second", "silver", "silver", models.LeagueStayed, 2, 3)).Return(nil).Once()
+	mockDB.On("ApplyLeagueResultTransaction", mock.Anything, leagueResult("third", "silver", "bronze", models.LeagueRelegated, 3, 3)).Return(nil).Once()
+	// Winning a group without scoring promotes nobody, and the lowest league relegates nobody
+	mockDB.On("ApplyLeagueResultTransaction", mock.Anything, leagueResult("idle", "bronze", "bronze", models.LeagueStayed, 1, 2)).Return(nil).Once()
+	mockDB.On("ApplyLeagueResultTransaction", mock.Anything, leagueResult("again", "bronze", "bronze", models.LeagueStayed, 2, 2)).Return(apperrors.ErrLeagueResultApplied).Once()
+
+	report, err := service.ApplyLeagues(context.Background(), tID)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.LeagueReport{
+		TournamentID:   tID,
+		Entries:        5,
+		Promoted:       1,
+		Relegated:      1,
+		Stayed:         2,
+		AlreadyApplied: 1,
+	}, report)
+	mockDB.AssertExpectations(t)
+}
+
+func TestApplyLeagues_SmallGroupsMoveNobody(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+	service.Leagues = models.LeagueLadder{
+		{ID: "bronze", Name: "Bronze", RewardPercent: 100, PromoteTop: 1, MinGroupSize: 3},
+		{ID: "silver", Name: "Silver", RewardPercent: 150, PromoteTop: 1, RelegateBottom: 1, MinGroupSize: 3},
+		{ID: "gold", Name: "Gold", RewardPercent: 200, RelegateBottom: 1, MinGroupSize: 3},
+	}
+
+	tID := "2024-01-02"
+	group := tID + "-silver-group-0-1"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID}, nil)
+	mockDB.On("QueryTournamentEntries", mock.Anything, tID, models.PageRequest{}).Return(models.Page[models.TournamentEntry]{
+		Items: []models.TournamentEntry{
+			{TournamentID: tID, UserID: "first", GroupID: group, League: "silver", Score: 30},
+			{TournamentID: tID, UserID: "second", GroupID: group, League: "silver", Score: 20},
+		},
+	}, nil).Once()
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, group).Return([]models.TournamentEntry{
+		{UserID: "first", Score: 30}, {UserID: "second", Score: 20},
+	}, nil).Once()
+
+	// Two players are below the minimum of three: the winner isn't promoted, the last isn't relegated
+	mockDB.On("ApplyLeagueResultTransaction", mock.Anything, leagueResult("first", "silver", "silver", models.LeagueStayed, 1, 2)).Return(nil).Once()
+	mockDB.On("ApplyLeagueResultTransaction", mock.Anything, leagueResult("second", "silver", "silver", models.LeagueStayed, 2, 2)).Return(nil).Once()
+
+	report, err := service.ApplyLeagues(context.Background(), tID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Stayed)
+	assert.Zero(t, report.Promoted)
+	assert.Zero(t, report.Relegated)
+	mockDB.AssertExpectations(t)
+}
+
+func TestApplyLeagues_StillActive(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	mockDB.On("GetTournament", mock.Anything, "2024-01-02").Return(&models.Tournament{TournamentID: "2024-01-02", Active: true}, nil)
+
+	report, err := service.ApplyLeagues(context.Background(), "2024-01-02")
+	assert.Nil(t, report)
+	assert.Equal(t, apperrors.ErrTournamentStillActive, err)
+}
+
+func TestEnterTournament_GroupsWithinTheUsersLeague(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+	service.Leagues = testLadder
+
+	tournament := &models.Tournament{TournamentID: "2024-01-02", Active: true}
+	mockDB.On("GetTournament", mock.Anything, "2024-01-02").Return(tournament, nil)
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID: "u1", Level: 20, Coins: 1000, LeagueState: models.LeagueState{League: "gold"},
+	}, nil)
+	mockDB.On("GetUser", mock.Anything, "u2").Return(&models.User{
+		UserID: "u2", Level: 20, Coins: 1000, LeagueState: models.LeagueState{League: "retired"},
+	}, nil)
+	mockDB.On("EnterTournamentTransaction", mock.Anything, "u1", 20, 1000, tournament, "gold").Return(nil).Once()
+	// A league the ladder no longer has counts as the lowest one
+	mockDB.On("EnterTournamentTransaction", mock.Anything, "u2", 20, 1000, tournament, "bronze").Return(nil).Once()
+
+	_, err := service.EnterTournament(context.Background(), "u1", "2024-01-02")
+	assert.NoError(t, err)
+	_, err = service.EnterTournament(context.Background(), "u2", "2024-01-02")
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+}
+
+func TestClaimReward_ScaledByLeague(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+	service.Leagues = testLadder
+	service.Rewards = models.RewardTable{{MinRank: 1, MaxRank: 1, Bundle: models.RewardBundle{Coins: 1001, Lives: 2}}}
+
+	tID := "2024-01-02"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID}, nil)
+	mockDB.On("GetTournamentEntry", mock.Anything, tID, "u1").Return(&models.TournamentEntry{
+		TournamentID: tID, UserID: "u1", GroupID: "g-1", League: "silver", Score: 10,
+	}, nil)
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, "g-1").Return([]models.TournamentEntry{{UserID: "u1", Score: 10}}, nil)
+	// Coins are scaled and rounded down; items are not
+	mockDB.On("ClaimRewardTransaction", mock.Anything, "u1", models.RewardBundle{Coins: 1501, Lives: 2}, tID).Return(nil).Once()
+
+	_, reward, err := service.ClaimReward(context.Background(), tID, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1501, reward.Coins)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetLeague(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	leagueService := services.NewLeagueService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "new").Return(&models.User{UserID: "new"}, nil)
+	mockDB.On("GetUser", mock.Anything, "pro").Return(&models.User{
+		UserID: "pro", LeagueState: models.LeagueState{League: "platinum", LeagueTournament: "2024-01-02"},
+	}, nil)
+	mockDB.On("GetUser", mock.Anything, "ghost").Return(nil, nil)
+
+	league, err := leagueService.GetLeague(context.Background(), "new")
+	assert.NoError(t, err)
+	assert.Equal(t, "bronze", league.League)
+	assert.Equal(t, 1, league.Tier)
+	assert.Equal(t, 5, league.Tiers)
+	assert.Equal(t, 100, league.RewardPercent)
+
+	league, err = leagueService.GetLeague(context.Background(), "pro")
+	assert.NoError(t, err)
+	assert.Equal(t, "Platinum", league.Name)
+	assert.Equal(t, 4, league.Tier)
+	assert.Equal(t, 200, league.RewardPercent)
+	assert.Equal(t, "2024-01-02", league.LastTournamentID)
+
+	_, err = leagueService.GetLeague(context.Background(), "ghost")
+	assert.Equal(t, apperrors.ErrUserNotFound, err)
+}
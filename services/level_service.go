@@ -0,0 +1,230 @@
+// services/level_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"good_blast/database"
+	"good_blast/errors"
+	"good_blast/models"
+
+	"github.com/google/uuid"
+)
+
+// DefaultMaxSessionDuration is how long after starting a level its result may be reported.
+const DefaultMaxSessionDuration = 2 * time.Hour
+
+// tournamentPointsPerLevel is the tournament score a level win is worth.
+const tournamentPointsPerLevel = 1
+
+// LevelService implements LevelServiceInterface. It turns a finished level attempt into
+// the progress, tournament score and lives changes it implies.
+type LevelService struct {
+	DB                 database.DatabaseInterface
+	Users              UserServiceInterface
+	Tournaments        TournamentServiceInterface
+	Inventory          InventoryServiceInterface
+	MaxSessionDuration time.Duration        // 0 means DefaultMaxSessionDuration
+	Catalog            *models.LevelCatalog // what each level pays; nil means models.DefaultLevelCatalog
+}
+
+// NewLevelService creates a new instance of LevelService.
+func NewLevelService(db database.DatabaseInterface, users UserServiceInterface, tournaments TournamentServiceInterface, inventory InventoryServiceInterface) *LevelService {
+	return &LevelService{
+		DB:                 db,
+		Users:              users,
+		Tournaments:        tournaments,
+		Inventory:          inventory,
+		MaxSessionDuration: DefaultMaxSessionDuration,
+	}
+}
+
+func (s *LevelService) maxSessionDuration() time.Duration {
+	if s.MaxSessionDuration > 0 {
+		return s.MaxSessionDuration
+	}
+	return DefaultMaxSessionDuration
+}
+
+// GetCatalog returns the level catalog in use.
+func (s *LevelService) GetCatalog() *models.LevelCatalog {
+	if s.Catalog != nil {
+		return s.Catalog
+	}
+	return models.DefaultLevelCatalog
+}
+
+// StartLevel spends a life and opens a session for an attempt at level. Users can play their
+// current level and replay any level below it.
+func (s *LevelService) StartLevel(ctx context.Context, userID string, level int) (*models.LevelSession, *models.LivesStatus, error) {
+	user, err := s.Users.GetUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if level < 1 || level > user.Level {
+		return nil, nil, errors.ErrLevelLocked
+	}
+
+	// The life is spent first: a session must never exist without one
+	lives, err := s.Inventory.ConsumeLife(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session := models.LevelSession{
+		SessionID: uuid.New().String(),
+		UserID:    userID,
+		Level:     level,
+		Status:    models.LevelSessionStarted,
+		Replay:    level < user.Level,
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := s.DB.PutLevelSession(ctx, session); err != nil {
+		log.Println("Error starting level session:", err)
+		return nil, nil, err
+	}
+	return &session, lives, nil
+}
+
+// validateResult checks that a reported result is plausible.
+func validateResult(result models.LevelResult) error {
+	if result.Moves < 0 || result.Score < 0 || (result.Won && result.Moves == 0) {
+		return errors.ErrInvalidLevelResult
+	}
+	for id, n := range result.BoostersUsed {
+		if id == "" || n <= 0 || n > models.MaxBoosterPurchase {
+			return errors.ErrInvalidLevelResult
+		}
+	}
+	return nil
+}
+
+// FinishLevel records the result of a level session. Winning the user's current level advances
+// them to the next one, with its catalog reward, and scores a point in today's tournament when
+// they entered it. Winning a replay pays the level's coin reward only; losses change nothing.
+//
+// Progress and the tournament point are applied before the session is marked finished, so a
+// failure in between leaves the session open for the client to retry instead of losing the win.
+// Neither is applied twice by the retry: the progress update is conditioned on the user's level
+// and the point is written together with a mark on the session. Replay rewards are not
+// conditional, so a replay is marked finished first and its reward paid at most once.
+func (s *LevelService) FinishLevel(ctx context.Context, userID, sessionID string, result models.LevelResult) (*models.LevelOutcome, error) {
+	if err := validateResult(result); err != nil {
+		return nil, err
+	}
+
+	session, err := s.DB.GetLevelSession(ctx, sessionID)
+	if err != nil {
+		log.Println("Error fetching level session:", err)
+		return nil, fmt.Errorf("could not fetch level session: %w", err)
+	}
+	if session == nil || session.UserID != userID {
+		return nil, errors.ErrLevelSessionNotFound
+	}
+	if session.Status != models.LevelSessionStarted {
+		return nil, errors.ErrLevelSessionFinished
+	}
+	now := time.Now().UTC()
+	if started, err := time.Parse(time.RFC3339, session.StartedAt); err == nil && now.Sub(started) > s.maxSessionDuration() {
+		return nil, errors.ErrLevelSessionExpired
+	}
+
+	session.Status = models.LevelSessionLost
+	if result.Won {
+		session.Status = models.LevelSessionWon
+	}
+	session.FinishedAt = now.Format(time.RFC3339)
+	session.Moves = result.Moves
+	session.Score = result.Score
+	session.BoostersUsed = result.BoostersUsed
+
+	if !result.Won || session.Replay {
+		if err := s.finishSession(ctx, *session); err != nil {
+			return nil, err
+		}
+		user, err := s.Users.GetUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		outcome := &models.LevelOutcome{Session: *session, Level: user.Level, Coins: user.Coins}
+		if !result.Won {
+			return outcome, nil
+		}
+		return s.payReplay(ctx, outcome)
+	}
+
+	user, err := s.Users.UpdateUserProgress(ctx, userID, session.Level+1)
+	var reward *models.RewardBundle
+	switch err {
+	case nil:
+		cleared := s.GetCatalog().ClearReward(session.Level, session.Level+1)
+		reward = &cleared
+	case errors.ErrInvalidLevelIncrease:
+		// Already past the level: an earlier attempt at finishing this session applied the
+		// progress, or another session won the level first. There is nothing left to pay, but
+		// the win still scores its point.
+		if user, err = s.Users.GetUser(ctx, userID); err != nil {
+			return nil, err
+		}
+	default:
+		log.Println("Error applying level progress:", err)
+		return nil, err
+	}
+	outcome := &models.LevelOutcome{Level: user.Level, Coins: user.Coins, Reward: reward}
+
+	tournamentID := now.Format("2006-01-02")
+	score, err := s.Tournaments.ScoreLevelWin(ctx, tournamentID, userID, session.SessionID, tournamentPointsPerLevel)
+	switch err {
+	case nil:
+		session.Scored = true
+		outcome.TournamentScore = &score
+	case errors.ErrTournamentEntryNotFound, errors.ErrTournamentNotActive:
+		// Not in today's tournament, or it has ended and its ranks are final
+	default:
+		log.Println("Error scoring level in tournament:", err)
+		return nil, err
+	}
+
+	if err := s.finishSession(ctx, *session); err != nil {
+		return nil, err
+	}
+	outcome.Session = *session
+	return outcome, nil
+}
+
+// finishSession marks a session finished with its result.
+func (s *LevelService) finishSession(ctx context.Context, session models.LevelSession) error {
+	if err := s.DB.FinishLevelSession(ctx, session); err != nil {
+		log.Println("Error finishing level session:", err)
+		return err
+	}
+	log.Printf("level finished: user=%s level=%d status=%s moves=%d score=%d boosters=%v",
+		session.UserID, session.Level, session.Status, session.Moves, session.Score, session.BoostersUsed)
+	return nil
+}
+
+// payReplay credits the coin reward for winning a level that was already cleared and records it
+// in the user's history.
+func (s *LevelService) payReplay(ctx context.Context, outcome *models.LevelOutcome) (*models.LevelOutcome, error) {
+	coins := s.GetCatalog().Level(outcome.Session.Level).CoinReward
+	if coins == 0 {
+		return outcome, nil
+	}
+
+	entry := models.HistoryEntry{
+		UserID: outcome.Session.UserID,
+		Type:   models.HistoryLevelReward,
+		Coins:  coins,
+		Reason: fmt.Sprintf("replayed level %d", outcome.Session.Level),
+	}
+	if err := s.DB.AdjustCoinsTransaction(ctx, entry); err != nil {
+		log.Println("Error paying level replay reward:", err)
+		return nil, err
+	}
+	outcome.Coins += coins
+	outcome.Reward = &models.RewardBundle{Coins: coins}
+	return outcome, nil
+}
@@ -0,0 +1,300 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apperrors "good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+	"good_blast/services/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newLevelService(db *mocks.MockDatabase) *services.LevelService {
+	return services.NewLevelService(db, services.NewUserService(db), services.NewTournamentService(db), services.NewInventoryService(db))
+}
+
+func startedSession(userID string, level int, startedAt time.Time) *models.LevelSession {
+	return &models.LevelSession{
+		SessionID: "s1",
+		UserID:    userID,
+		Level:     level,
+		Status:    models.LevelSessionStarted,
+		StartedAt: startedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func TestStartLevel_SpendsLifeAndOpensSession(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	levelService := newLevelService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 12}, nil)
+	mockDB.On("SetLives", mock.Anything, "u1", models.LivesRecord{}, mock.MatchedBy(func(r models.LivesRecord) bool { return r.Lives == 4 })).Return(nil).Once()
+	mockDB.On("PutLevelSession", mock.Anything, mock.MatchedBy(func(s models.LevelSession) bool {
+		return s.UserID == "u1" && s.Level == 12 && s.Status == models.LevelSessionStarted && s.SessionID != "" && !s.Replay
+	})).Return(nil).Once()
+
+	session, lives, err := levelService.StartLevel(context.Background(), "u1", 12)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, session.Level)
+	assert.Equal(t, 4, lives.Lives)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestStartLevel_RejectsLockedLevels(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	levelService := newLevelService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 12}, nil)
+
+	_, _, err := levelService.StartLevel(context.Background(), "u1", 13)
+	assert.Equal(t, apperrors.ErrLevelLocked, err)
+	_, _, err = levelService.StartLevel(context.Background(), "u1", 0)
+	assert.Equal(t, apperrors.ErrLevelLocked, err)
+
+	mockDB.AssertNotCalled(t, "SetLives", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockDB.AssertNotCalled(t, "PutLevelSession", mock.Anything, mock.Anything)
+}
+
+func TestStartLevel_NoLivesLeft(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	levelService := newLevelService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{
+		UserID:    "u1",
+		Level:     3,
+		Inventory: models.Inventory{LivesUpdatedAt: time.Now().Unix()},
+	}, nil)
+
+	_, _, err := levelService.StartLevel(context.Background(), "u1", 3)
+	assert.Equal(t, apperrors.ErrNoLivesLeft, err)
+
+	mockDB.AssertNotCalled(t, "PutLevelSession", mock.Anything, mock.Anything)
+}
+
+func TestFinishLevel_WinAdvancesProgressAndTournamentScore(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	levelService := newLevelService(mockDB)
+	today := time.Now().UTC().Format("2006-01-02")
+
+	mockDB.On("GetLevelSession", mock.Anything, "s1").Return(startedSession("u1", 12, time.Now().Add(-5*time.Minute)), nil).Once()
+	mockDB.On("FinishLevelSession", mock.Anything, mock.MatchedBy(func(s models.LevelSession) bool {
+		return s.Status == models.LevelSessionWon && s.Moves == 18 && s.Score == 4200 && s.BoostersUsed["rocket"] == 1
+	})).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 12, Coins: 1000}, nil).Once()
+	mockDB.On("AdvanceLevel", mock.Anything, "u1", 12, 13, models.RewardBundle{Coins: 100}).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 13, Coins: 1100}, nil).Once()
+	mockDB.On("GetTournamentEntry", mock.Anything, today, "u1").Return(&models.TournamentEntry{UserID: "u1", GroupID: "g1", Score: 4}, nil).Once()
+	mockDB.On("ScoreLevelSessionTransaction", mock.Anything, today, "u1", "s1", 1).Return(nil).Once()
+
+	outcome, err := levelService.FinishLevel(context.Background(), "u1", "s1", models.LevelResult{
+		Won:          true,
+		Moves:        18,
+		Score:        4200,
+		BoostersUsed: map[string]int{"rocket": 1},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 13, outcome.Level)
+	assert.Equal(t, 1100, outcome.Coins)
+	assert.Equal(t, &models.RewardBundle{Coins: 100}, outcome.Reward)
+	if assert.NotNil(t, outcome.TournamentScore) {
+		assert.Equal(t, 5, *outcome.TournamentScore)
+	}
+	assert.True(t, outcome.Session.Scored)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestFinishLevel_WinOutsideTournament(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	levelService := newLevelService(mockDB)
+
+	mockDB.On("GetLevelSession", mock.Anything, "s1").Return(startedSession("u1", 2, time.Now()), nil).Once()
+	mockDB.On("FinishLevelSession", mock.Anything, mock.Anything).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 2, Coins: 1000}, nil).Once()
+	mockDB.On("AdvanceLevel", mock.Anything, "u1", 2, 3, models.RewardBundle{Coins: 100}).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 3, Coins: 1100}, nil).Once()
+	mockDB.On("GetTournamentEntry", mock.Anything, mock.Anything, "u1").Return(nil, nil).Once()
+
+	outcome, err := levelService.FinishLevel(context.Background(), "u1", "s1", models.LevelResult{Won: true, Moves: 9, Score: 800})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, outcome.Level)
+	assert.Nil(t, outcome.TournamentScore)
+
+	mockDB.AssertNotCalled(t, "ScoreLevelSessionTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFinishLevel_LossAndReplayChangeNothing(t *testing.T) {
+	for name, tc := range map[string]struct {
+		level int
+		won   bool
+	}{
+		"loss":   {level: 12, won: false},
+		"replay": {level: 7, won: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockDB := new(mocks.MockDatabase)
+			levelService := newLevelService(mockDB)
+
+			session := startedSession("u1", tc.level, time.Now())
+			session.Replay = tc.level < 12
+			mockDB.On("GetLevelSession", mock.Anything, "s1").Return(session, nil).Once()
+			mockDB.On("FinishLevelSession", mock.Anything, mock.Anything).Return(nil).Once()
+			mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 12, Coins: 1000}, nil).Once()
+
+			outcome, err := levelService.FinishLevel(context.Background(), "u1", "s1", models.LevelResult{Won: tc.won, Moves: 20, Score: 100})
+			assert.NoError(t, err)
+			assert.Equal(t, 12, outcome.Level)
+
+			mockDB.AssertNotCalled(t, "AdvanceLevel", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			mockDB.AssertNotCalled(t, "AdjustCoinsTransaction", mock.Anything, mock.Anything)
+			mockDB.AssertNotCalled(t, "GetTournamentEntry", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestFinishLevel_ReplayPaysCoinReward(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	levelService := newLevelService(mockDB)
+	levelService.Catalog = &models.LevelCatalog{
+		Version: "v2",
+		Default: models.LevelDefinition{Difficulty: models.DifficultyNormal, FirstClearBonus: models.RewardBundle{Coins: 100}},
+		Levels:  []models.LevelDefinition{{Level: 5, Difficulty: models.DifficultyHard, CoinReward: 20}},
+	}
+
+	session := startedSession("u1", 5, time.Now())
+	session.Replay = true
+	mockDB.On("GetLevelSession", mock.Anything, "s1").Return(session, nil).Once()
+	mockDB.On("FinishLevelSession", mock.Anything, mock.Anything).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 12, Coins: 1000}, nil).Once()
+	mockDB.On("AdjustCoinsTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.UserID == "u1" && e.Type == models.HistoryLevelReward && e.Coins == 20
+	})).Return(nil).Once()
+
+	outcome, err := levelService.FinishLevel(context.Background(), "u1", "s1", models.LevelResult{Won: true, Moves: 11, Score: 900})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, outcome.Level)
+	assert.Equal(t, 1020, outcome.Coins)
+	assert.Equal(t, &models.RewardBundle{Coins: 20}, outcome.Reward)
+
+	mockDB.AssertExpectations(t)
+	mockDB.AssertNotCalled(t, "AdvanceLevel", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFinishLevel_FailedProgressLeavesSessionOpen(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	levelService := newLevelService(mockDB)
+	result := models.LevelResult{Won: true, Moves: 9, Score: 800}
+
+	mockDB.On("GetLevelSession", mock.Anything, "s1").Return(startedSession("u1", 2, time.Now()), nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 2, Coins: 1000}, nil).Once()
+	mockDB.On("AdvanceLevel", mock.Anything, "u1", 2, 3, models.RewardBundle{Coins: 100}).Return(apperrors.ErrThrottled).Once()
+
+	_, err := levelService.FinishLevel(context.Background(), "u1", "s1", result)
+	assert.ErrorIs(t, err, apperrors.ErrThrottled)
+	mockDB.AssertNotCalled(t, "FinishLevelSession", mock.Anything, mock.Anything)
+
+	// The retry finds the progress applied by another attempt: nothing is paid, but the win scores
+	today := time.Now().UTC().Format("2006-01-02")
+	mockDB.On("GetLevelSession", mock.Anything, "s1").Return(startedSession("u1", 2, time.Now()), nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 3, Coins: 1100}, nil)
+	mockDB.On("GetTournamentEntry", mock.Anything, today, "u1").Return(&models.TournamentEntry{UserID: "u1", GroupID: "g1", Score: 4}, nil).Once()
+	mockDB.On("ScoreLevelSessionTransaction", mock.Anything, today, "u1", "s1", 1).Return(nil).Once()
+	mockDB.On("FinishLevelSession", mock.Anything, mock.Anything).Return(nil).Once()
+
+	outcome, err := levelService.FinishLevel(context.Background(), "u1", "s1", result)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, outcome.Level)
+	assert.Nil(t, outcome.Reward)
+	if assert.NotNil(t, outcome.TournamentScore) {
+		assert.Equal(t, 5, *outcome.TournamentScore)
+	}
+	mockDB.AssertExpectations(t)
+}
+
+func TestFinishLevel_FailedScoreLeavesSessionOpen(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	levelService := newLevelService(mockDB)
+	result := models.LevelResult{Won: true, Moves: 9, Score: 800}
+	today := time.Now().UTC().Format("2006-01-02")
+
+	mockDB.On("GetLevelSession", mock.Anything, "s1").Return(startedSession("u1", 2, time.Now()), nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 2, Coins: 1000}, nil).Once()
+	mockDB.On("AdvanceLevel", mock.Anything, "u1", 2, 3, models.RewardBundle{Coins: 100}).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 3, Coins: 1100}, nil)
+	mockDB.On("GetTournamentEntry", mock.Anything, today, "u1").Return(&models.TournamentEntry{UserID: "u1", GroupID: "g1", Score: 4}, nil)
+	mockDB.On("ScoreLevelSessionTransaction", mock.Anything, today, "u1", "s1", 1).Return(apperrors.ErrThrottled).Once()
+
+	_, err := levelService.FinishLevel(context.Background(), "u1", "s1", result)
+	assert.ErrorIs(t, err, apperrors.ErrThrottled)
+	mockDB.AssertNotCalled(t, "FinishLevelSession", mock.Anything, mock.Anything)
+
+	// The retry scores the point the first attempt missed, then closes the session
+	mockDB.On("GetLevelSession", mock.Anything, "s1").Return(startedSession("u1", 2, time.Now()), nil).Once()
+	mockDB.On("ScoreLevelSessionTransaction", mock.Anything, today, "u1", "s1", 1).Return(nil).Once()
+	mockDB.On("FinishLevelSession", mock.Anything, mock.Anything).Return(nil).Once()
+
+	outcome, err := levelService.FinishLevel(context.Background(), "u1", "s1", result)
+	assert.NoError(t, err)
+	if assert.NotNil(t, outcome.TournamentScore) {
+		assert.Equal(t, 5, *outcome.TournamentScore)
+	}
+	mockDB.AssertExpectations(t)
+}
+
+func TestFinishLevel_EndedTournamentIsNotScored(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	levelService := newLevelService(mockDB)
+	today := time.Now().UTC().Format("2006-01-02")
+
+	mockDB.On("GetLevelSession", mock.Anything, "s1").Return(startedSession("u1", 2, time.Now()), nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 2, Coins: 1000}, nil).Once()
+	mockDB.On("AdvanceLevel", mock.Anything, "u1", 2, 3, models.RewardBundle{Coins: 100}).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 3, Coins: 1100}, nil).Once()
+	mockDB.On("GetTournamentEntry", mock.Anything, today, "u1").Return(&models.TournamentEntry{UserID: "u1", GroupID: "g1", Score: 4}, nil).Once()
+	mockDB.On("ScoreLevelSessionTransaction", mock.Anything, today, "u1", "s1", 1).Return(apperrors.ErrTournamentNotActive).Once()
+	mockDB.On("FinishLevelSession", mock.Anything, mock.Anything).Return(nil).Once()
+
+	outcome, err := levelService.FinishLevel(context.Background(), "u1", "s1", models.LevelResult{Won: true, Moves: 9, Score: 800})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, outcome.Level)
+	assert.Nil(t, outcome.TournamentScore)
+	mockDB.AssertExpectations(t)
+}
+
+func TestFinishLevel_RejectsInvalidSessions(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	levelService := newLevelService(mockDB)
+	ctx := context.Background()
+	result := models.LevelResult{Won: true, Moves: 10, Score: 500}
+
+	mockDB.On("GetLevelSession", mock.Anything, "missing").Return(nil, nil)
+	_, err := levelService.FinishLevel(ctx, "u1", "missing", result)
+	assert.Equal(t, apperrors.ErrLevelSessionNotFound, err)
+
+	mockDB.On("GetLevelSession", mock.Anything, "other").Return(startedSession("u2", 1, time.Now()), nil)
+	_, err = levelService.FinishLevel(ctx, "u1", "other", result)
+	assert.Equal(t, apperrors.ErrLevelSessionNotFound, err)
+
+	finished := startedSession("u1", 1, time.Now())
+	finished.Status = models.LevelSessionLost
+	mockDB.On("GetLevelSession", mock.Anything, "finished").Return(finished, nil)
+	_, err = levelService.FinishLevel(ctx, "u1", "finished", result)
+	assert.Equal(t, apperrors.ErrLevelSessionFinished, err)
+
+	mockDB.On("GetLevelSession", mock.Anything, "old").Return(startedSession("u1", 1, time.Now().Add(-3*time.Hour)), nil)
+	_, err = levelService.FinishLevel(ctx, "u1", "old", result)
+	assert.Equal(t, apperrors.ErrLevelSessionExpired, err)
+
+	_, err = levelService.FinishLevel(ctx, "u1", "s1", models.LevelResult{Won: true, Moves: 0, Score: 10})
+	assert.Equal(t, apperrors.ErrInvalidLevelResult, err)
+	_, err = levelService.FinishLevel(ctx, "u1", "s1", models.LevelResult{Moves: 5, BoostersUsed: map[string]int{"hammer": -1}})
+	assert.Equal(t, apperrors.ErrInvalidLevelResult, err)
+
+	mockDB.AssertNotCalled(t, "FinishLevelSession", mock.Anything, mock.Anything)
+}
@@ -29,9 +29,9 @@ func (m *MockDatabase) GetUser(ctx context.Context, userId string) (*models.User
 	return nil, args.Error(1)
 }
 
-// UpdateUserCoinsAndLevel mocks the UpdateUserCoinsAndLevel method of DatabaseInterface.
-func (m *MockDatabase) UpdateUserCoinsAndLevel(ctx context.Context, userId string, newLevel, newCoins int) error {
-	args := m.Called(ctx, userId, newLevel, newCoins)
+// AdvanceLevel mocks the AdvanceLevel method of DatabaseInterface.
+func (m *MockDatabase) AdvanceLevel(ctx context.Context, userId string, fromLevel, toLevel int, reward models.RewardBundle) error {
+	args := m.Called(ctx, userId, fromLevel, toLevel, reward)
 	return args.Error(0)
 }
 
@@ -78,21 +78,21 @@ func (m *MockDatabase) UpdateTournamentScore(ctx context.Context, tournamentId,
 }
 
 // QueryGlobalLeaderboard mocks the QueryGlobalLeaderboard method of DatabaseInterface.
-func (m *MockDatabase) QueryGlobalLeaderboard(ctx context.Context) ([]models.User, error) {
-	args := m.Called(ctx)
-	if users, ok := args.Get(0).([]models.User); ok {
+func (m *MockDatabase) QueryGlobalLeaderboard(ctx context.Context, page models.PageRequest) (models.Page[models.User], error) {
+	args := m.Called(ctx, page)
+	if users, ok := args.Get(0).(models.Page[models.User]); ok {
 		return users, args.Error(1)
 	}
-	return nil, args.Error(1)
+	return models.Page[models.User]{}, args.Error(1)
 }
 
 // QueryUsersByCountryLevel mocks the QueryUsersByCountryLevel method of DatabaseInterface.
-func (m *MockDatabase) QueryUsersByCountryLevel(ctx context.Context, country string) ([]models.User, error) {
-	args := m.Called(ctx, country)
-	if users, ok := args.Get(0).([]models.User); ok {
+func (m *MockDatabase) QueryUsersByCountryLevel(ctx context.Context, country string, page models.PageRequest) (models.Page[models.User], error) {
+	args := m.Called(ctx, country, page)
+	if users, ok := args.Get(0).(models.Page[models.User]); ok {
 		return users, args.Error(1)
 	}
-	return nil, args.Error(1)
+	return models.Page[models.User]{}, args.Error(1)
 }
 
 // QueryTournamentEntriesByGroupScore mocks the QueryTournamentEntriesByGroupScore method of DatabaseInterface.
@@ -105,20 +105,422 @@ func (m *MockDatabase) QueryTournamentEntriesByGroupScore(ctx context.Context, g
 }
 
 // EnterTournamentTransaction mocks the EnterTournamentTransaction method of DatabaseInterface.
-func (m *MockDatabase) EnterTournamentTransaction(ctx context.Context, userID string, level, coins int, t *models.Tournament) error {
-	args := m.Called(ctx, userID, level, coins, t)
+func (m *MockDatabase) EnterTournamentTransaction(ctx context.Context, userID string, level, coins int, t *models.Tournament, league string) error {
+	args := m.Called(ctx, userID, level, coins, t, league)
 	return args.Error(0)
 }
 
 // ClaimRewardTransaction mocks the ClaimRewardTransaction method of DatabaseInterface.
-func (m *MockDatabase) ClaimRewardTransaction(ctx context.Context, userID string, reward int, tournamentID string) error {
+func (m *MockDatabase) ClaimRewardTransaction(ctx context.Context, userID string, reward models.RewardBundle, tournamentID string) error {
 	args := m.Called(ctx, userID, reward, tournamentID)
 	return args.Error(0)
 }
 
 // QueryTournamentEntries mocks the QueryTournamentEntries method of DatabaseInterface.
-func (m *MockDatabase) QueryTournamentEntries(ctx context.Context, tournamentId string) ([]models.TournamentEntry, error) {
+func (m *MockDatabase) QueryTournamentEntries(ctx context.Context, tournamentId string, page models.PageRequest) (models.Page[models.TournamentEntry], error) {
+	args := m.Called(ctx, tournamentId, page)
+	if entries, ok := args.Get(0).(models.Page[models.TournamentEntry]); ok {
+		return entries, args.Error(1)
+	}
+	return models.Page[models.TournamentEntry]{}, args.Error(1)
+}
+
+// CancelTournament mocks the CancelTournament method of DatabaseInterface.
+func (m *MockDatabase) CancelTournament(ctx context.Context, tournamentId string) error {
+	args := m.Called(ctx, tournamentId)
+	return args.Error(0)
+}
+
+// AdjustCoinsTransaction mocks the AdjustCoinsTransaction method of DatabaseInterface.
+func (m *MockDatabase) AdjustCoinsTransaction(ctx context.Context, entry models.HistoryEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+// QueryUserHistory mocks the QueryUserHistory method of DatabaseInterface.
+func (m *MockDatabase) QueryUserHistory(ctx context.Context, userId string, page models.PageRequest) (models.Page[models.HistoryEntry], error) {
+	args := m.Called(ctx, userId, page)
+	if entries, ok := args.Get(0).(models.Page[models.HistoryEntry]); ok {
+		return entries, args.Error(1)
+	}
+	return models.Page[models.HistoryEntry]{}, args.Error(1)
+}
+
+// MarkRefundsDone mocks the MarkRefundsDone method of DatabaseInterface.
+func (m *MockDatabase) MarkRefundsDone(ctx context.Context, tournamentId string) error {
 	args := m.Called(ctx, tournamentId)
+	return args.Error(0)
+}
+
+// RefundEntryTransaction mocks the RefundEntryTransaction method of DatabaseInterface.
+func (m *MockDatabase) RefundEntryTransaction(ctx context.Context, entry models.HistoryEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+// CloseTournament mocks the CloseTournament method of DatabaseInterface.
+func (m *MockDatabase) CloseTournament(ctx context.Context, tournamentId, claimDeadline string) error {
+	args := m.Called(ctx, tournamentId, claimDeadline)
+	return args.Error(0)
+}
+
+// ExpireEntryReward mocks the ExpireEntryReward method of DatabaseInterface.
+func (m *MockDatabase) ExpireEntryReward(ctx context.Context, tournamentId, userId string) error {
+	args := m.Called(ctx, tournamentId, userId)
+	return args.Error(0)
+}
+
+// QueryUnclaimedEntriesByUser mocks the QueryUnclaimedEntriesByUser method of DatabaseInterface.
+func (m *MockDatabase) QueryUnclaimedEntriesByUser(ctx context.Context, userId, sinceTournamentId string, page models.PageRequest) (models.Page[models.TournamentEntry], error) {
+	args := m.Called(ctx, userId, sinceTournamentId, page)
+	if entries, ok := args.Get(0).(models.Page[models.TournamentEntry]); ok {
+		return entries, args.Error(1)
+	}
+	return models.Page[models.TournamentEntry]{}, args.Error(1)
+}
+
+// GrantRewardTransaction mocks the GrantRewardTransaction method of DatabaseInterface.
+func (m *MockDatabase) GrantRewardTransaction(ctx context.Context, entry models.HistoryEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+// PurchaseBoostersTransaction mocks the PurchaseBoostersTransaction method of DatabaseInterface.
+func (m *MockDatabase) PurchaseBoostersTransaction(ctx context.Context, entry models.HistoryEntry, maxOwned int) error {
+	args := m.Called(ctx, entry, maxOwned)
+	return args.Error(0)
+}
+
+// ConsumeBoosters mocks the ConsumeBoosters method of DatabaseInterface.
+func (m *MockDatabase) ConsumeBoosters(ctx context.Context, userId, boosterId string, quantity int) (int, error) {
+	args := m.Called(ctx, userId, boosterId, quantity)
+	return args.Int(0), args.Error(1)
+}
+
+// SetLives mocks the SetLives method of DatabaseInterface.
+func (m *MockDatabase) SetLives(ctx context.Context, userId string, from, to models.LivesRecord) error {
+	args := m.Called(ctx, userId, from, to)
+	return args.Error(0)
+}
+
+// RefillLivesTransaction mocks the RefillLivesTransaction method of DatabaseInterface.
+func (m *MockDatabase) RefillLivesTransaction(ctx context.Context, entry models.HistoryEntry, from, to models.LivesRecord) error {
+	args := m.Called(ctx, entry, from, to)
+	return args.Error(0)
+}
+
+// PutLevelSession mocks the PutLevelSession method of DatabaseInterface.
+func (m *MockDatabase) PutLevelSession(ctx context.Context, session models.LevelSession) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+// GetLevelSession mocks the GetLevelSession method of DatabaseInterface.
+func (m *MockDatabase) GetLevelSession(ctx context.Context, sessionId string) (*models.LevelSession, error) {
+	args := m.Called(ctx, sessionId)
+	if session, ok := args.Get(0).(*models.LevelSession); ok {
+		return session, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// FinishLevelSession mocks the FinishLevelSession method of DatabaseInterface.
+func (m *MockDatabase) FinishLevelSession(ctx context.Context, session models.LevelSession) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+// ScoreLevelSessionTransaction mocks the ScoreLevelSessionTransaction method of DatabaseInterface.
+func (m *MockDatabase) ScoreLevelSessionTransaction(ctx context.Context, tournamentId, userId, sessionId string, points int) error {
+	args := m.Called(ctx, tournamentId, userId, sessionId, points)
+	return args.Error(0)
+}
+
+// CheckInTransaction mocks the CheckInTransaction method of DatabaseInterface.
+func (m *MockDatabase) CheckInTransaction(ctx context.Context, entry models.HistoryEntry, from, to models.CheckInState) error {
+	args := m.Called(ctx, entry, from, to)
+	return args.Error(0)
+}
+
+// AddQuestProgress mocks the AddQuestProgress method of DatabaseInterface.
+func (m *MockDatabase) AddQuestProgress(ctx context.Context, userId, questKey string, count int) error {
+	args := m.Called(ctx, userId, questKey, count)
+	return args.Error(0)
+}
+
+// QueryUserQuests mocks the QueryUserQuests method of DatabaseInterface.
+func (m *MockDatabase) QueryUserQuests(ctx context.Context, userId, day string) ([]models.QuestProgress, error) {
+	args := m.Called(ctx, userId, day)
+	if quests, ok := args.Get(0).([]models.QuestProgress); ok {
+		return quests, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// ClaimQuestTransaction mocks the ClaimQuestTransaction method of DatabaseInterface.
+func (m *MockDatabase) ClaimQuestTransaction(ctx context.Context, entry models.HistoryEntry, questKey string, target int) error {
+	args := m.Called(ctx, entry, questKey, target)
+	return args.Error(0)
+}
+
+// AddAchievementStats mocks the AddAchievementStats method of DatabaseInterface.
+func (m *MockDatabase) AddAchievementStats(ctx context.Context, userId string, delta models.AchievementStats) (*models.User, error) {
+	args := m.Called(ctx, userId, delta)
+	if user, ok := args.Get(0).(*models.User); ok {
+		return user, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// UnlockAchievement mocks the UnlockAchievement method of DatabaseInterface.
+func (m *MockDatabase) UnlockAchievement(ctx context.Context, a models.UnlockedAchievement) error {
+	args := m.Called(ctx, a)
+	return args.Error(0)
+}
+
+// QueryUserAchievements mocks the QueryUserAchievements method of DatabaseInterface.
+func (m *MockDatabase) QueryUserAchievements(ctx context.Context, userId string) ([]models.UnlockedAchievement, error) {
+	args := m.Called(ctx, userId)
+	if unlocked, ok := args.Get(0).([]models.UnlockedAchievement); ok {
+		return unlocked, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// GetSeasonProgress mocks the GetSeasonProgress method of DatabaseInterface.
+func (m *MockDatabase) GetSeasonProgress(ctx context.Context, userId, seasonId string) (*models.SeasonProgress, error) {
+	args := m.Called(ctx, userId, seasonId)
+	if progress, ok := args.Get(0).(*models.SeasonProgress); ok {
+		return progress, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// AddSeasonXP mocks the AddSeasonXP method of DatabaseInterface.
+func (m *MockDatabase) AddSeasonXP(ctx context.Context, userId, seasonId string, xp int) error {
+	args := m.Called(ctx, userId, seasonId, xp)
+	return args.Error(0)
+}
+
+// ClaimSeasonTierTransaction mocks the ClaimSeasonTierTransaction method of DatabaseInterface.
+func (m *MockDatabase) ClaimSeasonTierTransaction(ctx context.Context, entry models.HistoryEntry, seasonId string, tier, xp int, track string) error {
+	args := m.Called(ctx, entry, seasonId, tier, xp, track)
+	return args.Error(0)
+}
+
+// PurchaseSeasonPremiumTransaction mocks the PurchaseSeasonPremiumTransaction method of DatabaseInterface.
+func (m *MockDatabase) PurchaseSeasonPremiumTransaction(ctx context.Context, entry models.HistoryEntry, seasonId string) error {
+	args := m.Called(ctx, entry, seasonId)
+	return args.Error(0)
+}
+
+// ApplyLeagueResultTransaction mocks the ApplyLeagueResultTransaction method of DatabaseInterface.
+func (m *MockDatabase) ApplyLeagueResultTransaction(ctx context.Context, result models.LeagueResult) error {
+	args := m.Called(ctx, result)
+	return args.Error(0)
+}
+
+// QueryLeagueHistory mocks the QueryLeagueHistory method of DatabaseInterface.
+func (m *MockDatabase) QueryLeagueHistory(ctx context.Context, userId string, page models.PageRequest) (models.Page[models.LeagueResult], error) {
+	args := m.Called(ctx, userId, page)
+	if results, ok := args.Get(0).(models.Page[models.LeagueResult]); ok {
+		return results, args.Error(1)
+	}
+	return models.Page[models.LeagueResult]{}, args.Error(1)
+}
+
+// CreateClanTransaction mocks the CreateClanTransaction method of DatabaseInterface.
+func (m *MockDatabase) CreateClanTransaction(ctx context.Context, clan models.Clan, leader models.ClanMember) error {
+	args := m.Called(ctx, clan, leader)
+	return args.Error(0)
+}
+
+// GetClan mocks the GetClan method of DatabaseInterface.
+func (m *MockDatabase) GetClan(ctx context.Context, clanId string) (*models.Clan, error) {
+	args := m.Called(ctx, clanId)
+	if clan, ok := args.Get(0).(*models.Clan); ok {
+		return clan, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// GetClanMember mocks the GetClanMember method of DatabaseInterface.
+func (m *MockDatabase) GetClanMember(ctx context.Context, clanId, userId string) (*models.ClanMember, error) {
+	args := m.Called(ctx, clanId, userId)
+	if member, ok := args.Get(0).(*models.ClanMember); ok {
+		return member, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// QueryClanMembers mocks the QueryClanMembers method of DatabaseInterface.
+func (m *MockDatabase) QueryClanMembers(ctx context.Context, clanId string) ([]models.ClanMember, error) {
+	args := m.Called(ctx, clanId)
+	if members, ok := args.Get(0).([]models.ClanMember); ok {
+		return members, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// JoinClanTransaction mocks the JoinClanTransaction method of DatabaseInterface.
+func (m *MockDatabase) JoinClanTransaction(ctx context.Context, member models.ClanMember, maxMembers int) error {
+	args := m.Called(ctx, member, maxMembers)
+	return args.Error(0)
+}
+
+// RemoveClanMemberTransaction mocks the RemoveClanMemberTransaction method of DatabaseInterface.
+func (m *MockDatabase) RemoveClanMemberTransaction(ctx context.Context, member models.ClanMember, by *models.ClanMember) error {
+	args := m.Called(ctx, member, by)
+	return args.Error(0)
+}
+
+// DisbandClanTransaction mocks the DisbandClanTransaction method of DatabaseInterface.
+func (m *MockDatabase) DisbandClanTransaction(ctx context.Context, leader models.ClanMember) error {
+	args := m.Called(ctx, leader)
+	return args.Error(0)
+}
+
+// SetClanRoleTransaction mocks the SetClanRoleTransaction method of DatabaseInterface.
+func (m *MockDatabase) SetClanRoleTransaction(ctx context.Context, member models.ClanMember, role string, by models.ClanMember) error {
+	args := m.Called(ctx, member, role, by)
+	return args.Error(0)
+}
+
+// SyncClanMemberLevel mocks the SyncClanMemberLevel method of DatabaseInterface.
+func (m *MockDatabase) SyncClanMemberLevel(ctx context.Context, member models.ClanMember, level int) error {
+	args := m.Called(ctx, member, level)
+	return args.Error(0)
+}
+
+// SearchClans mocks the SearchClans method of DatabaseInterface.
+func (m *MockDatabase) SearchClans(ctx context.Context, namePrefix, country string, page models.PageRequest) (models.Page[models.Clan], error) {
+	args := m.Called(ctx, namePrefix, country, page)
+	if clans, ok := args.Get(0).(models.Page[models.Clan]); ok {
+		return clans, args.Error(1)
+	}
+	return models.Page[models.Clan]{}, args.Error(1)
+}
+
+// QueryClanLeaderboard mocks the QueryClanLeaderboard method of DatabaseInterface.
+func (m *MockDatabase) QueryClanLeaderboard(ctx context.Context, page models.PageRequest) (models.Page[models.Clan], error) {
+	args := m.Called(ctx, page)
+	if clans, ok := args.Get(0).(models.Page[models.Clan]); ok {
+		return clans, args.Error(1)
+	}
+	return models.Page[models.Clan]{}, args.Error(1)
+}
+
+// GetClanTournamentEntry mocks the GetClanTournamentEntry method of DatabaseInterface.
+func (m *MockDatabase) GetClanTournamentEntry(ctx context.Context, tournamentId, clanId string) (*models.ClanTournamentEntry, error) {
+	args := m.Called(ctx, tournamentId, clanId)
+	if entry, ok := args.Get(0).(*models.ClanTournamentEntry); ok {
+		return entry, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// EnterClanTournament mocks the EnterClanTournament method of DatabaseInterface.
+func (m *MockDatabase) EnterClanTournament(ctx context.Context, entry models.ClanTournamentEntry, t *models.Tournament) (*models.ClanTournamentEntry, error) {
+	args := m.Called(ctx, entry, t)
+	if entered, ok := args.Get(0).(*models.ClanTournamentEntry); ok {
+		return entered, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// AddClanContribution mocks the AddClanContribution method of DatabaseInterface.
+func (m *MockDatabase) AddClanContribution(ctx context.Context, tournamentId, clanId, userId string, increment int) error {
+	args := m.Called(ctx, tournamentId, clanId, userId, increment)
+	return args.Error(0)
+}
+
+// QueryClanTournamentGroup mocks the QueryClanTournamentGroup method of DatabaseInterface.
+func (m *MockDatabase) QueryClanTournamentGroup(ctx context.Context, groupId string) ([]models.ClanTournamentEntry, error) {
+	args := m.Called(ctx, groupId)
+	if entries, ok := args.Get(0).([]models.ClanTournamentEntry); ok {
+		return entries, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// QueryClanTournamentEntries mocks the QueryClanTournamentEntries method of DatabaseInterface.
+func (m *MockDatabase) QueryClanTournamentEntries(ctx context.Context, tournamentId string, page models.PageRequest) (models.Page[models.ClanTournamentEntry], error) {
+	args := m.Called(ctx, tournamentId, page)
+	if entries, ok := args.Get(0).(models.Page[models.ClanTournamentEntry]); ok {
+		return entries, args.Error(1)
+	}
+	return models.Page[models.ClanTournamentEntry]{}, args.Error(1)
+}
+
+// QueryClanContributions mocks the QueryClanContributions method of DatabaseInterface.
+func (m *MockDatabase) QueryClanContributions(ctx context.Context, tournamentId, clanId string) ([]models.ClanContribution, error) {
+	args := m.Called(ctx, tournamentId, clanId)
+	if contributions, ok := args.Get(0).([]models.ClanContribution); ok {
+		return contributions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// PayClanRewardTransaction mocks the PayClanRewardTransaction method of DatabaseInterface.
+func (m *MockDatabase) PayClanRewardTransaction(ctx context.Context, entry models.HistoryEntry, clanId string) error {
+	args := m.Called(ctx, entry, clanId)
+	return args.Error(0)
+}
+
+// GetFriendship mocks the GetFriendship method of DatabaseInterface.
+func (m *MockDatabase) GetFriendship(ctx context.Context, userId, friendId string) (*models.Friendship, error) {
+	args := m.Called(ctx, userId, friendId)
+	if f, ok := args.Get(0).(*models.Friendship); ok {
+		return f, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// QueryFriendships mocks the QueryFriendships method of DatabaseInterface.
+func (m *MockDatabase) QueryFriendships(ctx context.Context, userId string) ([]models.Friendship, error) {
+	args := m.Called(ctx, userId)
+	if friendships, ok := args.Get(0).([]models.Friendship); ok {
+		return friendships, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// SendFriendRequestTransaction mocks the SendFriendRequestTransaction method of DatabaseInterface.
+func (m *MockDatabase) SendFriendRequestTransaction(ctx context.Context, request, incoming models.Friendship) error {
+	args := m.Called(ctx, request, incoming)
+	return args.Error(0)
+}
+
+// AcceptFriendRequestTransaction mocks the AcceptFriendRequestTransaction method of DatabaseInterface.
+func (m *MockDatabase) AcceptFriendRequestTransaction(ctx context.Context, userId, friendId string, maxFriends int) error {
+	args := m.Called(ctx, userId, friendId, maxFriends)
+	return args.Error(0)
+}
+
+// RemoveFriendshipTransaction mocks the RemoveFriendshipTransaction method of DatabaseInterface.
+func (m *MockDatabase) RemoveFriendshipTransaction(ctx context.Context, f models.Friendship) error {
+	args := m.Called(ctx, f)
+	return args.Error(0)
+}
+
+// BlockUserTransaction mocks the BlockUserTransaction method of DatabaseInterface.
+func (m *MockDatabase) BlockUserTransaction(ctx context.Context, block models.Friendship, existing *models.Friendship) error {
+	args := m.Called(ctx, block, existing)
+	return args.Error(0)
+}
+
+// BatchGetUsers mocks the BatchGetUsers method of DatabaseInterface.
+func (m *MockDatabase) BatchGetUsers(ctx context.Context, userIds []string) ([]models.User, error) {
+	args := m.Called(ctx, userIds)
+	if users, ok := args.Get(0).([]models.User); ok {
+		return users, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// BatchGetTournamentEntries mocks the BatchGetTournamentEntries method of DatabaseInterface.
+func (m *MockDatabase) BatchGetTournamentEntries(ctx context.Context, tournamentId string, userIds []string) ([]models.TournamentEntry, error) {
+	args := m.Called(ctx, tournamentId, userIds)
 	if entries, ok := args.Get(0).([]models.TournamentEntry); ok {
 		return entries, args.Error(1)
 	}
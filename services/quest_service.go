@@ -0,0 +1,141 @@
+// services/quest_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"good_blast/database"
+	"good_blast/errors"
+	"good_blast/models"
+)
+
+// QuestService implements QuestServiceInterface and QuestTracker.
+type QuestService struct {
+	DB     database.DatabaseInterface
+	Pool   models.QuestPool // quests daily missions are drawn from
+	PerDay int              // quests each user gets per day
+}
+
+// NewQuestService creates a new instance of QuestService with the default quest pool.
+func NewQuestService(db database.DatabaseInterface) *QuestService {
+	return &QuestService{
+		DB:     db,
+		Pool:   models.DefaultQuestPool,
+		PerDay: models.DefaultQuestsPerDay,
+	}
+}
+
+// questsFor returns the quests a user has on a UTC day.
+func (s *QuestService) questsFor(userID, day string) []models.QuestDefinition {
+	return s.Pool.ForDay(userID, day, s.PerDay)
+}
+
+// Record counts an event towards the user's quests for today. Quest progress is a side effect
+// of the action that caused the event, so failures are logged rather than returned.
+func (s *QuestService) Record(ctx context.Context, userID string, event models.QuestEvent) {
+	day := time.Now().UTC().Format(dayFormat)
+	for _, q := range s.questsFor(userID, day) {
+		n := q.Counts(event)
+		if n == 0 {
+			continue
+		}
+		if err := s.DB.AddQuestProgress(ctx, userID, models.QuestKey(day, q.ID), n); err != nil {
+			log.Printf("Error recording %s for quest %s of user %s: %v", event.Type, q.ID, userID, err)
+		}
+	}
+}
+
+// GetQuests returns the user's quests for today with their progress.
+func (s *QuestService) GetQuests(ctx context.Context, userID string) (*models.DailyQuests, error) {
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching user:", err)
+		return nil, fmt.Errorf("could not fetch user data: %w", err)
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	now := time.Now().UTC()
+	day := now.Format(dayFormat)
+	stored, err := s.DB.QueryUserQuests(ctx, userID, day)
+	if err != nil {
+		log.Println("Error fetching quests:", err)
+		return nil, fmt.Errorf("could not fetch quests: %w", err)
+	}
+	progress := make(map[string]models.QuestProgress, len(stored))
+	for _, p := range stored {
+		progress[p.QuestKey] = p
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daily := &models.DailyQuests{
+		Day:      day,
+		ResetsAt: midnight.AddDate(0, 0, 1).Format(time.RFC3339),
+		Quests:   []models.Quest{},
+	}
+	for _, q := range s.questsFor(userID, day) {
+		p := progress[models.QuestKey(day, q.ID)]
+		quest := models.Quest{
+			ID:          q.ID,
+			Description: q.Description,
+			Progress:    p.Progress,
+			Target:      q.Target,
+			Completed:   p.Progress >= q.Target,
+			Claimed:     p.ClaimedAt != "",
+			Reward:      q.Reward,
+		}
+		if quest.Progress > q.Target {
+			quest.Progress = q.Target
+		}
+		daily.Quests = append(daily.Quests, quest)
+	}
+	return daily, nil
+}
+
+// ClaimQuest grants the reward of one of today's quests once it is completed. The reward and
+// the claim are applied together, so a quest pays at most once.
+func (s *QuestService) ClaimQuest(ctx context.Context, userID, questID string) (*models.Quest, error) {
+	day := time.Now().UTC().Format(dayFormat)
+	var def *models.QuestDefinition
+	for _, q := range s.questsFor(userID, day) {
+		if q.ID == questID {
+			q := q
+			def = &q
+			break
+		}
+	}
+	if def == nil {
+		return nil, errors.ErrQuestNotFound
+	}
+
+	entry := models.HistoryEntry{
+		UserID: userID,
+		Type:   models.HistoryQuestReward,
+		Coins:  def.Reward.Coins,
+		Reason: fmt.Sprintf("quest %s on %s", def.ID, day),
+		Actor:  userID,
+	}
+	items := def.Reward
+	items.Coins = 0
+	if !items.IsEmpty() {
+		entry.Bundle = &items
+	}
+	if err := s.DB.ClaimQuestTransaction(ctx, entry, models.QuestKey(day, def.ID), def.Target); err != nil {
+		log.Println("Error claiming quest:", err)
+		return nil, err
+	}
+
+	return &models.Quest{
+		ID:          def.ID,
+		Description: def.Description,
+		Progress:    def.Target,
+		Target:      def.Target,
+		Completed:   true,
+		Claimed:     true,
+		Reward:      def.Reward,
+	}, nil
+}
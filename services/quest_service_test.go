@@ -0,0 +1,171 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	apperrors "good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+	"good_blast/services/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testQuestPool is small enough that every user gets all of it each day.
+var testQuestPool = models.QuestPool{
+	{ID: "clear_3", Description: "Clear 3 levels", Event: models.QuestEventLevelCleared, Target: 3, Reward: models.RewardBundle{Coins: 150}},
+	{ID: "enter", Description: "Enter today's tournament", Event: models.QuestEventTournamentEntered, Target: 1, Reward: models.RewardBundle{Coins: 200, Lives: 1}},
+	{ID: "top_10", Description: "Reach top 10", Event: models.QuestEventTournamentRank, Target: 1, MaxRank: 10, Reward: models.RewardBundle{Coins: 300}},
+}
+
+func newTestQuestService(db *mocks.MockDatabase) *services.QuestService {
+	questService := services.NewQuestService(db)
+	questService.Pool = testQuestPool
+	questService.PerDay = len(testQuestPool)
+	return questService
+}
+
+// recordingTracker remembers the quest events it is told about.
+type recordingTracker struct {
+	events []models.QuestEvent
+}
+
+func (r *recordingTracker) Record(ctx context.Context, userID string, event models.QuestEvent) {
+	r.events = append(r.events, event)
+}
+
+func TestQuestPool_ForDayIsStablePerUserAndDay(t *testing.T) {
+	day := daysAgo(0)
+	quests := models.DefaultQuestPool.ForDay("u1", day, 3)
+	assert.Len(t, quests, 3)
+	assert.Equal(t, quests, models.DefaultQuestPool.ForDay("u1", day, 3))
+
+	// Quests are shown in pool order whatever was drawn
+	for i := 1; i < len(quests); i++ {
+		assert.Less(t, indexOf(models.DefaultQuestPool, quests[i-1].ID), indexOf(models.DefaultQuestPool, quests[i].ID))
+	}
+
+	assert.Len(t, models.DefaultQuestPool.ForDay("u1", day, 10), len(models.DefaultQuestPool))
+}
+
+func indexOf(pool models.QuestPool, id string) int {
+	for i, q := range pool {
+		if q.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestRecord_CountsMatchingQuests(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	questService := newTestQuestService(mockDB)
+	ctx := context.Background()
+	day := daysAgo(0)
+
+	mockDB.On("AddQuestProgress", mock.Anything, "u1", day+"#clear_3", 2).Return(nil).Once()
+	questService.Record(ctx, "u1", models.QuestEvent{Type: models.QuestEventLevelCleared, Count: 2})
+
+	// Only ranks within MaxRank count
+	questService.Record(ctx, "u1", models.QuestEvent{Type: models.QuestEventTournamentRank, Count: 1, Rank: 11})
+	mockDB.On("AddQuestProgress", mock.Anything, "u1", day+"#top_10", 1).Return(nil).Once()
+	questService.Record(ctx, "u1", models.QuestEvent{Type: models.QuestEventTournamentRank, Count: 1, Rank: 4})
+
+	// Failures are logged, not returned
+	mockDB.On("AddQuestProgress", mock.Anything, "u1", day+"#enter", 1).Return(apperrors.ErrThrottled).Once()
+	questService.Record(ctx, "u1", models.QuestEvent{Type: models.QuestEventTournamentEntered, Count: 1})
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetQuests(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	questService := newTestQuestService(mockDB)
+	day := daysAgo(0)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1"}, nil)
+	mockDB.On("QueryUserQuests", mock.Anything, "u1", day).Return([]models.QuestProgress{
+		{UserID: "u1", QuestKey: day + "#clear_3", Progress: 5},
+		{UserID: "u1", QuestKey: day + "#enter", Progress: 1, ClaimedAt: "2024-11-02T10:00:00Z"},
+	}, nil)
+
+	daily, err := questService.GetQuests(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, day, daily.Day)
+	assert.Len(t, daily.Quests, 3)
+
+	assert.Equal(t, "clear_3", daily.Quests[0].ID)
+	assert.Equal(t, 3, daily.Quests[0].Progress) // capped at the target
+	assert.True(t, daily.Quests[0].Completed)
+	assert.False(t, daily.Quests[0].Claimed)
+	assert.True(t, daily.Quests[1].Claimed)
+	assert.Equal(t, 0, daily.Quests[2].Progress)
+	assert.False(t, daily.Quests[2].Completed)
+
+	mockDB.On("GetUser", mock.Anything, "ghost").Return(nil, nil)
+	_, err = questService.GetQuests(context.Background(), "ghost")
+	assert.Equal(t, apperrors.ErrUserNotFound, err)
+}
+
+func TestClaimQuest(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	questService := newTestQuestService(mockDB)
+	ctx := context.Background()
+	day := daysAgo(0)
+
+	mockDB.On("ClaimQuestTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.Type == models.HistoryQuestReward && e.Coins == 200 && e.Bundle != nil && e.Bundle.Lives == 1
+	}), day+"#enter", 1).Return(nil).Once()
+
+	quest, err := questService.ClaimQuest(ctx, "u1", "enter")
+	assert.NoError(t, err)
+	assert.True(t, quest.Claimed)
+	assert.Equal(t, models.RewardBundle{Coins: 200, Lives: 1}, quest.Reward)
+
+	mockDB.On("ClaimQuestTransaction", mock.Anything, mock.Anything, day+"#clear_3", 3).Return(apperrors.ErrQuestNotCompleted).Once()
+	_, err = questService.ClaimQuest(ctx, "u1", "clear_3")
+	assert.Equal(t, apperrors.ErrQuestNotCompleted, err)
+
+	_, err = questService.ClaimQuest(ctx, "u1", "clear_100")
+	assert.Equal(t, apperrors.ErrQuestNotFound, err)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateUserProgress_RecordsClearedLevels(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	tracker := &recordingTracker{}
+	userService := services.NewUserService(mockDB)
+	userService.Quests = tracker
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 5}, nil).Once()
+	mockDB.On("AdvanceLevel", mock.Anything, "u1", 5, 8, mock.Anything).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 8}, nil).Once()
+
+	_, err := userService.UpdateUserProgress(context.Background(), "u1", 8)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.QuestEvent{{Type: models.QuestEventLevelCleared, Count: 3}}, tracker.events)
+}
+
+func TestEnterTournament_RecordsQuestEvent(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	tracker := &recordingTracker{}
+	tournamentService := services.NewTournamentService(mockDB)
+	tournamentService.Quests = tracker
+
+	tournament := &models.Tournament{TournamentID: "2024-01-02", Active: true}
+	mockDB.On("GetTournament", mock.Anything, "2024-01-02").Return(tournament, nil)
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 15, Coins: 1000}, nil)
+	mockDB.On("EnterTournamentTransaction", mock.Anything, "u1", 15, 1000, tournament, "bronze").Return(apperrors.ErrAlreadyInTournament).Once()
+
+	// A failed entry counts for nothing
+	_, err := tournamentService.EnterTournament(context.Background(), "u1", "2024-01-02")
+	assert.Equal(t, apperrors.ErrAlreadyInTournament, err)
+	assert.Empty(t, tracker.events)
+
+	mockDB.On("EnterTournamentTransaction", mock.Anything, "u1", 15, 1000, tournament, "bronze").Return(nil).Once()
+	_, err = tournamentService.EnterTournament(context.Background(), "u1", "2024-01-02")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.QuestEvent{{Type: models.QuestEventTournamentEntered, Count: 1}}, tracker.events)
+}
@@ -2,27 +2,35 @@ package services
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 
+	"good_blast/config"
+
 	"github.com/redis/go-redis/v9"
 )
 
-var RDB *redis.Client
-
-func InitRedis() error {
-	RDB = redis.NewClient(&redis.Options{
-		Addr:     "localhost:6379", // Redis is running inside same container
-		Password: "",               // no password if not set
-		DB:       0,                // default DB
-	})
+// NewClient creates a Redis client from the configuration and pings it.
+// The client is returned even when the ping fails: go-redis reconnects lazily,
+// so callers may keep using it and treat errors as cache misses until Redis is back.
+func NewClient(cfg config.RedisConfig) (*redis.Client, error) {
+	opts := &redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	client := redis.NewClient(opts)
 
 	// Test connection
 	ctx := context.Background()
-	if err := RDB.Ping(ctx).Err(); err != nil {
-		return fmt.Errorf("failed to connect to Redis: %v", err)
+	if err := client.Ping(ctx).Err(); err != nil {
+		return client, fmt.Errorf("failed to connect to Redis: %v", err)
 	}
 
 	log.Println("Redis connected successfully")
-	return nil
+	return client, nil
 }
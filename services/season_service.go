@@ -0,0 +1,207 @@
+// services/season_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"good_blast/database"
+	"good_blast/errors"
+	"good_blast/models"
+)
+
+// SeasonService implements SeasonServiceInterface and SeasonTracker.
+type SeasonService struct {
+	DB      database.DatabaseInterface
+	Seasons models.SeasonSchedule // no seasons leaves the pass switched off
+}
+
+// NewSeasonService creates a new instance of SeasonService with no seasons scheduled.
+func NewSeasonService(db database.DatabaseInterface) *SeasonService {
+	return &SeasonService{DB: db}
+}
+
+// current returns the season running now.
+func (s *SeasonService) current() (models.Season, bool) {
+	return s.Seasons.At(time.Now())
+}
+
+// addXP adds XP to the user's progress in the running season. Like quest progress it is a side
+// effect of another action, so failures are logged rather than returned.
+func (s *SeasonService) addXP(ctx context.Context, userID, seasonID string, xp int) {
+	if err := s.DB.AddSeasonXP(ctx, userID, seasonID, xp); err != nil {
+		log.Printf("Error adding %d XP in season %s for user %s: %v", xp, seasonID, userID, err)
+	}
+}
+
+// LevelsCleared grants the running season's XP for count cleared levels.
+func (s *SeasonService) LevelsCleared(ctx context.Context, userID string, count int) {
+	season, ok := s.current()
+	if !ok || count < 1 || season.LevelXP == 0 {
+		return
+	}
+	s.addXP(ctx, userID, season.ID, count*season.LevelXP)
+}
+
+// TournamentPlaced grants the running season's XP for a tournament rank whose reward was paid.
+func (s *SeasonService) TournamentPlaced(ctx context.Context, userID string, rank int) {
+	season, ok := s.current()
+	if !ok {
+		return
+	}
+	if xp := season.XPForRank(rank); xp > 0 {
+		s.addXP(ctx, userID, season.ID, xp)
+	}
+}
+
+// progress returns the user's progress in a season, empty when they have none yet.
+func (s *SeasonService) progress(ctx context.Context, userID, seasonID string) (models.SeasonProgress, error) {
+	p, err := s.DB.GetSeasonProgress(ctx, userID, seasonID)
+	if err != nil {
+		log.Println("Error fetching season progress:", err)
+		return models.SeasonProgress{}, fmt.Errorf("could not fetch season progress: %w", err)
+	}
+	if p == nil {
+		return models.SeasonProgress{UserID: userID, SeasonID: seasonID}, nil
+	}
+	return *p, nil
+}
+
+// seasonStatus builds the user's view of a season from their progress.
+func seasonStatus(season models.Season, p models.SeasonProgress) *models.SeasonStatus {
+	st := &models.SeasonStatus{
+		SeasonID:    season.ID,
+		Name:        season.Name,
+		EndsAt:      season.EndsAt,
+		XP:          p.XP,
+		Tier:        season.TierFor(p.XP),
+		Premium:     p.Premium,
+		PremiumCost: season.PremiumCost,
+		Tiers:       make([]models.SeasonTierStatus, 0, len(season.Tiers)),
+	}
+	for i, t := range season.Tiers {
+		n := i + 1
+		st.Tiers = append(st.Tiers, models.SeasonTierStatus{
+			Tier:           n,
+			XP:             t.XP,
+			Reached:        p.XP >= t.XP,
+			Free:           t.Free,
+			FreeClaimed:    p.Claimed(models.SeasonTrackFree, n),
+			Premium:        t.Premium,
+			PremiumClaimed: p.Claimed(models.SeasonTrackPremium, n),
+		})
+	}
+	return st
+}
+
+// GetSeason returns the running season with the user's XP, tiers reached and claims.
+func (s *SeasonService) GetSeason(ctx context.Context, userID string) (*models.SeasonStatus, error) {
+	season, ok := s.current()
+	if !ok {
+		return nil, errors.ErrNoActiveSeason
+	}
+
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching user:", err)
+		return nil, fmt.Errorf("could not fetch user data: %w", err)
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	p, err := s.progress(ctx, userID, season.ID)
+	if err != nil {
+		return nil, err
+	}
+	return seasonStatus(season, p), nil
+}
+
+// ClaimTier grants the reward of a reached tier of the running season on the free or premium
+// track. The reward and the claim are applied together, so a tier pays at most once per track.
+func (s *SeasonService) ClaimTier(ctx context.Context, userID string, tier int, track string) (*models.SeasonClaim, error) {
+	if track != models.SeasonTrackFree && track != models.SeasonTrackPremium {
+		return nil, errors.ErrInvalidSeasonTrack
+	}
+	season, ok := s.current()
+	if !ok {
+		return nil, errors.ErrNoActiveSeason
+	}
+	def, ok := season.Tier(tier)
+	if !ok {
+		return nil, errors.ErrSeasonTierNotFound
+	}
+	reward := def.Free
+	if track == models.SeasonTrackPremium {
+		reward = def.Premium
+	}
+	if reward.IsEmpty() {
+		return nil, errors.ErrSeasonTierNotFound
+	}
+
+	// Fail early with the precise error; the transaction checks the same conditions again
+	p, err := s.progress(ctx, userID, season.ID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.Claimed(track, tier):
+		return nil, errors.ErrSeasonTierClaimed
+	case track == models.SeasonTrackPremium && !p.Premium:
+		return nil, errors.ErrSeasonPremiumRequired
+	case p.XP < def.XP:
+		return nil, errors.ErrSeasonTierLocked
+	}
+
+	entry := models.HistoryEntry{
+		UserID: userID,
+		Type:   models.HistorySeasonReward,
+		Coins:  reward.Coins,
+		Reason: fmt.Sprintf("season %s tier %d %s", season.ID, tier, track),
+		Actor:  userID,
+	}
+	items := reward
+	items.Coins = 0
+	if !items.IsEmpty() {
+		entry.Bundle = &items
+	}
+	if err := s.DB.ClaimSeasonTierTransaction(ctx, entry, season.ID, tier, def.XP, track); err != nil {
+		log.Println("Error claiming season tier:", err)
+		return nil, err
+	}
+
+	return &models.SeasonClaim{
+		SeasonID: season.ID,
+		Tier:     tier,
+		Track:    track,
+		Reward:   reward,
+	}, nil
+}
+
+// PurchasePremium buys the running season's premium pass for its premium cost in coins.
+func (s *SeasonService) PurchasePremium(ctx context.Context, userID string) (*models.SeasonStatus, error) {
+	season, ok := s.current()
+	if !ok {
+		return nil, errors.ErrNoActiveSeason
+	}
+
+	entry := models.HistoryEntry{
+		UserID: userID,
+		Type:   models.HistorySeasonPremium,
+		Coins:  -season.PremiumCost,
+		Reason: fmt.Sprintf("premium pass for season %s", season.ID),
+		Actor:  userID,
+	}
+	if err := s.DB.PurchaseSeasonPremiumTransaction(ctx, entry, season.ID); err != nil {
+		log.Println("Error purchasing premium pass:", err)
+		return nil, err
+	}
+
+	p, err := s.progress(ctx, userID, season.ID)
+	if err != nil {
+		return nil, err
+	}
+	return seasonStatus(season, p), nil
+}
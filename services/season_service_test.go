@@ -0,0 +1,172 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apperrors "good_blast/errors"
+	"good_blast/models"
+	"good_blast/services"
+	"good_blast/services/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newSeasonService returns a SeasonService whose only season is running now.
+func newSeasonService(db *mocks.MockDatabase) *services.SeasonService {
+	now := time.Now().UTC()
+	s := services.NewSeasonService(db)
+	s.Seasons = models.SeasonSchedule{
+		{
+			ID:          "past",
+			StartsAt:    now.AddDate(0, -2, 0).Format(time.RFC3339),
+			EndsAt:      now.AddDate(0, -1, 0).Format(time.RFC3339),
+			LevelXP:     99,
+			Tiers:       []models.SeasonTier{{XP: 1, Free: models.RewardBundle{Coins: 1}}},
+			PlacementXP: []models.PlacementXP{{MinRank: 1, MaxRank: 10, XP: 99}},
+		},
+		{
+			ID:          "s1",
+			Name:        "Spring",
+			StartsAt:    now.AddDate(0, 0, -7).Format(time.RFC3339),
+			EndsAt:      now.AddDate(0, 0, 7).Format(time.RFC3339),
+			LevelXP:     10,
+			PlacementXP: []models.PlacementXP{{MinRank: 1, MaxRank: 1, XP: 200}, {MinRank: 2, MaxRank: 3, XP: 100}},
+			PremiumCost: 1000,
+			Tiers: []models.SeasonTier{
+				{XP: 100, Free: models.RewardBundle{Coins: 100}},
+				{XP: 300, Free: models.RewardBundle{Coins: 200}, Premium: models.RewardBundle{Coins: 500, Boosters: map[string]int{"hammer": 1}}},
+			},
+		},
+	}
+	return s
+}
+
+func TestSeasonTracker_GrantsXPInTheRunningSeason(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	seasonService := newSeasonService(mockDB)
+
+	mockDB.On("AddSeasonXP", mock.Anything, "u1", "s1", 30).Return(nil).Once()
+	mockDB.On("AddSeasonXP", mock.Anything, "u1", "s1", 100).Return(nil).Once()
+
+	seasonService.LevelsCleared(context.Background(), "u1", 3)
+	seasonService.TournamentPlaced(context.Background(), "u1", 2)
+	// Ranks outside the placement XP earn nothing
+	seasonService.TournamentPlaced(context.Background(), "u1", 4)
+	mockDB.AssertExpectations(t)
+
+	// Without a running season nothing is recorded
+	idle := services.NewSeasonService(mockDB)
+	idle.LevelsCleared(context.Background(), "u1", 3)
+	mockDB.AssertNumberOfCalls(t, "AddSeasonXP", 2)
+}
+
+func TestGetSeason(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	seasonService := newSeasonService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1"}, nil)
+	mockDB.On("GetSeasonProgress", mock.Anything, "u1", "s1").Return(&models.SeasonProgress{
+		UserID: "u1", SeasonID: "s1", XP: 150, ClaimedFree: []int{1},
+	}, nil)
+
+	status, err := seasonService.GetSeason(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, "s1", status.SeasonID)
+	assert.Equal(t, 150, status.XP)
+	assert.Equal(t, 1, status.Tier)
+	assert.Equal(t, 1000, status.PremiumCost)
+	assert.Len(t, status.Tiers, 2)
+	assert.True(t, status.Tiers[0].Reached)
+	assert.True(t, status.Tiers[0].FreeClaimed)
+	assert.False(t, status.Tiers[1].Reached)
+
+	_, err = services.NewSeasonService(mockDB).GetSeason(context.Background(), "u1")
+	assert.Equal(t, apperrors.ErrNoActiveSeason, err)
+}
+
+func TestGetSeason_NoProgressYet(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	seasonService := newSeasonService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1"}, nil)
+	mockDB.On("GetSeasonProgress", mock.Anything, "u1", "s1").Return(nil, nil)
+
+	status, err := seasonService.GetSeason(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, status.XP)
+	assert.Equal(t, 0, status.Tier)
+	assert.False(t, status.Premium)
+}
+
+func TestClaimTier(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	seasonService := newSeasonService(mockDB)
+
+	mockDB.On("GetSeasonProgress", mock.Anything, "u1", "s1").Return(&models.SeasonProgress{
+		UserID: "u1", SeasonID: "s1", XP: 350, Premium: true,
+	}, nil)
+	mockDB.On("ClaimSeasonTierTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.UserID == "u1" && e.Type == models.HistorySeasonReward && e.Coins == 500 &&
+			e.Bundle != nil && e.Bundle.Boosters["hammer"] == 1 && e.Bundle.Coins == 0
+	}), "s1", 2, 300, models.SeasonTrackPremium).Return(nil).Once()
+
+	claim, err := seasonService.ClaimTier(context.Background(), "u1", 2, models.SeasonTrackPremium)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, claim.Tier)
+	assert.Equal(t, 500, claim.Reward.Coins)
+	mockDB.AssertExpectations(t)
+}
+
+func TestClaimTier_Rejected(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	seasonService := newSeasonService(mockDB)
+
+	mockDB.On("GetSeasonProgress", mock.Anything, "u1", "s1").Return(&models.SeasonProgress{
+		UserID: "u1", SeasonID: "s1", XP: 150, ClaimedFree: []int{1},
+	}, nil)
+
+	cases := []struct {
+		name  string
+		tier  int
+		track string
+		want  error
+	}{
+		{"unknown track", 1, "gold", apperrors.ErrInvalidSeasonTrack},
+		{"unknown tier", 3, models.SeasonTrackFree, apperrors.ErrSeasonTierNotFound},
+		{"empty reward", 1, models.SeasonTrackPremium, apperrors.ErrSeasonTierNotFound},
+		{"already claimed", 1, models.SeasonTrackFree, apperrors.ErrSeasonTierClaimed},
+		{"no premium pass", 2, models.SeasonTrackPremium, apperrors.ErrSeasonPremiumRequired},
+		{"not reached", 2, models.SeasonTrackFree, apperrors.ErrSeasonTierLocked},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := seasonService.ClaimTier(context.Background(), "u1", tc.tier, tc.track)
+			assert.Equal(t, tc.want, err)
+		})
+	}
+	mockDB.AssertNotCalled(t, "ClaimSeasonTierTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPurchasePremium(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	seasonService := newSeasonService(mockDB)
+
+	mockDB.On("PurchaseSeasonPremiumTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.UserID == "u1" && e.Type == models.HistorySeasonPremium && e.Coins == -1000
+	}), "s1").Return(nil).Once()
+	mockDB.On("GetSeasonProgress", mock.Anything, "u1", "s1").Return(&models.SeasonProgress{
+		UserID: "u1", SeasonID: "s1", Premium: true,
+	}, nil)
+
+	status, err := seasonService.PurchasePremium(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.True(t, status.Premium)
+
+	mockDB.On("PurchaseSeasonPremiumTransaction", mock.Anything, mock.Anything, "s1").Return(apperrors.ErrSeasonPremiumOwned).Once()
+	_, err = seasonService.PurchasePremium(context.Background(), "u1")
+	assert.Equal(t, apperrors.ErrSeasonPremiumOwned, err)
+	mockDB.AssertExpectations(t)
+}
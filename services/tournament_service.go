@@ -3,6 +3,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
@@ -11,15 +12,40 @@ import (
 	"good_blast/models"
 )
 
+// DefaultSeatShards is the number of seat counters a new tournament spreads joins over when
+// players are not grouped by league; each league has a single counter. Each counter absorbs
+// roughly 1000 joins per second.
+const DefaultSeatShards = 8
+
+// DefaultClaimWindow is how long after a tournament ends its rewards can be claimed.
+const DefaultClaimWindow = 7 * 24 * time.Hour
+
+// pendingRewardsLookback widens the range of tournaments GetPendingRewards looks at beyond
+// the claim window: a tournament runs for a day and may be ended late.
+const pendingRewardsLookback = 48 * time.Hour
+
 // TournamentService implements TournamentServiceInterface.
 type TournamentService struct {
-	DB database.DatabaseInterface
+	DB           database.DatabaseInterface
+	Leaderboards LeaderboardInvalidator // optional; notified when a group's scores change
+	Quests       QuestTracker           // optional; counts entries and rewarded ranks towards daily quests
+	Achievements AchievementTracker     // optional; evaluates achievements after a reward is paid
+	Seasons      SeasonTracker          // optional; grants season pass XP for rewarded ranks
+	Clans        ClanScoreTracker       // optional; adds scores to the user's clan's tournament entry
+	SeatShards   int                    // seat counters per new tournament; 0 means DefaultSeatShards
+	ClaimWindow  time.Duration          // how long rewards stay claimable after a tournament ends; 0 means DefaultClaimWindow
+	Rewards      models.RewardTable     // reward bundle per rank within a group; nil means models.DefaultRewardTable
+	Leagues      models.LeagueLadder    // leagues groups are matched in and rewards scaled by; nil means models.DefaultLeagueLadder
 }
 
 // NewTournamentService creates a new instance of TournamentService.
 func NewTournamentService(db database.DatabaseInterface) *TournamentService {
 	return &TournamentService{
-		DB: db,
+		DB:          db,
+		SeatShards:  DefaultSeatShards,
+		ClaimWindow: DefaultClaimWindow,
+		Rewards:     models.DefaultRewardTable,
+		Leagues:     models.DefaultLeagueLadder,
 	}
 }
 
@@ -40,13 +66,16 @@ func (s *TournamentService) StartTournament(ctx context.Context) (*models.Tourna
 
 	startTime := nowUTC.Format(time.RFC3339)                   // e.g., "2024-01-15T00:00:00Z"
 	endTime := nowUTC.Add(24 * time.Hour).Format(time.RFC3339) // e.g., "2024-01-16T00:00:00Z"
+	seatShards := s.SeatShards
+	if seatShards < 1 {
+		seatShards = DefaultSeatShards
+	}
 	tournament := models.Tournament{
-		TournamentID:      tournamentID,
-		StartTime:         startTime,
-		EndTime:           endTime,
-		Active:            true,
-		CurrentGroupIndex: 1, // Initialize group index
-		CurrentGroupCount: 0, // Initialize group count
+		TournamentID: tournamentID,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		Active:       true,
+		SeatShards:   seatShards, // Fixed for the tournament's lifetime so seat numbers stay consistent
 	}
 
 	// Insert into Tournaments table
@@ -58,7 +87,7 @@ func (s *TournamentService) StartTournament(ctx context.Context) (*models.Tourna
 	return &tournament, nil
 }
 
-// EndTournament marks a tournament as inactive.
+// EndTournament marks a tournament as inactive and opens its claim window.
 func (s *TournamentService) EndTournament(ctx context.Context, tournamentID string) error {
 	t, err := s.DB.GetTournament(ctx, tournamentID)
 	if err != nil {
@@ -72,8 +101,9 @@ func (s *TournamentService) EndTournament(ctx context.Context, tournamentID stri
 		return errors.ErrTournamentAlreadyInactive
 	}
 
-	// Mark the tournament as inactive
-	if err := s.DB.UpdateTournamentStatus(ctx, tournamentID, false); err != nil {
+	// Mark the tournament as inactive; each tournament keeps the deadline it ended with
+	deadline := time.Now().UTC().Add(s.claimWindow()).Format(time.RFC3339)
+	if err := s.DB.CloseTournament(ctx, tournamentID, deadline); err != nil {
 		log.Println("Error ending tournament:", err)
 		return err
 	}
@@ -102,26 +132,40 @@ func (s *TournamentService) EnterTournament(ctx context.Context, userID string,
 	if user == nil {
 		return 0, errors.ErrUserNotFound
 	}
-	if user.Level < 10 {
+	if user.Level < models.MinEntryLevel {
 		return 0, errors.ErrUserLevelTooLow
 	}
-	if user.Coins < 500 {
+	if user.Coins < models.EntryFee {
 		return 0, errors.ErrInsufficientCoins
 	}
 
-	// Perform the tournament entry transaction
-	err = s.DB.EnterTournamentTransaction(ctx, userID, user.Level, user.Coins, t)
+	// Perform the tournament entry transaction; the user is grouped with players of their league
+	err = s.DB.EnterTournamentTransaction(ctx, userID, user.Level, user.Coins, t, s.ladder().Of(user.League).ID)
 	if err != nil {
 		log.Println("EnterTournamentTransaction error:", err)
 		return 0, err
 	}
 
-	remainingCoins := user.Coins - 500
+	if s.Quests != nil {
+		s.Quests.Record(ctx, userID, models.QuestEvent{Type: models.QuestEventTournamentEntered, Count: 1})
+	}
+
+	remainingCoins := user.Coins - models.EntryFee
 	return remainingCoins, nil
 }
 
 // UpdateScore increments a user's score during the active tournament.
 func (s *TournamentService) UpdateScore(ctx context.Context, tournamentID string, userID string, increment int) (int, error) {
+	// Scores are frozen once the tournament ends: its ranks are being paid out
+	t, err := s.DB.GetTournament(ctx, tournamentID)
+	if err != nil {
+		log.Println("Error fetching tournament:", err)
+		return 0, err
+	}
+	if t == nil || !t.Active || t.Cancelled {
+		return 0, errors.ErrTournamentNotActive
+	}
+
 	// Fetch the tournament entry
 	entry, err := s.DB.GetTournamentEntry(ctx, tournamentID, userID)
 	if err != nil {
@@ -137,92 +181,606 @@ func (s *TournamentService) UpdateScore(ctx context.Context, tournamentID string
 		log.Println("Error updating tournament score:", err)
 		return 0, err
 	}
+	s.scoreAdded(ctx, *entry, increment)
 
 	newScore := entry.Score + increment
 	return newScore, nil
 }
 
+// ScoreLevelWin adds points to the user's score in the active tournament for a won level
+// session. The score and a mark on the session are written together, so a session scores at
+// most once: when it already did, the entry's current score is returned without adding to it.
+func (s *TournamentService) ScoreLevelWin(ctx context.Context, tournamentID, userID, sessionID string, points int) (int, error) {
+	entry, err := s.DB.GetTournamentEntry(ctx, tournamentID, userID)
+	if err != nil {
+		log.Println("Error fetching tournament entry:", err)
+		return 0, err
+	}
+	if entry == nil {
+		return 0, errors.ErrTournamentEntryNotFound
+	}
+
+	err = s.DB.ScoreLevelSessionTransaction(ctx, tournamentID, userID, sessionID, points)
+	switch err {
+	case nil:
+	case errors.ErrLevelSessionScored:
+		return entry.Score, nil
+	default:
+		log.Println("Error scoring level session:", err)
+		return 0, err
+	}
+	s.scoreAdded(ctx, *entry, points)
+
+	return entry.Score + points, nil
+}
+
+// scoreAdded tells the leaderboards and the clan tracker about points added to an entry.
+func (s *TournamentService) scoreAdded(ctx context.Context, entry models.TournamentEntry, points int) {
+	if s.Leaderboards != nil {
+		s.Leaderboards.GroupScoreChanged(ctx, entry.GroupID)
+	}
+	if s.Clans != nil {
+		s.Clans.ScoreAdded(ctx, entry.TournamentID, entry.UserID, points)
+	}
+}
+
 // ClaimReward allows a user to claim their reward after the tournament has ended.
-func (s *TournamentService) ClaimReward(ctx context.Context, tournamentID string, userID string) (int, int, error) {
+// It returns the user's rank within their group and the bundle granted.
+func (s *TournamentService) ClaimReward(ctx context.Context, tournamentID string, userID string) (int, models.RewardBundle, error) {
+	var none models.RewardBundle
+
 	// Fetch the tournament
 	t, err := s.DB.GetTournament(ctx, tournamentID)
 	if err != nil || t == nil {
 		log.Println("Error or no tournament found:", err)
-		return 0, 0, errors.ErrTournamentNotFound
+		return 0, none, errors.ErrTournamentNotFound
+	}
+
+	// Cancelled tournaments pay no rewards
+	if t.Cancelled {
+		return 0, none, errors.ErrTournamentCancelled
 	}
 
 	// Ensure the tournament has ended
 	if t.Active {
-		return 0, 0, errors.ErrTournamentStillActive
+		return 0, none, errors.ErrTournamentStillActive
+	}
+
+	// Rewards left unclaimed past the deadline are forfeited
+	if claimWindowClosed(t, time.Now()) {
+		return 0, none, errors.ErrRewardExpired
 	}
 
 	// Fetch the user's tournament entry
 	entry, err := s.DB.GetTournamentEntry(ctx, tournamentID, userID)
 	if err != nil {
 		log.Println("Error fetching tournament entry:", err)
-		return 0, 0, err
+		return 0, none, err
 	}
 	if entry == nil {
-		return 0, 0, errors.ErrTournamentEntryNotFound
+		return 0, none, errors.ErrTournamentEntryNotFound
 	}
 
 	// Check if reward has already been claimed
 	if entry.ClaimedReward {
-		return 0, 0, errors.ErrRewardAlreadyClaimed
+		return 0, none, errors.ErrRewardAlreadyClaimed
+	}
+	if entry.Expired {
+		return 0, none, errors.ErrRewardExpired
 	}
 
 	// Retrieve the groupId from the user's tournament entry
 	groupID := entry.GroupID
 	if groupID == "" {
-		return 0, 0, errors.ErrGroupIDMissing
+		return 0, none, errors.ErrGroupIDMissing
 	}
 
-	// Query top users within the user's group using the GroupScoreIndex
-	topEntries, err := s.DB.QueryTournamentEntriesByGroupScore(ctx, groupID) // Fetch top 10 for rewards
+	// Determine user's rank within the group
+	userRank, err := s.groupRank(ctx, groupID, userID)
 	if err != nil {
 		log.Println("Error querying GroupScoreIndex:", err)
-		return 0, 0, err
-	}
-
-	// Determine user's rank within the group
-	var userRank int
-	for i, e := range topEntries {
-		if e.UserID == userID {
-			userRank = i + 1 // 1-based index
-			break
-		}
+		return 0, none, err
 	}
 
 	// If user is not in the top 10 of their group, no reward is applicable
 	if userRank == 0 {
-		return 0, 0, errors.ErrNoRewardForRank
-	}
-
-	// Reward logic based on rank within the group
-	var reward int
-	switch {
-	case userRank == 1:
-		reward = 5000
-	case userRank == 2:
-		reward = 3000
-	case userRank == 3:
-		reward = 2000
-	case userRank >= 4 && userRank <= 10:
-		reward = 1000
-	default:
-		reward = 0
+		return 0, none, errors.ErrNoRewardForRank
 	}
 
-	if reward == 0 {
-		return userRank, 0, errors.ErrNoRewardForRank
+	// Reward bundle from the reward table, based on rank within the group and scaled by league
+	reward := s.rewardFor(userRank, entry.League)
+
+	if reward.IsEmpty() {
+		return userRank, none, errors.ErrNoRewardForRank
 	}
 
 	// Perform a transaction to update user coins and mark reward as claimed
 	err = s.DB.ClaimRewardTransaction(ctx, userID, reward, tournamentID)
 	if err != nil {
 		log.Println("Error during reward transaction:", err)
-		return 0, 0, err
+		return 0, none, err
 	}
+	s.rewardPaid(ctx, userID, userRank, reward)
 
 	return userRank, reward, nil
 }
+
+// rewardPaid tells the quest, achievement and season trackers about a paid tournament reward.
+func (s *TournamentService) rewardPaid(ctx context.Context, userID string, rank int, reward models.RewardBundle) {
+	if s.Quests != nil {
+		s.Quests.Record(ctx, userID, models.QuestEvent{Type: models.QuestEventTournamentRank, Count: 1, Rank: rank})
+	}
+	if s.Achievements != nil {
+		s.Achievements.TournamentRewardPaid(ctx, userID, rank, reward)
+	}
+	if s.Seasons != nil {
+		s.Seasons.TournamentPlaced(ctx, userID, rank)
+	}
+}
+
+// groupRank returns the user's 1-based rank within a group, or 0 when they are not in its standings.
+func (s *TournamentService) groupRank(ctx context.Context, groupID, userID string) (int, error) {
+	// Query top users within the group using the GroupScoreIndex
+	topEntries, err := s.DB.QueryTournamentEntriesByGroupScore(ctx, groupID)
+	if err != nil {
+		return 0, err
+	}
+	for i, e := range topEntries {
+		if e.UserID == userID {
+			return i + 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// claimWindow returns the configured claim window, falling back to DefaultClaimWindow.
+func (s *TournamentService) claimWindow() time.Duration {
+	if s.ClaimWindow <= 0 {
+		return DefaultClaimWindow
+	}
+	return s.ClaimWindow
+}
+
+// claimWindowClosed reports whether the rewards of an ended tournament have expired at now.
+// Tournaments without a deadline were ended before claim windows existed and never expire.
+func claimWindowClosed(t *models.Tournament, now time.Time) bool {
+	if t.ClaimDeadline == "" {
+		return false
+	}
+	deadline, err := time.Parse(time.RFC3339, t.ClaimDeadline)
+	return err == nil && now.After(deadline)
+}
+
+// rewardFor returns the bundle earned for a 1-based rank within a group of a league. Entries
+// from before leagues existed have no league and earn the unscaled reward.
+func (s *TournamentService) rewardFor(rank int, league string) models.RewardBundle {
+	rewards := s.Rewards
+	if rewards == nil {
+		rewards = models.DefaultRewardTable
+	}
+	bundle := rewards.ForRank(rank)
+	if league == "" {
+		return bundle
+	}
+	return bundle.Scaled(s.ladder().Of(league).RewardPercent)
+}
+
+// ladder returns the configured league ladder, falling back to models.DefaultLeagueLadder.
+func (s *TournamentService) ladder() models.LeagueLadder {
+	if s.Leagues == nil {
+		return models.DefaultLeagueLadder
+	}
+	return s.Leagues
+}
+
+// CancelTournament voids a tournament: it stops accepting entries, blocks reward claims
+// and settlement, and refunds the entry fee to every entrant whose reward was not already paid,
+// recording each refund in the user's history. Refunds are conditional per entry, so an interrupted cancellation can simply be
+// run again to finish the job; the returned report covers the work done by this call.
+func (s *TournamentService) CancelTournament(ctx context.Context, tournamentID, actor string) (*models.CancellationReport, error) {
+	t, err := s.DB.GetTournament(ctx, tournamentID)
+	if err != nil {
+		log.Println("Error fetching tournament:", err)
+		return nil, err
+	}
+	if t == nil {
+		return nil, errors.ErrTournamentNotFound
+	}
+	if t.Cancelled && t.RefundsDone {
+		return nil, errors.ErrTournamentCancelled
+	}
+
+	if !t.Cancelled {
+		if err := s.DB.CancelTournament(ctx, tournamentID); err != nil {
+			log.Println("Error cancelling tournament:", err)
+			return nil, err
+		}
+	}
+
+	// Entries are only written while the tournament is active, so once it is cancelled
+	// a single pass sees every entry there will ever be.
+	report := &models.CancellationReport{TournamentID: tournamentID}
+	if err := s.refundEntries(ctx, tournamentID, actor, report); err != nil {
+		log.Println("Error refunding tournament entries:", err)
+		return report, err
+	}
+
+	if err := s.DB.MarkRefundsDone(ctx, tournamentID); err != nil {
+		log.Println("Error marking refunds done:", err)
+		return report, err
+	}
+	report.Completed = true
+	return report, nil
+}
+
+// refundEntries refunds every entry not refunded yet, counting the outcome in report.
+func (s *TournamentService) refundEntries(ctx context.Context, tournamentID, actor string, report *models.CancellationReport) error {
+	return s.forEachEntry(ctx, tournamentID, func(e models.TournamentEntry) error {
+		report.Entries++
+		if e.Refunded {
+			report.AlreadyRefunded++
+			return nil
+		}
+		if e.ClaimedReward {
+			report.RewardClaimed++
+			return nil
+		}
+
+		err := s.DB.RefundEntryTransaction(ctx, models.HistoryEntry{
+			UserID:       e.UserID,
+			Type:         models.HistoryEntryFeeRefund,
+			Coins:        models.EntryFee,
+			Reason:       "tournament " + tournamentID + " cancelled",
+			Actor:        actor,
+			TournamentID: tournamentID,
+		})
+		if err == errors.ErrEntryAlreadyRefunded {
+			report.AlreadyRefunded++
+			return nil
+		}
+		if err == errors.ErrRewardAlreadyClaimed {
+			report.RewardClaimed++
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to refund %s: %w", e.UserID, err)
+		}
+		report.Refunded++
+		report.CoinsRefunded += models.EntryFee
+		return nil
+	})
+}
+
+// forEachEntry calls fn for every entry of a tournament, following pagination.
+func (s *TournamentService) forEachEntry(ctx context.Context, tournamentID string, fn func(models.TournamentEntry) error) error {
+	page := models.PageRequest{}
+	for {
+		entries, err := s.DB.QueryTournamentEntries(ctx, tournamentID, page)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries.Items {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		if entries.NextCursor == "" {
+			return nil
+		}
+		page.Cursor = entries.NextCursor
+	}
+}
+
+// TournamentResults calls fn with the final standing of every entrant, page by page.
+// Ranks come from the GroupScoreIndex, the same source ClaimReward uses.
+func (s *TournamentService) TournamentResults(ctx context.Context, tournamentID string, fn func(models.TournamentResult) error) error {
+	ranks := make(map[string]map[string]int) // groupId -> userId -> rank
+
+	return s.forEachEntry(ctx, tournamentID, func(e models.TournamentEntry) error {
+		groupRanks, ok := ranks[e.GroupID]
+		if !ok && e.GroupID != "" {
+			standings, err := s.DB.QueryTournamentEntriesByGroupScore(ctx, e.GroupID)
+			if err != nil {
+				return err
+			}
+			groupRanks = make(map[string]int, len(standings))
+			for i, st := range standings {
+				groupRanks[st.UserID] = i + 1
+			}
+			ranks[e.GroupID] = groupRanks
+		}
+
+		rank := groupRanks[e.UserID]
+		bundle := s.rewardFor(rank, e.League)
+		return fn(models.TournamentResult{
+			TournamentID:  tournamentID,
+			GroupID:       e.GroupID,
+			UserID:        e.UserID,
+			League:        e.League,
+			Score:         e.Score,
+			Rank:          rank,
+			GroupSize:     len(groupRanks),
+			Reward:        bundle.Coins,
+			Bundle:        bundle,
+			ClaimedReward: e.ClaimedReward,
+		})
+	})
+}
+
+// SettleTournament pays every unclaimed reward of an ended tournament. Claims are
+// conditional, so running it again (or alongside users claiming) never pays twice.
+func (s *TournamentService) SettleTournament(ctx context.Context, tournamentID string) (*models.SettlementReport, error) {
+	t, err := s.DB.GetTournament(ctx, tournamentID)
+	if err != nil {
+		log.Println("Error fetching tournament:", err)
+		return nil, err
+	}
+	if t == nil {
+		return nil, errors.ErrTournamentNotFound
+	}
+	if t.Cancelled {
+		return nil, errors.ErrTournamentCancelled
+	}
+	if t.Active {
+		return nil, errors.ErrTournamentStillActive
+	}
+	if claimWindowClosed(t, time.Now()) {
+		return nil, errors.ErrRewardExpired
+	}
+
+	report := &models.SettlementReport{TournamentID: tournamentID}
+	err = s.TournamentResults(ctx, tournamentID, func(r models.TournamentResult) error {
+		report.Entries++
+		switch {
+		case r.Bundle.IsEmpty():
+			report.NoReward++
+			return nil
+		case r.ClaimedReward:
+			report.AlreadyClaimed++
+			return nil
+		}
+
+		err := s.DB.ClaimRewardTransaction(ctx, r.UserID, r.Bundle, tournamentID)
+		if err == errors.ErrRewardAlreadyClaimed {
+			report.AlreadyClaimed++
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to pay reward to %s: %w", r.UserID, err)
+		}
+		s.rewardPaid(ctx, r.UserID, r.Rank, r.Bundle)
+		report.Paid++
+		report.CoinsPaid += r.Reward
+		return nil
+	})
+	if err != nil {
+		log.Println("Error settling tournament:", err)
+		return report, err
+	}
+	return report, nil
+}
+
+// ApplyLeagues moves every entrant of an ended tournament up or down the league ladder by
+// their rank within their group and records the move in their league history. Results are
+// applied conditionally and in tournament order, so a run can be interrupted and repeated, but
+// a tournament's results are skipped for users whose later tournament was applied first.
+func (s *TournamentService) ApplyLeagues(ctx context.Context, tournamentID string) (*models.LeagueReport, error) {
+	t, err := s.DB.GetTournament(ctx, tournamentID)
+	if err != nil {
+		log.Println("Error fetching tournament:", err)
+		return nil, err
+	}
+	if t == nil {
+		return nil, errors.ErrTournamentNotFound
+	}
+	if t.Cancelled {
+		return nil, errors.ErrTournamentCancelled
+	}
+	if t.Active {
+		return nil, errors.ErrTournamentStillActive
+	}
+
+	ladder := s.ladder()
+	now := time.Now().UTC().Format(time.RFC3339)
+	report := &models.LeagueReport{TournamentID: tournamentID}
+	err = s.TournamentResults(ctx, tournamentID, func(r models.TournamentResult) error {
+		report.Entries++
+		from := ladder.Of(r.League).ID
+		to, movement := ladder.Move(from, r.Rank, r.GroupSize, r.Score)
+
+		err := s.DB.ApplyLeagueResultTransaction(ctx, models.LeagueResult{
+			UserID:       r.UserID,
+			TournamentID: tournamentID,
+			GroupID:      r.GroupID,
+			Rank:         r.Rank,
+			GroupSize:    r.GroupSize,
+			From:         from,
+			To:           to,
+			Movement:     movement,
+			AppliedAt:    now,
+		})
+		if err == errors.ErrLeagueResultApplied {
+			report.AlreadyApplied++
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply league result of %s: %w", r.UserID, err)
+		}
+		switch movement {
+		case models.LeaguePromoted:
+			report.Promoted++
+		case models.LeagueRelegated:
+			report.Relegated++
+		default:
+			report.Stayed++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("Error applying league results:", err)
+		return report, err
+	}
+	return report, nil
+}
+
+// GetPendingRewards lists the rewards a user has earned in ended tournaments but not claimed yet,
+// newest tournament first, so the client can prompt for them at login.
+func (s *TournamentService) GetPendingRewards(ctx context.Context, userID string) ([]models.PendingReward, error) {
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching user:", err)
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	// Tournament IDs are dates, so entries older than any open claim window are skipped by the
+	// key condition. A tournament ended with a longer window than the current one may be missed
+	// here, but stays claimable until its own deadline.
+	now := time.Now().UTC()
+	since := now.Add(-s.claimWindow() - pendingRewardsLookback).Format("2006-01-02")
+
+	tournaments := make(map[string]*models.Tournament)
+	pending := []models.PendingReward{}
+	page := models.PageRequest{}
+	for {
+		entries, err := s.DB.QueryUnclaimedEntriesByUser(ctx, userID, since, page)
+		if err != nil {
+			log.Println("Error querying unclaimed entries:", err)
+			return nil, err
+		}
+
+		for _, e := range entries.Items {
+			t, ok := tournaments[e.TournamentID]
+			if !ok {
+				t, err = s.DB.GetTournament(ctx, e.TournamentID)
+				if err != nil {
+					log.Println("Error fetching tournament:", err)
+					return nil, err
+				}
+				tournaments[e.TournamentID] = t
+			}
+			if t == nil || t.Active || t.Cancelled || e.GroupID == "" || claimWindowClosed(t, now) {
+				continue
+			}
+
+			rank, err := s.groupRank(ctx, e.GroupID, userID)
+			if err != nil {
+				log.Println("Error querying GroupScoreIndex:", err)
+				return nil, err
+			}
+			reward := s.rewardFor(rank, e.League)
+			if reward.IsEmpty() {
+				continue
+			}
+			pending = append(pending, models.PendingReward{
+				TournamentID:  e.TournamentID,
+				GroupID:       e.GroupID,
+				Rank:          rank,
+				Reward:        reward.Coins,
+				Bundle:        reward,
+				ClaimDeadline: t.ClaimDeadline,
+			})
+		}
+
+		if entries.NextCursor == "" {
+			return pending, nil
+		}
+		page.Cursor = entries.NextCursor
+	}
+}
+
+// ClaimAllRewards claims every pending reward of a user, one transaction per tournament, and
+// reports the outcome for each. Claims are conditional, so a call that fails part-way (or races
+// another claim) can simply be repeated without paying twice. On such a failure the result of
+// the claims made so far is returned along with the error.
+func (s *TournamentService) ClaimAllRewards(ctx context.Context, userID string) (*models.ClaimAllResult, error) {
+	pending, err := s.GetPendingRewards(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ClaimAllResult{UserID: userID, Claims: make([]models.RewardClaim, 0, len(pending))}
+	for _, p := range pending {
+		claim := models.RewardClaim{
+			TournamentID: p.TournamentID,
+			GroupID:      p.GroupID,
+			Rank:         p.Rank,
+			Status:       models.ClaimStatusClaimed,
+		}
+
+		err := s.DB.ClaimRewardTransaction(ctx, userID, p.Bundle, p.TournamentID)
+		switch err {
+		case nil:
+			claim.Reward = p.Reward
+			bundle := p.Bundle // p is reused by the loop
+			claim.Bundle = &bundle
+			result.TotalReward += p.Reward
+			s.rewardPaid(ctx, userID, p.Rank, p.Bundle)
+		case errors.ErrRewardAlreadyClaimed:
+			claim.Status = models.ClaimStatusAlreadyClaimed
+		case errors.ErrRewardExpired:
+			claim.Status = models.ClaimStatusExpired
+		case errors.ErrTournamentCancelled:
+			claim.Status = models.ClaimStatusCancelled
+		default:
+			log.Println("Error during reward transaction:", err)
+			return result, err
+		}
+		result.Claims = append(result.Claims, claim)
+	}
+	return result, nil
+}
+
+// ExpireRewards marks every unclaimed entry of a tournament whose claim window has closed as
+// expired, so its rewards stop showing as pending and can no longer be claimed or settled.
+// Entries are expired conditionally, so a run can be interrupted and repeated safely.
+func (s *TournamentService) ExpireRewards(ctx context.Context, tournamentID string) (*models.ExpiryReport, error) {
+	t, err := s.DB.GetTournament(ctx, tournamentID)
+	if err != nil {
+		log.Println("Error fetching tournament:", err)
+		return nil, err
+	}
+	if t == nil {
+		return nil, errors.ErrTournamentNotFound
+	}
+	if t.Cancelled {
+		return nil, errors.ErrTournamentCancelled
+	}
+	if t.Active {
+		return nil, errors.ErrTournamentStillActive
+	}
+	if !claimWindowClosed(t, time.Now()) {
+		return nil, errors.ErrClaimWindowOpen
+	}
+
+	report := &models.ExpiryReport{TournamentID: tournamentID}
+	err = s.forEachEntry(ctx, tournamentID, func(e models.TournamentEntry) error {
+		report.Entries++
+		switch {
+		case e.ClaimedReward || e.Refunded:
+			report.AlreadyClaimed++
+			return nil
+		case e.Expired:
+			report.AlreadyExpired++
+			return nil
+		}
+
+		err := s.DB.ExpireEntryReward(ctx, tournamentID, e.UserID)
+		if err == errors.ErrRewardAlreadyClaimed {
+			report.AlreadyClaimed++
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to expire reward of %s: %w", e.UserID, err)
+		}
+		report.Expired++
+		return nil
+	})
+	if err != nil {
+		log.Println("Error expiring rewards:", err)
+		return report, err
+	}
+	return report, nil
+}
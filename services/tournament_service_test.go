@@ -32,6 +32,7 @@ func TestStartTournament_Success(t *testing.T) {
 	assert.NotNil(t, tournament)
 	assert.Equal(t, today, tournament.TournamentID)
 	assert.True(t, tournament.Active)
+	assert.Equal(t, services.DefaultSeatShards, tournament.SeatShards)
 
 	mockDB.AssertExpectations(t)
 }
@@ -43,12 +44,10 @@ func TestStartTournament_AlreadyActive(t *testing.T) {
 	ctx := context.Background()
 	today := time.Now().UTC().Format("2006-01-02")
 	activeTournament := &models.Tournament{
-		TournamentID:      today,
-		StartTime:         "someStartTime",
-		EndTime:           "someEndTime",
-		Active:            true,
-		CurrentGroupIndex: 1,
-		CurrentGroupCount: 0,
+		TournamentID: today,
+		StartTime:    "someStartTime",
+		EndTime:      "someEndTime",
+		Active:       true,
 	}
 
 	mockDB.On("GetTournament", mock.Anything, today).Return(activeTournament, nil)
@@ -68,15 +67,17 @@ func TestEndTournament_Success(t *testing.T) {
 	ctx := context.Background()
 	tID := "2024-01-02"
 	tournament := &models.Tournament{
-		TournamentID:      tID,
-		Active:            true,
-		CurrentGroupIndex: 1,
-		CurrentGroupCount: 0,
+		TournamentID: tID,
+		Active:       true,
 	}
 
 	// Mock retrieval and update
 	mockDB.On("GetTournament", mock.Anything, tID).Return(tournament, nil)
-	mockDB.On("UpdateTournamentStatus", mock.Anything, tID, false).Return(nil)
+	// The claim window opens when the tournament ends
+	mockDB.On("CloseTournament", mock.Anything, tID, mock.MatchedBy(func(deadline string) bool {
+		d, err := time.Parse(time.RFC3339, deadline)
+		return err == nil && time.Until(d) > services.DefaultClaimWindow-time.Minute
+	})).Return(nil)
 
 	err := service.EndTournament(ctx, tID)
 	assert.NoError(t, err)
@@ -127,10 +128,8 @@ func TestEnterTournament_Success(t *testing.T) {
 	userID := "user123"
 	tID := "2024-01-02"
 	tournament := &models.Tournament{
-		TournamentID:      tID,
-		Active:            true,
-		CurrentGroupIndex: 1,
-		CurrentGroupCount: 0,
+		TournamentID: tID,
+		Active:       true,
 	}
 	user := &models.User{
 		UserID: "user123",
@@ -142,7 +141,7 @@ func TestEnterTournament_Success(t *testing.T) {
 	mockDB.On("GetTournament", mock.Anything, tID).Return(tournament, nil).Once()
 	mockDB.On("GetUser", mock.Anything, userID).Return(user, nil).Once()
 	// EnterTournamentTransaction should succeed
-	mockDB.On("EnterTournamentTransaction", mock.Anything, userID, user.Level, user.Coins, tournament).Return(nil).Once()
+	mockDB.On("EnterTournamentTransaction", mock.Anything, userID, user.Level, user.Coins, tournament, "bronze").Return(nil).Once()
 
 	remainingCoins, err := service.EnterTournament(ctx, userID, tID)
 	assert.NoError(t, err)
@@ -178,10 +177,8 @@ func TestEnterTournament_UserNotFound(t *testing.T) {
 	userID := "unknown-user"
 	tID := "2024-01-02"
 	tournament := &models.Tournament{
-		TournamentID:      tID,
-		Active:            true,
-		CurrentGroupIndex: 1,
-		CurrentGroupCount: 0,
+		TournamentID: tID,
+		Active:       true,
 	}
 
 	mockDB.On("GetTournament", mock.Anything, tID).Return(tournament, nil)
@@ -201,10 +198,8 @@ func TestEnterTournament_LevelTooLow(t *testing.T) {
 	userID := "lowlevel-user"
 	tID := "2024-01-02"
 	tournament := &models.Tournament{
-		TournamentID:      tID,
-		Active:            true,
-		CurrentGroupIndex: 1,
-		CurrentGroupCount: 0,
+		TournamentID: tID,
+		Active:       true,
 	}
 	user := &models.User{
 		UserID: "lowlevel-user",
@@ -229,10 +224,8 @@ func TestEnterTournament_InsufficientCoins(t *testing.T) {
 	userID := "poor-user"
 	tID := "2024-01-02"
 	tournament := &models.Tournament{
-		TournamentID:      tID,
-		Active:            true,
-		CurrentGroupIndex: 1,
-		CurrentGroupCount: 0,
+		TournamentID: tID,
+		Active:       true,
 	}
 	user := &models.User{
 		UserID: "poor-user",
@@ -263,6 +256,7 @@ func TestUpdateScore_Success(t *testing.T) {
 		GroupID:      "group-1",
 	}
 
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Active: true}, nil)
 	mockDB.On("GetTournamentEntry", mock.Anything, tID, userID).Return(entry, nil).Once()
 	mockDB.On("UpdateTournamentScore", mock.Anything, tID, userID, 50).Return(nil).Once()
 
@@ -280,6 +274,7 @@ func TestUpdateScore_EntryNotFound(t *testing.T) {
 	tID := "2024-01-02"
 	userID := "unknown-user"
 
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Active: true}, nil)
 	mockDB.On("GetTournamentEntry", mock.Anything, tID, userID).Return((*models.TournamentEntry)(nil), nil)
 
 	_, err := service.UpdateScore(ctx, tID, userID, 10)
@@ -288,6 +283,19 @@ func TestUpdateScore_EntryNotFound(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+func TestUpdateScore_TournamentEnded(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	// Ended earlier today: its ranks are being paid out
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Active: false}, nil)
+
+	_, err := service.UpdateScore(context.Background(), tID, "user123", 10)
+	assert.Equal(t, errors.ErrTournamentNotActive, err)
+	mockDB.AssertNotCalled(t, "UpdateTournamentScore", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestClaimReward_Success(t *testing.T) {
 	mockDB := new(mocks.MockDatabase)
 	service := services.NewTournamentService(mockDB)
@@ -296,10 +304,8 @@ func TestClaimReward_Success(t *testing.T) {
 	tID := "2024-01-02"
 	userID := "user123"
 	tournament := &models.Tournament{
-		TournamentID:      tID,
-		Active:            false,
-		CurrentGroupIndex: 1,
-		CurrentGroupCount: 0,
+		TournamentID: tID,
+		Active:       false,
 	}
 	entry := &models.TournamentEntry{
 		TournamentID:  tID,
@@ -319,12 +325,40 @@ func TestClaimReward_Success(t *testing.T) {
 	mockDB.On("GetTournament", mock.Anything, tID).Return(tournament, nil)
 	mockDB.On("GetTournamentEntry", mock.Anything, tID, userID).Return(entry, nil)
 	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, "g-1").Return(topEntries, nil)
-	mockDB.On("ClaimRewardTransaction", mock.Anything, userID, 5000, tID).Return(nil)
+	mockDB.On("ClaimRewardTransaction", mock.Anything, userID, models.RewardBundle{Coins: 5000}, tID).Return(nil)
 
 	rank, reward, err := service.ClaimReward(ctx, tID, userID)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, rank)
-	assert.Equal(t, 5000, reward)
+	assert.Equal(t, models.RewardBundle{Coins: 5000}, reward)
+	mockDB.AssertExpectations(t)
+}
+
+func TestClaimReward_GrantsBundleFromRewardTable(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+	bundle := models.RewardBundle{
+		Coins:      100,
+		Lives:      2,
+		Boosters:   map[string]int{"rocket": 1},
+		Currencies: map[string]int{"gems": 5},
+		Cosmetics:  []string{"crown"},
+	}
+	service.Rewards = models.RewardTable{{MinRank: 1, MaxRank: 3, Bundle: bundle}}
+
+	tID := "2024-01-02"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID}, nil)
+	mockDB.On("GetTournamentEntry", mock.Anything, tID, "user1").Return(&models.TournamentEntry{TournamentID: tID, UserID: "user1", GroupID: "g-1"}, nil)
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, "g-1").Return([]models.TournamentEntry{
+		{UserID: "a"}, {UserID: "user1"},
+	}, nil)
+	mockDB.On("ClaimRewardTransaction", mock.Anything, "user1", bundle, tID).Return(nil)
+
+	rank, reward, err := service.ClaimReward(context.Background(), tID, "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rank)
+	assert.Equal(t, bundle, reward)
+
 	mockDB.AssertExpectations(t)
 }
 
@@ -377,6 +411,7 @@ func TestClaimReward_EntryNotFound(t *testing.T) {
 	}
 
 	mockDB.On("GetTournament", mock.Anything, tID).Return(inactiveTournament, nil)
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Active: true}, nil)
 	mockDB.On("GetTournamentEntry", mock.Anything, tID, userID).Return((*models.TournamentEntry)(nil), nil)
 
 	_, _, err := service.ClaimReward(ctx, tID, userID)
@@ -447,3 +482,366 @@ func TestClaimReward_NoRewardForRank(t *testing.T) {
 	assert.Equal(t, errors.ErrNoRewardForRank, err)
 	mockDB.AssertExpectations(t)
 }
+
+func TestClaimReward_Cancelled(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Cancelled: true}, nil)
+
+	rank, reward, err := service.ClaimReward(context.Background(), tID, "user1")
+	assert.Equal(t, errors.ErrTournamentCancelled, err)
+	assert.Zero(t, rank)
+	assert.True(t, reward.IsEmpty())
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestClaimReward_Expired(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	deadline := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, ClaimDeadline: deadline}, nil)
+
+	_, _, err := service.ClaimReward(context.Background(), tID, "user1")
+	assert.Equal(t, errors.ErrRewardExpired, err)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetPendingRewards_ListsClaimableRewards(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	userID := "user1"
+	open := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	closed := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	mockDB.On("GetUser", mock.Anything, userID).Return(&models.User{UserID: userID}, nil)
+	mockDB.On("QueryUnclaimedEntriesByUser", mock.Anything, userID, mock.AnythingOfType("string"), models.PageRequest{}).
+		Return(models.Page[models.TournamentEntry]{Items: []models.TournamentEntry{
+			{TournamentID: "2024-01-05", UserID: userID, GroupID: "g5"}, // still running
+			{TournamentID: "2024-01-04", UserID: userID, GroupID: "g4"},
+			{TournamentID: "2024-01-03", UserID: userID, GroupID: "g3"}, // outside the top 10
+			{TournamentID: "2024-01-02", UserID: userID, GroupID: "g2"}, // deadline passed
+		}}, nil)
+	mockDB.On("GetTournament", mock.Anything, "2024-01-05").Return(&models.Tournament{TournamentID: "2024-01-05", Active: true}, nil)
+	mockDB.On("GetTournament", mock.Anything, "2024-01-04").Return(&models.Tournament{TournamentID: "2024-01-04", ClaimDeadline: open}, nil)
+	mockDB.On("GetTournament", mock.Anything, "2024-01-03").Return(&models.Tournament{TournamentID: "2024-01-03", ClaimDeadline: open}, nil)
+	mockDB.On("GetTournament", mock.Anything, "2024-01-02").Return(&models.Tournament{TournamentID: "2024-01-02", ClaimDeadline: closed}, nil)
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, "g4").Return([]models.TournamentEntry{
+		{UserID: "other"}, {UserID: userID},
+	}, nil)
+	standings := make([]models.TournamentEntry, 11)
+	standings[10].UserID = userID
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, "g3").Return(standings, nil)
+
+	rewards, err := service.GetPendingRewards(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.PendingReward{
+		{TournamentID: "2024-01-04", GroupID: "g4", Rank: 2, Reward: 3000, Bundle: models.RewardBundle{Coins: 3000}, ClaimDeadline: open},
+	}, rewards)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetPendingRewards_UserNotFound(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "ghost").Return((*models.User)(nil), nil)
+
+	rewards, err := service.GetPendingRewards(context.Background(), "ghost")
+	assert.Nil(t, rewards)
+	assert.Equal(t, errors.ErrUserNotFound, err)
+}
+
+func TestClaimAllRewards_ClaimsEachTournament(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	userID := "user1"
+	mockDB.On("GetUser", mock.Anything, userID).Return(&models.User{UserID: userID}, nil)
+	mockDB.On("QueryUnclaimedEntriesByUser", mock.Anything, userID, mock.AnythingOfType("string"), models.PageRequest{}).
+		Return(models.Page[models.TournamentEntry]{Items: []models.TournamentEntry{
+			{TournamentID: "2024-01-03", UserID: userID, GroupID: "g3"},
+			{TournamentID: "2024-01-02", UserID: userID, GroupID: "g2"},
+		}}, nil)
+	mockDB.On("GetTournament", mock.Anything, mock.AnythingOfType("string")).Return(&models.Tournament{}, nil)
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, "g3").Return([]models.TournamentEntry{{UserID: userID}}, nil)
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, "g2").Return([]models.TournamentEntry{
+		{UserID: "a"}, {UserID: "b"}, {UserID: userID},
+	}, nil)
+	mockDB.On("ClaimRewardTransaction", mock.Anything, userID, models.RewardBundle{Coins: 5000}, "2024-01-03").Return(nil).Once()
+	// Claimed from another device in the meantime
+	mockDB.On("ClaimRewardTransaction", mock.Anything, userID, models.RewardBundle{Coins: 2000}, "2024-01-02").Return(errors.ErrRewardAlreadyClaimed).Once()
+
+	result, err := service.ClaimAllRewards(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ClaimAllResult{
+		UserID: userID,
+		Claims: []models.RewardClaim{
+			{TournamentID: "2024-01-03", GroupID: "g3", Rank: 1, Reward: 5000, Bundle: &models.RewardBundle{Coins: 5000}, Status: models.ClaimStatusClaimed},
+			{TournamentID: "2024-01-02", GroupID: "g2", Rank: 3, Status: models.ClaimStatusAlreadyClaimed},
+		},
+		TotalReward: 5000,
+	}, result)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestClaimAllRewards_ReturnsClaimsMadeBeforeAFailure(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	userID := "user1"
+	mockDB.On("GetUser", mock.Anything, userID).Return(&models.User{UserID: userID}, nil)
+	mockDB.On("QueryUnclaimedEntriesByUser", mock.Anything, userID, mock.AnythingOfType("string"), models.PageRequest{}).
+		Return(models.Page[models.TournamentEntry]{Items: []models.TournamentEntry{
+			{TournamentID: "2024-01-03", UserID: userID, GroupID: "g3"},
+			{TournamentID: "2024-01-02", UserID: userID, GroupID: "g2"},
+		}}, nil)
+	mockDB.On("GetTournament", mock.Anything, mock.AnythingOfType("string")).Return(&models.Tournament{}, nil)
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, mock.AnythingOfType("string")).Return([]models.TournamentEntry{{UserID: userID}}, nil)
+	mockDB.On("ClaimRewardTransaction", mock.Anything, userID, models.RewardBundle{Coins: 5000}, "2024-01-03").Return(nil).Once()
+	mockDB.On("ClaimRewardTransaction", mock.Anything, userID, models.RewardBundle{Coins: 5000}, "2024-01-02").Return(errors.ErrThrottled).Once()
+
+	result, err := service.ClaimAllRewards(context.Background(), userID)
+	assert.ErrorIs(t, err, errors.ErrThrottled)
+	if assert.NotNil(t, result) {
+		assert.Len(t, result.Claims, 1)
+		assert.Equal(t, models.ClaimStatusClaimed, result.Claims[0].Status)
+		assert.Equal(t, 5000, result.TotalReward)
+	}
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestClaimAllRewards_NothingPending(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "user1").Return(&models.User{UserID: "user1"}, nil)
+	mockDB.On("QueryUnclaimedEntriesByUser", mock.Anything, "user1", mock.AnythingOfType("string"), models.PageRequest{}).
+		Return(models.Page[models.TournamentEntry]{}, nil)
+
+	result, err := service.ClaimAllRewards(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Empty(t, result.Claims)
+	assert.Zero(t, result.TotalReward)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestExpireRewards_MarksUnclaimedEntries(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	deadline := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, ClaimDeadline: deadline}, nil)
+	mockDB.On("QueryTournamentEntries", mock.Anything, tID, models.PageRequest{}).Return(models.Page[models.TournamentEntry]{
+		Items: []models.TournamentEntry{
+			{TournamentID: tID, UserID: "claimed", ClaimedReward: true},
+			{TournamentID: tID, UserID: "expired", Expired: true},
+			{TournamentID: tID, UserID: "idle"},
+			{TournamentID: tID, UserID: "late"},
+		},
+	}, nil)
+	mockDB.On("ExpireEntryReward", mock.Anything, tID, "idle").Return(nil).Once()
+	// Claimed just before the deadline, after the entries were read
+	mockDB.On("ExpireEntryReward", mock.Anything, tID, "late").Return(errors.ErrRewardAlreadyClaimed).Once()
+
+	report, err := service.ExpireRewards(context.Background(), tID)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ExpiryReport{
+		TournamentID:   tID,
+		Entries:        4,
+		Expired:        1,
+		AlreadyExpired: 1,
+		AlreadyClaimed: 2,
+	}, report)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestExpireRewards_WindowStillOpen(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	deadline := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, ClaimDeadline: deadline}, nil)
+
+	report, err := service.ExpireRewards(context.Background(), tID)
+	assert.Nil(t, report)
+	assert.Equal(t, errors.ErrClaimWindowOpen, err)
+}
+
+func TestCancelTournament_RefundsEveryEntrant(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Active: true}, nil)
+	mockDB.On("CancelTournament", mock.Anything, tID).Return(nil).Once()
+
+	mockDB.On("QueryTournamentEntries", mock.Anything, tID, models.PageRequest{}).Return(models.Page[models.TournamentEntry]{
+		Items: []models.TournamentEntry{{TournamentID: tID, UserID: "u1"}, {TournamentID: tID, UserID: "u2"}},
+	}, nil).Once()
+	for _, u := range []string{"u1", "u2"} {
+		user := u
+		mockDB.On("RefundEntryTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+			return e.UserID == user && e.Coins == models.EntryFee && e.Type == models.HistoryEntryFeeRefund &&
+				e.TournamentID == tID && e.Actor == "ops"
+		})).Return(nil).Once()
+	}
+	mockDB.On("MarkRefundsDone", mock.Anything, tID).Return(nil).Once()
+
+	report, err := service.CancelTournament(context.Background(), tID, "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Refunded)
+	assert.Equal(t, 2*models.EntryFee, report.CoinsRefunded)
+	assert.Equal(t, 2, report.Entries)
+	assert.Equal(t, 0, report.AlreadyRefunded)
+	assert.True(t, report.Completed)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestCancelTournament_ResumesInterruptedRefunds(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	// Already cancelled by an earlier run that stopped halfway
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Cancelled: true}, nil)
+	mockDB.On("QueryTournamentEntries", mock.Anything, tID, models.PageRequest{}).Return(models.Page[models.TournamentEntry]{
+		Items: []models.TournamentEntry{{TournamentID: tID, UserID: "u1", Refunded: true}, {TournamentID: tID, UserID: "u2"}},
+	}, nil).Once()
+	mockDB.On("RefundEntryTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.UserID == "u2"
+	})).Return(nil).Once()
+	mockDB.On("MarkRefundsDone", mock.Anything, tID).Return(nil).Once()
+
+	report, err := service.CancelTournament(context.Background(), tID, "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Refunded)
+	assert.Equal(t, 1, report.AlreadyRefunded)
+	assert.True(t, report.Completed)
+
+	mockDB.AssertNotCalled(t, "CancelTournament", mock.Anything, mock.Anything)
+	mockDB.AssertExpectations(t)
+}
+
+func TestCancelTournament_StopsOnRefundError(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Cancelled: true}, nil)
+	mockDB.On("QueryTournamentEntries", mock.Anything, tID, models.PageRequest{}).Return(models.Page[models.TournamentEntry]{
+		Items: []models.TournamentEntry{{TournamentID: tID, UserID: "u1"}},
+	}, nil).Once()
+	mockDB.On("RefundEntryTransaction", mock.Anything, mock.Anything).Return(errors.ErrThrottled).Once()
+
+	report, err := service.CancelTournament(context.Background(), tID, "ops")
+	assert.ErrorIs(t, err, errors.ErrThrottled)
+	assert.False(t, report.Completed)
+
+	mockDB.AssertNotCalled(t, "MarkRefundsDone", mock.Anything, mock.Anything)
+}
+
+func TestCancelTournament_AlreadyCompleted(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Cancelled: true, RefundsDone: true}, nil)
+
+	report, err := service.CancelTournament(context.Background(), tID, "ops")
+	assert.Nil(t, report)
+	assert.Equal(t, errors.ErrTournamentCancelled, err)
+}
+
+func TestSettleTournament_PaysUnclaimedRewards(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	group := tID + "-group-0-1"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID}, nil)
+
+	// Two pages of entries; the group's standings are queried once
+	mockDB.On("QueryTournamentEntries", mock.Anything, tID, models.PageRequest{}).Return(models.Page[models.TournamentEntry]{
+		Items: []models.TournamentEntry{
+			{TournamentID: tID, UserID: "first", GroupID: group, Score: 30},
+			{TournamentID: tID, UserID: "second", GroupID: group, Score: 20, ClaimedReward: true},
+		},
+		NextCursor: "next",
+	}, nil).Once()
+	mockDB.On("QueryTournamentEntries", mock.Anything, tID, models.PageRequest{Cursor: "next"}).Return(models.Page[models.TournamentEntry]{
+		Items: []models.TournamentEntry{
+			{TournamentID: tID, UserID: "third", GroupID: group, Score: 10},
+		},
+	}, nil).Once()
+	mockDB.On("QueryTournamentEntriesByGroupScore", mock.Anything, group).Return([]models.TournamentEntry{
+		{UserID: "first", Score: 30}, {UserID: "second", Score: 20}, {UserID: "third", Score: 10},
+	}, nil).Once()
+
+	mockDB.On("ClaimRewardTransaction", mock.Anything, "first", models.RewardBundle{Coins: 5000}, tID).Return(nil).Once()
+	// Claimed by the user between the query and the settlement
+	mockDB.On("ClaimRewardTransaction", mock.Anything, "third", models.RewardBundle{Coins: 2000}, tID).Return(errors.ErrRewardAlreadyClaimed).Once()
+
+	report, err := service.SettleTournament(context.Background(), tID)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.SettlementReport{
+		TournamentID:   tID,
+		Entries:        3,
+		Paid:           1,
+		CoinsPaid:      5000,
+		AlreadyClaimed: 2,
+	}, report)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestSettleTournament_StillActive(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Active: true}, nil)
+
+	report, err := service.SettleTournament(context.Background(), tID)
+	assert.Nil(t, report)
+	assert.Equal(t, errors.ErrTournamentStillActive, err)
+}
+
+func TestCancelTournament_DoesNotRefundPaidRewards(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	service := services.NewTournamentService(mockDB)
+
+	tID := "2024-01-02"
+	mockDB.On("GetTournament", mock.Anything, tID).Return(&models.Tournament{TournamentID: tID, Cancelled: true}, nil)
+	mockDB.On("QueryTournamentEntries", mock.Anything, tID, models.PageRequest{}).Return(models.Page[models.TournamentEntry]{
+		Items: []models.TournamentEntry{
+			{TournamentID: tID, UserID: "winner", ClaimedReward: true},
+			{TournamentID: tID, UserID: "racer"},
+		},
+	}, nil)
+	// Claimed between the read and the refund
+	mockDB.On("RefundEntryTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.UserID == "racer"
+	})).Return(errors.ErrRewardAlreadyClaimed).Once()
+	mockDB.On("MarkRefundsDone", mock.Anything, tID).Return(nil).Once()
+
+	report, err := service.CancelTournament(context.Background(), tID, "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Refunded)
+	assert.Equal(t, 2, report.RewardClaimed)
+	assert.True(t, report.Completed)
+	mockDB.AssertExpectations(t)
+}
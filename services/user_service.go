@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
 	"good_blast/database"
 	"good_blast/errors"
@@ -15,7 +16,13 @@ import (
 
 // UserService implements UserServiceInterface.
 type UserService struct {
-	DB database.DatabaseInterface
+	DB           database.DatabaseInterface
+	Leaderboards LeaderboardInvalidator // optional; notified when a user's level changes
+	Catalog      *models.LevelCatalog   // what each level pays; nil means models.DefaultLevelCatalog
+	Quests       QuestTracker           // optional; counts cleared levels towards daily quests
+	Achievements AchievementTracker     // optional; evaluates achievements after progress
+	Seasons      SeasonTracker          // optional; grants season pass XP for cleared levels
+	Clans        ClanTracker            // optional; counts the new level in the user's clan total
 }
 
 // NewUserService creates a new instance of UserService.
@@ -25,6 +32,13 @@ func NewUserService(db database.DatabaseInterface) *UserService {
 	}
 }
 
+func (s *UserService) catalog() *models.LevelCatalog {
+	if s.Catalog != nil {
+		return s.Catalog
+	}
+	return models.DefaultLevelCatalog
+}
+
 // CreateUser handles user creation logic.
 func (s *UserService) CreateUser(ctx context.Context, username, country string) (*models.User, error) {
 	// Generate a unique userId
@@ -62,7 +76,8 @@ func (s *UserService) GetUser(ctx context.Context, userID string) (*models.User,
 	return user, nil
 }
 
-// UpdateUserProgress updates the user's level and coins based on progress.
+// UpdateUserProgress updates the user's level and pays the level catalog's reward for every
+// level cleared on the way.
 func (s *UserService) UpdateUserProgress(ctx context.Context, userID string, newLevel int) (*models.User, error) {
 	// Fetch current user data
 	user, err := s.DB.GetUser(ctx, userID)
@@ -80,15 +95,15 @@ func (s *UserService) UpdateUserProgress(ctx context.Context, userID string, new
 		return nil, errors.ErrInvalidLevelIncrease
 	}
 
-	// Calculate coins gained
-	levelIncrement := newLevel - user.Level
-	coinsGained := levelIncrement * 100
-
-	newCoins := user.Coins + coinsGained
+	// Every level from the current one up to newLevel is cleared for the first time
+	reward := s.catalog().ClearReward(user.Level, newLevel)
 
 	// Update user in DynamoDB
-	if err := s.DB.UpdateUserCoinsAndLevel(ctx, userID, newLevel, newCoins); err != nil {
+	if err := s.DB.AdvanceLevel(ctx, userID, user.Level, newLevel, reward); err != nil {
 		log.Println("Error updating user progress:", err)
+		if err == errors.ErrUserNotFound {
+			return nil, err
+		}
 		return nil, fmt.Errorf("could not update user progress: %w", err)
 	}
 
@@ -99,5 +114,101 @@ func (s *UserService) UpdateUserProgress(ctx context.Context, userID string, new
 		return nil, fmt.Errorf("could not fetch updated user data: %w", err)
 	}
 
+	// Rankings changed; stop serving the stale cached leaderboards
+	if s.Leaderboards != nil && updatedUser != nil {
+		s.Leaderboards.UserLevelChanged(ctx, *updatedUser)
+	}
+	if s.Quests != nil {
+		s.Quests.Record(ctx, userID, models.QuestEvent{Type: models.QuestEventLevelCleared, Count: newLevel - user.Level})
+	}
+	if s.Achievements != nil && updatedUser != nil {
+		s.Achievements.LevelReached(ctx, *updatedUser, user.Level, reward)
+	}
+	if s.Seasons != nil {
+		s.Seasons.LevelsCleared(ctx, userID, newLevel-user.Level)
+	}
+	if s.Clans != nil && updatedUser != nil {
+		s.Clans.MemberLevelChanged(ctx, *updatedUser)
+	}
+
 	return updatedUser, nil
 }
+
+// AdjustCoins adds delta (which may be negative) to a user's coins on behalf of an operator
+// and records the change with its reason in the user's history.
+func (s *UserService) AdjustCoins(ctx context.Context, userID string, delta int, reason, actor string) (*models.User, error) {
+	if delta == 0 || strings.TrimSpace(reason) == "" {
+		return nil, errors.ErrInvalidCoinAdjustment
+	}
+
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching user:", err)
+		return nil, fmt.Errorf("could not fetch user data: %w", err)
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	entry := models.HistoryEntry{
+		UserID: userID,
+		Type:   models.HistoryCoinAdjustment,
+		Coins:  delta,
+		Reason: reason,
+		Actor:  actor,
+	}
+	if err := s.DB.AdjustCoinsTransaction(ctx, entry); err != nil {
+		log.Println("Error adjusting coins:", err)
+		return nil, err
+	}
+
+	updatedUser, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching updated user:", err)
+		return nil, fmt.Errorf("could not fetch updated user data: %w", err)
+	}
+	return updatedUser, nil
+}
+
+// GrantReward gives a user a reward bundle on behalf of an operator, applying the whole bundle
+// atomically, and records it with its reason in the user's history.
+func (s *UserService) GrantReward(ctx context.Context, userID string, bundle models.RewardBundle, reason, actor string) (*models.User, error) {
+	if bundle.IsEmpty() || bundle.Validate() != nil || strings.TrimSpace(reason) == "" {
+		return nil, errors.ErrInvalidRewardGrant
+	}
+
+	entry := models.HistoryEntry{
+		UserID: userID,
+		Type:   models.HistoryRewardGrant,
+		Coins:  bundle.Coins,
+		Reason: reason,
+		Actor:  actor,
+	}
+	// Coins are recorded in entry.Coins like any other balance change; the bundle keeps the rest
+	items := bundle
+	items.Coins = 0
+	if !items.IsEmpty() {
+		entry.Bundle = &items
+	}
+	if err := s.DB.GrantRewardTransaction(ctx, entry); err != nil {
+		log.Println("Error granting reward:", err)
+		return nil, err
+	}
+
+	user, err := s.DB.GetUser(ctx, userID)
+	if err != nil {
+		log.Println("Error fetching updated user:", err)
+		return nil, fmt.Errorf("could not fetch updated user data: %w", err)
+	}
+	return user, nil
+}
+
+// GetUserHistory returns one page of a user's balance history, newest first.
+func (s *UserService) GetUserHistory(ctx context.Context, userID string, page models.PageRequest) (models.Page[models.HistoryEntry], error) {
+	history, err := s.DB.QueryUserHistory(ctx, userID, page)
+	if err != nil && err != errors.ErrInvalidCursor {
+		log.Println("Error fetching user history:", err)
+		return history, fmt.Errorf("could not fetch user history: %w", err)
+	}
+	return history, err
+}
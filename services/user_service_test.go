@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	apperrors "good_blast/errors"
 	"good_blast/models"
 	"good_blast/services"
 	"good_blast/services/mocks"
@@ -113,7 +114,7 @@ func TestUpdateUserProgress_Success(t *testing.T) {
 
 	// Mock database calls:
 	mockDB.On("GetUser", mock.Anything, userId).Return(currentUser, nil).Once()
-	mockDB.On("UpdateUserCoinsAndLevel", mock.Anything, userId, newLevel, expectedCoins).Return(nil).Once()
+	mockDB.On("AdvanceLevel", mock.Anything, userId, currentUser.Level, newLevel, models.RewardBundle{Coins: coinsGained}).Return(nil).Once()
 
 	updatedUser := &models.User{
 		UserID:   userId,
@@ -136,6 +137,46 @@ func TestUpdateUserProgress_Success(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+func TestUpdateUserProgress_PaysLevelCatalog(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	userService := services.NewUserService(mockDB)
+	userService.Catalog = &models.LevelCatalog{
+		Version: "v2",
+		Default: models.LevelDefinition{Difficulty: models.DifficultyNormal, FirstClearBonus: models.RewardBundle{Coins: 50}},
+		Levels: []models.LevelDefinition{
+			{Level: 10, Difficulty: models.DifficultySuperHard, CoinReward: 20, FirstClearBonus: models.RewardBundle{Coins: 300, Boosters: map[string]int{"rocket": 1}}},
+		},
+		Milestones: []models.LevelMilestone{{Every: 10, Bundle: models.RewardBundle{Lives: 1}}},
+	}
+	ctx := context.Background()
+
+	// Clearing 9, 10 and 11: 50 + (20 + 300 and a rocket) + 50, and the milestone for 10
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 9, Coins: 1000}, nil).Once()
+	mockDB.On("AdvanceLevel", mock.Anything, "u1", 9, 12, models.RewardBundle{
+		Coins:    420,
+		Lives:    1,
+		Boosters: map[string]int{"rocket": 1},
+	}).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 12, Coins: 1420}, nil).Once()
+
+	user, err := userService.UpdateUserProgress(ctx, "u1", 12)
+	assert.NoError(t, err)
+	assert.Equal(t, 1420, user.Coins)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateUserProgress_ConcurrentProgress(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	userService := services.NewUserService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Level: 4, Coins: 1000}, nil).Once()
+	mockDB.On("AdvanceLevel", mock.Anything, "u1", 4, 5, mock.Anything).Return(apperrors.ErrTransactionConflict).Once()
+
+	_, err := userService.UpdateUserProgress(context.Background(), "u1", 5)
+	assert.ErrorIs(t, err, apperrors.ErrTransactionConflict)
+	mockDB.AssertExpectations(t)
+}
+
 func TestUpdateUserProgress_InvalidLevel(t *testing.T) {
 	// Arrange
 	mockDB := new(mocks.MockDatabase)
@@ -209,3 +250,87 @@ func TestCreateUser_DBError(t *testing.T) {
 	assert.Contains(t, err.Error(), "could not create user")
 	mockDB.AssertExpectations(t)
 }
+
+func TestAdjustCoins_RecordsHistory(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	userService := services.NewUserService(mockDB)
+	ctx := context.Background()
+
+	before := &models.User{UserID: "u1", Coins: 100}
+	after := &models.User{UserID: "u1", Coins: 600}
+	mockDB.On("GetUser", mock.Anything, "u1").Return(before, nil).Once()
+	mockDB.On("AdjustCoinsTransaction", mock.Anything, mock.MatchedBy(func(e models.HistoryEntry) bool {
+		return e.UserID == "u1" && e.Coins == 500 && e.Type == models.HistoryCoinAdjustment &&
+			e.Reason == "compensation for outage" && e.Actor == "ops"
+	})).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(after, nil).Once()
+
+	user, err := userService.AdjustCoins(ctx, "u1", 500, "compensation for outage", "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, 600, user.Coins)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestAdjustCoins_RequiresAmountAndReason(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	userService := services.NewUserService(mockDB)
+
+	_, err := userService.AdjustCoins(context.Background(), "u1", 0, "nothing", "ops")
+	assert.Equal(t, apperrors.ErrInvalidCoinAdjustment, err)
+	_, err = userService.AdjustCoins(context.Background(), "u1", 10, "  ", "ops")
+	assert.Equal(t, apperrors.ErrInvalidCoinAdjustment, err)
+
+	mockDB.AssertNotCalled(t, "AdjustCoinsTransaction", mock.Anything, mock.Anything)
+}
+
+func TestAdjustCoins_NegativeBalance(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	userService := services.NewUserService(mockDB)
+
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Coins: 100}, nil).Once()
+	mockDB.On("AdjustCoinsTransaction", mock.Anything, mock.Anything).Return(apperrors.ErrNegativeBalance).Once()
+
+	user, err := userService.AdjustCoins(context.Background(), "u1", -500, "chargeback", "ops")
+	assert.Nil(t, user)
+	assert.Equal(t, apperrors.ErrNegativeBalance, err)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestGrantReward_RecordsBundleInHistory(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	userService := services.NewUserService(mockDB)
+
+	bundle := models.RewardBundle{Coins: 100, Boosters: map[string]int{"rocket": 2}}
+	mockDB.On("GrantRewardTransaction", mock.Anything, models.HistoryEntry{
+		UserID: "u1",
+		Type:   models.HistoryRewardGrant,
+		Coins:  100,
+		Reason: "event prize",
+		Actor:  "ops",
+		Bundle: &models.RewardBundle{Boosters: map[string]int{"rocket": 2}},
+	}).Return(nil).Once()
+	mockDB.On("GetUser", mock.Anything, "u1").Return(&models.User{UserID: "u1", Coins: 1100}, nil).Once()
+
+	user, err := userService.GrantReward(context.Background(), "u1", bundle, "event prize", "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, 1100, user.Coins)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestGrantReward_RejectsInvalidBundles(t *testing.T) {
+	mockDB := new(mocks.MockDatabase)
+	userService := services.NewUserService(mockDB)
+	ctx := context.Background()
+
+	_, err := userService.GrantReward(ctx, "u1", models.RewardBundle{}, "nothing", "ops")
+	assert.Equal(t, apperrors.ErrInvalidRewardGrant, err)
+	_, err = userService.GrantReward(ctx, "u1", models.RewardBundle{Boosters: map[string]int{"rocket": -1}}, "oops", "ops")
+	assert.Equal(t, apperrors.ErrInvalidRewardGrant, err)
+	_, err = userService.GrantReward(ctx, "u1", models.RewardBundle{Lives: 1}, " ", "ops")
+	assert.Equal(t, apperrors.ErrInvalidRewardGrant, err)
+
+	mockDB.AssertNotCalled(t, "GrantRewardTransaction", mock.Anything, mock.Anything)
+}
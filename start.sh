@@ -6,5 +6,5 @@ redis-server --daemonize yes --dir /data
 # Start cron in the background
 cron
 
-# Start the Go application
-run-app
+# Start the Go application (exec so it receives SIGTERM directly and can shut down gracefully)
+exec run-app